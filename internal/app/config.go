@@ -1,7 +1,17 @@
 package app
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/history"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/keymap"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/logging"
 	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/session"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/theme"
 )
 
 type Config struct {
@@ -9,6 +19,22 @@ type Config struct {
 	DebugMode       bool
 	ForceLiveMode   bool
 	IsTestMode      bool
+
+	// LogLevel, LogFormat and LogFile configure the logger. An empty LogFile
+	// keeps log entries in memory only.
+	LogLevel  string
+	LogFormat string
+	LogFile   string
+
+	// CommandTimeout bounds each hyprctl or wlr-randr call; zero means
+	// runner.DefaultTimeout.
+	CommandTimeout time.Duration
+
+	// KeyMap is the TUI's key bindings; nil means keymap.Default().
+	KeyMap *keymap.KeyMap
+
+	// Theme is the TUI's palette; nil means theme.Terminal().
+	Theme *theme.Palette
 }
 
 type Services struct {
@@ -16,6 +42,11 @@ type Services struct {
 	MonitorDetector monitor.DetectorInterface
 	ScalingManager  monitor.ScalingManagerInterface
 	ConfigManager   monitor.ConfigManagerInterface
+	History         *history.Journal
+	Logger          *logging.Logger
+	Mode            Mode
+
+	sandbox *simulate.Sandbox
 }
 
 type MonitorDetectorInterface interface {
@@ -24,20 +55,81 @@ type MonitorDetectorInterface interface {
 
 type ScalingManagerInterface interface {
 	GetIntelligentScalingOptions(monitor monitor.Monitor) []monitor.ScalingOption
+	GetRecommendedScale(monitor monitor.Monitor) float64
 }
 
 type ConfigManagerInterface interface {
 	ApplyMonitorScale(monitor monitor.Monitor, scale float64) error
 	ApplyGTKScale(scale int) error
 	ApplyFontDPI(dpi int) error
+	ApplyFontScale(scale float64) error
+	ApplyCursorSize(size int) error
 	ApplyCompleteScalingOption(monitor monitor.Monitor, option monitor.ScalingOption) error
+	ApplyTransaction(tx monitor.Transaction) (monitor.Verification, error)
+	PlanScalingOption(monitor monitor.Monitor, option monitor.ScalingOption) (monitor.Plan, error)
+	PlanTransaction(tx monitor.Transaction) (monitor.Plan, error)
+	PlanWaybar(fontScale float64) (monitor.Plan, error)
+	Execute(plan monitor.Plan) (monitor.Verification, error)
+	ManagedFiles() []string
+	CurrentFontScale() (float64, error)
+	CurrentCursorSize() (int, error)
 }
 
 func NewServices(config *Config) *Services {
+	logger := newLogger(config)
+
+	mode := ResolveMode(config, session.DefaultProbe().Detect)
+	logger.Info("resolved mode", "session", mode.Session.String(), "probed", mode.Probed,
+		"backend", string(mode.Backend), "demo", mode.Demo, "reason", mode.Reason)
+
+	timeout := config.CommandTimeout
+	if timeout <= 0 {
+		timeout = runner.DefaultTimeout
+	}
+	commands := runner.NewExec(timeout)
+
+	detector := monitor.NewDetector()
+	if mode.Probed {
+		detector = monitor.NewDetectorForBackend(mode.Backend)
+	}
+	detector.SetLogger(logger.Logger)
+	detector.SetRunner(commands)
+	configManager := monitor.NewConfigManager(config.IsTestMode)
+	configManager.SetLogger(logger.Logger)
+	configManager.SetRunner(commands)
+
 	return &Services{
 		Config:          config,
-		MonitorDetector: monitor.NewDetector(),
+		MonitorDetector: detector,
 		ScalingManager:  monitor.NewScalingManager(),
-		ConfigManager:   monitor.NewConfigManager(config.IsTestMode),
+		ConfigManager:   configManager,
+		History:         history.NewDefaultJournal(),
+		Logger:          logger,
+		Mode:            mode,
+	}
+}
+
+// newLogger opens the configured log file. If that fails, entries are still
+// kept in memory for the TUI log viewer, starting with the reason.
+func newLogger(config *Config) *logging.Logger {
+	level := config.LogLevel
+	if level == "" && config.DebugMode {
+		level = "debug"
+	}
+
+	logger, err := logging.Open(logging.Options{Level: level, Format: config.LogFormat, Path: config.LogFile})
+	if err != nil {
+		logger = logging.Discard()
+		logger.Warn("logging to memory only", "error", err)
+	}
+	return logger
+}
+
+// Close releases the log file and removes any simulation sandbox.
+func (s *Services) Close() error {
+	if err := s.sandbox.Close(); err != nil {
+		s.Logger.Close()
+		return fmt.Errorf("failed to remove sandbox: %w", err)
 	}
+	return s.Logger.Close()
 }
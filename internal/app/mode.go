@@ -0,0 +1,60 @@
+package app
+
+import (
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/session"
+)
+
+// Mode is how the application runs: what session it is in, how monitors are
+// detected, and whether changes are applied for real or only simulated.
+type Mode struct {
+	Session session.Session
+	// Probed is false when the environment check was skipped; monitors are
+	// then detected with whatever tool is installed.
+	Probed  bool
+	Backend session.Backend
+	// Demo simulates changes instead of applying them. The TUI also falls
+	// back to demo mode when detection fails, unless live mode is forced.
+	Demo bool
+	// Simulation names the scenario being simulated, if any; see
+	// NewSimulatedServices.
+	Simulation string
+	Reason     string
+}
+
+// ResolveMode maps the command-line flags and the probed session onto a
+// mode:
+//
+//   - By default the session is probed. Hyprland runs live with hyprctl;
+//     Sway and other wlroots compositors show real monitors through
+//     wlr-randr in demo mode; GNOME, KDE, X11 and TTYs use demo monitors.
+//   - --no-hyprland-check skips the probe: monitors are detected with
+//     hyprctl or wlr-randr, whichever exists, and the TUI runs live unless
+//     detection fails.
+//   - --force-live never falls back to demo mode, whatever the session or
+//     detection result.
+//
+// --debug only affects logging.
+func ResolveMode(config *Config, probe func() session.Session) Mode {
+	var mode Mode
+
+	if config.NoHyprlandCheck {
+		mode.Reason = "environment check skipped"
+	} else {
+		mode.Session = probe()
+		mode.Probed = true
+		mode.Backend = mode.Session.Backend()
+		if mode.Session.CanApply() {
+			mode.Reason = mode.Session.Kind.String() + " session"
+		} else {
+			mode.Demo = true
+			mode.Reason = mode.Session.Kind.String() + " session; applying changes needs Hyprland"
+		}
+	}
+
+	if config.ForceLiveMode {
+		mode.Demo = false
+		mode.Reason += "; live mode forced"
+	}
+
+	return mode
+}
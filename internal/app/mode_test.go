@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/session"
+)
+
+func TestResolveMode(t *testing.T) {
+	sessions := []struct {
+		kind    session.Kind
+		backend session.Backend
+	}{
+		{session.Hyprland, session.BackendHyprctl},
+		{session.Sway, session.BackendWlrRandr},
+		{session.Wlroots, session.BackendWlrRandr},
+		{session.GNOME, session.BackendNone},
+		{session.KDE, session.BackendNone},
+		{session.X11, session.BackendNone},
+		{session.TTY, session.BackendNone},
+	}
+
+	for _, s := range sessions {
+		for _, noCheck := range []bool{false, true} {
+			for _, forceLive := range []bool{false, true} {
+				for _, debug := range []bool{false, true} {
+					name := fmt.Sprintf("%s/no-check=%v/force-live=%v/debug=%v", s.kind, noCheck, forceLive, debug)
+					t.Run(name, func(t *testing.T) {
+						probed := false
+						config := &Config{NoHyprlandCheck: noCheck, ForceLiveMode: forceLive, DebugMode: debug}
+						mode := ResolveMode(config, func() session.Session {
+							probed = true
+							return session.Session{Kind: s.kind}
+						})
+
+						if probed == noCheck || mode.Probed == noCheck {
+							t.Errorf("Expected the probe to run only without --no-hyprland-check (ran: %v)", probed)
+						}
+
+						wantBackend := s.backend
+						if noCheck {
+							wantBackend = session.BackendNone
+						}
+						if mode.Backend != wantBackend {
+							t.Errorf("Expected backend %q, got %q", wantBackend, mode.Backend)
+						}
+
+						wantDemo := !noCheck && !forceLive && s.kind != session.Hyprland
+						if mode.Demo != wantDemo {
+							t.Errorf("Expected demo=%v, got %v (%s)", wantDemo, mode.Demo, mode.Reason)
+						}
+						if mode.Reason == "" {
+							t.Error("Expected a reason")
+						}
+					})
+				}
+			}
+		}
+	}
+}
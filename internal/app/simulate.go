@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/cursor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/history"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/terminal"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/waybar"
+)
+
+// NewSimulatedServices runs against a simulated compositor instead of the
+// real system: monitors come from the scenario, applies change the
+// simulation, and config files and history live in the sandbox. Closing the
+// services removes the sandbox.
+func NewSimulatedServices(config *Config, sandbox *simulate.Sandbox) *Services {
+	logger := newLogger(config)
+
+	compositor := sandbox.Compositor
+	mode := Mode{
+		Backend:    "simulated",
+		Simulation: sandbox.Scenario.Name,
+		Reason:     "simulating scenario " + sandbox.Scenario.Name,
+	}
+	logger.Info("resolved mode", "simulation", mode.Simulation, "sandbox", sandbox.Dir, "reason", mode.Reason)
+
+	bar := waybar.NewManager(sandbox.ConfigHome(), sandbox.StateDir(), func() error {
+		_, err := compositor.Run(context.Background(), waybar.ReloadCommand[0], waybar.ReloadCommand[1:]...)
+		return err
+	})
+	configManager := monitor.NewConfigManagerWithAdapters(config.IsTestMode,
+		terminal.NewManager(sandbox.ConfigHome(), sandbox.StateDir()),
+		cursor.NewManager(sandbox.ConfigHome(), sandbox.HomeDir(), nil, func(string) string { return "" }),
+		bar)
+	configManager.SetLogger(logger.Logger)
+	configManager.UseBackend(compositor, compositor.DetectMonitors)
+
+	return &Services{
+		Config:          config,
+		MonitorDetector: compositor,
+		ScalingManager:  monitor.NewScalingManager(),
+		ConfigManager:   configManager,
+		History:         history.NewJournal(filepath.Join(sandbox.StateDir(), "history"), history.DefaultRetention),
+		Logger:          logger,
+		Mode:            mode,
+		sandbox:         sandbox,
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ApplyOptions selects the monitor and scaling to apply. A non-zero Scale
+// switches to manual scaling; otherwise Option picks a smart scaling option
+// by its 1-based position, falling back to the recommended one.
+type ApplyOptions struct {
+	Monitor  string
+	Option   int
+	Scale    float64
+	GTKScale int
+	FontDPI  int
+	Reason   string
+	DryRun   bool
+}
+
+// NewApplyCommand builds the "apply" command, which applies a scaling option
+// without the TUI. With --dry-run it prints the exact plan instead.
+func NewApplyCommand(newServices func() *app.Services) *cobra.Command {
+	var opts ApplyOptions
+
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply a scaling option, or show exactly what it would do with --dry-run",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runApply(cmd.OutOrStdout(), newServices(), opts)
+		},
+	}
+
+	applyCmd.Flags().StringVar(&opts.Monitor, "monitor", "", "Monitor to configure, or \"focused\" (defaults to the first detected monitor)")
+	applyCmd.Flags().IntVar(&opts.Option, "option", 0, "Smart scaling option number (defaults to the recommended option)")
+	applyCmd.Flags().Float64Var(&opts.Scale, "scale", 0, "Manual monitor scale; overrides --option")
+	applyCmd.Flags().IntVar(&opts.GTKScale, "gtk-scale", 1, "Manual GTK scale, used with --scale")
+	applyCmd.Flags().IntVar(&opts.FontDPI, "font-dpi", 96, "Manual font DPI, used with --scale")
+	applyCmd.Flags().StringVar(&opts.Reason, "reason", "", "Why the change is being made, recorded in history")
+	applyCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the commands, environment changes and file diffs without applying them")
+
+	return applyCmd
+}
+
+func runApply(out io.Writer, services *app.Services, opts ApplyOptions) error {
+	monitors, err := services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return fmt.Errorf("failed to detect monitors: %w", err)
+	}
+
+	target, err := service.SelectMonitor(monitors, opts.Monitor)
+	if err != nil {
+		return err
+	}
+
+	option, err := selectOption(services, target, opts)
+	if err != nil {
+		return err
+	}
+
+	plan, err := services.ConfigManager.PlanScalingOption(target, option)
+	if err != nil {
+		return fmt.Errorf("failed to plan changes: %w", err)
+	}
+
+	if opts.DryRun {
+		fmt.Fprint(out, plan.String())
+		return nil
+	}
+
+	verification, err := service.Execute(services, "cli", monitors, plan, opts.Reason)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Applied %s to %s.\n", option.DisplayName, target.Name)
+	reportDrift(out, verification)
+	return nil
+}
+
+// reportDrift explains anything the compositor did differently from what was
+// requested and how to get the nearest valid scale instead.
+func reportDrift(out io.Writer, verification monitor.Verification) {
+	for _, drift := range verification.Drift {
+		fmt.Fprintf(out, "Warning: %s\n", drift)
+	}
+	for _, drift := range verification.ScaleDrift() {
+		nearest := utils.NearestValidScale(drift.Target.Scale, drift.Current.Width, drift.Current.Height, types.ValidHyprlandScales)
+		fmt.Fprintf(out, "The nearest valid scale for %s is %.2f; run 'apply --monitor %s --scale %g' to use it.\n",
+			drift.Current.Name, nearest, drift.Current.Name, nearest)
+	}
+}
+
+func selectOption(services *app.Services, target monitor.Monitor, opts ApplyOptions) (monitor.ScalingOption, error) {
+	if opts.Scale > 0 {
+		return monitor.ScalingOption{
+			MonitorScale: opts.Scale,
+			GTKScale:     opts.GTKScale,
+			FontDPI:      opts.FontDPI,
+			DisplayName:  "Manual Settings",
+			Description:  "Custom scaling values",
+		}, nil
+	}
+
+	option, err := service.SelectOption(services.ScalingManager.GetIntelligentScalingOptions(target), opts.Option)
+	if err != nil {
+		return monitor.ScalingOption{}, fmt.Errorf("%s: %w", target.Name, err)
+	}
+	return option, nil
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+)
+
+func runApplyCommand(t *testing.T, services *app.Services, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewApplyCommand(func() *app.Services { return services })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestApplyDryRun(t *testing.T) {
+	monitors := []monitor.Monitor{
+		{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 1.0},
+		{Name: "DP-1", Width: 1920, Height: 1080, Scale: 1.0},
+	}
+	services, configManager := newTestServices(t, monitors)
+
+	out, err := runApplyCommand(t, services, "--dry-run", "--monitor", "DP-1", "--scale", "1.25")
+	if err != nil {
+		t.Fatalf("apply --dry-run failed: %v", err)
+	}
+	for _, expected := range []string{"Plan: Apply Manual Settings to DP-1", "$ hyprctl keyword monitor DP-1,preferred,auto,1.25000"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+	if len(configManager.executed) != 0 {
+		t.Error("Dry run must not execute the plan")
+	}
+	if entries, _ := services.History.List(); len(entries) != 0 {
+		t.Error("Dry run must not be journaled")
+	}
+}
+
+func TestApplyRecommended(t *testing.T) {
+	monitors := []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 1.0}}
+	services, configManager := newTestServices(t, monitors)
+
+	var recommended monitor.ScalingOption
+	for _, option := range services.ScalingManager.GetIntelligentScalingOptions(monitors[0]) {
+		if option.IsRecommended {
+			recommended = option
+		}
+	}
+
+	out, err := runApplyCommand(t, services, "--reason", "Text too small")
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if !strings.Contains(out, "Applied "+recommended.DisplayName+" to eDP-1") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+	if len(configManager.executed) != 1 {
+		t.Fatalf("Expected the plan to be executed once, got %d", len(configManager.executed))
+	}
+
+	entries, err := services.History.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Reason != "Text too small" || entries[0].Source != "cli" {
+		t.Errorf("Expected the apply to be journaled first, got %+v", entries)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	monitors := []monitor.Monitor{{Name: "eDP-1", Width: 1920, Height: 1080, Scale: 1.0}}
+
+	for _, args := range [][]string{
+		{"--monitor", "HDMI-A-1"},
+		{"--option", "99"},
+	} {
+		services, configManager := newTestServices(t, monitors)
+		if _, err := runApplyCommand(t, services, args...); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+		if len(configManager.executed) != 0 {
+			t.Errorf("Nothing should be executed for %v", args)
+		}
+	}
+}
+
+func TestApplyReportsDrift(t *testing.T) {
+	monitors := []monitor.Monitor{{Name: "eDP-1", Width: 2256, Height: 1504, Scale: 1.0}}
+	services, configManager := newTestServices(t, monitors)
+	adjusted := monitor.Monitor{Name: "eDP-1", Width: 2256, Height: 1504, Scale: 1.33}
+	configManager.verification = monitor.Verification{
+		Monitors: []monitor.Monitor{adjusted},
+		Drift: []monitor.Drift{{
+			Property:  monitor.DriftScale,
+			Requested: "1.3",
+			Actual:    "1.33",
+			Target:    monitor.MonitorTarget{Monitor: monitors[0], Scale: 1.3},
+			Current:   adjusted,
+		}},
+	}
+
+	out, err := runApplyCommand(t, services, "--scale", "1.3")
+	if err != nil {
+		t.Fatalf("Drift must not fail the apply, got %v", err)
+	}
+	for _, expected := range []string{
+		"Warning: eDP-1 scale: requested 1.3, got 1.33",
+		"The nearest valid scale for eDP-1 is 1.33",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestApplySimulated(t *testing.T) {
+	services, sandbox := simtest.Services(t, "6k-laptop")
+
+	out, err := runApplyCommand(t, services, "--monitor", "eDP-1", "--scale", "1.5")
+	if err != nil {
+		t.Fatalf("apply failed: %v\n%s", err, out)
+	}
+	monitors, _ := sandbox.Compositor.DetectMonitors()
+	if monitors[0].Name != "eDP-1" || monitors[0].Scale != 1.5 {
+		t.Errorf("Expected the simulated eDP-1 at x1.5, got %+v", monitors[0])
+	}
+	if entries, _ := services.History.List(); len(entries) != 1 {
+		t.Errorf("Expected the apply to be journaled in the sandbox, got %d entries", len(entries))
+	}
+
+	// 1.5 doesn't divide 6016x3384, so the simulated compositor adjusts it
+	out, err = runApplyCommand(t, services, "--monitor", "DP-1", "--scale", "1.5")
+	if err != nil {
+		t.Fatalf("apply failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Warning: DP-1 scale: requested 1.5, got 1") {
+		t.Errorf("Expected drift to be reported, got:\n%s", out)
+	}
+}
+
+func TestApplySimulatedFailureRollsBack(t *testing.T) {
+	services, sandbox := simtest.Services(t, "apply-fails")
+
+	out, err := runApplyCommand(t, services, "--monitor", "DP-2", "--scale", "1.5")
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("Expected the apply to fail and roll back, got %v\n%s", err, out)
+	}
+
+	monitors, _ := sandbox.Compositor.DetectMonitors()
+	if monitors[1].Name != "DP-2" || monitors[1].Scale != 2 {
+		t.Errorf("Expected DP-2 to stay at x2, got %+v", monitors[1])
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+const defaultBundlePath = "omarchy-monitor-settings-doctor.tar.gz"
+
+// NewDoctorCommand builds the "doctor" command, which checks the display
+// environment and reports what to fix. With --bundle it also writes a
+// redacted tarball to attach to bug reports.
+func NewDoctorCommand(newServices func() *app.Services) *cobra.Command {
+	var bundle string
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the display environment and suggest fixes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDoctor(cmd.OutOrStdout(), newServices(), doctor.DefaultEnvironment(), bundle, time.Now())
+		},
+	}
+
+	doctorCmd.Flags().StringVar(&bundle, "bundle", "", "Write a redacted tarball for bug reports (default "+defaultBundlePath+")")
+	doctorCmd.Flags().Lookup("bundle").NoOptDefVal = defaultBundlePath
+
+	return doctorCmd
+}
+
+func runDoctor(out io.Writer, services *app.Services, env doctor.Environment, bundle string, now time.Time) error {
+	env.Detect = services.MonitorDetector.DetectMonitors
+	env.ManagedFiles = services.ConfigManager.ManagedFiles()
+	env.LogFile = services.Logger.Path()
+
+	report := doctor.Run(env)
+	fmt.Fprint(out, report.String())
+
+	if bundle != "" {
+		if err := doctor.WriteBundle(bundle, env, report, now); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\nWrote %s; attach it to your bug report.\n", bundle)
+	}
+
+	if failed := report.Count(doctor.Fail); failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
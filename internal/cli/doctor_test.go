@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/doctor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+)
+
+func TestDoctorBundle(t *testing.T) {
+	services, _ := newTestServices(t, []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 2}})
+	root := t.TempDir()
+	env := doctor.Environment{
+		Getenv:     func(string) string { return "" },
+		LookPath:   func(string) (string, error) { return "", errors.New("not found") },
+		Run:        func(string, ...string) ([]byte, error) { return nil, errors.New("not found") },
+		Dial:       func(string) error { return errors.New("refused") },
+		HomeDir:    root,
+		ConfigHome: filepath.Join(root, ".config"),
+		RuntimeDir: filepath.Join(root, "run"),
+		DRMDir:     filepath.Join(root, "drm"),
+	}
+	bundle := filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+	var out bytes.Buffer
+	err := runDoctor(&out, services, env, bundle, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "checks failed") {
+		t.Fatalf("Expected failed checks to fail the command, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"FAIL  Hyprland session",
+		"PASS  Monitor detection",
+		"Wrote " + bundle,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+	if _, err := os.Stat(bundle); err != nil {
+		t.Errorf("Expected the bundle to be written even when checks fail: %v", err)
+	}
+}
+
+func TestDoctorBundleFlagDefault(t *testing.T) {
+	cmd := NewDoctorCommand(nil)
+	if err := cmd.ParseFlags([]string{"--bundle"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	if got := cmd.Flags().Lookup("bundle").Value.String(); got != defaultBundlePath {
+		t.Errorf("Expected --bundle alone to use %s, got %q", defaultBundlePath, got)
+	}
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// NewHistoryCommand builds the "history" command and its list, show and
+// restore subcommands. Services are created lazily so that --help never
+// touches the system.
+func NewHistoryCommand(newServices func() *app.Services) *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "List, inspect and restore snapshots taken before each change",
+	}
+
+	historyCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List recorded changes, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runHistoryList(cmd.OutOrStdout(), newServices())
+		},
+	})
+
+	historyCmd.AddCommand(&cobra.Command{
+		Use:   "show <id>",
+		Short: "Show who changed what, when and why",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryShow(cmd.OutOrStdout(), newServices(), args[0])
+		},
+	})
+
+	historyCmd.AddCommand(&cobra.Command{
+		Use:   "restore <id>",
+		Short: "Restore the monitor scales and config files from a snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryRestore(cmd.OutOrStdout(), newServices(), args[0])
+		},
+	})
+
+	return historyCmd
+}
+
+func runHistoryList(out io.Writer, services *app.Services) error {
+	if services.History == nil {
+		return errors.New("history is not available")
+	}
+
+	entries, err := services.History.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No changes recorded yet.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tWHO\tACTION")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s@%s\t%s\n",
+			entry.ID, entry.Time.Local().Format("2006-01-02 15:04:05"), entry.User, entry.Host, entry.Action)
+	}
+	return w.Flush()
+}
+
+func runHistoryShow(out io.Writer, services *app.Services, id string) error {
+	if services.History == nil {
+		return errors.New("history is not available")
+	}
+
+	entry, err := services.History.Get(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "ID:      %s\n", entry.ID)
+	fmt.Fprintf(out, "Time:    %s\n", entry.Time.Local().Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(out, "Who:     %s@%s (via %s)\n", entry.User, entry.Host, entry.Source)
+	fmt.Fprintf(out, "Action:  %s\n", entry.Action)
+	if entry.Reason != "" {
+		fmt.Fprintf(out, "Reason:  %s\n", entry.Reason)
+	}
+
+	fmt.Fprintln(out, "\nMonitors:")
+	if len(entry.Monitors) == 0 {
+		fmt.Fprintln(out, "  (none)")
+	}
+	for _, state := range entry.Monitors {
+		fmt.Fprintf(out, "  %-10s %dx%d@%.2fHz  scale %.2fx  at %d,%d\n",
+			state.Name, state.Width, state.Height, state.RefreshRate, state.Scale, state.X, state.Y)
+	}
+
+	fmt.Fprintln(out, "\nFiles:")
+	if len(entry.Files) == 0 {
+		fmt.Fprintln(out, "  (none)")
+	}
+	for _, file := range entry.Files {
+		if file.Existed {
+			fmt.Fprintf(out, "  %s\n", file.Path)
+		} else {
+			fmt.Fprintf(out, "  %s (did not exist)\n", file.Path)
+		}
+	}
+
+	return nil
+}
+
+func runHistoryRestore(out io.Writer, services *app.Services, id string) error {
+	if services.History == nil {
+		return errors.New("history is not available")
+	}
+
+	live, err := services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return fmt.Errorf("failed to detect monitors: %w", err)
+	}
+
+	safety, err := services.History.Restore(id, "cli", live, services.ConfigManager.ApplyMonitorScale)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", id, err)
+	}
+
+	fmt.Fprintf(out, "Restored %s.\n", id)
+	fmt.Fprintf(out, "The previous state was saved as %s; run 'history restore %s' to undo.\n", safety.ID, safety.ID)
+	return nil
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/history"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+)
+
+type fakeDetector struct {
+	monitors []monitor.Monitor
+}
+
+func (f *fakeDetector) DetectMonitors() ([]monitor.Monitor, error) {
+	return f.monitors, nil
+}
+
+type fakeConfigManager struct {
+	scales       map[string]float64
+	executed     []monitor.Plan
+	verification monitor.Verification
+}
+
+func (f *fakeConfigManager) ApplyMonitorScale(mon monitor.Monitor, scale float64) error {
+	f.scales[mon.Name] = scale
+	return nil
+}
+
+func (f *fakeConfigManager) ApplyGTKScale(scale int) error      { return nil }
+func (f *fakeConfigManager) ApplyFontDPI(dpi int) error         { return nil }
+func (f *fakeConfigManager) ApplyFontScale(scale float64) error { return nil }
+func (f *fakeConfigManager) ApplyCursorSize(size int) error     { return nil }
+func (f *fakeConfigManager) ApplyCompleteScalingOption(mon monitor.Monitor, option monitor.ScalingOption) error {
+	return nil
+}
+func (f *fakeConfigManager) PlanScalingOption(mon monitor.Monitor, option monitor.ScalingOption) (monitor.Plan, error) {
+	return monitor.Plan{
+		Description: "Apply " + option.DisplayName + " to " + mon.Name,
+		Commands: []monitor.Command{{
+			Name: "hyprctl",
+			Args: []string{"keyword", "monitor", fmt.Sprintf("%s,preferred,auto,%.5f", mon.Name, option.MonitorScale)},
+		}},
+	}, nil
+}
+func (f *fakeConfigManager) ApplyTransaction(tx monitor.Transaction) (monitor.Verification, error) {
+	return monitor.Verification{}, nil
+}
+func (f *fakeConfigManager) PlanTransaction(tx monitor.Transaction) (monitor.Plan, error) {
+	return monitor.Plan{Description: tx.Description}, nil
+}
+func (f *fakeConfigManager) PlanWaybar(fontScale float64) (monitor.Plan, error) {
+	return monitor.Plan{}, nil
+}
+func (f *fakeConfigManager) Execute(plan monitor.Plan) (monitor.Verification, error) {
+	f.executed = append(f.executed, plan)
+	return f.verification, nil
+}
+func (f *fakeConfigManager) ManagedFiles() []string             { return nil }
+func (f *fakeConfigManager) CurrentFontScale() (float64, error) { return 1, nil }
+func (f *fakeConfigManager) CurrentCursorSize() (int, error)    { return 24, nil }
+
+func newTestServices(t *testing.T, monitors []monitor.Monitor) (*app.Services, *fakeConfigManager) {
+	t.Helper()
+	configManager := &fakeConfigManager{scales: make(map[string]float64)}
+	return &app.Services{
+		Config:          &app.Config{NoHyprlandCheck: true},
+		MonitorDetector: &fakeDetector{monitors: monitors},
+		ScalingManager:  monitor.NewScalingManager(),
+		ConfigManager:   configManager,
+		History:         history.NewJournal(t.TempDir(), history.DefaultRetention),
+	}, configManager
+}
+
+func runHistory(t *testing.T, services *app.Services, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewHistoryCommand(func() *app.Services { return services })
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestHistoryCommands(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "envs.conf")
+	if err := os.WriteFile(configPath, []byte("env = XCURSOR_SIZE,24\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	before := []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1800, Scale: 1.0}}
+	services, configManager := newTestServices(t, before)
+
+	out, err := runHistory(t, services, "list")
+	if err != nil || !strings.Contains(out, "No changes recorded yet.") {
+		t.Fatalf("Unexpected empty list output (%v):\n%s", err, out)
+	}
+
+	entry, err := services.History.Record(history.Record{
+		Source:   "tui",
+		Action:   "Apply 2x HiDPI to eDP-1",
+		Reason:   "Text too small",
+		Monitors: before,
+		Paths:    []string{configPath},
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	out, err = runHistory(t, services, "list")
+	if err != nil || !strings.Contains(out, entry.ID) || !strings.Contains(out, "Apply 2x HiDPI to eDP-1") {
+		t.Errorf("Unexpected list output (%v):\n%s", err, out)
+	}
+
+	out, err = runHistory(t, services, "show", entry.ID)
+	if err != nil {
+		t.Fatalf("show failed: %v", err)
+	}
+	for _, expected := range []string{"Reason:  Text too small", "eDP-1", "scale 1.00x", configPath} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in show output:\n%s", expected, out)
+		}
+	}
+
+	// Simulate the apply, then restore.
+	if err := os.WriteFile(configPath, []byte("env = XCURSOR_SIZE,48\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	services.MonitorDetector = &fakeDetector{monitors: []monitor.Monitor{{Name: "eDP-1", Scale: 2.0}}}
+
+	out, err = runHistory(t, services, "restore", entry.ID)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if !strings.Contains(out, "Restored "+entry.ID) {
+		t.Errorf("Unexpected restore output:\n%s", out)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "env = XCURSOR_SIZE,24\n" {
+		t.Errorf("Expected config to be restored, got %q", string(data))
+	}
+	if configManager.scales["eDP-1"] != 1.0 {
+		t.Errorf("Expected eDP-1 scale 1.0 to be restored, got %v", configManager.scales)
+	}
+}
+
+func TestHistoryShowUnknownID(t *testing.T) {
+	services, _ := newTestServices(t, nil)
+	if _, err := runHistory(t, services, "show", "does-not-exist"); err == nil {
+		t.Error("Expected error for unknown history entry")
+	}
+}
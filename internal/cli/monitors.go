@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// outputFlags are the --json and --yaml flags shared by list and recommend.
+type outputFlags struct {
+	json, yaml bool
+}
+
+func (o *outputFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.json, "json", false, "Print JSON (see the schema command)")
+	cmd.Flags().BoolVar(&o.yaml, "yaml", false, "Print YAML with the same fields as --json")
+	cmd.MarkFlagsMutuallyExclusive("json", "yaml")
+}
+
+// format is the schema format asked for, or "" for text.
+func (o outputFlags) format() string {
+	switch {
+	case o.json:
+		return schema.FormatJSON
+	case o.yaml:
+		return schema.FormatYAML
+	}
+	return ""
+}
+
+// NewListCommand builds the "list" command, which prints the detected
+// monitors with their estimated PPI and recommended scale.
+func NewListCommand(newServices func() *app.Services) *cobra.Command {
+	var output outputFlags
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List detected monitors, as text, JSON or YAML",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runList(cmd.OutOrStdout(), newServices(), output.format())
+		},
+	}
+	output.register(listCmd)
+
+	return listCmd
+}
+
+func runList(out io.Writer, services *app.Services, format string) error {
+	monitors, err := services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return fmt.Errorf("failed to detect monitors: %w", err)
+	}
+
+	doc := service.Describe(monitors, services.ScalingManager, false)
+	if format != "" {
+		return schema.Write(out, doc, format)
+	}
+
+	if len(doc.Monitors) == 0 {
+		return errors.New("no monitors detected")
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tRESOLUTION\tSCALE\tPPI\tRECOMMENDED\tMODEL")
+	for _, mon := range doc.Monitors {
+		fmt.Fprintf(w, "%s\t%s@%.0fHz\t%.2f\t%.0f\t%.2f\t%s %s\n",
+			mon.Name, utils.FormatResolution(mon.Width, mon.Height), mon.RefreshRate,
+			mon.Scale, mon.PPI, mon.RecommendedScale, mon.Make, mon.Model)
+	}
+	return w.Flush()
+}
+
+// NewRecommendCommand builds the "recommend" command, which prints the smart
+// scaling options for each monitor, or for the one given with --monitor.
+func NewRecommendCommand(newServices func() *app.Services) *cobra.Command {
+	var output outputFlags
+	var name string
+
+	recommendCmd := &cobra.Command{
+		Use:   "recommend",
+		Short: "Show the smart scaling options for each monitor, as text, JSON or YAML",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runRecommend(cmd.OutOrStdout(), newServices(), name, output.format())
+		},
+	}
+	recommendCmd.Flags().StringVar(&name, "monitor", "", "Only this monitor (defaults to every detected monitor)")
+	output.register(recommendCmd)
+
+	return recommendCmd
+}
+
+func runRecommend(out io.Writer, services *app.Services, name, format string) error {
+	monitors, err := services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	if name != "" {
+		target, err := service.SelectMonitor(monitors, name)
+		if err != nil {
+			return err
+		}
+		monitors = []monitor.Monitor{target}
+	}
+
+	doc := service.Describe(monitors, services.ScalingManager, true)
+	if format != "" {
+		return schema.Write(out, doc, format)
+	}
+
+	if len(doc.Monitors) == 0 {
+		return errors.New("no monitors detected")
+	}
+	for i, mon := range doc.Monitors {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s (%s, ~%.0f PPI, currently %.2fx)\n",
+			mon.Name, utils.FormatResolution(mon.Width, mon.Height), mon.PPI, mon.Scale)
+		for j, option := range mon.Options {
+			marker := " "
+			if option.Recommended {
+				marker = "*"
+			}
+			fmt.Fprintf(out, "  %s %d. %-18s %.2fx  GTK %dx  %d DPI  %dx%d  %s\n",
+				marker, j+1, option.Name, option.MonitorScale, option.GTKScale, option.FontDPI,
+				option.EffectiveWidth, option.EffectiveHeight, option.Description)
+		}
+	}
+	fmt.Fprintln(out, "\n* recommended; apply one with 'apply --monitor <name> --option <n>'")
+	return nil
+}
+
+// NewSchemaCommand builds the "schema" command, which prints the JSON Schema
+// for list --json and recommend --json.
+func NewSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for list --json and recommend --json",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, err := cmd.OutOrStdout().Write(schema.JSONSchema)
+			return err
+		},
+	}
+}
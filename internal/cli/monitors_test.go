@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func runCommand(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+var listMonitors = []monitor.Monitor{
+	{Name: "eDP-1", Make: "BOE", Width: 2880, Height: 1920, RefreshRate: 120, Scale: 2.0, IsActive: true},
+	{Name: "DP-1", Make: "Dell", Width: 1920, Height: 1080, RefreshRate: 60, Scale: 1.0, IsActive: true},
+}
+
+func TestListCommand(t *testing.T) {
+	services, _ := newTestServices(t, listMonitors)
+
+	out, err := runCommand(t, NewListCommand(func() *app.Services { return services }))
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	for _, expected := range []string{"NAME", "eDP-1", "2880x1920@120Hz", "DP-1"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, out)
+		}
+	}
+
+	out, err = runCommand(t, NewListCommand(func() *app.Services { return services }), "--json")
+	if err != nil {
+		t.Fatalf("list --json failed: %v", err)
+	}
+	var doc schema.Document
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("list --json isn't JSON: %v\n%s", err, out)
+	}
+	if doc.SchemaVersion != schema.Version || len(doc.Monitors) != 2 || doc.Monitors[0].PPI == 0 || doc.Monitors[0].Options != nil {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+
+	out, err = runCommand(t, NewListCommand(func() *app.Services { return services }), "--yaml")
+	if err != nil {
+		t.Fatalf("list --yaml failed: %v", err)
+	}
+	doc = schema.Document{}
+	if err := yaml.Unmarshal([]byte(out), &doc); err != nil || len(doc.Monitors) != 2 {
+		t.Errorf("Expected YAML with two monitors, got %v:\n%s", err, out)
+	}
+
+	if _, err := runCommand(t, NewListCommand(func() *app.Services { return services }), "--json", "--yaml"); err == nil {
+		t.Error("Expected --json and --yaml together to be rejected")
+	}
+}
+
+func TestRecommendCommand(t *testing.T) {
+	services, _ := newTestServices(t, listMonitors)
+
+	out, err := runCommand(t, NewRecommendCommand(func() *app.Services { return services }), "--monitor", "eDP-1", "--json")
+	if err != nil {
+		t.Fatalf("recommend --json failed: %v", err)
+	}
+	var doc schema.Document
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("recommend --json isn't JSON: %v\n%s", err, out)
+	}
+	if len(doc.Monitors) != 1 || doc.Monitors[0].Name != "eDP-1" || len(doc.Monitors[0].Options) == 0 {
+		t.Fatalf("Expected eDP-1 with its options, got %+v", doc)
+	}
+	var recommended int
+	for _, option := range doc.Monitors[0].Options {
+		if option.Recommended {
+			recommended++
+			if option.MonitorScale != doc.Monitors[0].RecommendedScale {
+				t.Errorf("Expected recommended_scale to match the recommended option, got %.2f and %.2f",
+					doc.Monitors[0].RecommendedScale, option.MonitorScale)
+			}
+		}
+	}
+	if recommended != 1 {
+		t.Errorf("Expected one recommended option, got %d", recommended)
+	}
+
+	out, err = runCommand(t, NewRecommendCommand(func() *app.Services { return services }))
+	if err != nil {
+		t.Fatalf("recommend failed: %v", err)
+	}
+	if !strings.Contains(out, "eDP-1") || !strings.Contains(out, "DP-1") || !strings.Contains(out, "* 1.") {
+		t.Errorf("Expected both monitors with the recommended option marked, got:\n%s", out)
+	}
+
+	if _, err := runCommand(t, NewRecommendCommand(func() *app.Services { return services }), "--monitor", "HDMI-A-9"); err == nil {
+		t.Error("Expected an unknown monitor to be an error")
+	}
+}
+
+func TestSchemaCommand(t *testing.T) {
+	out, err := runCommand(t, NewSchemaCommand())
+	if err != nil {
+		t.Fatalf("schema failed: %v", err)
+	}
+	if out != string(schema.JSONSchema) {
+		t.Error("Expected the schema command to print the embedded schema")
+	}
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/notify"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// scaleStep is a "scale" subcommand. action describes the change in history,
+// given the monitor name and new scale.
+type scaleStep struct {
+	name   string
+	short  string
+	action string
+}
+
+var scaleSteps = []scaleStep{
+	{"up", "Step to the next larger valid scale", "Scale %s up to %.2fx"},
+	{"down", "Step to the next smaller valid scale", "Scale %s down to %.2fx"},
+	{"reset", "Go back to 1x", "Reset %s to %.2fx"},
+	{"recommended", "Use the recommended scale", "Scale %s to the recommended %.2fx"},
+}
+
+// scaleOptions are the flags shared by every "scale" subcommand.
+type scaleOptions struct {
+	monitor string
+	notify  bool
+	dryRun  bool
+}
+
+// NewScaleCommand builds the "scale" command and its up, down, reset and
+// recommended subcommands, quick actions for compositor keybindings. They
+// only change the monitor scale, leaving GTK and font settings alone.
+func NewScaleCommand(newServices func() *app.Services) *cobra.Command {
+	var opts scaleOptions
+
+	scaleCmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Change one monitor's scale without the TUI, for keybindings",
+	}
+
+	for _, step := range scaleSteps {
+		step := step
+		scaleCmd.AddCommand(&cobra.Command{
+			Use:   step.name,
+			Short: step.short,
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				var notifier *notify.Notifier
+				if opts.notify && !opts.dryRun {
+					var err error
+					if notifier, err = notify.Session(); err != nil {
+						fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+					} else {
+						defer notifier.Close()
+					}
+				}
+				return runScale(cmd.OutOrStdout(), cmd.ErrOrStderr(), newServices(), notifier, step, opts)
+			},
+		})
+	}
+
+	scaleCmd.PersistentFlags().StringVar(&opts.monitor, "monitor", service.Focused, "Monitor to scale, or \"focused\"")
+	scaleCmd.PersistentFlags().BoolVar(&opts.notify, "notify", false, "Show the result as a desktop notification")
+	scaleCmd.PersistentFlags().BoolVar(&opts.dryRun, "dry-run", false, "Print the plan without applying it")
+
+	return scaleCmd
+}
+
+// runScale applies step and, with a notifier, reports how it went, failures
+// included, since a keybinding has nowhere else to show them. A notification
+// that can't be shown is only a warning on errOut.
+func runScale(out, errOut io.Writer, services *app.Services, notifier *notify.Notifier, step scaleStep, opts scaleOptions) error {
+	note, err := stepScale(out, services, step, opts)
+	if notifier == nil || opts.dryRun {
+		return err
+	}
+
+	if err != nil {
+		note = notify.Notification{Summary: "Couldn't change the scale", Body: err.Error(), Critical: true}
+	}
+	if _, notifyErr := notifier.Notify(note); notifyErr != nil {
+		fmt.Fprintf(errOut, "Warning: %v\n", notifyErr)
+	}
+	return err
+}
+
+func stepScale(out io.Writer, services *app.Services, step scaleStep, opts scaleOptions) (notify.Notification, error) {
+	monitors, err := services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return notify.Notification{}, fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	target, err := service.SelectMonitor(monitors, opts.monitor)
+	if err != nil {
+		return notify.Notification{}, err
+	}
+
+	var scale float64
+	switch step.name {
+	case "up", "down":
+		scale = utils.FindNextValidScale(target.Scale, step.name == "up", types.ValidHyprlandScales)
+	case "reset":
+		scale = 1.0
+	case "recommended":
+		scale = services.ScalingManager.GetRecommendedScale(target)
+	default:
+		return notify.Notification{}, fmt.Errorf("unknown scale step %q", step.name)
+	}
+
+	if sameScale(scale, target.Scale) {
+		fmt.Fprintf(out, "%s is already at %.2fx.\n", target.Name, target.Scale)
+		return scaleNotification(target, target.Scale), nil
+	}
+
+	plan, err := services.ConfigManager.PlanTransaction(monitor.Transaction{
+		Description: fmt.Sprintf(step.action, target.Name, scale),
+		Monitors:    []monitor.MonitorTarget{{Monitor: target, Scale: scale}},
+	})
+	if err != nil {
+		return notify.Notification{}, fmt.Errorf("failed to plan changes: %w", err)
+	}
+
+	if opts.dryRun {
+		fmt.Fprint(out, plan.String())
+		return notify.Notification{}, nil
+	}
+
+	verification, err := service.Execute(services, "cli", monitors, plan, "scale "+step.name)
+	if err != nil {
+		return notify.Notification{}, err
+	}
+
+	fmt.Fprintf(out, "Scaled %s to %.2fx.\n", target.Name, scale)
+	reportDrift(out, verification)
+
+	note := scaleNotification(target, scale)
+	for _, drift := range verification.ScaleDrift() {
+		note.Body += fmt.Sprintf("\nHyprland is using %.2fx instead", drift.Current.Scale)
+	}
+	return note, nil
+}
+
+func scaleNotification(mon monitor.Monitor, scale float64) notify.Notification {
+	width, height := utils.CalculateEffectiveResolution(mon.Width, mon.Height, scale)
+	return notify.Notification{
+		Summary: fmt.Sprintf("%s at %.2fx", mon.Name, scale),
+		Body:    "Looks like " + utils.FormatResolution(width, height),
+		Icon:    "video-display",
+		Value:   scalePercent(scale),
+	}
+}
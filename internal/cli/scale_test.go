@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/notify"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+)
+
+// fakeBus collects the summaries and bodies of notifications sent to it, or
+// fails every call with err.
+type fakeBus struct {
+	notes []string
+	err   error
+}
+
+func (f *fakeBus) Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	if f.err != nil {
+		return &dbus.Call{Err: f.err}
+	}
+	f.notes = append(f.notes, args[3].(string)+": "+args[4].(string))
+	return &dbus.Call{Body: []interface{}{uint32(len(f.notes))}}
+}
+
+func TestScaleCommand(t *testing.T) {
+	monitors := []monitor.Monitor{
+		{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 2.0},
+		{Name: "DP-1", Width: 1920, Height: 1080, Scale: 1.0, IsPrimary: true},
+	}
+	services, configManager := newTestServices(t, monitors)
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"up"}, "Plan: Scale DP-1 up to 1.25x"},
+		{[]string{"down", "--monitor", "eDP-1"}, "Plan: Scale eDP-1 down to 1.75x"},
+		{[]string{"reset", "--monitor", "eDP-1"}, "Plan: Reset eDP-1 to 1.00x"},
+		{[]string{"recommended", "--monitor", "eDP-1"}, "eDP-1 is already at 2.00x."},
+	} {
+		out, err := runCommand(t, NewScaleCommand(func() *app.Services { return services }), append(tc.args, "--dry-run")...)
+		if err != nil {
+			t.Fatalf("scale %v failed: %v", tc.args, err)
+		}
+		if !strings.Contains(out, tc.expected) {
+			t.Errorf("scale %v: expected %q in:\n%s", tc.args, tc.expected, out)
+		}
+	}
+	if len(configManager.executed) != 0 {
+		t.Error("Dry run must not execute the plan")
+	}
+
+	if _, err := runCommand(t, NewScaleCommand(func() *app.Services { return services }), "up", "--monitor", "HDMI-A-1"); err == nil {
+		t.Error("Expected an unknown monitor to fail")
+	}
+}
+
+func TestScaleNotifies(t *testing.T) {
+	monitors := []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 2.0, IsPrimary: true}}
+	services, configManager := newTestServices(t, monitors)
+	bus := &fakeBus{}
+	notifier := notify.New(bus)
+
+	var out bytes.Buffer
+	if err := runScale(&out, io.Discard, services, notifier, scaleSteps[0], scaleOptions{monitor: service.Focused}); err != nil {
+		t.Fatalf("scale up failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Scaled eDP-1 to 2.25x.") || len(configManager.executed) != 1 {
+		t.Errorf("Expected the scale to be applied, got:\n%s", out.String())
+	}
+	entries, _ := services.History.List()
+	if len(entries) != 1 || entries[0].Action != "Scale eDP-1 up to 2.25x" {
+		t.Errorf("Expected the step to be journaled, got %+v", entries)
+	}
+
+	// The largest scale has nowhere to go, but still says where it is
+	monitors[0].Scale = 3.0
+	if err := runScale(&out, io.Discard, services, notifier, scaleSteps[0], scaleOptions{monitor: service.Focused}); err != nil {
+		t.Fatalf("scale up failed: %v", err)
+	}
+	if len(configManager.executed) != 1 {
+		t.Error("Expected nothing to be applied at the largest scale")
+	}
+
+	services.MonitorDetector = &failingDetector{}
+	if err := runScale(&out, io.Discard, services, notifier, scaleSteps[1], scaleOptions{monitor: service.Focused}); err == nil {
+		t.Error("Expected detection to fail")
+	}
+
+	expected := []string{
+		"eDP-1 at 2.25x: Looks like 1280x853",
+		"eDP-1 at 3.00x: Looks like 960x640",
+		"Couldn't change the scale: failed to detect monitors: no compositor",
+	}
+	if strings.Join(bus.notes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected notifications:\n%s", strings.Join(bus.notes, "\n"))
+	}
+}
+
+type failingDetector struct{}
+
+func (failingDetector) DetectMonitors() ([]monitor.Monitor, error) {
+	return nil, errors.New("no compositor")
+}
+
+func TestScaleNotifyFailureWarnsOnStderr(t *testing.T) {
+	monitors := []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 2.0, IsPrimary: true}}
+	services, _ := newTestServices(t, monitors)
+	notifier := notify.New(&fakeBus{err: errors.New("no notification daemon")})
+
+	var out, errOut bytes.Buffer
+	if err := runScale(&out, &errOut, services, notifier, scaleSteps[0], scaleOptions{monitor: service.Focused}); err != nil {
+		t.Fatalf("A failed notification must not fail the step, got %v", err)
+	}
+	if strings.Contains(out.String(), "Warning") || !strings.Contains(errOut.String(), "no notification daemon") {
+		t.Errorf("Expected the warning on stderr only, got stdout %q and stderr %q", out.String(), errOut.String())
+	}
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// NewServeCommand builds the "serve" command, which keeps running and lets
+// other programs query and change scaling. It answers JSON-RPC on a Unix
+// socket, which the TUI uses too when it finds one, and with --dbus also
+// publishes org.omarchy.MonitorSettings on the session bus.
+func NewServeCommand(newServices func() *app.Services) *cobra.Command {
+	var socketPath string
+	var useDBus bool
+	var interval time.Duration
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Let other programs query and change scaling",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if socketPath == "" && !useDBus {
+				return errors.New("nothing to serve; pass --socket or --dbus")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			var listener net.Listener
+			if socketPath != "" {
+				var err error
+				if listener, err = listenSocket(socketPath); err != nil {
+					return err
+				}
+				defer listener.Close()
+			}
+
+			var conn *dbus.Conn
+			if useDBus {
+				var err error
+				if conn, err = dbus.ConnectSessionBus(); err != nil {
+					return fmt.Errorf("failed to connect to the session bus: %w", err)
+				}
+				defer conn.Close()
+			}
+
+			svc := service.New(newServices())
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			changes := svc.Watch(ctx, hyprlandEvents(ctx, cmd.ErrOrStderr()), interval)
+
+			var servers []func(<-chan schema.Document) error
+			if listener != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Serving JSON-RPC on %s.\n", socketPath)
+				servers = append(servers, func(changes <-chan schema.Document) error {
+					return service.ServeRPC(ctx, listener, svc, changes)
+				})
+			}
+			if conn != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on the session bus.\n", service.BusName)
+				servers = append(servers, func(changes <-chan schema.Document) error {
+					return service.ServeDBus(ctx, conn, svc, changes)
+				})
+			}
+
+			// The first server to fail stops the others
+			outputs := fanOut(ctx, changes, len(servers))
+			errs := make(chan error, len(servers))
+			for i, serve := range servers {
+				go func(serve func(<-chan schema.Document) error, changes <-chan schema.Document) {
+					err := serve(changes)
+					cancel()
+					errs <- err
+				}(serve, outputs[i])
+			}
+			var err error
+			for range servers {
+				if serveErr := <-errs; serveErr != nil && err == nil {
+					err = serveErr
+				}
+			}
+			return err
+		},
+	}
+
+	serveCmd.Flags().StringVar(&socketPath, "socket", client.DefaultSocketPath(), "Answer JSON-RPC on this Unix socket; empty to serve only D-Bus")
+	serveCmd.Flags().BoolVar(&useDBus, "dbus", false, "Also publish "+service.BusName+" on the session bus")
+	serveCmd.Flags().DurationVar(&interval, "interval", defaultWatchInterval, "Also check for monitor changes this often")
+
+	return serveCmd
+}
+
+// listenSocket listens on path, readable only by the user. A socket left
+// behind by a server that died is replaced; a live one is not.
+func listenSocket(path string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a server is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// fanOut copies every document from in to n channels, until ctx ends or in
+// is closed.
+func fanOut(ctx context.Context, in <-chan schema.Document, n int) []<-chan schema.Document {
+	outs := make([]chan schema.Document, n)
+	result := make([]<-chan schema.Document, n)
+	for i := range outs {
+		outs[i] = make(chan schema.Document)
+		result[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		for doc := range in {
+			for _, out := range outs {
+				select {
+				case out <- doc:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return result
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+)
+
+func TestServeNeedsTransport(t *testing.T) {
+	called := false
+	cmd := NewServeCommand(func() *app.Services { called = true; return nil })
+
+	_, err := runCommand(t, cmd, "--socket", "")
+	if err == nil || !strings.Contains(err.Error(), "--socket or --dbus") {
+		t.Errorf("Expected serve without a transport to ask for one, got %v", err)
+	}
+	if called {
+		t.Error("Services must not be created when there's nothing to serve")
+	}
+}
+
+func TestServeSocket(t *testing.T) {
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+	services, _ := simtest.Services(t, "6k-laptop")
+
+	path := filepath.Join(simtest.SocketDir(t, "serve"), "run", client.SocketName)
+
+	// A socket left behind by a server that died is replaced
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("Failed to create the runtime dir: %v", err)
+	}
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := NewServeCommand(func() *app.Services { return services })
+	out := &syncBuffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{"--socket", path})
+	served := make(chan error, 1)
+	go func() { served <- cmd.ExecuteContext(ctx) }()
+
+	var c *client.Client
+	deadline := time.Now().Add(2 * time.Second)
+	for c == nil && time.Now().Before(deadline) {
+		if c, err = client.Dial(path); err != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if c == nil {
+		t.Fatalf("serve never listened on %s: %v", path, err)
+	}
+	defer c.Close()
+
+	doc, err := c.List(ctx)
+	if err != nil || len(doc.Monitors) != 2 {
+		t.Fatalf("Expected both monitors over the socket, got %+v, %v", doc, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the socket to be private, got %v, %v", info.Mode(), err)
+	}
+
+	_, err = runCommand(t, NewServeCommand(func() *app.Services { return services }), "--socket", path)
+	if err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Errorf("Expected a second server to be refused, got %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serve failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("serve didn't stop when cancelled")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be removed on exit, got %v", err)
+	}
+	if lines := out.lines(); lines[len(lines)-1] != "Serving JSON-RPC on "+path+"." {
+		t.Errorf("Unexpected output: %q", lines)
+	}
+}
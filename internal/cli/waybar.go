@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/events"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/session"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/waybar"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchInterval is how often --watch re-detects anyway, to pick up
+// scale changes that Hyprland doesn't announce on its event socket.
+const defaultWatchInterval = 5 * time.Second
+
+// NewWaybarCommand builds the "waybar" command, which prints the focused
+// monitor's scale as a Waybar custom module. With --watch it keeps printing
+// a line whenever that changes.
+func NewWaybarCommand(newServices func() *app.Services) *cobra.Command {
+	var watch bool
+	var interval time.Duration
+
+	waybarCmd := &cobra.Command{
+		Use:   "waybar",
+		Short: "Print the focused monitor's scale as Waybar custom-module JSON",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			services := newServices()
+			if !watch {
+				return writeModule(cmd.OutOrStdout(), services)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			changes := hyprlandEvents(ctx, cmd.ErrOrStderr())
+			return watchModule(ctx, cmd.OutOrStdout(), services, changes, interval)
+		},
+	}
+
+	waybarCmd.Flags().BoolVar(&watch, "watch", false, "Keep running and print a new line when the focused monitor or its scale changes")
+	waybarCmd.Flags().DurationVar(&interval, "interval", defaultWatchInterval, "With --watch, also re-detect this often")
+
+	return waybarCmd
+}
+
+// moduleFor describes the focused monitor. The class says whether its scale
+// is the recommended one, and the percentage is for format-icons.
+func moduleFor(services *app.Services) (waybar.Module, error) {
+	monitors, err := services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return waybar.Module{}, fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	mon, err := service.SelectMonitor(monitors, service.Focused)
+	if err != nil {
+		return waybar.Module{}, err
+	}
+
+	recommended := services.ScalingManager.GetRecommendedScale(mon)
+	class := "custom"
+	if sameScale(mon.Scale, recommended) {
+		class = "recommended"
+	}
+	effectiveWidth, effectiveHeight := utils.CalculateEffectiveResolution(mon.Width, mon.Height, mon.Scale)
+
+	return waybar.Module{
+		Text: fmt.Sprintf("%.2fx", mon.Scale),
+		Tooltip: fmt.Sprintf("%s: %s@%.0fHz\nScale %.2fx (recommended %.2fx)\nLooks like %s",
+			mon.Name, utils.FormatResolution(mon.Width, mon.Height), mon.RefreshRate,
+			mon.Scale, recommended, utils.FormatResolution(effectiveWidth, effectiveHeight)),
+		Class:      class,
+		Percentage: scalePercent(mon.Scale),
+	}, nil
+}
+
+// hyprlandEvents follows the Hyprland event socket until ctx ends. Without
+// one, it warns on errOut and returns nil, which never delivers, so callers
+// fall back to polling.
+func hyprlandEvents(ctx context.Context, errOut io.Writer) <-chan events.Event {
+	probe := session.DefaultProbe()
+	conn, err := events.Dial(probe.RuntimeDir, probe.Getenv("HYPRLAND_INSTANCE_SIGNATURE"))
+	if err != nil {
+		fmt.Fprintf(errOut, "Watching without monitor events: %v\n", err)
+		return nil
+	}
+	return events.Stream(ctx, conn)
+}
+
+// scalePercent is scale as a share of the largest scale Hyprland accepts.
+func scalePercent(scale float64) int {
+	largest := types.ValidHyprlandScales[len(types.ValidHyprlandScales)-1]
+	return int(math.Round(scale / largest * 100))
+}
+
+func writeModule(out io.Writer, services *app.Services) error {
+	module, err := moduleFor(services)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(out).Encode(module)
+}
+
+// watchModule prints the module, then prints it again whenever it changes,
+// checking after every monitor event and every interval. Detection errors
+// are shown in the module rather than ending the watch, since Waybar would
+// otherwise show nothing until it restarts the command.
+func watchModule(ctx context.Context, out io.Writer, services *app.Services, changes <-chan events.Event, interval time.Duration) error {
+	encoder := json.NewEncoder(out)
+	var last waybar.Module
+	printed := false
+
+	update := func() error {
+		module, err := moduleFor(services)
+		if err != nil {
+			module = waybar.Module{Text: "?", Tooltip: err.Error(), Class: "error"}
+		}
+		if printed && module == last {
+			return nil
+		}
+		last, printed = module, true
+		return encoder.Encode(module)
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-changes:
+			if !ok {
+				// The compositor went away; keep polling
+				changes = nil
+				continue
+			}
+			if !event.MonitorChange() {
+				continue
+			}
+		case <-tick:
+		}
+		if err := update(); err != nil {
+			return err
+		}
+	}
+}
+
+// NewCycleCommand builds the "cycle" command, which moves the focused
+// monitor to its next smart scaling option, for a Waybar on-click action.
+func NewCycleCommand(newServices func() *app.Services) *cobra.Command {
+	var reverse, dryRun bool
+
+	cycleCmd := &cobra.Command{
+		Use:   "cycle",
+		Short: "Step the focused monitor through its smart scaling options",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCycle(cmd.OutOrStdout(), newServices(), reverse, dryRun)
+		},
+	}
+
+	cycleCmd.Flags().BoolVar(&reverse, "reverse", false, "Step to the previous option instead")
+	cycleCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan without applying it")
+
+	return cycleCmd
+}
+
+// runCycle applies the option after the one matching the focused monitor's
+// current scale, wrapping around. A scale that matches no option starts at
+// the first.
+func runCycle(out io.Writer, services *app.Services, reverse, dryRun bool) error {
+	monitors, err := services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	mon, err := service.SelectMonitor(monitors, service.Focused)
+	if err != nil {
+		return err
+	}
+
+	options := services.ScalingManager.GetIntelligentScalingOptions(mon)
+	if len(options) == 0 {
+		return fmt.Errorf("no scaling options for %s", mon.Name)
+	}
+
+	next := 0
+	for i, option := range options {
+		if sameScale(option.MonitorScale, mon.Scale) {
+			step := 1
+			if reverse {
+				step = len(options) - 1
+			}
+			next = (i + step) % len(options)
+			break
+		}
+	}
+
+	return runApply(out, services, ApplyOptions{
+		Monitor: mon.Name,
+		Option:  next + 1,
+		Reason:  "Cycled from Waybar",
+		DryRun:  dryRun,
+	})
+}
+
+func sameScale(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/events"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/waybar"
+)
+
+func TestWaybarCommand(t *testing.T) {
+	monitors := []monitor.Monitor{
+		{Name: "eDP-1", Width: 2880, Height: 1920, RefreshRate: 120, Scale: 1.0},
+		{Name: "DP-1", Width: 1920, Height: 1080, RefreshRate: 60, Scale: 1.0, IsPrimary: true},
+	}
+	services, _ := newTestServices(t, monitors)
+
+	out, err := runCommand(t, NewWaybarCommand(func() *app.Services { return services }))
+	if err != nil {
+		t.Fatalf("waybar failed: %v", err)
+	}
+	var module waybar.Module
+	if err := json.Unmarshal([]byte(out), &module); err != nil {
+		t.Fatalf("waybar output isn't JSON: %v\n%s", err, out)
+	}
+	if module.Text != "1.00x" || module.Class != "recommended" || module.Percentage != 33 {
+		t.Errorf("Unexpected module: %+v", module)
+	}
+	if !strings.HasPrefix(module.Tooltip, "DP-1: 1920x1080@60Hz") {
+		t.Errorf("Expected the focused monitor in the tooltip, got %q", module.Tooltip)
+	}
+
+	monitors[1].IsPrimary = false
+	out, err = runCommand(t, NewWaybarCommand(func() *app.Services { return services }))
+	if err != nil {
+		t.Fatalf("waybar failed: %v", err)
+	}
+	module = waybar.Module{}
+	if err := json.Unmarshal([]byte(out), &module); err != nil {
+		t.Fatalf("waybar output isn't JSON: %v\n%s", err, out)
+	}
+	if !strings.HasPrefix(module.Tooltip, "eDP-1") || module.Class != "custom" {
+		t.Errorf("Expected the first monitor at a custom scale without a focused one, got %+v", module)
+	}
+}
+
+// syncBuffer lets the test read output while watchModule is still writing.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+}
+
+func TestWatchModule(t *testing.T) {
+	detector := &fakeDetector{monitors: []monitor.Monitor{
+		{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 2.0, IsPrimary: true},
+		{Name: "DP-1", Width: 1920, Height: 1080, Scale: 1.0},
+	}}
+	services, _ := newTestServices(t, nil)
+	services.MonitorDetector = detector
+
+	var out syncBuffer
+	changes := make(chan events.Event)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watchModule(ctx, &out, services, changes, 0) }()
+
+	// Events that don't touch monitors, or leave the module as it was, print nothing
+	changes <- events.Event{Name: "workspace", Data: "2"}
+	changes <- events.Event{Name: "configreloaded"}
+	// A send only returns once the previous event has been handled
+	changes <- events.Event{Name: "workspace", Data: "3"}
+
+	focused := []monitor.Monitor{
+		{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 2.0},
+		{Name: "DP-1", Width: 1920, Height: 1080, Scale: 1.0, IsPrimary: true},
+	}
+	detector.monitors = focused
+	changes <- events.Event{Name: "focusedmon", Data: "DP-1,2"}
+	changes <- events.Event{Name: "workspace", Data: "4"}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+
+	lines := out.lines()
+	if len(lines) != 2 {
+		t.Fatalf("Expected a line at start and one per change, got:\n%s", strings.Join(lines, "\n"))
+	}
+	for i, name := range []string{"eDP-1", "DP-1"} {
+		var module waybar.Module
+		if err := json.Unmarshal([]byte(lines[i]), &module); err != nil {
+			t.Fatalf("Line %d isn't JSON: %v", i, err)
+		}
+		if !strings.HasPrefix(module.Tooltip, name+":") {
+			t.Errorf("Line %d: expected %s, got %+v", i, name, module)
+		}
+	}
+}
+
+func TestWatchModulePolls(t *testing.T) {
+	services, _ := newTestServices(t, []monitor.Monitor{{Name: "eDP-1", Width: 1920, Height: 1080, Scale: 1.0}})
+
+	var out syncBuffer
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := watchModule(ctx, &out, services, nil, time.Millisecond); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	if lines := out.lines(); len(lines) != 1 {
+		t.Errorf("Expected polling to print only changes, got %d lines", len(lines))
+	}
+}
+
+func TestCycleCommand(t *testing.T) {
+	mon := monitor.Monitor{Name: "eDP-1", Width: 2880, Height: 1920, Scale: 1.0, IsPrimary: true}
+	services, configManager := newTestServices(t, []monitor.Monitor{mon})
+	options := services.ScalingManager.GetIntelligentScalingOptions(mon)
+	if len(options) < 2 {
+		t.Fatalf("Expected several options, got %d", len(options))
+	}
+
+	scaleArg := func(scale float64) string {
+		return fmt.Sprintf("eDP-1,preferred,auto,%.5f", scale)
+	}
+	cycle := func(scale float64, args ...string) string {
+		t.Helper()
+		mon.Scale = scale
+		services.MonitorDetector = &fakeDetector{monitors: []monitor.Monitor{mon}}
+		out, err := runCommand(t, NewCycleCommand(func() *app.Services { return services }), args...)
+		if err != nil {
+			t.Fatalf("cycle failed: %v", err)
+		}
+		return out
+	}
+
+	if out := cycle(options[0].MonitorScale, "--dry-run"); !strings.Contains(out, scaleArg(options[1].MonitorScale)) {
+		t.Errorf("Expected the second option, got:\n%s", out)
+	}
+	last := options[len(options)-1].MonitorScale
+	if out := cycle(last, "--dry-run"); !strings.Contains(out, scaleArg(options[0].MonitorScale)) {
+		t.Errorf("Expected cycling to wrap to the first option, got:\n%s", out)
+	}
+	if out := cycle(options[0].MonitorScale, "--reverse", "--dry-run"); !strings.Contains(out, scaleArg(last)) {
+		t.Errorf("Expected --reverse to wrap to the last option, got:\n%s", out)
+	}
+	if len(configManager.executed) != 0 {
+		t.Error("Dry run must not execute the plan")
+	}
+
+	cycle(options[0].MonitorScale)
+	if len(configManager.executed) != 1 {
+		t.Fatalf("Expected the plan to be executed once, got %d", len(configManager.executed))
+	}
+	entries, _ := services.History.List()
+	if len(entries) != 1 || entries[0].Reason != "Cycled from Waybar" {
+		t.Errorf("Expected the cycle to be journaled, got %+v", entries)
+	}
+}
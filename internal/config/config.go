@@ -0,0 +1,87 @@
+// Package config loads the user's settings file, config.yaml in the
+// application's config directory. Every setting is optional; a missing file
+// means the defaults.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/keymap"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/theme"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the settings file.
+type File struct {
+	Keymap keymap.Config `yaml:"keymap"`
+	// Theme is a built-in theme name or a TOML palette; see theme.Resolve.
+	// Relative paths are relative to the config file.
+	Theme string `yaml:"theme"`
+
+	dir string
+}
+
+// DefaultPath is where the settings file lives unless --config says
+// otherwise.
+func DefaultPath() string {
+	return filepath.Join(utils.ConfigDir(), "config.yaml")
+}
+
+// Load reads the settings file at path. A file that doesn't exist gives the
+// defaults.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{dir: filepath.Dir(path)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	file, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	file.dir = filepath.Dir(path)
+	return file, nil
+}
+
+// Parse reads settings from YAML, rejecting unknown fields so typos don't go
+// unnoticed.
+func Parse(data []byte) (*File, error) {
+	var file File
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return &file, nil
+}
+
+// Palette resolves the theme the file asks for. NO_COLOR in the environment
+// overrides it.
+func (f *File) Palette() (*theme.Palette, error) {
+	name := f.Theme
+	if strings.HasSuffix(name, ".toml") && !filepath.IsAbs(name) && f.dir != "" {
+		name = filepath.Join(f.dir, name)
+	}
+	palette, err := theme.Resolve(name, theme.OmarchyDir(utils.ConfigHome()), os.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid theme: %w", err)
+	}
+	return &palette, nil
+}
+
+// KeyMap builds the keymap the file asks for.
+func (f *File) KeyMap() (*keymap.KeyMap, error) {
+	keys, err := keymap.New(f.Keymap)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keymap: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	file, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("Expected a missing file to give the defaults, got %v", err)
+	}
+	keys, err := file.KeyMap()
+	if err != nil || keys.Preset != "default" {
+		t.Errorf("Expected the default keymap, got %v, %v", keys, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "keymap:\n  preset: emacs\n  bindings:\n    refresh: [R]\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	keys, err := file.KeyMap()
+	if err != nil {
+		t.Fatalf("KeyMap failed: %v", err)
+	}
+	if keys.Preset != "emacs" || strings.Join(keys.Refresh.Keys(), ",") != "R" {
+		t.Errorf("Unexpected keymap: preset %s, refresh %v", keys.Preset, keys.Refresh.Keys())
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	if _, err := Parse([]byte("keymap:\n  presets: vim\n")); err == nil {
+		t.Error("Expected a misspelt field to be rejected")
+	}
+	if _, err := Parse(nil); err != nil {
+		t.Errorf("Expected an empty file to be fine, got %v", err)
+	}
+
+	file, err := Parse([]byte("keymap:\n  bindings:\n    quit: [r]\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := file.KeyMap(); err == nil || !strings.Contains(err.Error(), "invalid keymap") {
+		t.Errorf("Expected a conflicting keymap to be rejected, got %v", err)
+	}
+}
+
+func TestPalette(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mine.toml"), []byte("blue = \"#112233\"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write theme: %v", err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("theme: mine.toml\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	palette, err := file.Palette()
+	if err != nil {
+		t.Fatalf("Expected the theme to be found next to the config, got %v", err)
+	}
+	if palette.Name != "mine" || palette.Blue != "#112233" {
+		t.Errorf("Unexpected palette: %+v", palette)
+	}
+
+	file.Theme = "solarized"
+	if _, err := file.Palette(); err == nil || !strings.Contains(err.Error(), "invalid theme") {
+		t.Errorf("Expected an unknown theme to be rejected, got %v", err)
+	}
+}
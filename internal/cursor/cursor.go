@@ -0,0 +1,433 @@
+package cursor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+const (
+	DefaultTheme = "Adwaita"
+	DefaultSize  = 24
+
+	xcursorMagic     = "Xcur"
+	xcursorImageType = 0xfffd0002
+)
+
+var commonSizes = []int{24, 32, 48, 64}
+
+var (
+	gtkThemeNamePattern = regexp.MustCompile(`^\s*gtk-cursor-theme-name\s*=\s*(.+?)\s*$`)
+	gtkThemeSizePattern = regexp.MustCompile(`^(\s*gtk-cursor-theme-size\s*=\s*)(\d+)(.*)$`)
+	inheritsPattern     = regexp.MustCompile(`^\s*Inherits\s*=\s*(.+?)\s*$`)
+)
+
+// Theme describes an installed cursor theme and the nominal sizes it ships.
+type Theme struct {
+	Name     string
+	Dir      string
+	Sizes    []int
+	Scalable bool
+}
+
+// Supports reports whether the theme can render the cursor at size without
+// the toolkit falling back to a blurry rescale.
+func (t Theme) Supports(size int) bool {
+	if t.Scalable {
+		return true
+	}
+	for _, s := range t.Sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Nearest returns the shipped size closest to size.
+func (t Theme) Nearest(size int) int {
+	if t.Scalable || len(t.Sizes) == 0 {
+		return size
+	}
+	best := t.Sizes[0]
+	for _, s := range t.Sizes {
+		if abs(s-size) < abs(best-size) || (abs(s-size) == abs(best-size) && s > best) {
+			best = s
+		}
+	}
+	return best
+}
+
+// Change is a pending edit to a config file that carries cursor settings.
+type Change struct {
+	Path   string
+	Before string
+	After  string
+}
+
+type Manager struct {
+	configHome string
+	home       string
+	searchPath []string
+	getenv     func(string) string
+}
+
+func NewManager(configHome, home string, searchPath []string, getenv func(string) string) *Manager {
+	return &Manager{
+		configHome: configHome,
+		home:       home,
+		searchPath: searchPath,
+		getenv:     getenv,
+	}
+}
+
+func NewDefaultManager() *Manager {
+	home := utils.HomeDir()
+	return NewManager(utils.ConfigHome(), home, defaultSearchPath(home), os.Getenv)
+}
+
+func defaultSearchPath(home string) []string {
+	if xcursorPath := os.Getenv("XCURSOR_PATH"); xcursorPath != "" {
+		return filepath.SplitList(xcursorPath)
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	paths := []string{filepath.Join(dataHome, "icons"), filepath.Join(home, ".icons")}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		paths = append(paths, filepath.Join(dir, "icons"))
+	}
+	return append(paths, "/usr/share/pixmaps")
+}
+
+// HyprEnvPath is the Hyprland config file that holds env = lines.
+func (m *Manager) HyprEnvPath() string {
+	return filepath.Join(m.configHome, "hypr", "envs.conf")
+}
+
+// Paths lists every file Changes may write.
+func (m *Manager) Paths() []string {
+	return append([]string{m.HyprEnvPath()}, m.gtkSettingsPaths()...)
+}
+
+func (m *Manager) gtkSettingsPaths() []string {
+	return []string{
+		filepath.Join(m.configHome, "gtk-3.0", "settings.ini"),
+		filepath.Join(m.configHome, "gtk-4.0", "settings.ini"),
+	}
+}
+
+// CurrentThemeName works out the active cursor theme the same way the
+// toolkits do: explicit environment first, then GTK settings, then the
+// default icon theme's Inherits line.
+func (m *Manager) CurrentThemeName() string {
+	for _, key := range []string{"HYPRCURSOR_THEME", "XCURSOR_THEME"} {
+		if value := strings.TrimSpace(m.getenv(key)); value != "" {
+			return value
+		}
+	}
+
+	for _, path := range m.gtkSettingsPaths() {
+		if name := findValue(path, gtkThemeNamePattern); name != "" {
+			return strings.Trim(name, `"'`)
+		}
+	}
+
+	for _, dir := range []string{filepath.Join(m.home, ".icons"), filepath.Join(m.home, ".local", "share", "icons")} {
+		if name := findValue(filepath.Join(dir, "default", "index.theme"), inheritsPattern); name != "" {
+			return strings.TrimSpace(strings.Split(name, ",")[0])
+		}
+	}
+
+	return DefaultTheme
+}
+
+// LookupTheme finds the named theme on the search path and reads the sizes
+// it ships from its cursor files.
+func (m *Manager) LookupTheme(name string) (Theme, error) {
+	for _, base := range m.searchPath {
+		dir := filepath.Join(base, name)
+
+		if info, err := os.Stat(filepath.Join(dir, "hyprcursors")); err == nil && info.IsDir() {
+			return Theme{Name: name, Dir: dir, Scalable: true}, nil
+		}
+
+		cursorsDir := filepath.Join(dir, "cursors")
+		if info, err := os.Stat(cursorsDir); err != nil || !info.IsDir() {
+			continue
+		}
+
+		for _, file := range []string{"left_ptr", "default", "arrow"} {
+			sizes, err := readXcursorSizes(filepath.Join(cursorsDir, file))
+			if err == nil && len(sizes) > 0 {
+				return Theme{Name: name, Dir: dir, Sizes: sizes}, nil
+			}
+		}
+	}
+
+	return Theme{}, fmt.Errorf("cursor theme %q not found", name)
+}
+
+// CurrentTheme returns the active theme, falling back to an unvalidated
+// description when it isn't installed in any known location.
+func (m *Manager) CurrentTheme() (Theme, error) {
+	name := m.CurrentThemeName()
+	theme, err := m.LookupTheme(name)
+	if err != nil {
+		return Theme{Name: name}, err
+	}
+	return theme, nil
+}
+
+// Changes computes the env and GTK settings edits for size without writing.
+func (m *Manager) Changes(size int) ([]Change, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid cursor size: %d", size)
+	}
+
+	var changes []Change
+
+	envPath := m.HyprEnvPath()
+	before, err := readOptional(envPath)
+	if err != nil {
+		return nil, err
+	}
+	after := SetEnvLine(before, "XCURSOR_SIZE", strconv.Itoa(size))
+	after = SetEnvLine(after, "HYPRCURSOR_SIZE", strconv.Itoa(size))
+	if after != before {
+		changes = append(changes, Change{Path: envPath, Before: before, After: after})
+	}
+
+	gtkPaths := m.gtkSettingsPaths()
+	var existing []string
+	for _, path := range gtkPaths {
+		if utils.FileExists(path) {
+			existing = append(existing, path)
+		}
+	}
+	if len(existing) == 0 {
+		existing = gtkPaths[:1]
+	}
+
+	for _, path := range existing {
+		before, err := readOptional(path)
+		if err != nil {
+			return nil, err
+		}
+		after := setGTKCursorSize(before, size)
+		if after != before {
+			changes = append(changes, Change{Path: path, Before: before, After: after})
+		}
+	}
+
+	return changes, nil
+}
+
+// CurrentSize is the cursor size the Hyprland env file sets, or DefaultSize
+// when it sets none.
+func (m *Manager) CurrentSize() (int, error) {
+	content, err := readOptional(m.HyprEnvPath())
+	if err != nil {
+		return 0, err
+	}
+	if value, ok := EnvValue(content, "XCURSOR_SIZE"); ok {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			return size, nil
+		}
+	}
+	return DefaultSize, nil
+}
+
+// Commit writes the given changes to disk.
+func (m *Manager) Commit(changes []Change) error {
+	for _, change := range changes {
+		if err := utils.WriteFileAtomic(change.Path, []byte(change.After), 0644); err != nil {
+			return fmt.Errorf("failed to update %s: %w", change.Path, err)
+		}
+	}
+	return nil
+}
+
+// RecommendedSize maps a monitor scale onto the nearest size commonly
+// shipped by cursor themes, preferring the larger size on a tie.
+func RecommendedSize(monitorScale float64) int {
+	target := float64(DefaultSize) * monitorScale
+
+	best := commonSizes[0]
+	for _, size := range commonSizes {
+		if math.Abs(float64(size)-target) <= math.Abs(float64(best)-target) {
+			best = size
+		}
+	}
+	return best
+}
+
+func readXcursorSizes(path string) ([]int, error) {
+	f, err := os.Open(path) // nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != xcursorMagic {
+		return nil, errors.New("not an Xcursor file")
+	}
+
+	headerSize := binary.LittleEndian.Uint32(header[4:8])
+	ntoc := binary.LittleEndian.Uint32(header[12:16])
+	if ntoc > 1024 {
+		return nil, errors.New("implausible Xcursor table of contents")
+	}
+	if headerSize > 16 {
+		if _, err := f.Seek(int64(headerSize), io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[int]bool)
+	entry := make([]byte, 12)
+	for i := uint32(0); i < ntoc; i++ {
+		if _, err := io.ReadFull(f, entry); err != nil {
+			return nil, err
+		}
+		if binary.LittleEndian.Uint32(entry[0:4]) == xcursorImageType {
+			seen[int(binary.LittleEndian.Uint32(entry[4:8]))] = true
+		}
+	}
+
+	sizes := make([]int, 0, len(seen))
+	for size := range seen {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+	return sizes, nil
+}
+
+func findValue(path string, pattern *regexp.Regexp) string {
+	f, err := os.Open(path) // nosec G304
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if match := pattern.FindStringSubmatch(scanner.Text()); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+func readOptional(path string) (string, error) {
+	data, err := os.ReadFile(path) // nosec G304
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// EnvValue is the value of the last "env = KEY,value" line for key in
+// content, such as the Hyprland env file.
+func EnvValue(content, key string) (string, bool) {
+	pattern := envLinePattern(key)
+	value, found := "", false
+	for _, line := range strings.Split(content, "\n") {
+		if match := pattern.FindStringSubmatch(line); match != nil {
+			value, found = match[2], true
+		}
+	}
+	return value, found
+}
+
+func envLinePattern(key string) *regexp.Regexp {
+	return regexp.MustCompile(`^(\s*env\s*=\s*` + regexp.QuoteMeta(key) + `\s*,\s*)([^#]*?)(\s*(?:#.*)?)$`)
+}
+
+// SetEnvLine updates (or appends) a Hyprland "env = KEY,value" line.
+func SetEnvLine(content, key, value string) string {
+	pattern := envLinePattern(key)
+
+	lines := strings.Split(content, "\n")
+	replaced := false
+	for i, line := range lines {
+		if pattern.MatchString(line) {
+			lines[i] = pattern.ReplaceAllString(line, "${1}"+value+"${3}")
+			replaced = true
+		}
+	}
+	if replaced {
+		return strings.Join(lines, "\n")
+	}
+
+	entry := fmt.Sprintf("env = %s,%s\n", key, value)
+	if content == "" {
+		return entry
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + entry
+}
+
+func setGTKCursorSize(content string, size int) string {
+	value := strconv.Itoa(size)
+	lines := strings.Split(content, "\n")
+	settingsHeader := -1
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "[Settings]" {
+			settingsHeader = i
+		}
+		if gtkThemeSizePattern.MatchString(line) {
+			lines[i] = gtkThemeSizePattern.ReplaceAllString(line, "${1}"+value+"${3}")
+			return strings.Join(lines, "\n")
+		}
+	}
+
+	entry := "gtk-cursor-theme-size=" + value
+	if settingsHeader >= 0 {
+		lines = append(lines[:settingsHeader+1], append([]string{entry}, lines[settingsHeader+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + "[Settings]\n" + entry + "\n"
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
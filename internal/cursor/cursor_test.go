@@ -0,0 +1,208 @@
+package cursor
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+// xcursorFile builds a minimal Xcursor file whose table of contents lists
+// one image per size, plus a comment entry that must be ignored.
+func xcursorFile(sizes ...int) []byte {
+	buf := []byte(xcursorMagic)
+	buf = binary.LittleEndian.AppendUint32(buf, 16)
+	buf = binary.LittleEndian.AppendUint32(buf, 0x10000)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(sizes)+1))
+	for _, size := range sizes {
+		buf = binary.LittleEndian.AppendUint32(buf, xcursorImageType)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(size))
+		buf = binary.LittleEndian.AppendUint32(buf, 0)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, 0xfffe0001)
+	buf = binary.LittleEndian.AppendUint32(buf, 1)
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	return buf
+}
+
+func newTestManager(t *testing.T, env map[string]string) (*Manager, string, string) {
+	t.Helper()
+	configHome := t.TempDir()
+	icons := t.TempDir()
+	getenv := func(key string) string { return env[key] }
+	return NewManager(configHome, t.TempDir(), []string{icons}, getenv), configHome, icons
+}
+
+func TestLookupThemeReadsXcursorSizes(t *testing.T) {
+	manager, _, icons := newTestManager(t, nil)
+	writeFile(t, filepath.Join(icons, "Bibata", "cursors", "left_ptr"), xcursorFile(48, 24, 32, 24))
+
+	theme, err := manager.LookupTheme("Bibata")
+	if err != nil {
+		t.Fatalf("LookupTheme failed: %v", err)
+	}
+	if got := theme.Sizes; len(got) != 3 || got[0] != 24 || got[1] != 32 || got[2] != 48 {
+		t.Errorf("Expected sizes [24 32 48], got %v", got)
+	}
+	if !theme.Supports(32) || theme.Supports(64) {
+		t.Errorf("Unexpected Supports results for sizes %v", theme.Sizes)
+	}
+	if got := theme.Nearest(64); got != 48 {
+		t.Errorf("Expected nearest size 48, got %d", got)
+	}
+	if got := theme.Nearest(40); got != 48 {
+		t.Errorf("Expected ties to prefer the larger size, got %d", got)
+	}
+
+	if _, err := manager.LookupTheme("Missing"); err == nil {
+		t.Error("Expected error for missing theme")
+	}
+}
+
+func TestLookupThemeHyprcursorIsScalable(t *testing.T) {
+	manager, _, icons := newTestManager(t, nil)
+	if err := os.MkdirAll(filepath.Join(icons, "Vector", "hyprcursors"), 0750); err != nil {
+		t.Fatalf("Failed to create theme: %v", err)
+	}
+
+	theme, err := manager.LookupTheme("Vector")
+	if err != nil {
+		t.Fatalf("LookupTheme failed: %v", err)
+	}
+	if !theme.Scalable || !theme.Supports(57) || theme.Nearest(57) != 57 {
+		t.Errorf("Expected scalable theme to support any size, got %+v", theme)
+	}
+}
+
+func TestCurrentThemeName(t *testing.T) {
+	manager, configHome, _ := newTestManager(t, map[string]string{"XCURSOR_THEME": "FromEnv"})
+	if got := manager.CurrentThemeName(); got != "FromEnv" {
+		t.Errorf("Expected environment to win, got %q", got)
+	}
+
+	manager, configHome, _ = newTestManager(t, nil)
+	if got := manager.CurrentThemeName(); got != DefaultTheme {
+		t.Errorf("Expected default theme, got %q", got)
+	}
+
+	writeFile(t, filepath.Join(configHome, "gtk-3.0", "settings.ini"), []byte("[Settings]\ngtk-cursor-theme-name=\"Bibata\"\n"))
+	if got := manager.CurrentThemeName(); got != "Bibata" {
+		t.Errorf("Expected GTK theme, got %q", got)
+	}
+}
+
+func TestChangesUpdatesEnvAndGTK(t *testing.T) {
+	manager, configHome, _ := newTestManager(t, nil)
+
+	envPath := filepath.Join(configHome, "hypr", "envs.conf")
+	writeFile(t, envPath, []byte("# Cursor\nenv = XCURSOR_SIZE,24 # default\nenv = GDK_SCALE,1\n"))
+	gtk4Path := filepath.Join(configHome, "gtk-4.0", "settings.ini")
+	writeFile(t, gtk4Path, []byte("[Settings]\ngtk-theme-name=Adwaita\ngtk-cursor-theme-size = 24\n"))
+
+	changes, err := manager.Changes(48)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+
+	expectedEnv := "# Cursor\nenv = XCURSOR_SIZE,48 # default\nenv = GDK_SCALE,1\nenv = HYPRCURSOR_SIZE,48\n"
+	if changes[0].Path != envPath || changes[0].After != expectedEnv {
+		t.Errorf("Unexpected env change:\n%s", changes[0].After)
+	}
+	if changes[1].Path != gtk4Path || !strings.Contains(changes[1].After, "gtk-cursor-theme-size = 48") {
+		t.Errorf("Unexpected GTK change:\n%s", changes[1].After)
+	}
+
+	if err := manager.Commit(changes); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	again, err := manager.Changes(48)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("Expected no changes after commit, got %d", len(again))
+	}
+}
+
+func TestCurrentSize(t *testing.T) {
+	manager, configHome, _ := newTestManager(t, nil)
+
+	if size, err := manager.CurrentSize(); err != nil || size != DefaultSize {
+		t.Errorf("Expected the default without an env file, got %d, %v", size, err)
+	}
+	writeFile(t, filepath.Join(configHome, "hypr", "envs.conf"), []byte("env = XCURSOR_SIZE,48 # large\n"))
+	if size, err := manager.CurrentSize(); err != nil || size != 48 {
+		t.Errorf("Expected 48 from the env file, got %d, %v", size, err)
+	}
+}
+
+func TestChangesCreatesGTKSettings(t *testing.T) {
+	manager, configHome, _ := newTestManager(t, nil)
+
+	changes, err := manager.Changes(32)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+	if changes[1].Path != filepath.Join(configHome, "gtk-3.0", "settings.ini") {
+		t.Errorf("Expected gtk-3.0 settings to be created, got %s", changes[1].Path)
+	}
+	if changes[1].After != "[Settings]\ngtk-cursor-theme-size=32\n" {
+		t.Errorf("Unexpected GTK settings:\n%s", changes[1].After)
+	}
+
+	if _, err := manager.Changes(0); err == nil {
+		t.Error("Expected error for zero size")
+	}
+}
+
+func TestRecommendedSize(t *testing.T) {
+	tests := []struct {
+		scale    float64
+		expected int
+	}{
+		{1.0, 24},
+		{1.25, 32},
+		{1.5, 32},
+		{1.66667, 48},
+		{2.0, 48},
+		{2.5, 64},
+		{3.0, 64},
+	}
+
+	for _, tt := range tests {
+		if got := RecommendedSize(tt.scale); got != tt.expected {
+			t.Errorf("RecommendedSize(%.2f) = %d, expected %d", tt.scale, got, tt.expected)
+		}
+	}
+}
+
+func TestEnvValue(t *testing.T) {
+	content := "# Cursor\nenv = XCURSOR_SIZE,24 # default\nenv = GDK_SCALE,1\nenv = XCURSOR_SIZE , 32\n"
+
+	if value, ok := EnvValue(content, "XCURSOR_SIZE"); !ok || value != "32" {
+		t.Errorf("Expected the last XCURSOR_SIZE line, got %q, %v", value, ok)
+	}
+	if value, ok := EnvValue(content, "GDK_SCALE"); !ok || value != "1" {
+		t.Errorf("Expected GDK_SCALE=1, got %q, %v", value, ok)
+	}
+	if _, ok := EnvValue(content, "XFT_DPI"); ok {
+		t.Error("Expected XFT_DPI to be missing")
+	}
+}
@@ -0,0 +1,193 @@
+package doctor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bundleVariables are the environment variables included in a bundle.
+var bundleVariables = append([]string{
+	"HYPRLAND_INSTANCE_SIGNATURE",
+	"WAYLAND_DISPLAY",
+	"XDG_CURRENT_DESKTOP",
+	"XDG_SESSION_TYPE",
+	"XCURSOR_THEME",
+	"XFT_DPI",
+	"TERM",
+}, scalingVariables...)
+
+// Redactor scrubs the home directory, user name, host name and Hyprland
+// instance signature from anything put in a bundle.
+type Redactor struct {
+	pattern      *regexp.Regexp
+	replacements map[string]string
+}
+
+func NewRedactor(env Environment) *Redactor {
+	type secret struct{ value, replacement string }
+	var secrets []secret
+	add := func(value, replacement string) {
+		if value != "" {
+			secrets = append(secrets, secret{value, replacement})
+		}
+	}
+
+	add(env.HomeDir, "~")
+	add(env.Getenv("HYPRLAND_INSTANCE_SIGNATURE"), "<instance>")
+	add(env.Hostname, "<host>")
+	add(env.User, "<user>")
+
+	// Longest first, so the home directory wins over the user name in it
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return len(secrets[i].value) > len(secrets[j].value)
+	})
+
+	r := &Redactor{replacements: make(map[string]string)}
+	var alternatives []string
+	for _, s := range secrets {
+		if _, ok := r.replacements[s.value]; ok {
+			continue
+		}
+		r.replacements[s.value] = s.replacement
+		alternatives = append(alternatives, boundedPattern(s.value))
+	}
+	if len(alternatives) > 0 {
+		r.pattern = regexp.MustCompile(strings.Join(alternatives, "|"))
+	}
+	return r
+}
+
+// boundedPattern matches value only where it isn't part of a longer word,
+// so a user called "al" leaves "alacritty" alone but not "/home/al/".
+func boundedPattern(value string) string {
+	pattern := regexp.QuoteMeta(value)
+	if isWordByte(value[0]) {
+		pattern = `\b` + pattern
+	}
+	if isWordByte(value[len(value)-1]) {
+		pattern += `\b`
+	}
+	return pattern
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+func (r *Redactor) Redact(text string) string {
+	if r.pattern == nil {
+		return text
+	}
+	return r.pattern.ReplaceAllStringFunc(text, func(match string) string {
+		return r.replacements[match]
+	})
+}
+
+// WriteBundle writes a gzipped tarball for bug reports: the report, raw
+// monitor tool output, relevant environment variables, the Hyprland and
+// managed config files and the log, all redacted.
+func WriteBundle(path string, env Environment, report Report, now time.Time) error {
+	redactor := NewRedactor(env)
+	files := bundleFiles(env, report)
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := []byte(redactor.Redact(files[name]))
+		header := &tar.Header{
+			Name:    "omarchy-monitor-settings-doctor/" + name,
+			Mode:    0600,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			out.Close()
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			out.Close()
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// bundleFiles collects the bundle's contents by name, before redaction.
+func bundleFiles(env Environment, report Report) map[string]string {
+	files := map[string]string{"report.txt": report.String()}
+
+	for name, args := range map[string][]string{
+		"commands/hyprctl-version.txt":  {"hyprctl", "version"},
+		"commands/hyprctl-monitors.txt": {"hyprctl", "monitors", "all"},
+		"commands/wlr-randr.txt":        {"wlr-randr"},
+	} {
+		if _, err := env.LookPath(args[0]); err != nil {
+			continue
+		}
+		output, err := env.Run(args[0], args[1:]...)
+		text := string(output)
+		if err != nil {
+			text += fmt.Sprintf("\n(exit: %v)\n", err)
+		}
+		files[name] = text
+	}
+
+	var vars strings.Builder
+	for _, name := range bundleVariables {
+		fmt.Fprintf(&vars, "%s=%s\n", name, env.Getenv(name))
+	}
+	files["environment.txt"] = vars.String()
+
+	configs := []string{hyprlandConfigPath(env), monitorsConfPath(env)}
+	configs = append(configs, env.ManagedFiles...)
+	for _, path := range configs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(env.ConfigHome, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			rel = filepath.Base(path)
+		}
+		files[filepath.ToSlash(filepath.Join("config", rel))] = string(data)
+	}
+
+	if env.LogFile != "" {
+		if data, err := os.ReadFile(env.LogFile); err == nil {
+			files["log/"+filepath.Base(env.LogFile)] = string(data)
+		}
+	}
+
+	return files
+}
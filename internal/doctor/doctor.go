@@ -0,0 +1,539 @@
+// Package doctor diagnoses the display environment: the Hyprland session and
+// its IPC socket, the monitor tools, the kernel's view of connected outputs,
+// the config files this application reads and writes, and environment
+// variables that fight with per-monitor scaling.
+package doctor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+type Status int
+
+const (
+	Pass Status = iota
+	Warn
+	Fail
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Warn:
+		return "WARN"
+	default:
+		return "FAIL"
+	}
+}
+
+// Check is the outcome of one diagnosis. Fix says what to do about a warning
+// or failure.
+type Check struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+type Report struct {
+	Checks []Check
+}
+
+// Count returns how many checks ended with status.
+func (r Report) Count(status Status) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+
+	width := 0
+	for _, check := range r.Checks {
+		if len(check.Name) > width {
+			width = len(check.Name)
+		}
+	}
+
+	for _, check := range r.Checks {
+		fmt.Fprintf(&b, "%s  %-*s  %s\n", check.Status, width, check.Name, check.Detail)
+		if check.Fix != "" && check.Status != Pass {
+			fmt.Fprintf(&b, "      %-*s  Fix: %s\n", width, "", check.Fix)
+		}
+	}
+	fmt.Fprintf(&b, "\n%d passed, %d warnings, %d failed\n", r.Count(Pass), r.Count(Warn), r.Count(Fail))
+	return b.String()
+}
+
+// Environment is everything the checks look at, so they can run against a
+// fake system in tests.
+type Environment struct {
+	Getenv   func(string) string
+	LookPath func(string) (string, error)
+	Run      func(name string, args ...string) ([]byte, error)
+	// Dial reports whether a unix socket accepts connections.
+	Dial   func(path string) error
+	Detect func() ([]monitor.Monitor, error)
+
+	HomeDir    string
+	ConfigHome string
+	RuntimeDir string
+	// DRMDir is the kernel's DRM class directory, normally /sys/class/drm.
+	DRMDir string
+	// ProfileFiles are shell profiles and environment.d files where a
+	// variable would apply to the whole session.
+	ProfileFiles []string
+	ManagedFiles []string
+	LogFile      string
+	User         string
+	Hostname     string
+}
+
+// DefaultEnvironment looks at the real system.
+func DefaultEnvironment() Environment {
+	home := utils.HomeDir()
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+
+	profiles := []string{"/etc/environment"}
+	for _, name := range []string{".profile", ".bash_profile", ".bashrc", ".zprofile", ".zshenv", ".zshrc"} {
+		profiles = append(profiles, filepath.Join(home, name))
+	}
+	if matches, err := filepath.Glob(filepath.Join(utils.ConfigHome(), "environment.d", "*.conf")); err == nil {
+		profiles = append(profiles, matches...)
+	}
+
+	commands := runner.NewExec(runner.DefaultTimeout)
+	env := Environment{
+		Getenv:   os.Getenv,
+		LookPath: exec.LookPath,
+		Run: func(name string, args ...string) ([]byte, error) {
+			result, err := commands.Run(context.Background(), name, args...)
+			return result.Output(), err
+		},
+		Dial: func(path string) error {
+			conn, err := net.DialTimeout("unix", path, time.Second)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+		Detect:       monitor.NewDetector().DetectMonitors,
+		HomeDir:      home,
+		ConfigHome:   utils.ConfigHome(),
+		RuntimeDir:   runtimeDir,
+		DRMDir:       "/sys/class/drm",
+		ProfileFiles: profiles,
+	}
+
+	if current, err := user.Current(); err == nil {
+		env.User = current.Username
+	}
+	env.Hostname, _ = os.Hostname()
+	return env
+}
+
+// Run performs every check in order.
+func Run(env Environment) Report {
+	var report Report
+	add := func(check Check) {
+		report.Checks = append(report.Checks, check)
+	}
+
+	add(checkSession(env))
+	add(checkWayland(env))
+	add(checkSocket(env))
+	hyprctl := checkTool(env, "hyprctl", "version")
+	add(hyprctl)
+	wlrRandr := checkTool(env, "wlr-randr", "--version")
+	add(wlrRandr)
+	if hyprctl.Status != Pass && wlrRandr.Status != Pass {
+		add(Check{
+			Name:   "Detection tools",
+			Status: Fail,
+			Detail: "neither hyprctl nor wlr-randr is available; only demo monitors can be shown",
+			Fix:    "Install Hyprland (which provides hyprctl) or wlr-randr",
+		})
+	}
+	add(checkDetection(env))
+	add(checkConnectors(env))
+	add(checkHyprlandConfig(env))
+	add(checkMonitorsConf(env))
+	add(checkManagedFiles(env))
+	report.Checks = append(report.Checks, checkEnvConflicts(env)...)
+
+	return report
+}
+
+func checkSession(env Environment) Check {
+	check := Check{Name: "Hyprland session"}
+	if signature := env.Getenv("HYPRLAND_INSTANCE_SIGNATURE"); signature != "" {
+		check.Status = Pass
+		check.Detail = "HYPRLAND_INSTANCE_SIGNATURE is set"
+		return check
+	}
+	check.Status = Fail
+	check.Detail = "HYPRLAND_INSTANCE_SIGNATURE is not set"
+	check.Fix = "Run this from a terminal inside your Hyprland session, not over SSH or from a TTY"
+	return check
+}
+
+func checkWayland(env Environment) Check {
+	check := Check{Name: "Wayland display"}
+	if display := env.Getenv("WAYLAND_DISPLAY"); display != "" {
+		check.Status = Pass
+		check.Detail = fmt.Sprintf("WAYLAND_DISPLAY=%s (XDG_CURRENT_DESKTOP=%s)", display, valueOrUnset(env.Getenv("XDG_CURRENT_DESKTOP")))
+		return check
+	}
+	check.Status = Warn
+	check.Detail = "WAYLAND_DISPLAY is not set"
+	check.Fix = "Run this from a terminal inside your Wayland session"
+	return check
+}
+
+// socketPaths lists where Hyprland puts its IPC socket: under
+// $XDG_RUNTIME_DIR since 0.40, and under /tmp before that.
+func socketPaths(env Environment, signature string) []string {
+	return []string{
+		filepath.Join(env.RuntimeDir, "hypr", signature, ".socket.sock"),
+		filepath.Join("/tmp", "hypr", signature, ".socket.sock"),
+	}
+}
+
+func checkSocket(env Environment) Check {
+	check := Check{Name: "Hyprland IPC socket"}
+
+	signature := env.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if signature == "" {
+		check.Status = Fail
+		check.Detail = "no instance signature to find the socket with"
+		check.Fix = "Run this from inside your Hyprland session"
+		return check
+	}
+
+	var lastErr error
+	for _, path := range socketPaths(env, signature) {
+		if err := env.Dial(path); err != nil {
+			lastErr = err
+			continue
+		}
+		check.Status = Pass
+		check.Detail = "reachable at " + path
+		return check
+	}
+
+	check.Status = Fail
+	check.Detail = fmt.Sprintf("not reachable: %v", lastErr)
+	check.Fix = "Hyprland may have restarted; open a new terminal so it picks up the current HYPRLAND_INSTANCE_SIGNATURE"
+	return check
+}
+
+func checkTool(env Environment, name string, versionArg string) Check {
+	check := Check{Name: name}
+
+	path, err := env.LookPath(name)
+	if err != nil {
+		check.Status = Warn
+		check.Detail = "not found in PATH"
+		if name == "hyprctl" {
+			check.Fix = "hyprctl ships with Hyprland; make sure Hyprland is installed and on PATH"
+		} else {
+			check.Fix = "Install wlr-randr for detection outside Hyprland"
+		}
+		return check
+	}
+
+	check.Status = Pass
+	check.Detail = path
+	if output, err := env.Run(name, versionArg); err == nil {
+		if version := firstLine(string(output)); version != "" {
+			check.Detail = fmt.Sprintf("%s (%s)", path, version)
+		}
+	}
+	return check
+}
+
+func checkDetection(env Environment) Check {
+	check := Check{Name: "Monitor detection"}
+
+	monitors, err := env.Detect()
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Fix = "Run 'hyprctl monitors' and include its output when filing a bug"
+		return check
+	}
+	if len(monitors) == 0 {
+		check.Status = Fail
+		check.Detail = "no monitors detected"
+		check.Fix = "Run 'hyprctl monitors' and include its output when filing a bug"
+		return check
+	}
+
+	var names []string
+	for _, mon := range monitors {
+		names = append(names, fmt.Sprintf("%s %dx%d@%.2f x%.2f", mon.Name, mon.Width, mon.Height, mon.RefreshRate, mon.Scale))
+	}
+	check.Status = Pass
+	check.Detail = strings.Join(names, ", ")
+	return check
+}
+
+// checkConnectors reads what the kernel thinks is plugged in, which helps
+// tell a compositor problem from a cable or driver problem.
+func checkConnectors(env Environment) Check {
+	check := Check{Name: "DRM connectors"}
+
+	statuses, err := filepath.Glob(filepath.Join(env.DRMDir, "card*-*", "status"))
+	if err != nil || len(statuses) == 0 {
+		check.Status = Warn
+		check.Detail = "no connectors found under " + env.DRMDir
+		check.Fix = "Make sure the GPU driver is loaded (check 'lsmod' and 'dmesg')"
+		return check
+	}
+
+	var connected []string
+	for _, statusPath := range statuses {
+		data, err := os.ReadFile(statusPath)
+		if err != nil || strings.TrimSpace(string(data)) != "connected" {
+			continue
+		}
+		// card1-eDP-1 -> eDP-1
+		name := filepath.Base(filepath.Dir(statusPath))
+		if _, connector, ok := strings.Cut(name, "-"); ok {
+			name = connector
+		}
+		connected = append(connected, name)
+	}
+
+	if len(connected) == 0 {
+		check.Status = Warn
+		check.Detail = fmt.Sprintf("%d connectors, none connected", len(statuses))
+		check.Fix = "Check cables and that the display is powered on"
+		return check
+	}
+
+	check.Status = Pass
+	check.Detail = fmt.Sprintf("%d connected: %s", len(connected), strings.Join(connected, ", "))
+	return check
+}
+
+func hyprlandConfigPath(env Environment) string {
+	return filepath.Join(env.ConfigHome, "hypr", "hyprland.conf")
+}
+
+func monitorsConfPath(env Environment) string {
+	return filepath.Join(env.ConfigHome, "hypr", "monitors.conf")
+}
+
+func checkHyprlandConfig(env Environment) Check {
+	check := Check{Name: "Hyprland config"}
+	path := hyprlandConfigPath(env)
+	if !utils.FileExists(path) {
+		check.Status = Fail
+		check.Detail = path + " not found"
+		check.Fix = "Hyprland falls back to an autogenerated config; create " + path
+		return check
+	}
+	check.Status = Pass
+	check.Detail = path
+	return check
+}
+
+func checkMonitorsConf(env Environment) Check {
+	check := Check{Name: "monitors.conf"}
+	path := monitorsConfPath(env)
+
+	if !utils.FileExists(path) {
+		check.Status = Warn
+		check.Detail = path + " not found"
+		check.Fix = "Create it with your monitor = lines and add 'source = " + tildePath(env, path) + "' to hyprland.conf"
+		return check
+	}
+
+	sourced := sourcedFiles(env, hyprlandConfigPath(env), make(map[string]bool))
+	if !sourced[filepath.Clean(path)] {
+		check.Status = Warn
+		check.Detail = "exists but is not sourced from hyprland.conf"
+		check.Fix = "Add 'source = " + tildePath(env, path) + "' to hyprland.conf"
+		return check
+	}
+
+	check.Status = Pass
+	check.Detail = "sourced from hyprland.conf"
+	return check
+}
+
+var sourcePattern = regexp.MustCompile(`^\s*source\s*=\s*(.+?)\s*$`)
+
+// sourcedFiles follows "source =" lines from path, including nested ones.
+func sourcedFiles(env Environment, path string, seen map[string]bool) map[string]bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return seen
+	}
+	defer file.Close()
+
+	var next []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		match := sourcePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		target := expandPath(env, match[1], filepath.Dir(path))
+		matches, err := filepath.Glob(target)
+		if err != nil || len(matches) == 0 {
+			matches = []string{target}
+		}
+		for _, m := range matches {
+			m = filepath.Clean(m)
+			if !seen[m] {
+				seen[m] = true
+				next = append(next, m)
+			}
+		}
+	}
+
+	for _, nested := range next {
+		sourcedFiles(env, nested, seen)
+	}
+	return seen
+}
+
+func expandPath(env Environment, path, relativeTo string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = filepath.Join(env.HomeDir, strings.TrimPrefix(path, "~"))
+	}
+	path = strings.ReplaceAll(path, "$HOME", env.HomeDir)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(relativeTo, path)
+	}
+	return path
+}
+
+func tildePath(env Environment, path string) string {
+	if env.HomeDir != "" && strings.HasPrefix(path, env.HomeDir+string(filepath.Separator)) {
+		return "~" + strings.TrimPrefix(path, env.HomeDir)
+	}
+	return path
+}
+
+func checkManagedFiles(env Environment) Check {
+	check := Check{Name: "Config files", Status: Pass}
+
+	var found []string
+	for _, path := range env.ManagedFiles {
+		if utils.FileExists(path) {
+			found = append(found, tildePath(env, path))
+		}
+	}
+	if len(found) == 0 {
+		check.Detail = "none of the terminal, cursor, GTK or Waybar configs exist yet"
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d of %d found: %s", len(found), len(env.ManagedFiles), strings.Join(found, ", "))
+	return check
+}
+
+// scalingVariables override what the compositor and this application set
+// per monitor.
+var scalingVariables = []string{
+	"GDK_SCALE",
+	"GDK_DPI_SCALE",
+	"QT_SCALE_FACTOR",
+	"QT_SCREEN_SCALE_FACTORS",
+	"QT_AUTO_SCREEN_SCALE_FACTOR",
+	"ELM_SCALE",
+	"XCURSOR_SIZE",
+}
+
+var assignmentPattern = regexp.MustCompile(`^\s*(?:export\s+)?([A-Z_]+)\s*=`)
+
+// checkEnvConflicts flags scaling variables set for the whole session in
+// shell profiles or environment.d, and Qt/GDK overrides in the live
+// environment that defeat per-monitor scaling.
+func checkEnvConflicts(env Environment) []Check {
+	watched := make(map[string]bool)
+	for _, name := range scalingVariables {
+		watched[name] = true
+	}
+
+	var checks []Check
+	for _, path := range env.ProfileFiles {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			match := assignmentPattern.FindStringSubmatch(scanner.Text())
+			if match == nil || !watched[match[1]] {
+				continue
+			}
+			checks = append(checks, Check{
+				Name:   "Environment " + match[1],
+				Status: Warn,
+				Detail: fmt.Sprintf("set globally in %s", tildePath(env, path)),
+				Fix:    fmt.Sprintf("Remove %s from %s and let Hyprland's envs.conf manage it", match[1], tildePath(env, path)),
+			})
+		}
+		file.Close()
+	}
+
+	for _, name := range []string{"GDK_DPI_SCALE", "QT_SCALE_FACTOR", "QT_SCREEN_SCALE_FACTORS"} {
+		if value := env.Getenv(name); value != "" {
+			checks = append(checks, Check{
+				Name:   "Environment " + name,
+				Status: Warn,
+				Detail: fmt.Sprintf("%s=%s overrides per-monitor scaling", name, value),
+				Fix:    "Unset it so applications follow the monitor scale",
+			})
+		}
+	}
+
+	if len(checks) == 0 {
+		checks = append(checks, Check{Name: "Environment", Status: Pass, Detail: "no conflicting scaling variables"})
+	}
+	return checks
+}
+
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func valueOrUnset(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return value
+}
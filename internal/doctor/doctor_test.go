@@ -0,0 +1,256 @@
+package doctor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+)
+
+// healthyEnvironment is a working Hyprland session on a fake system rooted
+// in a temp dir.
+func healthyEnvironment(t *testing.T) (Environment, map[string]string) {
+	t.Helper()
+	root := t.TempDir()
+	home := filepath.Join(root, "home", "alice")
+	vars := map[string]string{
+		"HYPRLAND_INSTANCE_SIGNATURE": "abc123_1700000000",
+		"WAYLAND_DISPLAY":             "wayland-1",
+		"XDG_CURRENT_DESKTOP":         "Hyprland",
+	}
+
+	env := Environment{
+		Getenv: func(key string) string { return vars[key] },
+		LookPath: func(name string) (string, error) {
+			return "/usr/bin/" + name, nil
+		},
+		Run: func(name string, args ...string) ([]byte, error) {
+			switch name + " " + strings.Join(args, " ") {
+			case "hyprctl version":
+				return []byte("Hyprland 0.41.2 built from branch main\n"), nil
+			case "wlr-randr --version":
+				return []byte("wlr-randr 0.4.1\n"), nil
+			case "hyprctl monitors all":
+				return []byte("Monitor eDP-1 (ID 0):\n\t2880x1920@120.00000 at 0x0\n\tdescription: alice's laptop on host-1\n"), nil
+			}
+			return nil, errors.New("unexpected command")
+		},
+		Dial: func(path string) error {
+			if path == filepath.Join(root, "run", "hypr", vars["HYPRLAND_INSTANCE_SIGNATURE"], ".socket.sock") {
+				return nil
+			}
+			return errors.New("connection refused")
+		},
+		Detect: func() ([]monitor.Monitor, error) {
+			return []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1920, RefreshRate: 120, Scale: 2}}, nil
+		},
+		HomeDir:      home,
+		ConfigHome:   filepath.Join(home, ".config"),
+		RuntimeDir:   filepath.Join(root, "run"),
+		DRMDir:       filepath.Join(root, "drm"),
+		ProfileFiles: []string{filepath.Join(home, ".profile")},
+		User:         "alice",
+		Hostname:     "host-1",
+	}
+
+	write(t, filepath.Join(env.ConfigHome, "hypr", "hyprland.conf"), "source = ~/.config/hypr/conf.d/*.conf\n")
+	write(t, filepath.Join(env.ConfigHome, "hypr", "conf.d", "outputs.conf"), "source = ../monitors.conf # nested\n")
+	write(t, filepath.Join(env.ConfigHome, "hypr", "monitors.conf"), "monitor = eDP-1,preferred,auto,2\n")
+	write(t, filepath.Join(env.DRMDir, "card1-eDP-1", "status"), "connected\n")
+	write(t, filepath.Join(env.DRMDir, "card1-DP-1", "status"), "disconnected\n")
+	write(t, filepath.Join(home, ".profile"), "export PATH=$HOME/bin:$PATH\n")
+
+	return env, vars
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func findCheck(t *testing.T, report Report, name string) Check {
+	t.Helper()
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("No %q check in report:\n%s", name, report)
+	return Check{}
+}
+
+func TestRunHealthy(t *testing.T) {
+	env, _ := healthyEnvironment(t)
+
+	report := Run(env)
+	if report.Count(Warn) != 0 || report.Count(Fail) != 0 {
+		t.Fatalf("Expected a clean report, got:\n%s", report)
+	}
+
+	for name, detail := range map[string]string{
+		"hyprctl":           "Hyprland 0.41.2",
+		"DRM connectors":    "1 connected: eDP-1",
+		"monitors.conf":     "sourced from hyprland.conf",
+		"Monitor detection": "eDP-1 2880x1920@120.00 x2.00",
+	} {
+		if check := findCheck(t, report, name); !strings.Contains(check.Detail, detail) {
+			t.Errorf("Expected %s detail to contain %q, got %q", name, detail, check.Detail)
+		}
+	}
+}
+
+func TestRunReportsProblems(t *testing.T) {
+	env, vars := healthyEnvironment(t)
+	delete(vars, "HYPRLAND_INSTANCE_SIGNATURE")
+	vars["QT_SCALE_FACTOR"] = "2"
+	env.LookPath = func(string) (string, error) { return "", errors.New("not found") }
+	write(t, filepath.Join(env.ConfigHome, "hypr", "conf.d", "outputs.conf"), "# nothing here\n")
+	write(t, filepath.Join(env.HomeDir, ".profile"), "export GDK_SCALE=2\n")
+
+	report := Run(env)
+
+	for name, status := range map[string]Status{
+		"Hyprland session":            Fail,
+		"Hyprland IPC socket":         Fail,
+		"hyprctl":                     Warn,
+		"wlr-randr":                   Warn,
+		"Detection tools":             Fail,
+		"monitors.conf":               Warn,
+		"Environment GDK_SCALE":       Warn,
+		"Environment QT_SCALE_FACTOR": Warn,
+	} {
+		if check := findCheck(t, report, name); check.Status != status {
+			t.Errorf("Expected %s to be %s, got %s (%s)", name, status, check.Status, check.Detail)
+		}
+	}
+
+	text := report.String()
+	for _, expected := range []string{
+		"Fix: Add 'source = ~/.config/hypr/monitors.conf' to hyprland.conf",
+		"Fix: Remove GDK_SCALE from ~/.profile",
+		"failed",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, text)
+		}
+	}
+}
+
+func TestWriteBundleRedacts(t *testing.T) {
+	env, _ := healthyEnvironment(t)
+	kitty := filepath.Join(env.ConfigHome, "kitty", "kitty.conf")
+	write(t, kitty, "include /home/alice/themes/dark.conf\n")
+	env.ManagedFiles = []string{kitty}
+	env.LogFile = filepath.Join(t.TempDir(), "app.log")
+	write(t, env.LogFile, "msg=\"loaded monitors\" user=alice\n")
+
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	if err := WriteBundle(path, env, Run(env), time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+
+	contents := readBundle(t, path)
+	for _, name := range []string{
+		"report.txt",
+		"environment.txt",
+		"commands/hyprctl-monitors.txt",
+		"config/hypr/hyprland.conf",
+		"config/hypr/monitors.conf",
+		"config/kitty/kitty.conf",
+		"log/app.log",
+	} {
+		if _, ok := contents[name]; !ok {
+			t.Errorf("Expected %s in bundle, got %v", name, keys(contents))
+		}
+	}
+
+	for name, content := range contents {
+		for _, secret := range []string{"alice", "host-1", "abc123_1700000000", env.HomeDir} {
+			if strings.Contains(content, secret) {
+				t.Errorf("Expected %q to be redacted from %s, got:\n%s", secret, name, content)
+			}
+		}
+	}
+	if !strings.Contains(contents["environment.txt"], "HYPRLAND_INSTANCE_SIGNATURE=<instance>") {
+		t.Errorf("Expected the signature to be replaced, got:\n%s", contents["environment.txt"])
+	}
+	if !strings.Contains(contents["commands/hyprctl-monitors.txt"], "<user>'s laptop on <host>") {
+		t.Errorf("Expected user and host to be replaced, got:\n%s", contents["commands/hyprctl-monitors.txt"])
+	}
+}
+
+func TestRedactorMatchesWholeNames(t *testing.T) {
+	redactor := NewRedactor(Environment{
+		Getenv:   func(string) string { return "" },
+		HomeDir:  "/home/al",
+		User:     "al",
+		Hostname: "alpine-box",
+	})
+
+	tests := []struct {
+		in, want string
+	}{
+		{"include /home/al/themes/dark.conf", "include ~/themes/dark.conf"},
+		{"terminal = alacritty", "terminal = alacritty"},
+		{"user=al host=alpine-box", "user=<user> host=<host>"},
+		{"/home/alice/.config", "/home/alice/.config"},
+		{"owned by al:al", "owned by <user>:<user>"},
+	}
+	for _, tt := range tests {
+		if got := redactor.Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func readBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open bundle: %v", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Bundle is not gzipped: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	contents := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read bundle: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", header.Name, err)
+		}
+		contents[strings.TrimPrefix(header.Name, "omarchy-monitor-settings-doctor/")] = string(data)
+	}
+	return contents
+}
+
+func keys(m map[string]string) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+// Package events follows Hyprland's event socket (socket2), which writes a
+// line for everything that happens in the session, so callers can react to
+// monitors being added, removed or focused without polling.
+package events
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Event is one line from the socket, "name>>data".
+type Event struct {
+	Name string
+	Data string
+}
+
+// Parse splits an event line into its name and data.
+func Parse(line string) Event {
+	name, data, _ := strings.Cut(strings.TrimSpace(line), ">>")
+	return Event{Name: name, Data: data}
+}
+
+// monitorEvents change which monitors there are or which one is focused.
+var monitorEvents = map[string]bool{
+	"monitoradded":     true,
+	"monitoraddedv2":   true,
+	"monitorremoved":   true,
+	"monitorremovedv2": true,
+	"focusedmon":       true,
+	"focusedmonv2":     true,
+	"configreloaded":   true,
+}
+
+// MonitorChange reports whether the event can change the monitors' state.
+func (e Event) MonitorChange() bool {
+	return monitorEvents[e.Name]
+}
+
+// SocketPaths lists where Hyprland puts its event socket: under
+// $XDG_RUNTIME_DIR since 0.40, and under /tmp before that.
+func SocketPaths(runtimeDir, signature string) []string {
+	return []string{
+		filepath.Join(runtimeDir, "hypr", signature, ".socket2.sock"),
+		filepath.Join("/tmp", "hypr", signature, ".socket2.sock"),
+	}
+}
+
+// Dial connects to the event socket of the Hyprland instance with the given
+// signature.
+func Dial(runtimeDir, signature string) (net.Conn, error) {
+	if signature == "" {
+		return nil, errors.New("failed to find the Hyprland event socket: HYPRLAND_INSTANCE_SIGNATURE is not set")
+	}
+	var lastErr error
+	for _, path := range SocketPaths(runtimeDir, signature) {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("failed to connect to the Hyprland event socket: %w", lastErr)
+}
+
+// Stream reads events from r until it ends or ctx is cancelled, which closes
+// r. The channel is closed when reading stops.
+func Stream(ctx context.Context, r io.ReadCloser) <-chan Event {
+	events := make(chan Event)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		r.Close()
+	}()
+
+	go func() {
+		defer close(events)
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				continue
+			}
+			select {
+			case events <- Parse(scanner.Text()):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		line   string
+		event  Event
+		change bool
+	}{
+		{"monitoradded>>DP-1", Event{"monitoradded", "DP-1"}, true},
+		{"monitorremovedv2>>2,DP-1,Dell U2723QE\n", Event{"monitorremovedv2", "2,DP-1,Dell U2723QE"}, true},
+		{"focusedmon>>eDP-1,1", Event{"focusedmon", "eDP-1,1"}, true},
+		{"workspace>>2", Event{"workspace", "2"}, false},
+		{"configreloaded>>", Event{"configreloaded", ""}, true},
+	} {
+		event := Parse(tc.line)
+		if event != tc.event {
+			t.Errorf("Parse(%q) = %+v, want %+v", tc.line, event, tc.event)
+		}
+		if event.MonitorChange() != tc.change {
+			t.Errorf("%q: expected MonitorChange %v", tc.line, tc.change)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	r := io.NopCloser(strings.NewReader("workspace>>1\n\nmonitoradded>>DP-1\n"))
+
+	var got []Event
+	for event := range Stream(context.Background(), r) {
+		got = append(got, event)
+	}
+	if len(got) != 2 || got[1] != (Event{"monitoradded", "DP-1"}) {
+		t.Errorf("Unexpected events: %+v", got)
+	}
+}
+
+func TestStreamStopsOnCancel(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := Stream(ctx, client)
+
+	go func() { _, _ = server.Write([]byte("focusedmon>>DP-1,2\n")) }()
+	if event := <-events; event.Name != "focusedmon" {
+		t.Fatalf("Unexpected event: %+v", event)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no more events after cancelling")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stream to close after cancelling")
+	}
+}
+
+func TestDial(t *testing.T) {
+	if _, err := Dial(t.TempDir(), ""); err == nil {
+		t.Error("Expected an error without a signature")
+	}
+
+	runtimeDir := simtest.SocketDir(t, "hypr")
+
+	if _, err := Dial(runtimeDir, "sig"); err == nil {
+		t.Error("Expected an error with no socket")
+	}
+
+	path := SocketPaths(runtimeDir, "sig")[0]
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create the socket dir: %v", err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	conn, err := Dial(runtimeDir, "sig")
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,540 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+const (
+	entryFile = "entry.json"
+	filesDir  = "files"
+	idLayout  = "20060102-150405"
+)
+
+// ErrNotFound is returned when a history entry doesn't exist.
+var ErrNotFound = errors.New("history entry not found")
+
+// Retention controls how many journal entries are kept. Zero values disable
+// the corresponding limit.
+type Retention struct {
+	MaxEntries int
+	MaxAge     time.Duration
+}
+
+// DefaultRetention keeps the last 50 changes from the past 90 days.
+var DefaultRetention = Retention{
+	MaxEntries: 50,
+	MaxAge:     90 * 24 * time.Hour,
+}
+
+// MonitorState is the live state of one monitor at snapshot time.
+type MonitorState struct {
+	Name        string  `json:"name"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	RefreshRate float64 `json:"refresh_rate"`
+	Scale       float64 `json:"scale"`
+	X           int     `json:"x"`
+	Y           int     `json:"y"`
+}
+
+// FileSnapshot records the content of one config file before a change.
+// Files that didn't exist are recorded too, so restoring removes them.
+type FileSnapshot struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+	SHA256  string      `json:"sha256,omitempty"`
+	Blob    string      `json:"blob,omitempty"`
+}
+
+// Entry is one journal record: who changed what, when and why, together
+// with everything needed to undo it.
+type Entry struct {
+	ID       string         `json:"id"`
+	Time     time.Time      `json:"time"`
+	User     string         `json:"user"`
+	Host     string         `json:"host"`
+	Source   string         `json:"source"`
+	Action   string         `json:"action"`
+	Reason   string         `json:"reason"`
+	Monitors []MonitorState `json:"monitors"`
+	Files    []FileSnapshot `json:"files"`
+}
+
+// Record describes a change that is about to be applied.
+type Record struct {
+	Source   string
+	Action   string
+	Reason   string
+	Monitors []monitor.Monitor
+	Paths    []string
+}
+
+type Journal struct {
+	dir       string
+	retention Retention
+	now       func() time.Time
+}
+
+func NewJournal(dir string, retention Retention) *Journal {
+	return &Journal{
+		dir:       dir,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+func NewDefaultJournal() *Journal {
+	return NewJournal(filepath.Join(utils.StateDir(), "history"), DefaultRetention)
+}
+
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// Record snapshots the given monitors and files before a change is applied,
+// then prunes old entries.
+func (j *Journal) Record(rec Record) (Entry, error) {
+	entry, err := j.record(rec)
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := j.Prune(); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// record writes a new entry. The entry file is written last, so an
+// interrupted snapshot is never listed.
+func (j *Journal) record(rec Record) (Entry, error) {
+	now := j.now()
+
+	entry := Entry{
+		Time:     now,
+		User:     currentUser(),
+		Host:     hostname(),
+		Source:   rec.Source,
+		Action:   rec.Action,
+		Reason:   rec.Reason,
+		Monitors: monitorStates(rec.Monitors),
+	}
+
+	if err := os.MkdirAll(j.dir, 0700); err != nil {
+		return Entry{}, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	entry.ID = now.Format(idLayout)
+	for n := 2; ; n++ {
+		err := os.Mkdir(filepath.Join(j.dir, entry.ID), 0700)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return Entry{}, fmt.Errorf("failed to create history entry: %w", err)
+		}
+		entry.ID = now.Format(idLayout) + "-" + strconv.Itoa(n)
+	}
+	entryDir := filepath.Join(j.dir, entry.ID)
+
+	seen := make(map[string]bool)
+	for _, path := range rec.Paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		snapshot, err := snapshotFile(path, entryDir, len(entry.Files))
+		if err != nil {
+			os.RemoveAll(entryDir)
+			return Entry{}, err
+		}
+		entry.Files = append(entry.Files, snapshot)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		os.RemoveAll(entryDir)
+		return Entry{}, fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(entryDir, entryFile), append(data, '\n'), 0600); err != nil {
+		os.RemoveAll(entryDir)
+		return Entry{}, fmt.Errorf("failed to write history entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// List returns all complete entries, newest first.
+func (j *Journal) List() ([]Entry, error) {
+	dirs, err := os.ReadDir(j.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []Entry
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		entry, err := j.Get(dir.Name())
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(a, b int) bool {
+		if !entries[a].Time.Equal(entries[b].Time) {
+			return entries[a].Time.After(entries[b].Time)
+		}
+		return entries[a].ID > entries[b].ID
+	})
+	return entries, nil
+}
+
+func (j *Journal) Get(id string) (Entry, error) {
+	if id == "" || filepath.Base(id) != id {
+		return Entry{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(j.dir, id, entryFile)) // nosec G304
+	if os.IsNotExist(err) {
+		return Entry{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to read history entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("failed to parse history entry %s: %w", id, err)
+	}
+	return entry, nil
+}
+
+// Content returns the snapshotted content of one file in an entry.
+func (j *Journal) Content(entry Entry, file FileSnapshot) ([]byte, error) {
+	if !file.Existed {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(j.dir, entry.ID, filesDir, file.Blob)) // nosec G304
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot of %s: %w", file.Path, err)
+	}
+	return data, nil
+}
+
+// Restore rolls config files and monitors back to the snapshot taken for
+// id. The current state is recorded first, so a restore can itself be
+// undone. Files are staged next to their targets and only renamed into place
+// once every file has been staged; if a rename still fails, the files that
+// were already replaced are rolled back.
+//
+// applyMonitor sets a monitor's whole line: the monitor it is given carries
+// the mode and position to restore along with the scale. If a monitor can't
+// be restored, the monitors already changed and the files are put back the
+// way they were.
+func (j *Journal) Restore(id, source string, live []monitor.Monitor, applyMonitor func(monitor.Monitor, float64) error) (Entry, error) {
+	entry, err := j.Get(id)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	paths := make([]string, 0, len(entry.Files))
+	for _, file := range entry.Files {
+		paths = append(paths, file.Path)
+	}
+
+	// Pruning waits until the end so the entry being restored can't be
+	// pruned out from under us by the safety snapshot.
+	safety, err := j.record(Record{
+		Source:   source,
+		Action:   "Restore " + id,
+		Reason:   fmt.Sprintf("Snapshot taken before restoring %q", entry.Action),
+		Monitors: live,
+		Paths:    paths,
+	})
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to snapshot current state: %w", err)
+	}
+
+	if err := j.restoreFiles(entry); err != nil {
+		if rollbackErr := j.restoreFiles(safety); rollbackErr != nil {
+			return safety, fmt.Errorf("%w (rollback also failed: %v)", err, rollbackErr)
+		}
+		return safety, err
+	}
+
+	var changed []monitor.Monitor
+	for _, state := range entry.Monitors {
+		for _, mon := range live {
+			if mon.Name != state.Name || state.matches(mon) {
+				continue
+			}
+			if err := applyMonitor(state.monitor(mon), state.Scale); err != nil {
+				err = fmt.Errorf("failed to restore %s: %w", mon.Name, err)
+				return safety, j.rollback(safety, changed, applyMonitor, err)
+			}
+			changed = append(changed, mon)
+		}
+	}
+
+	return safety, j.Prune()
+}
+
+// rollback puts the monitors in changed back as they were, then the files
+// from the safety snapshot, and reports cause with anything that failed.
+func (j *Journal) rollback(safety Entry, changed []monitor.Monitor, applyMonitor func(monitor.Monitor, float64) error, cause error) error {
+	var failures []string
+	for _, mon := range changed {
+		if err := applyMonitor(mon, mon.Scale); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", mon.Name, err))
+		}
+	}
+	if err := j.restoreFiles(safety); err != nil {
+		failures = append(failures, err.Error())
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w (rollback also failed: %s)", cause, strings.Join(failures, "; "))
+	}
+	return fmt.Errorf("%w; everything was put back", cause)
+}
+
+// matches reports whether mon is already in the recorded state. Mode and
+// position are only compared when the snapshot recorded them.
+func (s MonitorState) matches(mon monitor.Monitor) bool {
+	if mon.Scale != s.Scale {
+		return false
+	}
+	if s.Width == 0 || s.Height == 0 || s.RefreshRate == 0 {
+		return true
+	}
+	return mon.Width == s.Width && mon.Height == s.Height && mon.RefreshRate == s.RefreshRate &&
+		mon.Position.X == s.X && mon.Position.Y == s.Y
+}
+
+// monitor is live with the recorded mode and position, when they were
+// recorded, so restoring sets the whole monitor line.
+func (s MonitorState) monitor(live monitor.Monitor) monitor.Monitor {
+	restored := live
+	if s.Width > 0 && s.Height > 0 && s.RefreshRate > 0 {
+		restored.Width, restored.Height, restored.RefreshRate = s.Width, s.Height, s.RefreshRate
+		restored.Position = monitor.Position{X: s.X, Y: s.Y}
+	}
+	return restored
+}
+
+type stagedFile struct {
+	tmp  string
+	path string
+}
+
+func (j *Journal) restoreFiles(entry Entry) error {
+	var staged []stagedFile
+	cleanup := func() {
+		for _, s := range staged {
+			os.Remove(s.tmp)
+		}
+	}
+
+	for _, file := range entry.Files {
+		if !file.Existed {
+			continue
+		}
+		data, err := j.Content(entry, file)
+		if err != nil {
+			cleanup()
+			return err
+		}
+		if file.SHA256 != "" && checksum(data) != file.SHA256 {
+			cleanup()
+			return fmt.Errorf("snapshot of %s is corrupt", file.Path)
+		}
+
+		tmp, err := stageFile(file.Path, data, file.Mode)
+		if err != nil {
+			cleanup()
+			return err
+		}
+		staged = append(staged, stagedFile{tmp: tmp, path: file.Path})
+	}
+
+	for i, s := range staged {
+		if err := os.Rename(s.tmp, s.path); err != nil {
+			for _, rest := range staged[i:] {
+				os.Remove(rest.tmp)
+			}
+			return fmt.Errorf("failed to restore %s: %w", s.path, err)
+		}
+	}
+
+	for _, file := range entry.Files {
+		if file.Existed {
+			continue
+		}
+		if err := os.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// Prune removes entries beyond the retention policy, along with any
+// incomplete snapshot directories.
+func (j *Journal) Prune() error {
+	dirs, err := os.ReadDir(j.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	entries, err := j.List()
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	cutoff := j.now().Add(-j.retention.MaxAge)
+	for i, entry := range entries {
+		if j.retention.MaxEntries > 0 && i >= j.retention.MaxEntries {
+			break
+		}
+		if j.retention.MaxAge > 0 && entry.Time.Before(cutoff) {
+			break
+		}
+		keep[entry.ID] = true
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() || keep[dir.Name()] {
+			continue
+		}
+		// Leave snapshots that are still being written alone.
+		if _, err := os.Stat(filepath.Join(j.dir, dir.Name(), entryFile)); os.IsNotExist(err) {
+			if info, err := dir.Info(); err == nil && j.now().Sub(info.ModTime()) < time.Hour {
+				continue
+			}
+		}
+		if err := os.RemoveAll(filepath.Join(j.dir, dir.Name())); err != nil {
+			return fmt.Errorf("failed to prune history entry %s: %w", dir.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func snapshotFile(path, entryDir string, index int) (FileSnapshot, error) {
+	snapshot := FileSnapshot{Path: path}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return FileSnapshot{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path) // nosec G304
+	if err != nil {
+		return FileSnapshot{}, fmt.Errorf("failed to snapshot %s: %w", path, err)
+	}
+
+	snapshot.Existed = true
+	snapshot.Mode = info.Mode().Perm()
+	snapshot.SHA256 = checksum(data)
+	snapshot.Blob = strconv.Itoa(index) + "-" + filepath.Base(path)
+
+	if err := utils.WriteFileAtomic(filepath.Join(entryDir, filesDir, snapshot.Blob), data, 0600); err != nil {
+		return FileSnapshot{}, fmt.Errorf("failed to snapshot %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+func stageFile(path string, data []byte, mode os.FileMode) (string, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".restore-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	name := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(name)
+		return "", fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(name)
+		return "", fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	if mode == 0 {
+		mode = 0644
+	}
+	if err := os.Chmod(name, mode); err != nil {
+		os.Remove(name)
+		return "", fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	return name, nil
+}
+
+func monitorStates(monitors []monitor.Monitor) []MonitorState {
+	states := make([]MonitorState, 0, len(monitors))
+	for _, m := range monitors {
+		states = append(states, MonitorState{
+			Name:        m.Name,
+			Width:       m.Width,
+			Height:      m.Height,
+			RefreshRate: m.RefreshRate,
+			Scale:       m.Scale,
+			X:           m.Position.X,
+			Y:           m.Position.Y,
+		})
+	}
+	return states
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func hostname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return host
+}
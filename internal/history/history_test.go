@@ -0,0 +1,245 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return string(data)
+}
+
+// newTestJournal returns a journal whose clock advances one minute per call.
+func newTestJournal(t *testing.T, retention Retention) *Journal {
+	t.Helper()
+	journal := NewJournal(t.TempDir(), retention)
+	clock := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	journal.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return journal
+}
+
+func TestRecordAndRestore(t *testing.T) {
+	journal := newTestJournal(t, Retention{})
+	configDir := t.TempDir()
+
+	kitty := filepath.Join(configDir, "kitty.conf")
+	envs := filepath.Join(configDir, "hypr", "envs.conf")
+	writeFile(t, kitty, "font_size 10\n")
+
+	monitors := []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1800, Scale: 1.0}}
+	entry, err := journal.Record(Record{
+		Source:   "test",
+		Action:   "Apply 2x",
+		Reason:   "Too small",
+		Monitors: monitors,
+		Paths:    []string{kitty, envs, kitty},
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if len(entry.Files) != 2 || !entry.Files[0].Existed || entry.Files[1].Existed {
+		t.Fatalf("Unexpected file snapshots: %+v", entry.Files)
+	}
+
+	// Apply the change.
+	writeFile(t, kitty, "font_size 20\n")
+	writeFile(t, envs, "env = XCURSOR_SIZE,48\n")
+	live := []monitor.Monitor{{Name: "eDP-1", Width: 2880, Height: 1800, Scale: 2.0}}
+
+	var restoredScale float64
+	safety, err := journal.Restore(entry.ID, "test", live, func(m monitor.Monitor, scale float64) error {
+		restoredScale = scale
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if got := readFile(t, kitty); got != "font_size 10\n" {
+		t.Errorf("Expected kitty config to be restored, got %q", got)
+	}
+	if _, err := os.Stat(envs); !os.IsNotExist(err) {
+		t.Error("Files that didn't exist before should be removed")
+	}
+	if restoredScale != 1.0 {
+		t.Errorf("Expected monitor scale 1.0 to be restored, got %.2f", restoredScale)
+	}
+
+	// The restore itself is journaled and can be undone.
+	entries, err := journal.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != safety.ID || entries[1].ID != entry.ID {
+		t.Fatalf("Expected the safety snapshot first, got %+v", entries)
+	}
+	if _, err := journal.Restore(safety.ID, "test", monitors, func(monitor.Monitor, float64) error { return nil }); err != nil {
+		t.Fatalf("Restore of safety snapshot failed: %v", err)
+	}
+	if got := readFile(t, kitty); got != "font_size 20\n" {
+		t.Errorf("Expected undo of restore, got %q", got)
+	}
+}
+
+func TestRestoreSetsModeAndPosition(t *testing.T) {
+	journal := newTestJournal(t, Retention{})
+
+	before := []monitor.Monitor{{Name: "DP-1", Width: 3840, Height: 2160, RefreshRate: 60, Scale: 1.5, Position: monitor.Position{X: 1440}}}
+	entry, err := journal.Record(Record{Action: "Apply", Monitors: before})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// Same scale, but the mode and position changed since
+	live := []monitor.Monitor{{Name: "DP-1", Width: 2560, Height: 1440, RefreshRate: 144, Scale: 1.5}}
+	var restored monitor.Monitor
+	if _, err := journal.Restore(entry.ID, "test", live, func(m monitor.Monitor, scale float64) error {
+		restored = m
+		restored.Scale = scale
+		return nil
+	}); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored != before[0] {
+		t.Errorf("Expected the whole monitor line restored as %+v, got %+v", before[0], restored)
+	}
+}
+
+func TestRestoreRollsBackWhenAMonitorFails(t *testing.T) {
+	journal := newTestJournal(t, Retention{})
+	path := filepath.Join(t.TempDir(), "kitty.conf")
+	writeFile(t, path, "font_size 10\n")
+
+	before := []monitor.Monitor{
+		{Name: "eDP-1", Width: 2880, Height: 1920, RefreshRate: 120, Scale: 2},
+		{Name: "DP-1", Width: 3840, Height: 2160, RefreshRate: 60, Scale: 1.5, Position: monitor.Position{X: 1440}},
+	}
+	entry, err := journal.Record(Record{Action: "Apply", Monitors: before, Paths: []string{path}})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	writeFile(t, path, "font_size 15\n")
+	live := []monitor.Monitor{
+		{Name: "eDP-1", Width: 2880, Height: 1920, RefreshRate: 120, Scale: 1.5},
+		{Name: "DP-1", Width: 3840, Height: 2160, RefreshRate: 60, Scale: 1, Position: monitor.Position{X: 1920}},
+	}
+	scales := map[string]float64{"eDP-1": 1.5, "DP-1": 1}
+	_, err = journal.Restore(entry.ID, "test", live, func(m monitor.Monitor, scale float64) error {
+		if m.Name == "DP-1" {
+			return errors.New("hyprctl: invalid scale")
+		}
+		scales[m.Name] = scale
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected the failing monitor to fail the restore")
+	}
+
+	if scales["eDP-1"] != 1.5 {
+		t.Errorf("Expected eDP-1 to be put back at 1.5x, got %v", scales["eDP-1"])
+	}
+	if got := readFile(t, path); got != "font_size 15\n" {
+		t.Errorf("Expected the files to be put back, got %q", got)
+	}
+}
+
+func TestRestoreRejectsCorruptSnapshot(t *testing.T) {
+	journal := newTestJournal(t, Retention{})
+	path := filepath.Join(t.TempDir(), "style.css")
+	writeFile(t, path, "original")
+
+	entry, err := journal.Record(Record{Action: "Apply", Paths: []string{path}})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	writeFile(t, filepath.Join(journal.Dir(), entry.ID, filesDir, entry.Files[0].Blob), "tampered")
+	writeFile(t, path, "changed")
+
+	if _, err := journal.Restore(entry.ID, "test", nil, nil); err == nil {
+		t.Fatal("Expected error for corrupt snapshot")
+	}
+	if got := readFile(t, path); got != "changed" {
+		t.Errorf("A failed restore must leave files untouched, got %q", got)
+	}
+}
+
+func TestGetUnknownEntry(t *testing.T) {
+	journal := newTestJournal(t, Retention{})
+	for _, id := range []string{"missing", "../etc", ""} {
+		if _, err := journal.Get(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): expected ErrNotFound, got %v", id, err)
+		}
+	}
+}
+
+func TestRetention(t *testing.T) {
+	journal := newTestJournal(t, Retention{MaxEntries: 3, MaxAge: 10 * time.Minute})
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		entry, err := journal.Record(Record{Action: "Apply"})
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	entries, err := journal.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].ID != ids[4] || entries[2].ID != ids[2] {
+		t.Fatalf("Expected the 3 newest entries, got %+v", entries)
+	}
+
+	// Jump forward so everything but the next entry is too old.
+	journal.now = func() time.Time { return entries[0].Time.Add(time.Hour) }
+	if _, err := journal.Record(Record{Action: "Apply"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if entries, _ := journal.List(); len(entries) != 1 {
+		t.Errorf("Expected old entries to be pruned, got %d", len(entries))
+	}
+}
+
+func TestRecordUniqueIDs(t *testing.T) {
+	journal := NewJournal(t.TempDir(), Retention{})
+	fixed := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	journal.now = func() time.Time { return fixed }
+
+	first, err := journal.Record(Record{Action: "one"})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	second, err := journal.Record(Record{Action: "two"})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Errorf("Expected unique IDs, both were %s", first.ID)
+	}
+}
@@ -0,0 +1,364 @@
+// Package keymap holds the TUI's key bindings. A preset (default, vim or
+// emacs) supplies every binding, and the user's config file can rebind
+// individual actions on top of it. The help screen and footer are generated
+// from the active keymap, so they always show the keys that really work.
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// ForceQuit always quits, whatever the keymap, so a broken config can't
+// trap anyone in the TUI.
+const ForceQuit = "ctrl+c"
+
+// KeyMap is every action the TUI responds to.
+type KeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Left    key.Binding
+	Right   key.Binding
+	Select  key.Binding
+	Back    key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+	Manual  key.Binding
+	Compare key.Binding
+	Stage   key.Binding
+	Unstage key.Binding
+	Refresh key.Binding
+	Demo    key.Binding
+
+	PageUp   key.Binding
+	PageDown key.Binding
+
+	Undo key.Binding
+	Redo key.Binding
+
+	ApplyDrafts   key.Binding
+	DiscardDrafts key.Binding
+
+	// Preset is the name of the preset the keymap started from.
+	Preset string
+}
+
+// Config is the keymap section of the config file:
+//
+//	keymap:
+//	  preset: vim
+//	  bindings:
+//	    refresh: [R, f5]
+type Config struct {
+	Preset   string              `yaml:"preset"`
+	Bindings map[string][]string `yaml:"bindings"`
+}
+
+// Action names a binding, as used in the config file.
+type Action struct {
+	Name        string
+	Description string
+	binding     func(*KeyMap) *key.Binding
+}
+
+// Actions lists every action in the order the help screen shows them.
+var Actions = []Action{
+	{"up", "Move up", func(k *KeyMap) *key.Binding { return &k.Up }},
+	{"down", "Move down", func(k *KeyMap) *key.Binding { return &k.Down }},
+	{"left", "Adjust values or pick an option to the left", func(k *KeyMap) *key.Binding { return &k.Left }},
+	{"right", "Adjust values or pick an option to the right", func(k *KeyMap) *key.Binding { return &k.Right }},
+	{"select", "Select option or apply changes", func(k *KeyMap) *key.Binding { return &k.Select }},
+	{"back", "Return to the previous screen", func(k *KeyMap) *key.Binding { return &k.Back }},
+	{"help", "Show this help screen", func(k *KeyMap) *key.Binding { return &k.Help }},
+	{"quit", "Quit application", func(k *KeyMap) *key.Binding { return &k.Quit }},
+	{"manual", "Switch to manual scaling (from smart scaling)", func(k *KeyMap) *key.Binding { return &k.Manual }},
+	{"compare", "Compare smart scaling options side by side", func(k *KeyMap) *key.Binding { return &k.Compare }},
+	{"stage", "Stage a monitor's scale to apply with others", func(k *KeyMap) *key.Binding { return &k.Stage }},
+	{"unstage", "Unstage the selected monitor", func(k *KeyMap) *key.Binding { return &k.Unstage }},
+	{"refresh", "Re-detect monitors, or retry a failed detection", func(k *KeyMap) *key.Binding { return &k.Refresh }},
+	{"demo", "Continue with demo monitors when detection fails", func(k *KeyMap) *key.Binding { return &k.Demo }},
+	{"pageup", "Scroll up a page when a screen doesn't fit", func(k *KeyMap) *key.Binding { return &k.PageUp }},
+	{"pagedown", "Scroll down a page when a screen doesn't fit", func(k *KeyMap) *key.Binding { return &k.PageDown }},
+	{"undo", "Undo the last staged change or apply", func(k *KeyMap) *key.Binding { return &k.Undo }},
+	{"redo", "Redo the last undone change", func(k *KeyMap) *key.Binding { return &k.Redo }},
+	{"applydrafts", "Apply every monitor's manual draft together", func(k *KeyMap) *key.Binding { return &k.ApplyDrafts }},
+	{"discarddrafts", "Discard every monitor's manual draft", func(k *KeyMap) *key.Binding { return &k.DiscardDrafts }},
+}
+
+// required actions can't be left without a key.
+var required = map[string]bool{"select": true, "back": true, "quit": true}
+
+// Binding returns the binding for an action in k.
+func (a Action) Binding(k *KeyMap) key.Binding {
+	return *a.binding(k)
+}
+
+// Presets lists the preset names.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var presets = map[string]map[string][]string{
+	"default": {
+		"up":       {"up", "k"},
+		"down":     {"down", "j"},
+		"left":     {"left"},
+		"right":    {"right"},
+		"select":   {"enter", " "},
+		"back":     {"esc"},
+		"help":     {"h", "?"},
+		"quit":     {"q"},
+		"manual":   {"m"},
+		"compare":  {"c"},
+		"stage":    {"s"},
+		"unstage":  {"x"},
+		"refresh":  {"r"},
+		"demo":     {"d"},
+		"pageup":   {"pgup"},
+		"pagedown": {"pgdown"},
+		"undo":     {"u"},
+		"redo":     {"ctrl+r"},
+
+		"applydrafts":   {"A"},
+		"discarddrafts": {"D"},
+	},
+	"vim": {
+		"up":       {"k", "up"},
+		"down":     {"j", "down"},
+		"left":     {"h", "left"},
+		"right":    {"l", "right"},
+		"select":   {"enter", " "},
+		"back":     {"esc"},
+		"help":     {"?"},
+		"quit":     {"q"},
+		"manual":   {"m"},
+		"compare":  {"c"},
+		"stage":    {"s"},
+		"unstage":  {"x"},
+		"refresh":  {"r"},
+		"demo":     {"d"},
+		"pageup":   {"ctrl+u", "pgup"},
+		"pagedown": {"ctrl+d", "pgdown"},
+		"undo":     {"u"},
+		"redo":     {"ctrl+r"},
+
+		"applydrafts":   {"A"},
+		"discarddrafts": {"D"},
+	},
+	"emacs": {
+		"up":       {"ctrl+p", "up"},
+		"down":     {"ctrl+n", "down"},
+		"left":     {"ctrl+b", "left"},
+		"right":    {"ctrl+f", "right"},
+		"select":   {"enter"},
+		"back":     {"ctrl+g", "esc"},
+		"help":     {"f1", "?"},
+		"quit":     {"q"},
+		"manual":   {"m"},
+		"compare":  {"c"},
+		"stage":    {"s"},
+		"unstage":  {"ctrl+d", "x"},
+		"refresh":  {"g"},
+		"demo":     {"d"},
+		"pageup":   {"alt+v", "pgup"},
+		"pagedown": {"ctrl+v", "pgdown"},
+		"undo":     {"ctrl+_", "u"},
+		"redo":     {"ctrl+r"},
+
+		"applydrafts":   {"A"},
+		"discarddrafts": {"D"},
+	},
+}
+
+// Default returns the default keymap.
+func Default() *KeyMap {
+	keys, _ := New(Config{})
+	return keys
+}
+
+// New builds a keymap from a preset (default if empty) with the config's
+// bindings replacing the preset's for the actions they name. Unknown presets
+// and actions, required actions left without a key, and keys bound to more
+// than one action are errors.
+func New(config Config) (*KeyMap, error) {
+	preset := config.Preset
+	if preset == "" {
+		preset = "default"
+	}
+	keys, ok := presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown keymap preset %q (available: %s)", preset, strings.Join(Presets(), ", "))
+	}
+
+	k := &KeyMap{Preset: preset}
+	for _, action := range Actions {
+		*action.binding(k) = newBinding(keys[action.Name], action.Description)
+	}
+
+	overrides := make([]string, 0, len(config.Bindings))
+	for name := range config.Bindings {
+		overrides = append(overrides, name)
+	}
+	sort.Strings(overrides)
+	for _, name := range overrides {
+		action, ok := lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown keymap action %q", name)
+		}
+		bound := make([]string, 0, len(config.Bindings[name]))
+		for _, key := range config.Bindings[name] {
+			bound = append(bound, normalize(key))
+		}
+		*action.binding(k) = newBinding(bound, action.Description)
+	}
+
+	if err := k.validate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func newBinding(keys []string, description string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(Describe(keys), description))
+}
+
+func lookup(name string) (Action, bool) {
+	for _, action := range Actions {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// normalize accepts the spellings people write in config files for keys
+// Bubble Tea names differently.
+func normalize(k string) string {
+	if k == " " {
+		return k
+	}
+	switch lower := strings.ToLower(strings.TrimSpace(k)); lower {
+	case "space":
+		return " "
+	case "return":
+		return "enter"
+	case "escape":
+		return "esc"
+	default:
+		if len(lower) > 1 {
+			return lower
+		}
+		// Single characters keep their case: R isn't r
+		return strings.TrimSpace(k)
+	}
+}
+
+// ErrConflict is returned, wrapped, when a key is bound to two actions.
+var ErrConflict = errors.New("key bound to more than one action")
+
+func (k *KeyMap) validate() error {
+	owners := make(map[string]string)
+	for _, action := range Actions {
+		keys := action.Binding(k).Keys()
+		if len(keys) == 0 && required[action.Name] {
+			return fmt.Errorf("keymap action %q needs at least one key", action.Name)
+		}
+		for _, key := range keys {
+			if key == "" {
+				return fmt.Errorf("keymap action %q has an empty key", action.Name)
+			}
+			if key == ForceQuit {
+				return fmt.Errorf("%s always quits and can't be bound to %q", ForceQuit, action.Name)
+			}
+			if owner, ok := owners[key]; ok && owner != action.Name {
+				return fmt.Errorf("%q is bound to both %q and %q: %w", Name(key), owner, action.Name, ErrConflict)
+			}
+			owners[key] = action.Name
+		}
+	}
+	return nil
+}
+
+// Name is how a key is shown to the user.
+func Name(k string) string {
+	switch k {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "enter":
+		return "⏎"
+	case " ":
+		return "space"
+	default:
+		return k
+	}
+}
+
+// Describe shows keys the way the help screen lists them.
+func Describe(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = Name(k)
+	}
+	return strings.Join(names, "/")
+}
+
+// Short shows a binding by its first key, for tight spaces like the footer.
+func Short(b key.Binding) string {
+	if keys := b.Keys(); len(keys) > 0 {
+		return Name(keys[0])
+	}
+	return ""
+}
+
+// Pair shows two opposite bindings such as up and down by their first keys:
+// "↑↓" for arrows, "k/j" otherwise.
+func Pair(a, b key.Binding) string {
+	return pairName(Short(a), Short(b))
+}
+
+// Pairs is Pair for every key, matching the bindings' keys up in order.
+func Pairs(a, b key.Binding) string {
+	x, y := a.Keys(), b.Keys()
+	var names []string
+	for i := 0; i < len(x) || i < len(y); i++ {
+		switch {
+		case i >= len(y):
+			names = append(names, Name(x[i]))
+		case i >= len(x):
+			names = append(names, Name(y[i]))
+		default:
+			names = append(names, pairName(Name(x[i]), Name(y[i])))
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func pairName(x, y string) string {
+	if isArrow(x) && isArrow(y) {
+		return x + y
+	}
+	return x + "/" + y
+}
+
+func isArrow(name string) bool {
+	switch name {
+	case "↑", "↓", "←", "→":
+		return true
+	}
+	return false
+}
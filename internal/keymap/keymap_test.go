@@ -0,0 +1,137 @@
+package keymap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(k string) tea.KeyMsg {
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "ctrl+p":
+		return tea.KeyMsg{Type: tea.KeyCtrlP}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, name := range Presets() {
+		keys, err := New(Config{Preset: name})
+		if err != nil {
+			t.Errorf("Preset %s: %v", name, err)
+			continue
+		}
+		for _, action := range Actions {
+			if len(action.Binding(keys).Keys()) == 0 {
+				t.Errorf("Preset %s leaves %s unbound", name, action.Name)
+			}
+		}
+	}
+}
+
+func TestPresetKeys(t *testing.T) {
+	tests := []struct {
+		preset string
+		key    string
+		want   func(*KeyMap) key.Binding
+	}{
+		{"default", "h", func(k *KeyMap) key.Binding { return k.Help }},
+		{"default", "k", func(k *KeyMap) key.Binding { return k.Up }},
+		{"vim", "h", func(k *KeyMap) key.Binding { return k.Left }},
+		{"vim", "l", func(k *KeyMap) key.Binding { return k.Right }},
+		{"emacs", "ctrl+p", func(k *KeyMap) key.Binding { return k.Up }},
+		{"emacs", "enter", func(k *KeyMap) key.Binding { return k.Select }},
+	}
+
+	for _, tt := range tests {
+		keys, err := New(Config{Preset: tt.preset})
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", tt.preset, err)
+		}
+		if !key.Matches(press(tt.key), tt.want(keys)) {
+			t.Errorf("%s: expected %q to match %v", tt.preset, tt.key, tt.want(keys).Keys())
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	keys, err := New(Config{Preset: "vim", Bindings: map[string][]string{
+		"refresh": {"R", "F5"},
+		"select":  {"Return", "space"},
+	}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if got := keys.Refresh.Keys(); strings.Join(got, ",") != "R,f5" {
+		t.Errorf("Expected refresh on R and f5, got %v", got)
+	}
+	if keys.Refresh.Help().Key != "R/f5" {
+		t.Errorf("Expected the help to follow the override, got %q", keys.Refresh.Help().Key)
+	}
+	if !key.Matches(press("enter"), keys.Select) || !key.Matches(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, keys.Select) {
+		t.Errorf("Expected select on enter and space, got %v", keys.Select.Keys())
+	}
+	if key.Matches(press("r"), keys.Refresh) {
+		t.Error("Expected the override to replace the preset's key")
+	}
+	// Everything else comes from the preset
+	if !key.Matches(press("l"), keys.Right) {
+		t.Errorf("Expected vim's right, got %v", keys.Right.Keys())
+	}
+}
+
+func TestInvalidKeymaps(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"unknown preset", Config{Preset: "nano"}, "unknown keymap preset"},
+		{"unknown action", Config{Bindings: map[string][]string{"zoom": {"z"}}}, "unknown keymap action"},
+		{"conflict", Config{Bindings: map[string][]string{"refresh": {"q"}}}, `"q" is bound to both "quit" and "refresh"`},
+		{"preset conflict", Config{Preset: "vim", Bindings: map[string][]string{"help": {"h"}}}, `"h" is bound to both "left" and "help"`},
+		{"force quit", Config{Bindings: map[string][]string{"back": {"ctrl+c"}}}, "always quits"},
+		{"required", Config{Bindings: map[string][]string{"quit": {}}}, "needs at least one key"},
+	}
+
+	for _, tt := range tests {
+		_, err := New(tt.config)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+		if tt.name == "conflict" && !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+	}
+
+	// Unbinding an optional action is fine
+	if _, err := New(Config{Bindings: map[string][]string{"demo": {}}}); err != nil {
+		t.Errorf("Expected demo to be unbindable, got %v", err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	keys := Default()
+	if got := Pair(keys.Up, keys.Down); got != "↑↓" {
+		t.Errorf("Expected ↑↓, got %q", got)
+	}
+	if got := Pairs(keys.Up, keys.Down); got != "↑↓ k/j" {
+		t.Errorf("Expected \"↑↓ k/j\", got %q", got)
+	}
+	if got := Short(keys.Select); got != "⏎" {
+		t.Errorf("Expected ⏎, got %q", got)
+	}
+
+	vim, _ := New(Config{Preset: "vim"})
+	if got := Pair(vim.Left, vim.Right); got != "h/l" {
+		t.Errorf("Expected h/l, got %q", got)
+	}
+}
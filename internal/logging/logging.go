@@ -0,0 +1,165 @@
+// Package logging provides the application's structured logger. Entries go to
+// a size-rotated file under the state directory and are also kept in memory
+// so the TUI can show them without writing to the terminal it is drawing on.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	// recentLimit is how many entries are kept for the in-TUI log viewer.
+	recentLimit = 500
+)
+
+// Options configures a Logger. Empty fields fall back to info-level text
+// output; an empty Path keeps entries in memory only.
+type Options struct {
+	Level  string
+	Format string
+	Path   string
+}
+
+// Logger is an slog.Logger that also remembers its recent entries.
+type Logger struct {
+	*slog.Logger
+	recent *Recent
+	file   *RotatingFile
+}
+
+// DefaultPath is where logs are written unless --log-file says otherwise.
+func DefaultPath() string {
+	return filepath.Join(utils.StateDir(), "log", "omarchy-monitor-settings.log")
+}
+
+// ParseLevel accepts debug, info, warn or error (case-insensitive).
+func ParseLevel(level string) (slog.Level, error) {
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q: use debug, info, warn or error", level)
+	}
+	return parsed, nil
+}
+
+// Open creates a logger that writes to opts.Path, rotating it as it grows.
+func Open(opts Options) (*Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	format := strings.ToLower(opts.Format)
+	if format == "" {
+		format = FormatText
+	}
+	if format != FormatText && format != FormatJSON {
+		return nil, fmt.Errorf("invalid log format %q: use text or json", opts.Format)
+	}
+
+	logger := &Logger{recent: NewRecent(recentLimit)}
+	handlers := []slog.Handler{slog.NewTextHandler(logger.recent, &slog.HandlerOptions{Level: level})}
+
+	if opts.Path != "" {
+		file, err := OpenRotatingFile(opts.Path, DefaultMaxSize, DefaultBackups)
+		if err != nil {
+			return nil, err
+		}
+		logger.file = file
+		handlers = append(handlers, newHandler(file, format, level))
+	}
+
+	logger.Logger = slog.New(teeHandler(handlers))
+	return logger, nil
+}
+
+// Discard returns a logger that only keeps entries in memory, for tests and
+// for when the log file can't be opened.
+func Discard() *Logger {
+	logger, _ := Open(Options{})
+	return logger
+}
+
+// Recent returns the most recent entries, oldest first, as text lines.
+func (l *Logger) Recent() []string {
+	if l == nil || l.recent == nil {
+		return nil
+	}
+	return l.recent.Lines()
+}
+
+// Path is the file being written to, or "" when logging to memory only.
+func (l *Logger) Path() string {
+	if l == nil || l.file == nil {
+		return ""
+	}
+	return l.file.Path()
+}
+
+func (l *Logger) Close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+func newHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// teeHandler sends every record to each of its handlers.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var firstErr error
+	for _, h := range t {
+		if !h.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, record.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
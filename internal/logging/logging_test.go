@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenWritesJSONAndKeepsRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log", "app.log")
+	logger, err := Open(Options{Level: "debug", Format: "json", Path: path})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer logger.Close()
+
+	logger.Debug("detected monitors", "count", 2)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected a JSON entry, got %q: %v", data, err)
+	}
+	if entry["msg"] != "detected monitors" || entry["level"] != "DEBUG" || entry["count"] != float64(2) {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+
+	recent := logger.Recent()
+	if len(recent) != 1 || !strings.Contains(recent[0], `msg="detected monitors" count=2`) {
+		t.Errorf("Expected the entry in text form for the viewer, got %v", recent)
+	}
+}
+
+func TestOpenFiltersByLevel(t *testing.T) {
+	logger, err := Open(Options{Level: "warn"})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	recent := logger.Recent()
+	if len(recent) != 1 || !strings.Contains(recent[0], "shown") {
+		t.Errorf("Expected only the warning, got %v", recent)
+	}
+}
+
+func TestOpenRejectsBadOptions(t *testing.T) {
+	for _, opts := range []Options{{Level: "loud"}, {Format: "xml"}} {
+		if _, err := Open(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile failed: %v", err)
+	}
+	defer file.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := fmt.Fprintf(file, "entry-%d\n", i); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	for name, expected := range map[string]string{
+		path:        "entry-3\n",
+		path + ".1": "entry-2\n",
+		path + ".2": "entry-1\n",
+	} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if string(data) != expected {
+			t.Errorf("Expected %s to contain %q, got %q", filepath.Base(name), expected, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only two backups to be kept")
+	}
+}
+
+func TestRotatingFileKeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile failed: %v", err)
+	}
+	defer file.Close()
+
+	// A directory in the way of the backup makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "busy"), 0750); err != nil {
+		t.Fatalf("Failed to block the backup: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := fmt.Fprintf(file, "entry-%d\n", i); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "entry-0\nentry-1\nentry-2\n" {
+		t.Errorf("Expected every entry in the current file, got %q, %v", data, err)
+	}
+}
+
+func TestRecentLimit(t *testing.T) {
+	recent := NewRecent(2)
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := recent.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if got := recent.Lines(); strings.Join(got, ",") != "b,c" {
+		t.Errorf("Expected the last two lines, got %v", got)
+	}
+}
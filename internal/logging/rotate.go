@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultMaxSize = 1 << 20
+	DefaultBackups = 3
+)
+
+// RotatingFile is an append-only log file that is renamed to path.1 (and
+// older copies shifted up to path.N) once it would grow past maxSize.
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+func OpenRotatingFile(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	r := &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) Path() string {
+	return r.path
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		// A failed rotation leaves the current file open, so keep writing
+		// to it and try again next time
+		if err := r.rotate(); err != nil && r.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file aside and opens a fresh one. If that fails
+// part way, whatever is now at path is reopened for appending, so logging
+// carries on; only if that fails too is the file left closed.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	r.file = nil
+
+	err := r.shift()
+	if err == nil {
+		err = r.open()
+	}
+	if err != nil && r.file == nil {
+		if reopenErr := r.open(); reopenErr != nil {
+			return fmt.Errorf("%v; %w", err, reopenErr)
+		}
+	}
+	return err
+}
+
+// shift renames path to path.1, path.1 to path.2 and so on, dropping the
+// oldest, or removes path when no backups are kept.
+func (r *RotatingFile) shift() error {
+	if r.backups > 0 {
+		for i := r.backups - 1; i >= 1; i-- {
+			older := fmt.Sprintf("%s.%d", r.path, i)
+			if err := os.Rename(older, fmt.Sprintf("%s.%d", r.path, i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rotate log file: %w", err)
+			}
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	} else if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return nil
+}
+
+// Recent keeps the last few lines written to it.
+type Recent struct {
+	mu    sync.Mutex
+	lines []string
+	limit int
+}
+
+func NewRecent(limit int) *Recent {
+	return &Recent{limit: limit}
+}
+
+func (r *Recent) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		r.lines = append(r.lines, line)
+	}
+	if over := len(r.lines) - r.limit; over > 0 {
+		r.lines = append([]string(nil), r.lines[over:]...)
+	}
+	return len(p), nil
+}
+
+// Lines returns a copy of the kept lines, oldest first.
+func (r *Recent) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lines...)
+}
@@ -1,13 +1,24 @@
 package monitor
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/cursor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/session"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/terminal"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/waybar"
 	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
 	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
 )
@@ -34,6 +45,7 @@ type ScalingOption struct {
 	GTKScale        int
 	FontDPI         int
 	FontScale       float64
+	CursorSize      int
 	DisplayName     string
 	Description     string
 	Reasoning       string
@@ -48,42 +60,123 @@ type DetectorInterface interface {
 
 type ScalingManagerInterface interface {
 	GetIntelligentScalingOptions(monitor Monitor) []ScalingOption
+	GetRecommendedScale(monitor Monitor) float64
 }
 
 type ConfigManagerInterface interface {
 	ApplyMonitorScale(monitor Monitor, scale float64) error
 	ApplyGTKScale(scale int) error
 	ApplyFontDPI(dpi int) error
+	ApplyFontScale(scale float64) error
+	ApplyCursorSize(size int) error
 	ApplyCompleteScalingOption(monitor Monitor, option ScalingOption) error
+	ApplyTransaction(tx Transaction) (Verification, error)
+	PlanScalingOption(monitor Monitor, option ScalingOption) (Plan, error)
+	PlanTransaction(tx Transaction) (Plan, error)
+	PlanWaybar(fontScale float64) (Plan, error)
+	Execute(plan Plan) (Verification, error)
+	ManagedFiles() []string
+	CurrentFontScale() (float64, error)
+	CurrentCursorSize() (int, error)
 }
 
-type Detector struct{}
+// discardLogger is used until SetLogger is called.
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// ErrNoBackend is returned when the session has no supported detection tool.
+var ErrNoBackend = errors.New("no monitor detection tool for this session")
+
+type Detector struct {
+	logger *slog.Logger
+	runner runner.Runner
+	// backend is the tool to detect with when fixed is set; otherwise
+	// hyprctl, then wlr-randr, then demo monitors are tried in turn.
+	backend session.Backend
+	fixed   bool
+}
 
 func NewDetector() *Detector {
-	return &Detector{}
+	return &Detector{logger: discardLogger, runner: runner.NewExec(runner.DefaultTimeout)}
+}
+
+// NewDetectorForBackend detects only with the given tool, as chosen for the
+// probed session. BackendNone makes every detection fail with ErrNoBackend.
+func NewDetectorForBackend(backend session.Backend) *Detector {
+	return &Detector{logger: discardLogger, runner: runner.NewExec(runner.DefaultTimeout), backend: backend, fixed: true}
+}
+
+func (md *Detector) SetLogger(logger *slog.Logger) {
+	md.logger = logger
+}
+
+// SetRunner runs hyprctl and wlr-randr through r.
+func (md *Detector) SetRunner(r runner.Runner) {
+	md.runner = r
 }
 
 func (md *Detector) DetectMonitors() ([]Monitor, error) {
-	if md.commandExists("hyprctl") {
-		return md.parseHyprctlOutput()
+	return md.DetectMonitorsContext(context.Background())
+}
+
+// DetectMonitorsContext is DetectMonitors, giving up when ctx is done.
+func (md *Detector) DetectMonitorsContext(ctx context.Context) ([]Monitor, error) {
+	logger := md.logger
+	if logger == nil {
+		logger = discardLogger
 	}
 
-	if md.commandExists("wlr-randr") {
-		return md.parseWlrRandrOutput()
+	backend := md.backend
+	if !md.fixed {
+		switch {
+		case md.commandExists("hyprctl"):
+			backend = session.BackendHyprctl
+		case md.commandExists("wlr-randr"):
+			backend = session.BackendWlrRandr
+		default:
+			logger.Warn("neither hyprctl nor wlr-randr found, using fallback monitors")
+			return md.GetFallbackMonitors(), nil
+		}
+	}
+
+	var (
+		monitors []Monitor
+		err      error
+	)
+	switch backend {
+	case session.BackendHyprctl:
+		monitors, err = md.parseHyprctlOutput(ctx)
+	case session.BackendWlrRandr:
+		monitors, err = md.parseWlrRandrOutput(ctx)
+	default:
+		err = ErrNoBackend
 	}
 
-	return md.GetFallbackMonitors(), nil
+	if err != nil {
+		logger.Error("monitor detection failed", "source", string(backend), "error", err)
+		return nil, err
+	}
+	logger.Debug("detected monitors", "source", string(backend), "count", len(monitors))
+	return monitors, nil
 }
 
-func (md *Detector) parseHyprctlOutput() ([]Monitor, error) {
-	cmd := exec.Command("hyprctl", "monitors")
-	output, err := cmd.CombinedOutput()
+func (md *Detector) parseHyprctlOutput(ctx context.Context) ([]Monitor, error) {
+	result, err := md.runner.Run(ctx, "hyprctl", "monitors")
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute hyprctl: %w", err)
 	}
 
+	return parseHyprctlMonitors(string(result.Stdout)), nil
+}
+
+var (
+	hyprctlModePattern     = regexp.MustCompile(`(\d+)x(\d+)@([\d.]+)`)
+	hyprctlPositionPattern = regexp.MustCompile(` at (-?\d+)x(-?\d+)`)
+)
+
+// parseHyprctlMonitors reads the text output of "hyprctl monitors".
+func parseHyprctlMonitors(output string) []Monitor {
 	var monitors []Monitor
-	lines := strings.Split(string(output), "\n")
+	lines := strings.Split(output, "\n")
 	var currentMonitor *Monitor
 
 	for _, line := range lines {
@@ -110,8 +203,9 @@ func (md *Detector) parseHyprctlOutput() ([]Monitor, error) {
 			continue
 		}
 
-		if strings.Contains(line, "x") && strings.Contains(line, "@") {
-			resolutionMatch := regexp.MustCompile(`(\d+)x(\d+)@([\d.]+)Hz`).FindStringSubmatch(line)
+		// availableModes lists every mode the monitor offers, not the current one
+		if strings.Contains(line, "x") && strings.Contains(line, "@") && !strings.HasPrefix(line, "availableModes:") {
+			resolutionMatch := hyprctlModePattern.FindStringSubmatch(line)
 			if len(resolutionMatch) > 3 {
 				if width, err := strconv.Atoi(resolutionMatch[1]); err == nil {
 					currentMonitor.Width = width
@@ -123,6 +217,10 @@ func (md *Detector) parseHyprctlOutput() ([]Monitor, error) {
 					currentMonitor.RefreshRate = refreshRate
 				}
 			}
+			if positionMatch := hyprctlPositionPattern.FindStringSubmatch(line); len(positionMatch) > 2 {
+				currentMonitor.Position.X, _ = strconv.Atoi(positionMatch[1])
+				currentMonitor.Position.Y, _ = strconv.Atoi(positionMatch[2])
+			}
 		}
 
 		if strings.HasPrefix(line, "scale:") {
@@ -158,18 +256,17 @@ func (md *Detector) parseHyprctlOutput() ([]Monitor, error) {
 		monitors = append(monitors, *currentMonitor)
 	}
 
-	return monitors, nil
+	return monitors
 }
 
-func (md *Detector) parseWlrRandrOutput() ([]Monitor, error) {
-	cmd := exec.Command("wlr-randr")
-	output, err := cmd.CombinedOutput()
+func (md *Detector) parseWlrRandrOutput(ctx context.Context) ([]Monitor, error) {
+	result, err := md.runner.Run(ctx, "wlr-randr")
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute wlr-randr: %w", err)
 	}
 
 	var monitors []Monitor
-	lines := strings.Split(string(output), "\n")
+	lines := strings.Split(string(result.Stdout), "\n")
 	var currentMonitor *Monitor
 
 	for _, line := range lines {
@@ -540,6 +637,10 @@ func (sm *ScalingManager) GetIntelligentScalingOptions(monitor Monitor) []Scalin
 		}
 	}
 
+	for i := range options {
+		options[i].CursorSize = cursor.RecommendedSize(options[i].MonitorScale)
+	}
+
 	// Add PPI-based recommendations for high DPI displays
 	if ppi > 200 {
 		// For very high DPI displays, prioritize integer scaling
@@ -554,6 +655,8 @@ func (sm *ScalingManager) GetIntelligentScalingOptions(monitor Monitor) []Scalin
 	return options
 }
 
+// GetRecommendedScale is the scale of the first recommended option, or 1.0
+// when none is.
 func (sm *ScalingManager) GetRecommendedScale(monitor Monitor) float64 {
 	options := sm.GetIntelligentScalingOptions(monitor)
 	for _, option := range options {
@@ -564,10 +667,14 @@ func (sm *ScalingManager) GetRecommendedScale(monitor Monitor) float64 {
 	return 1.0
 }
 
-// calculatePPI calculates the Pixels Per Inch for a monitor
+func (sm *ScalingManager) calculatePPI(monitor Monitor) float64 {
+	return EstimatePPI(monitor)
+}
+
+// EstimatePPI estimates the Pixels Per Inch for a monitor
 // For now, we'll use common screen sizes based on resolution
 // In a real implementation, this would read from EDID or system info
-func (sm *ScalingManager) calculatePPI(monitor Monitor) float64 {
+func EstimatePPI(monitor Monitor) float64 {
 	// Common screen sizes for different resolutions
 	// These are estimates based on typical laptop and monitor sizes
 	switch {
@@ -608,91 +715,308 @@ type ScalingRecommendation struct {
 }
 
 type ConfigManager struct {
-	isDemoMode bool
+	dryRun    bool
+	terminals *terminal.Manager
+	cursors   *cursor.Manager
+	waybar    *waybar.Manager
+	runner    runner.Runner
+	detect    func() ([]Monitor, error)
+	now       func() time.Time
+	logger    *slog.Logger
 }
 
-func NewConfigManager(isDemoMode bool) *ConfigManager {
-	return &ConfigManager{
-		isDemoMode: isDemoMode,
-	}
+// NewConfigManager creates a manager that plans and executes changes. In
+// dry-run mode plans are computed in full but Execute does nothing.
+func NewConfigManager(dryRun bool) *ConfigManager {
+	return NewConfigManagerWithAdapters(dryRun, terminal.NewDefaultManager(), cursor.NewDefaultManager(), waybar.NewDefaultManager())
 }
 
-func (cm *ConfigManager) ApplyMonitorScale(monitor Monitor, scale float64) error {
-	if cm.isDemoMode {
-		fmt.Printf("Demo: Would apply monitor scale %.2fx to %s\n", scale, monitor.Name)
-		return nil
+// NewConfigManagerWithAdapters is used when terminal, cursor and Waybar
+// configs live somewhere other than the user's XDG directories (tests,
+// alternate homes).
+func NewConfigManagerWithAdapters(dryRun bool, terminals *terminal.Manager, cursors *cursor.Manager, bar *waybar.Manager) *ConfigManager {
+	cm := &ConfigManager{
+		dryRun:    dryRun,
+		terminals: terminals,
+		cursors:   cursors,
+		waybar:    bar,
+		runner:    runner.NewExec(runner.DefaultTimeout),
+		now:       time.Now,
+		logger:    discardLogger,
 	}
+	cm.detect = cm.newDetector().DetectMonitors
+	return cm
+}
+
+// SetLogger sends the manager's log entries, and those of the detector it
+// uses to verify applies, to logger.
+func (cm *ConfigManager) SetLogger(logger *slog.Logger) {
+	cm.logger = logger
+	cm.detect = cm.newDetector().DetectMonitors
+}
+
+// SetRunner runs commands, and the detections that verify applies, through
+// r.
+func (cm *ConfigManager) SetRunner(r runner.Runner) {
+	cm.runner = r
+	cm.detect = cm.newDetector().DetectMonitors
+}
+
+// newDetector verifies with hyprctl, since that is what applies go through.
+func (cm *ConfigManager) newDetector() *Detector {
+	detector := NewDetectorForBackend(session.BackendHyprctl)
+	detector.SetLogger(cm.logger)
+	detector.SetRunner(cm.runner)
+	return detector
+}
+
+// UseBackend runs commands and verifies applies through something other
+// than the real system, such as a simulated compositor. Call it after
+// SetLogger and SetRunner.
+func (cm *ConfigManager) UseBackend(r runner.Runner, detect func() ([]Monitor, error)) {
+	cm.runner = r
+	cm.detect = detect
+}
+
+func (cm *ConfigManager) IsDryRun() bool {
+	return cm.dryRun
+}
+
+func (cm *ConfigManager) PlanMonitorScale(monitor Monitor, scale float64) Plan {
+	var plan Plan
 
 	validatedScale := utils.ValidateMonitorScale(scale, types.MinMonitorScale, types.MaxMonitorScale)
 	if validatedScale != scale {
-		fmt.Printf("Adjusted scale from %.3f to %.3f for Hyprland compatibility\n", scale, validatedScale)
+		plan.Notes = append(plan.Notes, fmt.Sprintf("Adjusted scale from %.3f to %.3f for Hyprland compatibility", scale, validatedScale))
 	}
 
-	monitorName := strings.ReplaceAll(monitor.Name, " ", "_")
-	monitorName = strings.ReplaceAll(monitorName, ";", "")
-	monitorName = strings.ReplaceAll(monitorName, "&", "")
-	monitorName = strings.ReplaceAll(monitorName, "|", "")
-	monitorName = strings.ReplaceAll(monitorName, "`", "")
-	monitorName = strings.ReplaceAll(monitorName, "$", "")
-	monitorName = strings.ReplaceAll(monitorName, "(", "")
-	monitorName = strings.ReplaceAll(monitorName, ")", "")
-	monitorName = strings.ReplaceAll(monitorName, "'", "")
-	monitorName = strings.ReplaceAll(monitorName, "\"", "")
+	plan.Commands = append(plan.Commands, monitorScaleCommand(monitor, validatedScale))
+	plan.Expect = append(plan.Expect, MonitorTarget{Monitor: monitor, Scale: validatedScale})
+	return plan
+}
+
+// monitorScaleCommand keeps the monitor's current mode and position when
+// they are known so that only the scale changes; otherwise Hyprland picks
+// the preferred mode and places the monitor automatically.
+func monitorScaleCommand(monitor Monitor, scale float64) Command {
+	mode, position := "preferred", "auto"
+	if hasKnownMode(monitor) {
+		mode = fmt.Sprintf("%dx%d@%s", monitor.Width, monitor.Height, strconv.FormatFloat(monitor.RefreshRate, 'f', -1, 64))
+		position = fmt.Sprintf("%dx%d", monitor.Position.X, monitor.Position.Y)
+	}
+	return Command{
+		Name: "hyprctl",
+		Args: []string{"keyword", "monitor", fmt.Sprintf("%s,%s,%s,%.5f", sanitizeMonitorName(monitor.Name), mode, position, scale)},
+	}
+}
+
+func hasKnownMode(monitor Monitor) bool {
+	return monitor.Width > 0 && monitor.Height > 0 && monitor.RefreshRate > 0
+}
+
+func (cm *ConfigManager) PlanGTKScale(scale int) Plan {
+	validatedScale := utils.ValidateGTKScale(scale, types.MinGTKScale, types.MaxGTKScale)
+	return Plan{Env: []EnvChange{{Key: "GDK_SCALE", Value: strconv.Itoa(validatedScale)}}}
+}
+
+func (cm *ConfigManager) PlanFontDPI(dpi int) Plan {
+	validatedDPI := utils.ValidateFontDPI(dpi, types.MinFontDPI, types.MaxFontDPI)
+	return Plan{Env: []EnvChange{{Key: "XFT_DPI", Value: strconv.Itoa(validatedDPI)}}}
+}
 
-	cmd := exec.Command("hyprctl", "keyword", "monitor", fmt.Sprintf("%s,preferred,auto,%.5f", monitorName, validatedScale)) // nosec G204
+// PlanFontScale rescales the font size of every detected terminal emulator
+// (Alacritty, Kitty, Foot, Ghostty) relative to its original size.
+func (cm *ConfigManager) PlanFontScale(scale float64) (Plan, error) {
+	var plan Plan
 
-	output, err := cmd.CombinedOutput()
+	changes, err := cm.terminals.Changes(scale)
 	if err != nil {
-		return fmt.Errorf("failed to apply monitor scale: %w, output: %s", err, string(output))
+		return Plan{}, fmt.Errorf("failed to plan terminal fonts: %w", err)
+	}
+	if len(changes) == 0 {
+		return plan, nil
 	}
 
-	return nil
-}
+	for _, change := range changes {
+		plan.addFile(FileChange{Path: change.Path, Before: change.Before, After: change.After})
+	}
 
-func (cm *ConfigManager) ApplyGTKScale(scale int) error {
-	if cm.isDemoMode {
-		fmt.Printf("Demo: Would apply GTK scale %dx system-wide\n", scale)
-		return nil
+	before, after, err := cm.terminals.BaselineUpdate(changes)
+	if err != nil {
+		return Plan{}, err
 	}
+	plan.addFile(FileChange{Path: cm.terminals.BaselinePath(), Before: before, After: after, State: true})
 
-	validatedScale := utils.ValidateGTKScale(scale, types.MinGTKScale, types.MaxGTKScale)
+	return plan, nil
+}
 
-	if err := os.Setenv("GDK_SCALE", fmt.Sprintf("%d", validatedScale)); err != nil {
-		return fmt.Errorf("failed to set GDK_SCALE: %w", err)
+// PlanCursorSize sets the cursor size for Hyprland, XWayland and GTK. When
+// the active theme doesn't ship the requested size, the nearest size it does
+// ship is used instead so the cursor stays crisp.
+func (cm *ConfigManager) PlanCursorSize(size int) (Plan, error) {
+	var plan Plan
+
+	theme, err := cm.cursors.CurrentTheme()
+	if err == nil && !theme.Supports(size) {
+		nearest := theme.Nearest(size)
+		plan.Notes = append(plan.Notes, fmt.Sprintf("Cursor theme %s doesn't ship %dpx; using %dpx", theme.Name, size, nearest))
+		size = nearest
 	}
 
-	return nil
+	changes, err := cm.cursors.Changes(size)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to plan cursor settings: %w", err)
+	}
+	for _, change := range changes {
+		plan.addFile(FileChange{Path: change.Path, Before: change.Before, After: change.After})
+	}
+
+	plan.Commands = append(plan.Commands, Command{Name: "hyprctl", Args: []string{"setcursor", theme.Name, strconv.Itoa(size)}})
+
+	return plan, nil
 }
 
-func (cm *ConfigManager) ApplyFontDPI(dpi int) error {
-	if cm.isDemoMode {
-		fmt.Printf("Demo: Would set Xft.dpi to %d in ~/.Xresources\n", dpi)
-		return nil
+// PlanWaybar proposes bar height and font size adjustments for the given
+// font scale, backs up the originals and reloads Waybar. Waybar is
+// optional, so no config means an empty plan.
+func (cm *ConfigManager) PlanWaybar(fontScale float64) (Plan, error) {
+	plan := Plan{Description: "Scale Waybar"}
+	if fontScale <= 0 || !cm.waybar.Detected() {
+		return plan, nil
 	}
 
-	validatedDPI := utils.ValidateFontDPI(dpi, types.MinFontDPI, types.MaxFontDPI)
+	changes, err := cm.waybar.Changes(fontScale)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to plan waybar changes: %w", err)
+	}
+	if len(changes) == 0 {
+		return plan, nil
+	}
+
+	backupDir := cm.waybar.BackupDir(cm.now())
+	for _, change := range changes {
+		backup, err := fileChangeFor(filepath.Join(backupDir, filepath.Base(change.Path)), change.Before, true)
+		if err != nil {
+			return Plan{}, err
+		}
+		plan.addFile(backup)
+	}
+	for _, change := range changes {
+		plan.addFile(FileChange{Path: change.Path, Before: change.Before, After: change.After})
+	}
 
-	if err := os.Setenv("XFT_DPI", fmt.Sprintf("%d", validatedDPI)); err != nil {
-		return fmt.Errorf("failed to set XFT_DPI: %w", err)
+	before, after, err := cm.waybar.BaselineUpdate(changes)
+	if err != nil {
+		return Plan{}, err
 	}
+	plan.addFile(FileChange{Path: cm.waybar.BaselinePath(), Before: before, After: after, State: true})
+
+	plan.Commands = append(plan.Commands, Command{
+		Name:     waybar.ReloadCommand[0],
+		Args:     waybar.ReloadCommand[1:],
+		Optional: true,
+	})
+
+	return plan, nil
+}
+
+// PlanScalingOption plans a single-monitor transaction for option. Font scale
+// and cursor size are skipped when the option leaves them unset.
+func (cm *ConfigManager) PlanScalingOption(monitor Monitor, option ScalingOption) (Plan, error) {
+	return cm.PlanTransaction(Transaction{
+		Description: fmt.Sprintf("Apply %s to %s", option.DisplayName, monitor.Name),
+		Monitors:    []MonitorTarget{{Monitor: monitor, Scale: option.MonitorScale}},
+		GTKScale:    option.GTKScale,
+		FontDPI:     option.FontDPI,
+		FontScale:   option.FontScale,
+		CursorSize:  option.CursorSize,
+	})
+}
 
+// apply executes plan for callers that only care whether it worked.
+func (cm *ConfigManager) apply(plan Plan) error {
+	_, err := cm.Execute(plan)
+	return err
+}
+
+func (cm *ConfigManager) ApplyMonitorScale(monitor Monitor, scale float64) error {
+	if err := cm.apply(cm.PlanMonitorScale(monitor, scale)); err != nil {
+		return fmt.Errorf("failed to apply monitor scale: %w", err)
+	}
 	return nil
 }
 
+func (cm *ConfigManager) ApplyGTKScale(scale int) error {
+	_, err := cm.ApplyTransaction(Transaction{Description: "Set GTK scale", GTKScale: scale})
+	return err
+}
+
+func (cm *ConfigManager) ApplyFontDPI(dpi int) error {
+	_, err := cm.ApplyTransaction(Transaction{Description: "Set font DPI", FontDPI: dpi})
+	return err
+}
+
+func (cm *ConfigManager) ApplyFontScale(scale float64) error {
+	plan, err := cm.PlanFontScale(scale)
+	if err != nil {
+		return err
+	}
+	return cm.apply(plan)
+}
+
+func (cm *ConfigManager) ApplyCursorSize(size int) error {
+	plan, err := cm.PlanCursorSize(size)
+	if err != nil {
+		return err
+	}
+	return cm.apply(plan)
+}
+
 func (cm *ConfigManager) ApplyCompleteScalingOption(monitor Monitor, option ScalingOption) error {
-	if err := cm.ApplyMonitorScale(monitor, option.MonitorScale); err != nil {
-		return fmt.Errorf("failed to apply monitor scale: %w", err)
+	plan, err := cm.PlanScalingOption(monitor, option)
+	if err != nil {
+		return err
 	}
+	return cm.apply(plan)
+}
 
-	if err := cm.ApplyGTKScale(option.GTKScale); err != nil {
-		return fmt.Errorf("failed to apply GTK scale: %w", err)
+func sanitizeMonitorName(name string) string {
+	name = strings.ReplaceAll(name, " ", "_")
+	for _, c := range []string{";", "&", "|", "`", "$", "(", ")", "'", "\""} {
+		name = strings.ReplaceAll(name, c, "")
 	}
+	return name
+}
 
-	if err := cm.ApplyFontDPI(option.FontDPI); err != nil {
-		return fmt.Errorf("failed to apply font DPI: %w", err)
+// fileChangeFor builds a change that replaces path's current content.
+func fileChangeFor(path, after string, state bool) (FileChange, error) {
+	data, err := os.ReadFile(path) // nosec G304
+	if err != nil && !os.IsNotExist(err) {
+		return FileChange{}, fmt.Errorf("failed to read %s: %w", path, err)
 	}
+	return FileChange{Path: path, Before: string(data), After: after, State: state}, nil
+}
 
-	return nil
+// ManagedFiles lists every config file an apply may touch, so callers can
+// snapshot them beforehand.
+func (cm *ConfigManager) ManagedFiles() []string {
+	var paths []string
+	paths = append(paths, cm.terminals.Paths()...)
+	paths = append(paths, cm.cursors.Paths()...)
+	paths = append(paths, cm.waybar.Paths()...)
+	return paths
+}
+
+// CurrentFontScale is the font scale the terminals are at, so a change to
+// it can be undone.
+func (cm *ConfigManager) CurrentFontScale() (float64, error) {
+	return cm.terminals.CurrentScale()
+}
+
+// CurrentCursorSize is the cursor size in effect, so a change to it can be
+// undone.
+func (cm *ConfigManager) CurrentCursorSize() (int, error) {
+	return cm.cursors.CurrentSize()
 }
 
 func (cm *ConfigManager) GetScalingExplanations() map[string]string {
@@ -700,5 +1024,7 @@ func (cm *ConfigManager) GetScalingExplanations() map[string]string {
 		"monitor_scale": "Controls the compositor-level scaling. Affects the entire display output.",
 		"gtk_scale":     "Controls GTK application scaling. Affects GTK-based applications.",
 		"font_dpi":      "Controls font rendering DPI. Affects text size and clarity.",
+		"font_scale":    "Scales terminal emulator font sizes relative to their original size.",
+		"cursor_size":   "Controls the cursor size for Hyprland, XWayland and GTK applications.",
 	}
 }
@@ -1,8 +1,19 @@
 package monitor
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/cursor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/session"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/terminal"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/waybar"
 )
 
 func TestNewDetector(t *testing.T) {
@@ -72,14 +83,15 @@ func TestGetFallbackMonitors(t *testing.T) {
 
 func TestParseHyprctlOutput(t *testing.T) {
 	tests := []struct {
-		name           string
-		input          string
-		expectedCount  int
-		expectedNames  []string
-		expectedWidth  []int
-		expectedHeight []int
-		expectedScale  []float64
-		shouldError    bool
+		name             string
+		input            string
+		expectedCount    int
+		expectedNames    []string
+		expectedWidth    []int
+		expectedHeight   []int
+		expectedScale    []float64
+		expectedPosition []Position
+		shouldError      bool
 	}{
 		{
 			name: "valid hyprctl output",
@@ -90,16 +102,17 @@ func TestParseHyprctlOutput(t *testing.T) {
 	model: 13 Inch Laptop
 
 Monitor DP-1 (ID 1):
-	3840x2160@60.00000 at 2880x0
+	3840x2160@60.00000 at 2880x-120
 	scale: 1.50
 	make: LG
 	model: 27UP850-W`,
-			expectedCount:  2,
-			expectedNames:  []string{"eDP-1", "DP-1"},
-			expectedWidth:  []int{2880, 3840},
-			expectedHeight: []int{1920, 2160},
-			expectedScale:  []float64{2.00, 1.50},
-			shouldError:    false,
+			expectedCount:    2,
+			expectedNames:    []string{"eDP-1", "DP-1"},
+			expectedWidth:    []int{2880, 3840},
+			expectedHeight:   []int{1920, 2160},
+			expectedScale:    []float64{2.00, 1.50},
+			expectedPosition: []Position{{X: 0, Y: 0}, {X: 2880, Y: -120}},
+			shouldError:      false,
 		},
 		{
 			name: "single monitor",
@@ -163,25 +176,24 @@ Monitor DP-1 (ID 1):
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			detector := NewDetector()
+			monitors := parseHyprctlMonitors(tt.input)
+			if len(monitors) != tt.expectedCount {
+				t.Fatalf("Expected %d monitors, got %d", tt.expectedCount, len(monitors))
+			}
 
-			// Skip tests that would require actual hyprctl command
-			if tt.input == "" {
-				monitors, err := detector.parseHyprctlOutput()
-				if err != nil {
-					t.Logf("Expected error when no hyprctl available: %v", err)
-					return
+			for i, monitor := range monitors {
+				if monitor.Name != tt.expectedNames[i] {
+					t.Errorf("Monitor %d: expected name %s, got %s", i, tt.expectedNames[i], monitor.Name)
 				}
-				if len(monitors) == 0 {
-					t.Log("No monitors detected, using fallback")
-					return
+				if tt.expectedWidth != nil && (monitor.Width != tt.expectedWidth[i] || monitor.Height != tt.expectedHeight[i]) {
+					t.Errorf("Monitor %d: expected %dx%d, got %dx%d", i, tt.expectedWidth[i], tt.expectedHeight[i], monitor.Width, monitor.Height)
+				}
+				if tt.expectedScale != nil && monitor.Scale != tt.expectedScale[i] {
+					t.Errorf("Monitor %d: expected scale %.2f, got %.2f", i, tt.expectedScale[i], monitor.Scale)
+				}
+				if tt.expectedPosition != nil && monitor.Position != tt.expectedPosition[i] {
+					t.Errorf("Monitor %d: expected position %+v, got %+v", i, tt.expectedPosition[i], monitor.Position)
 				}
-			}
-
-			// For now, just test that the detector can be created and fallback works
-			monitors := detector.GetFallbackMonitors()
-			if len(monitors) == 0 {
-				t.Error("Fallback monitors should not be empty")
 			}
 		})
 	}
@@ -251,6 +263,9 @@ func TestScalingManager(t *testing.T) {
 			if option.FontDPI <= 0 {
 				t.Errorf("Monitor %d, Option %d: FontDPI should be positive", i, j)
 			}
+			if option.CursorSize != cursor.RecommendedSize(option.MonitorScale) {
+				t.Errorf("Monitor %d, Option %d: CursorSize %d does not match monitor scale %.2f", i, j, option.CursorSize, option.MonitorScale)
+			}
 			if option.DisplayName == "" {
 				t.Errorf("Monitor %d, Option %d: DisplayName should not be empty", i, j)
 			}
@@ -312,7 +327,7 @@ func TestConfigManager(t *testing.T) {
 			}
 
 			explanations := manager.GetScalingExplanations()
-			expectedKeys := []string{"monitor_scale", "gtk_scale", "font_dpi"}
+			expectedKeys := []string{"monitor_scale", "gtk_scale", "font_dpi", "cursor_size"}
 			for _, key := range expectedKeys {
 				if _, exists := explanations[key]; !exists {
 					t.Errorf("Missing explanation for key: %s", key)
@@ -629,7 +644,7 @@ func BenchmarkParseHyprctlOutput(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		monitors, _ := detector.parseHyprctlOutput()
+		monitors, _ := detector.parseHyprctlOutput(context.Background())
 		_ = monitors
 	}
 }
@@ -644,3 +659,105 @@ func BenchmarkScalingOptions(b *testing.B) {
 		_ = options
 	}
 }
+
+func TestDetectorWithoutBackend(t *testing.T) {
+	detector := NewDetectorForBackend(session.BackendNone)
+	if _, err := detector.DetectMonitors(); !errors.Is(err, ErrNoBackend) {
+		t.Errorf("Expected ErrNoBackend, got %v", err)
+	}
+}
+
+// hyprctl-framework-4k.json is "hyprctl monitors" on a Framework 13 with a
+// 4K monitor, before and after its scale was changed.
+func loadReplay(t *testing.T, name string) *runner.Replayer {
+	t.Helper()
+	replayer, err := runner.LoadReplayer(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to load replay: %v", err)
+	}
+	return replayer
+}
+
+func TestDetectorReplaysCapturedOutput(t *testing.T) {
+	recorder := runner.NewRecorder(loadReplay(t, "hyprctl-framework-4k.json"))
+	detector := NewDetectorForBackend(session.BackendHyprctl)
+	detector.SetRunner(recorder)
+
+	monitors, err := detector.DetectMonitors()
+	if err != nil {
+		t.Fatalf("DetectMonitors failed: %v", err)
+	}
+	if len(monitors) != 2 {
+		t.Fatalf("Expected 2 monitors, got %+v", monitors)
+	}
+	external := monitors[1]
+	if external.Name != "DP-3" || external.Width != 3840 || external.RefreshRate != 59.997 ||
+		external.Scale != 1.5 || external.Position != (Position{X: 1440, Y: -240}) || external.Make != "LG Electronics" {
+		t.Errorf("Unexpected external monitor %+v", external)
+	}
+	if !monitors[0].IsPrimary || monitors[1].IsPrimary {
+		t.Error("Expected only the focused eDP-1 to be primary")
+	}
+
+	if got := recorder.Commands(); len(got) != 1 || got[0] != "hyprctl monitors" {
+		t.Errorf("Expected a single hyprctl monitors call, got %v", got)
+	}
+}
+
+func TestApplyRunsExactCommands(t *testing.T) {
+	replayer := loadReplay(t, "hyprctl-framework-4k.json")
+	recorder := runner.NewRecorder(replayer)
+	detector := NewDetectorForBackend(session.BackendHyprctl)
+	detector.SetRunner(recorder)
+	configDir, stateDir := t.TempDir(), t.TempDir()
+	manager := NewConfigManagerWithAdapters(false,
+		terminal.NewManager(configDir, stateDir),
+		cursor.NewManager(configDir, t.TempDir(), nil, func(string) string { return "" }),
+		waybar.NewManager(configDir, stateDir, nil))
+	manager.SetRunner(recorder)
+
+	monitors, err := detector.DetectMonitors()
+	if err != nil {
+		t.Fatalf("DetectMonitors failed: %v", err)
+	}
+	verification, err := manager.ApplyTransaction(Transaction{Monitors: []MonitorTarget{{Monitor: monitors[1], Scale: 2}}})
+	if err != nil {
+		t.Fatalf("ApplyTransaction failed: %v", err)
+	}
+	if verification.HasDrift() {
+		t.Errorf("Expected no drift, got %v", verification.Drift)
+	}
+
+	want := []string{
+		"hyprctl monitors",
+		"hyprctl monitors",
+		"hyprctl keyword monitor DP-3,3840x2160@59.997,1440x-240,2.00000",
+		"hyprctl monitors",
+	}
+	if got := recorder.Commands(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected commands:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Expected every recording to be replayed, got %v", unused)
+	}
+}
+
+func TestDetectorTimesOutOnHungCommand(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "hyprctl"), []byte("#!/bin/sh\nsleep 30\n"), 0700); err != nil {
+		t.Fatalf("Failed to write fake hyprctl: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	detector := NewDetectorForBackend(session.BackendHyprctl)
+	detector.SetRunner(runner.NewExec(100 * time.Millisecond))
+
+	start := time.Now()
+	_, err := detector.DetectMonitors()
+	if !errors.Is(err, runner.ErrTimeout) {
+		t.Fatalf("Expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected detection to give up promptly, took %s", elapsed)
+	}
+}
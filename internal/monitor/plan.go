@@ -0,0 +1,172 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+// Command is an external program the plan will run.
+type Command struct {
+	Name string
+	Args []string
+	// Optional commands may fail without failing the plan.
+	Optional bool
+}
+
+// String renders the command the way it would be typed in a shell.
+func (c Command) String() string {
+	parts := make([]string, 0, len(c.Args)+1)
+	parts = append(parts, shellQuote(c.Name))
+	for _, arg := range c.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// EnvChange is a session environment variable. It is written to the
+// Hyprland env file and set in the running compositor, so applications
+// launched from then on, and in later sessions, see it.
+type EnvChange struct {
+	Key   string
+	Value string
+}
+
+func (e EnvChange) String() string {
+	return e.Key + "=" + e.Value
+}
+
+// FileChange is a complete rewrite of one file.
+type FileChange struct {
+	Path   string
+	Before string
+	After  string
+	// State marks the application's own bookkeeping (baselines, backups) as
+	// opposed to user config files.
+	State bool
+}
+
+// Diff renders the change as a unified diff.
+func (f FileChange) Diff() string {
+	return utils.UnifiedDiff(f.Path, f.Before, f.After)
+}
+
+// Plan is everything an apply will do, computed up front so it can be shown
+// to the user and then executed exactly as shown.
+type Plan struct {
+	Description string
+	Notes       []string
+	Files       []FileChange
+	Env         []EnvChange
+	Commands    []Command
+	// Expect is the monitor state Execute verifies once the plan has run.
+	Expect []MonitorTarget
+}
+
+// IsEmpty reports whether executing the plan would do nothing.
+func (p Plan) IsEmpty() bool {
+	return len(p.Files) == 0 && len(p.Env) == 0 && len(p.Commands) == 0
+}
+
+// Merge appends other's steps to p.
+func (p *Plan) Merge(other Plan) {
+	p.Notes = append(p.Notes, other.Notes...)
+	p.Files = append(p.Files, other.Files...)
+	p.Env = append(p.Env, other.Env...)
+	p.Commands = append(p.Commands, other.Commands...)
+	p.Expect = append(p.Expect, other.Expect...)
+}
+
+func (p *Plan) addFile(change FileChange) {
+	if change.Before != change.After {
+		p.Files = append(p.Files, change)
+	}
+}
+
+// ConfigFiles returns the file changes that touch user config, leaving out
+// the application's own state.
+func (p Plan) ConfigFiles() []FileChange {
+	var files []FileChange
+	for _, file := range p.Files {
+		if !file.State {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// String renders the full plan, including the unified diff of every file.
+func (p Plan) String() string {
+	var b strings.Builder
+
+	if p.Description != "" {
+		fmt.Fprintf(&b, "Plan: %s\n", p.Description)
+	}
+	for _, note := range p.Notes {
+		fmt.Fprintf(&b, "Note: %s\n", note)
+	}
+	if p.IsEmpty() {
+		b.WriteString("Nothing to do.\n")
+		return b.String()
+	}
+
+	if len(p.Commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		for _, cmd := range p.Commands {
+			fmt.Fprintf(&b, "  $ %s\n", cmd)
+		}
+	}
+
+	if len(p.Env) > 0 {
+		b.WriteString("\nEnvironment:\n")
+		for _, env := range p.Env {
+			fmt.Fprintf(&b, "  %s\n", env)
+		}
+	}
+
+	if len(p.Files) > 0 {
+		b.WriteString("\nFiles:\n")
+		for _, file := range p.Files {
+			fmt.Fprintf(&b, "  %s\n", file.Path)
+		}
+		for _, file := range p.Files {
+			b.WriteString("\n")
+			b.WriteString(file.Diff())
+		}
+	}
+
+	return b.String()
+}
+
+// checkFiles makes sure no file changed since the plan was made, so the
+// plan never overwrites edits it didn't show.
+func checkFiles(files []FileChange) error {
+	for _, file := range files {
+		data, err := os.ReadFile(file.Path) // nosec G304
+		current := string(data)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read %s: %w", file.Path, err)
+			}
+			current = ""
+		}
+		if current != file.Before {
+			return fmt.Errorf("%s changed since the plan was made; review the plan again", file.Path)
+		}
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./,:=@%+", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
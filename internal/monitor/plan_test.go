@@ -0,0 +1,245 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/cursor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/terminal"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/waybar"
+)
+
+// planFixture runs plans against temp config dirs and a fake compositor
+// that tracks monitor scales set through hyprctl.
+type planFixture struct {
+	manager   *ConfigManager
+	configDir string
+	stateDir  string
+	ran       []string
+	scales    map[string]float64
+	// adjust makes the fake compositor pick its own scale for a monitor.
+	adjust map[string]float64
+	// unplugged monitors disappear from detection.
+	unplugged map[string]bool
+}
+
+func newPlanFixture(t *testing.T, dryRun bool) *planFixture {
+	t.Helper()
+	configDir := t.TempDir()
+	stateDir := t.TempDir()
+
+	f := &planFixture{
+		configDir: configDir,
+		stateDir:  stateDir,
+		scales:    map[string]float64{"eDP-1": 1.0, "DP-1": 1.0},
+		adjust:    make(map[string]float64),
+		unplugged: make(map[string]bool),
+	}
+	f.manager = NewConfigManagerWithAdapters(dryRun,
+		terminal.NewManager(configDir, stateDir),
+		cursor.NewManager(configDir, t.TempDir(), nil, func(string) string { return "" }),
+		waybar.NewManager(configDir, stateDir, nil))
+	f.manager.runner = runner.Func(func(_ context.Context, name string, args ...string) (runner.Result, error) {
+		f.ran = append(f.ran, Command{Name: name, Args: args}.String())
+		if name == "hyprctl" {
+			f.hyprctl(args)
+		}
+		return runner.Result{}, nil
+	})
+	f.manager.detect = func() ([]Monitor, error) {
+		var monitors []Monitor
+		for _, name := range []string{"eDP-1", "DP-1"} {
+			if !f.unplugged[name] {
+				monitors = append(monitors, Monitor{Name: name, Scale: f.scales[name]})
+			}
+		}
+		return monitors, nil
+	}
+	f.manager.now = func() time.Time { return time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC) }
+	return f
+}
+
+func (f *planFixture) hyprctl(args []string) {
+	calls := [][]string{args}
+	if len(args) == 2 && args[0] == "--batch" {
+		calls = nil
+		for _, call := range strings.Split(args[1], " ; ") {
+			calls = append(calls, strings.Fields(call))
+		}
+	}
+	for _, call := range calls {
+		if len(call) != 3 || call[0] != "keyword" || call[1] != "monitor" {
+			continue
+		}
+		fields := strings.Split(call[2], ",")
+		if scale, ok := f.adjust[fields[0]]; ok {
+			f.scales[fields[0]] = scale
+		} else if scale, err := strconv.ParseFloat(fields[3], 64); err == nil {
+			f.scales[fields[0]] = scale
+		}
+	}
+}
+
+func (f *planFixture) write(t *testing.T, rel, content string) string {
+	t.Helper()
+	path := filepath.Join(f.configDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return string(data)
+}
+
+func TestPlanScalingOption(t *testing.T) {
+	f := newPlanFixture(t, false)
+	kitty := f.write(t, "kitty/kitty.conf", "font_size 10\n")
+
+	option := ScalingOption{DisplayName: "1.5x Large", MonitorScale: 1.5, GTKScale: 1, FontDPI: 144, FontScale: 1.5, CursorSize: 36}
+	plan, err := f.manager.PlanScalingOption(Monitor{Name: "eDP-1"}, option)
+	if err != nil {
+		t.Fatalf("PlanScalingOption failed: %v", err)
+	}
+
+	if got := readString(t, kitty); got != "font_size 10\n" {
+		t.Fatalf("Planning must not write files, got %q", got)
+	}
+
+	text := plan.String()
+	for _, expected := range []string{
+		"Plan: Apply 1.5x Large to eDP-1",
+		"$ hyprctl --batch 'keyword monitor eDP-1,preferred,auto,1.50000 ; setcursor Adwaita 36 ; keyword env GDK_SCALE,1 ; keyword env XFT_DPI,144'",
+		"GDK_SCALE=1",
+		"XFT_DPI=144",
+		"+env = XCURSOR_SIZE,36",
+		"+env = GDK_SCALE,1",
+		"--- " + kitty,
+		"-font_size 10",
+		"+font_size 15",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected plan to contain %q, got:\n%s", expected, text)
+		}
+	}
+
+	if _, err := f.manager.Execute(plan); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got := readString(t, kitty); got != "font_size 15\n" {
+		t.Errorf("Expected kitty config to be updated, got %q", got)
+	}
+	if got := readString(t, filepath.Join(f.configDir, "hypr", "envs.conf")); !strings.Contains(got, "env = XCURSOR_SIZE,36\n") || !strings.Contains(got, "env = XFT_DPI,144\n") {
+		t.Errorf("Expected the cursor size and font DPI in the env file, got %q", got)
+	}
+	want := []string{"hyprctl --batch 'keyword monitor eDP-1,preferred,auto,1.50000 ; setcursor Adwaita 36 ; keyword env GDK_SCALE,1 ; keyword env XFT_DPI,144'"}
+	if strings.Join(f.ran, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected commands %v, got %v", want, f.ran)
+	}
+}
+
+func TestExecuteRejectsStalePlan(t *testing.T) {
+	f := newPlanFixture(t, false)
+	kitty := f.write(t, "kitty/kitty.conf", "font_size 10\n")
+
+	plan, err := f.manager.PlanScalingOption(Monitor{Name: "eDP-1"}, ScalingOption{MonitorScale: 2, GTKScale: 2, FontDPI: 96, FontScale: 2})
+	if err != nil {
+		t.Fatalf("PlanScalingOption failed: %v", err)
+	}
+
+	f.write(t, "kitty/kitty.conf", "font_size 12\n")
+	if _, err := f.manager.Execute(plan); err == nil {
+		t.Fatal("Expected an error for a file edited after planning")
+	}
+	if got := readString(t, kitty); got != "font_size 12\n" {
+		t.Errorf("A stale plan must not write files, got %q", got)
+	}
+	if len(f.ran) != 0 {
+		t.Errorf("A stale plan must not run commands, got %v", f.ran)
+	}
+}
+
+func TestExecuteDryRun(t *testing.T) {
+	f := newPlanFixture(t, true)
+	kitty := f.write(t, "kitty/kitty.conf", "font_size 10\n")
+
+	plan, err := f.manager.PlanScalingOption(Monitor{Name: "eDP-1"}, ScalingOption{MonitorScale: 2, GTKScale: 2, FontDPI: 96, FontScale: 2})
+	if err != nil {
+		t.Fatalf("PlanScalingOption failed: %v", err)
+	}
+	if plan.IsEmpty() {
+		t.Fatal("Dry-run plans should still be computed in full")
+	}
+
+	if _, err := f.manager.Execute(plan); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := readString(t, kitty); got != "font_size 10\n" {
+		t.Errorf("Dry run must not write files, got %q", got)
+	}
+	if len(f.ran) != 0 {
+		t.Errorf("Dry run must not run commands, got %v", f.ran)
+	}
+}
+
+func TestPlanWaybar(t *testing.T) {
+	f := newPlanFixture(t, false)
+	style := f.write(t, "waybar/style.css", "* {\n  font-size: 12px;\n}\n")
+
+	plan, err := f.manager.PlanWaybar(1.5)
+	if err != nil {
+		t.Fatalf("PlanWaybar failed: %v", err)
+	}
+
+	if files := plan.ConfigFiles(); len(files) != 1 || files[0].Path != style {
+		t.Fatalf("Expected only the stylesheet as a config change, got %+v", files)
+	}
+	if len(plan.Commands) != 1 || !plan.Commands[0].Optional || plan.Commands[0].Name != "pkill" {
+		t.Fatalf("Expected an optional reload command, got %+v", plan.Commands)
+	}
+
+	// Waybar not running must not fail the apply.
+	f.manager.runner = runner.Func(func(context.Context, string, ...string) (runner.Result, error) {
+		return runner.Result{ExitCode: 1}, errors.New("exit status 1")
+	})
+	if _, err := f.manager.Execute(plan); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if got := readString(t, style); !strings.Contains(got, "font-size: 18px;") {
+		t.Errorf("Expected stylesheet to be scaled, got %q", got)
+	}
+	backup := filepath.Join(f.stateDir, "backups", "waybar-20261018-090000", "style.css")
+	if got := readString(t, backup); got != "* {\n  font-size: 12px;\n}\n" {
+		t.Errorf("Expected the original to be backed up, got %q", got)
+	}
+}
+
+func TestPlanWaybarWithoutConfig(t *testing.T) {
+	f := newPlanFixture(t, false)
+
+	plan, err := f.manager.PlanWaybar(1.5)
+	if err != nil {
+		t.Fatalf("PlanWaybar failed: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("Expected an empty plan without a Waybar config, got %+v", plan)
+	}
+}
@@ -0,0 +1,336 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/cursor"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+// MonitorTarget is the scale one monitor should end up at.
+type MonitorTarget struct {
+	Monitor Monitor
+	Scale   float64
+}
+
+// Transaction is the desired state of any number of monitors plus the
+// settings shared by all of them. It is applied all at once or not at all.
+// Zero-valued global settings are left alone.
+type Transaction struct {
+	Description string
+	Monitors    []MonitorTarget
+	GTKScale    int
+	FontDPI     int
+	FontScale   float64
+	CursorSize  int
+}
+
+// PlanTransaction plans every change in tx. All hyprctl calls are folded into
+// a single --batch invocation so the compositor applies them together.
+func (cm *ConfigManager) PlanTransaction(tx Transaction) (Plan, error) {
+	plan := Plan{Description: tx.Description}
+
+	seen := make(map[string]bool)
+	for _, target := range tx.Monitors {
+		if seen[target.Monitor.Name] {
+			return Plan{}, fmt.Errorf("monitor %s appears more than once", target.Monitor.Name)
+		}
+		seen[target.Monitor.Name] = true
+		plan.Merge(cm.PlanMonitorScale(target.Monitor, target.Scale))
+	}
+
+	if tx.GTKScale > 0 {
+		plan.Merge(cm.PlanGTKScale(tx.GTKScale))
+	}
+	if tx.FontDPI > 0 {
+		plan.Merge(cm.PlanFontDPI(tx.FontDPI))
+	}
+
+	if tx.FontScale > 0 {
+		fontPlan, err := cm.PlanFontScale(tx.FontScale)
+		if err != nil {
+			return Plan{}, err
+		}
+		plan.Merge(fontPlan)
+	}
+
+	if tx.CursorSize > 0 {
+		cursorPlan, err := cm.PlanCursorSize(tx.CursorSize)
+		if err != nil {
+			return Plan{}, err
+		}
+		plan.Merge(cursorPlan)
+	}
+
+	if err := cm.persistEnv(&plan); err != nil {
+		return Plan{}, err
+	}
+
+	plan.Commands = batchHyprctl(plan.Commands)
+	return plan, nil
+}
+
+// persistEnv adds the steps that make plan.Env stick: the variables are
+// written to the Hyprland env file, on top of any change the plan already
+// makes to it, and set in the compositor with "hyprctl keyword env".
+func (cm *ConfigManager) persistEnv(plan *Plan) error {
+	if len(plan.Env) == 0 {
+		return nil
+	}
+
+	path := cm.cursors.HyprEnvPath()
+	index := -1
+	for i, file := range plan.Files {
+		if file.Path == path {
+			index = i
+		}
+	}
+
+	var before, after string
+	if index >= 0 {
+		before, after = plan.Files[index].Before, plan.Files[index].After
+	} else {
+		data, err := os.ReadFile(path) // nosec G304
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		before, after = string(data), string(data)
+	}
+
+	for _, env := range plan.Env {
+		after = cursor.SetEnvLine(after, env.Key, env.Value)
+		plan.Commands = append(plan.Commands, envCommand(env.Key, env.Value))
+	}
+
+	if index >= 0 {
+		plan.Files[index].After = after
+	} else {
+		plan.addFile(FileChange{Path: path, Before: before, After: after})
+	}
+	return nil
+}
+
+func envCommand(key, value string) Command {
+	return Command{Name: "hyprctl", Args: []string{"keyword", "env", key + "," + value}}
+}
+
+func (cm *ConfigManager) ApplyTransaction(tx Transaction) (Verification, error) {
+	plan, err := cm.PlanTransaction(tx)
+	if err != nil {
+		return Verification{}, err
+	}
+	return cm.Execute(plan)
+}
+
+// Execute carries out a plan exactly as computed: files are written first,
+// then commands are run in order.
+// Monitors are then re-detected and compared with plan.Expect; anything the
+// compositor adjusted is reported as drift. If any step fails or a monitor
+// vanishes, everything already done is rolled back. If a file changed since
+// the plan was made, nothing is done at all.
+func (cm *ConfigManager) Execute(plan Plan) (Verification, error) {
+	if cm.dryRun {
+		return Verification{}, nil
+	}
+
+	if err := checkFiles(plan.Files); err != nil {
+		cm.logger.Warn("plan is stale", "plan", plan.Description, "error", err)
+		return Verification{}, err
+	}
+
+	cm.logger.Info("applying plan", "plan", plan.Description,
+		"files", len(plan.Files), "env", len(plan.Env), "commands", len(plan.Commands))
+	undo := cm.snapshot(plan)
+
+	if err := cm.execute(plan); err != nil {
+		return Verification{}, cm.rollback(undo, err)
+	}
+
+	verification, err := cm.verify(plan.Expect)
+	if err != nil {
+		return Verification{}, cm.rollback(undo, err)
+	}
+
+	for _, drift := range verification.Drift {
+		cm.logger.Warn("compositor adjusted requested settings", "monitor", drift.Current.Name,
+			"property", string(drift.Property), "requested", drift.Requested, "actual", drift.Actual)
+	}
+	return verification, nil
+}
+
+func (cm *ConfigManager) execute(plan Plan) error {
+	for _, file := range plan.Files {
+		perm := os.FileMode(0644)
+		if file.State {
+			perm = 0600
+		}
+		if err := utils.WriteFileAtomic(file.Path, []byte(file.After), perm); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+
+	for _, cmd := range plan.Commands {
+		cm.logger.Debug("running command", "command", cmd.String())
+		if _, err := cm.runner.Run(context.Background(), cmd.Name, cmd.Args...); err != nil {
+			if !cmd.Optional {
+				return fmt.Errorf("failed to run %s: %w", cmd, err)
+			}
+			cm.logger.Debug("optional command failed", "command", cmd.String(), "error", err)
+		}
+	}
+
+	return nil
+}
+
+// verify re-detects monitors and compares them with what was requested.
+func (cm *ConfigManager) verify(expect []MonitorTarget) (Verification, error) {
+	if len(expect) == 0 {
+		return Verification{}, nil
+	}
+
+	live, err := cm.detect()
+	if err != nil {
+		return Verification{}, fmt.Errorf("failed to verify monitors: %w", err)
+	}
+
+	return Compare(expect, live)
+}
+
+// undoState is what it takes to put things back the way they were before a
+// plan ran.
+type undoState struct {
+	files    []FileChange
+	removals []string
+	commands []Command
+}
+
+// snapshot records the state a plan is about to change. Files are covered by
+// the plan itself (checkFiles guarantees Before is current), and so are the
+// environment and cursor size the env file held; monitor scales are read
+// now.
+func (cm *ConfigManager) snapshot(plan Plan) undoState {
+	var undo undoState
+
+	for _, file := range plan.Files {
+		if utils.FileExists(file.Path) {
+			undo.files = append(undo.files, FileChange{Path: file.Path, Before: file.After, After: file.Before, State: file.State})
+		} else {
+			undo.removals = append(undo.removals, file.Path)
+		}
+	}
+
+	if len(plan.Expect) > 0 {
+		live, err := cm.detect()
+		if err != nil {
+			live = nil
+		}
+		for _, target := range plan.Expect {
+			previous := target.Monitor
+			if current, ok := findMonitor(live, target.Monitor.Name); ok {
+				previous = current
+			}
+			if previous.Scale > 0 {
+				undo.commands = append(undo.commands, monitorScaleCommand(previous, previous.Scale))
+			}
+		}
+	}
+
+	// The env file holds the cursor size the session had before the plan
+	for _, file := range plan.Files {
+		if file.Path != cm.cursors.HyprEnvPath() {
+			continue
+		}
+		previous, ok := cursor.EnvValue(file.Before, "XCURSOR_SIZE")
+		if size, err := strconv.Atoi(strings.TrimSpace(previous)); ok && err == nil && size > 0 {
+			undo.commands = append(undo.commands, Command{
+				Name: "hyprctl",
+				Args: []string{"setcursor", cm.cursors.CurrentThemeName(), strconv.Itoa(size)},
+			})
+		}
+		// A variable the file didn't set before keeps its new value in the
+		// compositor until the session ends; hyprctl can't unset it
+		for _, env := range plan.Env {
+			if previous, ok := cursor.EnvValue(file.Before, env.Key); ok {
+				undo.commands = append(undo.commands, envCommand(env.Key, strings.TrimSpace(previous)))
+			}
+		}
+	}
+
+	undo.commands = batchHyprctl(undo.commands)
+	return undo
+}
+
+// rollback undoes as much as it can and reports the original failure along
+// with anything that couldn't be undone.
+func (cm *ConfigManager) rollback(undo undoState, cause error) error {
+	var failures []string
+
+	for _, file := range undo.files {
+		perm := os.FileMode(0644)
+		if file.State {
+			perm = 0600
+		}
+		if err := utils.WriteFileAtomic(file.Path, []byte(file.After), perm); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	for _, path := range undo.removals {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	for _, cmd := range undo.commands {
+		if _, err := cm.runner.Run(context.Background(), cmd.Name, cmd.Args...); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", cmd, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		cm.logger.Error("rollback incomplete", "cause", cause, "failures", failures)
+		return fmt.Errorf("%w; rollback incomplete: %s", cause, strings.Join(failures, "; "))
+	}
+	cm.logger.Warn("rolled back all changes", "cause", cause)
+	return fmt.Errorf("%w; all changes were rolled back", cause)
+}
+
+// batchHyprctl folds every required hyprctl call into one "hyprctl --batch"
+// at the position of the first, leaving other commands in order.
+func batchHyprctl(commands []Command) []Command {
+	var batch []string
+	for _, cmd := range commands {
+		if cmd.Name == "hyprctl" && !cmd.Optional {
+			batch = append(batch, strings.Join(cmd.Args, " "))
+		}
+	}
+	if len(batch) < 2 {
+		return commands
+	}
+
+	result := make([]Command, 0, len(commands)-len(batch)+1)
+	batched := false
+	for _, cmd := range commands {
+		if cmd.Name != "hyprctl" || cmd.Optional {
+			result = append(result, cmd)
+			continue
+		}
+		if !batched {
+			result = append(result, Command{Name: "hyprctl", Args: []string{"--batch", strings.Join(batch, " ; ")}})
+			batched = true
+		}
+	}
+	return result
+}
+
+func findMonitor(monitors []Monitor, name string) (Monitor, bool) {
+	for _, m := range monitors {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Monitor{}, false
+}
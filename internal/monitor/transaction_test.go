@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+)
+
+func TestTransactionAppliesAllMonitorsInOneBatch(t *testing.T) {
+	f := newPlanFixture(t, false)
+
+	tx := Transaction{
+		Description: "Apply to both",
+		Monitors: []MonitorTarget{
+			{Monitor: Monitor{Name: "eDP-1", Scale: 1.0}, Scale: 2.0},
+			{Monitor: Monitor{Name: "DP-1", Scale: 1.0}, Scale: 1.25},
+		},
+		GTKScale: 2,
+		FontDPI:  96,
+	}
+	verification, err := f.manager.ApplyTransaction(tx)
+	if err != nil {
+		t.Fatalf("ApplyTransaction failed: %v", err)
+	}
+	if verification.HasDrift() {
+		t.Errorf("Expected no drift, got %v", verification.Drift)
+	}
+
+	want := "hyprctl --batch 'keyword monitor eDP-1,preferred,auto,2.00000 ; keyword monitor DP-1,preferred,auto,1.25000 ; keyword env GDK_SCALE,2 ; keyword env XFT_DPI,96'"
+	if len(f.ran) != 1 || f.ran[0] != want {
+		t.Errorf("Expected a single batch call %q, got %v", want, f.ran)
+	}
+	if f.scales["eDP-1"] != 2.0 || f.scales["DP-1"] != 1.25 {
+		t.Errorf("Unexpected scales after apply: %v", f.scales)
+	}
+	if got := readString(t, filepath.Join(f.configDir, "hypr", "envs.conf")); got != "env = GDK_SCALE,2\nenv = XFT_DPI,96\n" {
+		t.Errorf("Expected the environment in the env file, got %q", got)
+	}
+}
+
+func TestTransactionReportsDrift(t *testing.T) {
+	f := newPlanFixture(t, false)
+	f.adjust["DP-1"] = 1.2
+
+	verification, err := f.manager.ApplyTransaction(Transaction{
+		Monitors: []MonitorTarget{
+			{Monitor: Monitor{Name: "eDP-1"}, Scale: 2.0},
+			{Monitor: Monitor{Name: "DP-1"}, Scale: 1.25},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Drift must not fail the apply, got %v", err)
+	}
+
+	drift := verification.ScaleDrift()
+	if len(drift) != 1 {
+		t.Fatalf("Expected one scale drift, got %v", verification.Drift)
+	}
+	if got := drift[0].String(); got != "DP-1 scale: requested 1.25, got 1.2" {
+		t.Errorf("Unexpected drift %q", got)
+	}
+	if f.scales["DP-1"] != 1.2 || f.scales["eDP-1"] != 2.0 {
+		t.Errorf("Drift must not be rolled back, got scales %v", f.scales)
+	}
+	if len(f.ran) != 1 {
+		t.Errorf("Expected no rollback commands, got %v", f.ran)
+	}
+}
+
+func TestTransactionRollsBackWhenMonitorDisappears(t *testing.T) {
+	f := newPlanFixture(t, false)
+	f.unplugged["DP-1"] = true
+	kitty := f.write(t, "kitty/kitty.conf", "font_size 10\n")
+	envs := f.write(t, "hypr/envs.conf", "env = XCURSOR_SIZE,24\nenv = GDK_SCALE,1\n")
+
+	tx := Transaction{
+		Monitors: []MonitorTarget{
+			{Monitor: Monitor{Name: "eDP-1"}, Scale: 2.0},
+			{Monitor: Monitor{Name: "DP-1"}, Scale: 1.25},
+		},
+		GTKScale:   2,
+		FontDPI:    120,
+		FontScale:  1.5,
+		CursorSize: 48,
+	}
+	_, err := f.manager.ApplyTransaction(tx)
+	if err == nil || !strings.Contains(err.Error(), "monitor DP-1 disappeared") || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("Expected a verification failure with rollback, got %v", err)
+	}
+
+	if f.scales["eDP-1"] != 1.0 {
+		t.Errorf("Expected eDP-1 to be rolled back to 1.0x, got %.2f", f.scales["eDP-1"])
+	}
+	if got := readString(t, kitty); got != "font_size 10\n" {
+		t.Errorf("Expected kitty config to be rolled back, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(f.stateDir, "font-baselines.json")); !os.IsNotExist(err) {
+		t.Error("State files created by the transaction should be removed on rollback")
+	}
+	if got := readString(t, envs); got != "env = XCURSOR_SIZE,24\nenv = GDK_SCALE,1\n" {
+		t.Errorf("Expected the env file to be rolled back, got %q", got)
+	}
+	undo := strings.Join(f.ran[1:], "\n")
+	if !strings.Contains(undo, "setcursor Adwaita 24") {
+		t.Errorf("Expected the cursor size to be put back, got %v", f.ran)
+	}
+	if !strings.Contains(undo, "keyword env GDK_SCALE,1") || strings.Contains(undo, "XFT_DPI") {
+		t.Errorf("Expected only GDK_SCALE to be put back in the compositor, got %v", f.ran)
+	}
+}
+
+func TestTransactionRollsBackWhenCommandFails(t *testing.T) {
+	f := newPlanFixture(t, false)
+	kitty := f.write(t, "kitty/kitty.conf", "font_size 10\n")
+
+	failed := false
+	run := f.manager.runner
+	f.manager.runner = runner.Func(func(ctx context.Context, name string, args ...string) (runner.Result, error) {
+		if !failed {
+			failed = true
+			return runner.Result{Stdout: []byte("ok\n"), Stderr: []byte("no such monitor"), ExitCode: 1}, errors.New("exit status 1: no such monitor")
+		}
+		return run.Run(ctx, name, args...)
+	})
+
+	_, err := f.manager.ApplyTransaction(Transaction{
+		Monitors:  []MonitorTarget{{Monitor: Monitor{Name: "eDP-1"}, Scale: 2.0}},
+		FontScale: 2,
+	})
+	if err == nil || strings.Count(err.Error(), "no such monitor") != 1 {
+		t.Fatalf("Expected an error with hyprctl's stderr once, got %v", err)
+	}
+	if got := readString(t, kitty); got != "font_size 10\n" {
+		t.Errorf("Expected kitty config to be rolled back, got %q", got)
+	}
+}
+
+func TestPlanTransactionRejectsDuplicateMonitors(t *testing.T) {
+	f := newPlanFixture(t, false)
+	_, err := f.manager.PlanTransaction(Transaction{Monitors: []MonitorTarget{
+		{Monitor: Monitor{Name: "eDP-1"}, Scale: 1.5},
+		{Monitor: Monitor{Name: "eDP-1"}, Scale: 2.0},
+	}})
+	if err == nil {
+		t.Error("Expected an error for a monitor listed twice")
+	}
+}
@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// scaleTolerance absorbs the rounding in hyprctl's two-decimal scale output.
+	scaleTolerance = 0.01
+	// refreshTolerance absorbs modes like 59.95Hz being reported for 60Hz.
+	refreshTolerance = 0.5
+)
+
+// DriftProperty names the part of a monitor's state that drifted.
+type DriftProperty string
+
+const (
+	DriftScale    DriftProperty = "scale"
+	DriftMode     DriftProperty = "mode"
+	DriftPosition DriftProperty = "position"
+)
+
+// Drift is a difference between what was requested for a monitor and what
+// the compositor actually did, for example requesting 1.25 and getting 1.2.
+type Drift struct {
+	Property  DriftProperty
+	Requested string
+	Actual    string
+	// Target is what was requested and Current is the re-detected monitor.
+	Target  MonitorTarget
+	Current Monitor
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s %s: requested %s, got %s", d.Current.Name, d.Property, d.Requested, d.Actual)
+}
+
+// Verification is the re-detected state after an apply and any drift from
+// what was requested.
+type Verification struct {
+	Monitors []Monitor
+	Drift    []Drift
+}
+
+// HasDrift reports whether the compositor changed anything that was asked for.
+func (v Verification) HasDrift() bool {
+	return len(v.Drift) > 0
+}
+
+// ScaleDrift returns only the drifts in monitor scale.
+func (v Verification) ScaleDrift() []Drift {
+	var drift []Drift
+	for _, d := range v.Drift {
+		if d.Property == DriftScale {
+			drift = append(drift, d)
+		}
+	}
+	return drift
+}
+
+// Compare checks each target against the live monitors. Mode and position
+// are only compared when the target's mode is known, since otherwise the
+// compositor was asked to pick them. A missing monitor is an error.
+func Compare(expect []MonitorTarget, live []Monitor) (Verification, error) {
+	verification := Verification{Monitors: live}
+
+	for _, target := range expect {
+		current, ok := findMonitor(live, target.Monitor.Name)
+		if !ok {
+			return Verification{}, fmt.Errorf("monitor %s disappeared while applying", target.Monitor.Name)
+		}
+
+		if math.Abs(current.Scale-target.Scale) > scaleTolerance {
+			verification.Drift = append(verification.Drift, Drift{
+				Property:  DriftScale,
+				Requested: formatScale(target.Scale),
+				Actual:    formatScale(current.Scale),
+				Target:    target,
+				Current:   current,
+			})
+		}
+
+		if !hasKnownMode(target.Monitor) {
+			continue
+		}
+
+		requested := target.Monitor
+		if current.Width != requested.Width || current.Height != requested.Height ||
+			math.Abs(current.RefreshRate-requested.RefreshRate) > refreshTolerance {
+			verification.Drift = append(verification.Drift, Drift{
+				Property:  DriftMode,
+				Requested: formatMode(requested),
+				Actual:    formatMode(current),
+				Target:    target,
+				Current:   current,
+			})
+		}
+
+		if current.Position != requested.Position {
+			verification.Drift = append(verification.Drift, Drift{
+				Property:  DriftPosition,
+				Requested: formatPosition(requested.Position),
+				Actual:    formatPosition(current.Position),
+				Target:    target,
+				Current:   current,
+			})
+		}
+	}
+
+	return verification, nil
+}
+
+func formatScale(scale float64) string {
+	return strconv.FormatFloat(math.Round(scale*100)/100, 'f', -1, 64)
+}
+
+func formatMode(monitor Monitor) string {
+	return fmt.Sprintf("%dx%d@%.2fHz", monitor.Width, monitor.Height, monitor.RefreshRate)
+}
+
+func formatPosition(position Position) string {
+	return fmt.Sprintf("%d,%d", position.X, position.Y)
+}
@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	laptop := Monitor{Name: "eDP-1", Width: 2880, Height: 1920, RefreshRate: 120, Position: Position{X: 0, Y: 0}}
+	external := Monitor{Name: "DP-1", Width: 3840, Height: 2160, RefreshRate: 60, Position: Position{X: 2304, Y: 0}}
+
+	tests := []struct {
+		name     string
+		expect   []MonitorTarget
+		live     []Monitor
+		expected []string
+	}{
+		{
+			name:   "matches",
+			expect: []MonitorTarget{{Monitor: laptop, Scale: 1.25}},
+			live:   []Monitor{withScale(laptop, 1.25)},
+		},
+		{
+			name:   "rounded scale is not drift",
+			expect: []MonitorTarget{{Monitor: laptop, Scale: 1.66667}},
+			live:   []Monitor{withScale(laptop, 1.67)},
+		},
+		{
+			name:     "adjusted scale",
+			expect:   []MonitorTarget{{Monitor: laptop, Scale: 1.3}},
+			live:     []Monitor{withScale(laptop, 1.33)},
+			expected: []string{"eDP-1 scale: requested 1.3, got 1.33"},
+		},
+		{
+			name:   "adjusted mode and position",
+			expect: []MonitorTarget{{Monitor: external, Scale: 1.5}},
+			live: []Monitor{{
+				Name: "DP-1", Width: 2560, Height: 1440, RefreshRate: 59.95,
+				Position: Position{X: 1920, Y: 0}, Scale: 1.5,
+			}},
+			expected: []string{
+				"DP-1 mode: requested 3840x2160@60.00Hz, got 2560x1440@59.95Hz",
+				"DP-1 position: requested 2304,0, got 1920,0",
+			},
+		},
+		{
+			name:   "unknown mode only checks scale",
+			expect: []MonitorTarget{{Monitor: Monitor{Name: "DP-1"}, Scale: 1.5}},
+			live:   []Monitor{withScale(external, 1.5)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification, err := Compare(tt.expect, tt.live)
+			if err != nil {
+				t.Fatalf("Compare failed: %v", err)
+			}
+
+			var got []string
+			for _, d := range verification.Drift {
+				got = append(got, d.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected drift %v, got %v", tt.expected, got)
+			}
+			if verification.HasDrift() != (len(tt.expected) > 0) {
+				t.Errorf("HasDrift() = %v, expected %v", verification.HasDrift(), len(tt.expected) > 0)
+			}
+		})
+	}
+}
+
+func TestCompareMissingMonitor(t *testing.T) {
+	_, err := Compare([]MonitorTarget{{Monitor: Monitor{Name: "DP-1"}, Scale: 1.5}}, []Monitor{{Name: "eDP-1", Scale: 1}})
+	if err == nil || !strings.Contains(err.Error(), "DP-1") {
+		t.Errorf("Expected an error naming the missing monitor, got %v", err)
+	}
+}
+
+func withScale(monitor Monitor, scale float64) Monitor {
+	monitor.Scale = scale
+	return monitor
+}
@@ -0,0 +1,114 @@
+// Package notify sends desktop notifications through the freedesktop
+// Notifications D-Bus interface, which mako, dunst and most other
+// notification daemons implement.
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName    = "org.freedesktop.Notifications"
+	objectPath = "/org/freedesktop/Notifications"
+	appName    = "omarchy-monitor-settings"
+
+	// defaultTimeout keeps a notification up long enough to read after a
+	// keypress without piling up when the key is held down.
+	defaultTimeout = 2 * time.Second
+)
+
+// Urgency levels from the notification spec.
+const (
+	urgencyNormal   byte = 1
+	urgencyCritical byte = 2
+)
+
+// Caller is the part of a D-Bus object that sending a notification needs.
+// *dbus.Object satisfies it; tests use a fake.
+type Caller interface {
+	Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call
+}
+
+// Notification is one message. Value, when between 1 and 100, is shown as a
+// progress bar by daemons that support it. Critical notifications stay up
+// until dismissed.
+type Notification struct {
+	Summary  string
+	Body     string
+	Icon     string
+	Value    int
+	Critical bool
+}
+
+// Notifier sends notifications. Each one replaces the previous one from this
+// program, so stepping the scale several times shows a single notification.
+type Notifier struct {
+	bus     Caller
+	closer  func() error
+	Timeout time.Duration
+}
+
+// New creates a notifier that calls bus.
+func New(bus Caller) *Notifier {
+	return &Notifier{bus: bus, Timeout: defaultTimeout}
+}
+
+// Session connects to the notification daemon on the session bus.
+func Session() (*Notifier, error) {
+	conn, err := dbus.SessionBusPrivate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %w", err)
+	}
+	if err := conn.Auth(nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to authenticate on the session bus: %w", err)
+	}
+	if err := conn.Hello(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to register on the session bus: %w", err)
+	}
+
+	notifier := New(conn.Object(busName, objectPath))
+	notifier.closer = conn.Close
+	return notifier, nil
+}
+
+// Notify sends note and returns the ID the daemon gave it.
+func (n *Notifier) Notify(note Notification) (uint32, error) {
+	urgency := urgencyNormal
+	if note.Critical {
+		urgency = urgencyCritical
+	}
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(urgency),
+		// Replace our previous notification instead of stacking a new one
+		"x-canonical-private-synchronous": dbus.MakeVariant(appName),
+		"x-dunst-stack-tag":               dbus.MakeVariant(appName),
+	}
+	if note.Value > 0 && note.Value <= 100 {
+		hints["value"] = dbus.MakeVariant(int32(note.Value))
+	}
+
+	var id uint32
+	call := n.bus.Call(busName+".Notify", 0,
+		appName, uint32(0), note.Icon, note.Summary, note.Body,
+		[]string{}, hints, int32(n.Timeout/time.Millisecond))
+	if call.Err != nil {
+		return 0, fmt.Errorf("failed to send notification: %w", call.Err)
+	}
+	if err := call.Store(&id); err != nil {
+		return 0, fmt.Errorf("failed to read notification ID: %w", err)
+	}
+	return id, nil
+}
+
+// Close disconnects from the bus, if New didn't get one from the caller.
+func (n *Notifier) Close() error {
+	if n.closer == nil {
+		return nil
+	}
+	return n.closer()
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeBus records calls the way a notification daemon would receive them.
+type fakeBus struct {
+	method string
+	args   []interface{}
+	err    error
+}
+
+func (f *fakeBus) Call(method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	f.method, f.args = method, args
+	if f.err != nil {
+		return &dbus.Call{Err: f.err}
+	}
+	return &dbus.Call{Body: []interface{}{uint32(42)}}
+}
+
+func TestNotify(t *testing.T) {
+	bus := &fakeBus{}
+	notifier := New(bus)
+
+	id, err := notifier.Notify(Notification{Summary: "eDP-1 at 1.25x", Body: "Looks like 2304x1536", Value: 42})
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("Expected the daemon's ID, got %d", id)
+	}
+	if bus.method != "org.freedesktop.Notifications.Notify" || len(bus.args) != 8 {
+		t.Fatalf("Unexpected call %s%v", bus.method, bus.args)
+	}
+	if bus.args[0] != "omarchy-monitor-settings" || bus.args[3] != "eDP-1 at 1.25x" || bus.args[4] != "Looks like 2304x1536" {
+		t.Errorf("Unexpected arguments: %v", bus.args)
+	}
+	if timeout := bus.args[7].(int32); timeout != int32(defaultTimeout/time.Millisecond) {
+		t.Errorf("Unexpected timeout %d", timeout)
+	}
+
+	hints := bus.args[6].(map[string]dbus.Variant)
+	if hints["value"].Value() != int32(42) || hints["urgency"].Value() != urgencyNormal {
+		t.Errorf("Unexpected hints: %v", hints)
+	}
+	if _, ok := hints["x-canonical-private-synchronous"]; !ok {
+		t.Error("Expected a hint to replace the previous notification")
+	}
+
+	if _, err := notifier.Notify(Notification{Summary: "No progress"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if _, ok := bus.args[6].(map[string]dbus.Variant)["value"]; ok {
+		t.Error("Expected no progress bar without a value")
+	}
+
+	if _, err := notifier.Notify(Notification{Summary: "Failed", Critical: true}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if urgency := bus.args[6].(map[string]dbus.Variant)["urgency"].Value(); urgency != urgencyCritical {
+		t.Errorf("Expected critical urgency, got %v", urgency)
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+	notifier := New(&fakeBus{err: errors.New("no daemon")})
+	if _, err := notifier.Notify(Notification{Summary: "Hello"}); err == nil {
+		t.Error("Expected the bus error")
+	}
+	if err := notifier.Close(); err != nil {
+		t.Errorf("Close without a connection failed: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Invocation is one recorded command and what it produced.
+type Invocation struct {
+	Name     string   `json:"name"`
+	Args     []string `json:"args,omitempty"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+	ExitCode int      `json:"exit_code"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// String returns the command line, as tests compare it.
+func (i Invocation) String() string {
+	return commandLine(i.Name, i.Args)
+}
+
+// Recorder runs commands through another Runner and remembers each call.
+type Recorder struct {
+	runner Runner
+	mu     sync.Mutex
+	calls  []Invocation
+}
+
+func NewRecorder(runner Runner) *Recorder {
+	return &Recorder{runner: runner}
+}
+
+func (r *Recorder) Run(ctx context.Context, name string, args ...string) (Result, error) {
+	result, err := r.runner.Run(ctx, name, args...)
+
+	call := Invocation{
+		Name:     name,
+		Args:     append([]string(nil), args...),
+		Stdout:   string(result.Stdout),
+		Stderr:   string(result.Stderr),
+		ExitCode: result.ExitCode,
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+	return result, err
+}
+
+// Invocations returns every call so far, in order.
+func (r *Recorder) Invocations() []Invocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Invocation(nil), r.calls...)
+}
+
+// Commands returns the command line of every call so far, in order.
+func (r *Recorder) Commands() []string {
+	calls := r.Invocations()
+	commands := make([]string, len(calls))
+	for i, call := range calls {
+		commands[i] = call.String()
+	}
+	return commands
+}
+
+// Save writes the calls so far as JSON, for replaying later.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Invocations(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode invocations: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to save invocations: %w", err)
+	}
+	return nil
+}
+
+// ErrUnexpectedCommand is returned, wrapped, when a Replayer is asked to run
+// a command it has no recording of.
+var ErrUnexpectedCommand = errors.New("unexpected command")
+
+// Replayer answers commands from recorded invocations instead of running
+// them. Each recording is used once, in order; when the recordings of a
+// command run out, the last one is repeated, so a recording of a single
+// "hyprctl monitors" serves any number of detections.
+type Replayer struct {
+	mu    sync.Mutex
+	calls []Invocation
+	used  []bool
+}
+
+func NewReplayer(calls []Invocation) *Replayer {
+	return &Replayer{calls: calls, used: make([]bool, len(calls))}
+}
+
+// LoadReplayer reads invocations saved by Recorder.Save.
+func LoadReplayer(path string) (*Replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invocations: %w", err)
+	}
+	var calls []Invocation
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return nil, fmt.Errorf("failed to parse invocations from %s: %w", path, err)
+	}
+	return NewReplayer(calls), nil
+}
+
+func (r *Replayer) Run(ctx context.Context, name string, args ...string) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{ExitCode: -1}, fmt.Errorf("%s: %w", commandLine(name, args), err)
+	}
+
+	line := commandLine(name, args)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, call := range r.calls {
+		if call.String() != line {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return Result{ExitCode: -1}, fmt.Errorf("%w: %s", ErrUnexpectedCommand, line)
+	}
+	r.used[match] = true
+
+	call := r.calls[match]
+	result := Result{Stdout: []byte(call.Stdout), Stderr: []byte(call.Stderr), ExitCode: call.ExitCode}
+	if call.Error != "" {
+		return result, errors.New(call.Error)
+	}
+	return result, nil
+}
+
+// Unused returns the recordings that were never replayed.
+func (r *Replayer) Unused() []Invocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Invocation
+	for i, call := range r.calls {
+		if !r.used[i] {
+			unused = append(unused, call)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,99 @@
+// Package runner runs external commands such as hyprctl and wlr-randr with
+// a timeout, keeping stdout and stderr apart. Tests swap in a Recorder or
+// Replayer to assert the exact commands run and to replay captured output.
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds every command unless the caller's context has an
+// earlier deadline. hyprctl answers in milliseconds; anything near this
+// means the compositor is wedged.
+const DefaultTimeout = 5 * time.Second
+
+// Result is what a finished command produced. ExitCode is -1 if the command
+// couldn't be started or was killed.
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// Output returns stdout followed by stderr, for error messages.
+func (r Result) Output() []byte {
+	if len(r.Stderr) == 0 {
+		return r.Stdout
+	}
+	output := make([]byte, 0, len(r.Stdout)+len(r.Stderr))
+	return append(append(output, r.Stdout...), r.Stderr...)
+}
+
+// Runner runs a command to completion or until ctx is done.
+type Runner interface {
+	Run(ctx context.Context, name string, args ...string) (Result, error)
+}
+
+// Func adapts a function to a Runner.
+type Func func(ctx context.Context, name string, args ...string) (Result, error)
+
+func (f Func) Run(ctx context.Context, name string, args ...string) (Result, error) {
+	return f(ctx, name, args...)
+}
+
+// ErrTimeout is returned, wrapped, when a command runs past its timeout.
+var ErrTimeout = errors.New("timed out")
+
+// Exec runs commands on the system.
+type Exec struct {
+	// Timeout bounds each call; zero means no limit beyond the context's.
+	Timeout time.Duration
+}
+
+func NewExec(timeout time.Duration) *Exec {
+	return &Exec{Timeout: timeout}
+}
+
+func (e *Exec) Run(ctx context.Context, name string, args ...string) (Result, error) {
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...) // nosec G204
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait forever for children that inherited the output pipes
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	result := Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), ExitCode: -1}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case err == nil:
+		return result, nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return result, fmt.Errorf("%s %w", commandLine(name, args), ErrTimeout)
+	case ctx.Err() != nil:
+		return result, fmt.Errorf("%s: %w", commandLine(name, args), ctx.Err())
+	}
+	if stderr := strings.TrimSpace(string(result.Stderr)); stderr != "" {
+		return result, fmt.Errorf("%w: %s", err, stderr)
+	}
+	return result, err
+}
+
+func commandLine(name string, args []string) string {
+	return strings.TrimSpace(name + " " + strings.Join(args, " "))
+}
@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecSeparatesOutput(t *testing.T) {
+	result, err := NewExec(DefaultTimeout).Run(context.Background(), "sh", "-c", "echo out; echo err >&2; exit 3")
+	if err == nil {
+		t.Fatal("Expected a non-zero exit to be an error")
+	}
+	if string(result.Stdout) != "out\n" || string(result.Stderr) != "err\n" {
+		t.Errorf("Expected stdout and stderr apart, got %q and %q", result.Stdout, result.Stderr)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", result.ExitCode)
+	}
+	if !strings.Contains(err.Error(), "err") {
+		t.Errorf("Expected stderr in the error, got %v", err)
+	}
+	if string(result.Output()) != "out\nerr\n" {
+		t.Errorf("Unexpected combined output %q", result.Output())
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	start := time.Now()
+	result, err := NewExec(100*time.Millisecond).Run(context.Background(), "sleep", "10")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the command to be killed promptly, took %s", elapsed)
+	}
+	if result.ExitCode != -1 {
+		t.Errorf("Expected a killed command to have exit code -1, got %d", result.ExitCode)
+	}
+}
+
+func TestExecCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewExec(0).Run(ctx, "sleep", "10"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	fake := Func(func(_ context.Context, name string, args ...string) (Result, error) {
+		if name == "hyprctl" && len(args) == 1 && args[0] == "monitors" {
+			return Result{Stdout: []byte("Monitor eDP-1 (ID 0):\n")}, nil
+		}
+		return Result{Stderr: []byte("no\n"), ExitCode: 1}, errors.New("exit status 1")
+	})
+
+	recorder := NewRecorder(fake)
+	ctx := context.Background()
+	recorder.Run(ctx, "hyprctl", "monitors")
+	recorder.Run(ctx, "hyprctl", "keyword", "monitor", "eDP-1,preferred,auto,2")
+
+	want := []string{"hyprctl monitors", "hyprctl keyword monitor eDP-1,preferred,auto,2"}
+	if got := recorder.Commands(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected commands %v, got %v", want, got)
+	}
+
+	path := filepath.Join(t.TempDir(), "calls.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("LoadReplayer failed: %v", err)
+	}
+
+	// The recorded output comes back, and the last recording of a command
+	// repeats once used up
+	for i := 0; i < 2; i++ {
+		result, err := replayer.Run(ctx, "hyprctl", "monitors")
+		if err != nil || string(result.Stdout) != "Monitor eDP-1 (ID 0):\n" {
+			t.Errorf("Replay %d: unexpected %+v, %v", i, result, err)
+		}
+	}
+
+	if len(replayer.Unused()) != 1 {
+		t.Errorf("Expected the keyword call to be unused, got %v", replayer.Unused())
+	}
+	result, err := replayer.Run(ctx, "hyprctl", "keyword", "monitor", "eDP-1,preferred,auto,2")
+	if err == nil || result.ExitCode != 1 || string(result.Stderr) != "no\n" {
+		t.Errorf("Expected the recorded failure to be replayed, got %+v, %v", result, err)
+	}
+
+	if _, err := replayer.Run(ctx, "wlr-randr"); !errors.Is(err, ErrUnexpectedCommand) {
+		t.Errorf("Expected ErrUnexpectedCommand, got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+)
+
+// The D-Bus name, object and interface the service is published as.
+const (
+	BusName    = "org.omarchy.MonitorSettings"
+	ObjectPath = dbus.ObjectPath("/org/omarchy/MonitorSettings")
+	Interface  = "org.omarchy.MonitorSettings"
+)
+
+// Monitors, options and profiles are sent as arrays of a{sv} dictionaries
+// keyed like the JSON output (see the schema command), so clients can ignore
+// keys they don't know as new ones are added.
+const introspection = `
+<node>
+  <interface name="` + Interface + `">
+    <method name="GetMonitors">
+      <arg name="monitors" type="aa{sv}" direction="out"/>
+    </method>
+    <method name="GetScalingOptions">
+      <arg name="monitor" type="s" direction="in"/>
+      <arg name="options" type="aa{sv}" direction="out"/>
+    </method>
+    <method name="Apply">
+      <arg name="monitor" type="s" direction="in"/>
+      <arg name="option" type="i" direction="in"/>
+      <arg name="reason" type="s" direction="in"/>
+      <arg name="applied" type="s" direction="out"/>
+    </method>
+    <method name="ListProfiles">
+      <arg name="profiles" type="aa{sv}" direction="out"/>
+    </method>
+    <signal name="MonitorsChanged">
+      <arg name="monitors" type="aa{sv}"/>
+    </signal>
+  </interface>` + introspect.IntrospectDataString + `</node>`
+
+// dbusObject is the exported object; its methods are the D-Bus methods.
+type dbusObject struct {
+	svc *Service
+}
+
+// GetMonitors lists the detected monitors.
+func (o dbusObject) GetMonitors() ([]map[string]dbus.Variant, *dbus.Error) {
+	doc, err := o.svc.Monitors(false)
+	if err != nil {
+		return nil, dbus.MakeFailedError(err)
+	}
+	return monitorDicts(doc), nil
+}
+
+// GetScalingOptions lists the smart scaling options for a monitor; an empty
+// name is the first monitor and "focused" the focused one.
+func (o dbusObject) GetScalingOptions(name string) ([]map[string]dbus.Variant, *dbus.Error) {
+	options, err := o.svc.Options(name)
+	if err != nil {
+		return nil, dbus.MakeFailedError(err)
+	}
+	dicts := make([]map[string]dbus.Variant, 0, len(options))
+	for _, option := range options {
+		dicts = append(dicts, optionDict(option))
+	}
+	return dicts, nil
+}
+
+// Apply applies the option at the 1-based position, or the recommended one
+// for 0, and says what was applied.
+func (o dbusObject) Apply(name string, option int32, reason string) (string, *dbus.Error) {
+	result, err := o.svc.Apply("dbus", client.ApplyRequest{Monitor: name, Option: int(option), Reason: reason})
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	return fmt.Sprintf("Applied %s to %s", result.Option, result.Monitor), nil
+}
+
+// ListProfiles lists the snapshots history can restore, newest first.
+func (o dbusObject) ListProfiles() ([]map[string]dbus.Variant, *dbus.Error) {
+	profiles, err := o.svc.Profiles()
+	if err != nil {
+		return nil, dbus.MakeFailedError(err)
+	}
+	dicts := make([]map[string]dbus.Variant, 0, len(profiles))
+	for _, profile := range profiles {
+		dicts = append(dicts, profileDict(profile))
+	}
+	return dicts, nil
+}
+
+// ServeDBus publishes svc on conn and emits MonitorsChanged for every
+// document from changes until ctx ends or changes is closed. It fails if
+// another process already owns the name.
+func ServeDBus(ctx context.Context, conn *dbus.Conn, svc *Service, changes <-chan schema.Document) error {
+	if err := conn.Export(dbusObject{svc}, ObjectPath, Interface); err != nil {
+		return fmt.Errorf("failed to export D-Bus object: %w", err)
+	}
+	if err := conn.Export(introspect.Introspectable(introspection), ObjectPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return fmt.Errorf("failed to export D-Bus introspection: %w", err)
+	}
+
+	reply, err := conn.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return fmt.Errorf("failed to request D-Bus name: %w", err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("failed to request D-Bus name: %s is already owned", BusName)
+	}
+	defer conn.ReleaseName(BusName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case doc, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			if err := conn.Emit(ObjectPath, Interface+".MonitorsChanged", monitorDicts(doc)); err != nil {
+				return fmt.Errorf("failed to emit MonitorsChanged: %w", err)
+			}
+		}
+	}
+}
+
+func monitorDicts(doc schema.Document) []map[string]dbus.Variant {
+	dicts := make([]map[string]dbus.Variant, 0, len(doc.Monitors))
+	for _, mon := range doc.Monitors {
+		dicts = append(dicts, map[string]dbus.Variant{
+			"name":              dbus.MakeVariant(mon.Name),
+			"make":              dbus.MakeVariant(mon.Make),
+			"model":             dbus.MakeVariant(mon.Model),
+			"width":             dbus.MakeVariant(int32(mon.Width)),
+			"height":            dbus.MakeVariant(int32(mon.Height)),
+			"refresh_rate":      dbus.MakeVariant(mon.RefreshRate),
+			"scale":             dbus.MakeVariant(mon.Scale),
+			"position":          dbus.MakeVariant([]int32{int32(mon.Position.X), int32(mon.Position.Y)}),
+			"active":            dbus.MakeVariant(mon.Active),
+			"primary":           dbus.MakeVariant(mon.Primary),
+			"ppi":               dbus.MakeVariant(mon.PPI),
+			"recommended_scale": dbus.MakeVariant(mon.RecommendedScale),
+		})
+	}
+	return dicts
+}
+
+func optionDict(option schema.Option) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"name":             dbus.MakeVariant(option.Name),
+		"description":      dbus.MakeVariant(option.Description),
+		"reasoning":        dbus.MakeVariant(option.Reasoning),
+		"recommended":      dbus.MakeVariant(option.Recommended),
+		"monitor_scale":    dbus.MakeVariant(option.MonitorScale),
+		"gtk_scale":        dbus.MakeVariant(int32(option.GTKScale)),
+		"font_dpi":         dbus.MakeVariant(int32(option.FontDPI)),
+		"font_scale":       dbus.MakeVariant(option.FontScale),
+		"cursor_size":      dbus.MakeVariant(int32(option.CursorSize)),
+		"effective_width":  dbus.MakeVariant(int32(option.EffectiveWidth)),
+		"effective_height": dbus.MakeVariant(int32(option.EffectiveHeight)),
+	}
+}
+
+func profileDict(profile client.Profile) map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"id":     dbus.MakeVariant(profile.ID),
+		"time":   dbus.MakeVariant(profile.Time.Unix()),
+		"user":   dbus.MakeVariant(profile.User),
+		"host":   dbus.MakeVariant(profile.Host),
+		"source": dbus.MakeVariant(profile.Source),
+		"action": dbus.MakeVariant(profile.Action),
+		"reason": dbus.MakeVariant(profile.Reason),
+	}
+}
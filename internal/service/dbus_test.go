@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+)
+
+// startBus runs a private session bus for the test and returns its address.
+func startBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	dir := simtest.SocketDir(t, "bus")
+
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address",
+		"--address=unix:path="+filepath.Join(dir, "bus"))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to read dbus-daemon output: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read the bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+func connectBus(t *testing.T, address string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Dial(address)
+	if err != nil {
+		t.Fatalf("Failed to connect to the bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := conn.Auth(nil); err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	if err := conn.Hello(); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	return conn
+}
+
+// waitForName waits until someone owns BusName.
+func waitForName(t *testing.T, conn *dbus.Conn) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		var owned bool
+		if err := conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, BusName).Store(&owned); err == nil && owned {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s never appeared on the bus", BusName)
+}
+
+func TestServeDBus(t *testing.T) {
+	address := startBus(t)
+	services, sandbox := simtest.Services(t, "6k-laptop")
+	svc := New(services)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- ServeDBus(ctx, connectBus(t, address), svc, svc.Watch(ctx, nil, 0))
+	}()
+
+	client := connectBus(t, address)
+	waitForName(t, client)
+	obj := client.Object(BusName, ObjectPath)
+
+	var monitors []map[string]dbus.Variant
+	if err := obj.Call(Interface+".GetMonitors", 0).Store(&monitors); err != nil {
+		t.Fatalf("GetMonitors failed: %v", err)
+	}
+	if len(monitors) != 2 || monitors[0]["name"].Value() != "eDP-1" || monitors[1]["scale"].Value() != 2.0 {
+		t.Fatalf("Unexpected monitors: %v", monitors)
+	}
+
+	var options []map[string]dbus.Variant
+	if err := obj.Call(Interface+".GetScalingOptions", 0, "focused").Store(&options); err != nil {
+		t.Fatalf("GetScalingOptions failed: %v", err)
+	}
+	if len(options) < 2 {
+		t.Fatalf("Expected several options, got %v", options)
+	}
+	index := 0
+	for i, option := range options {
+		if option["monitor_scale"].Value() != 2.0 {
+			index = i + 1
+			break
+		}
+	}
+
+	var xml string
+	if err := obj.Call("org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xml); err != nil {
+		t.Fatalf("Introspect failed: %v", err)
+	}
+	if !strings.Contains(xml, `<signal name="MonitorsChanged">`) {
+		t.Errorf("Expected the signal in the introspection data:\n%s", xml)
+	}
+
+	if err := client.AddMatchSignal(dbus.WithMatchInterface(Interface), dbus.WithMatchMember("MonitorsChanged")); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	signals := make(chan *dbus.Signal, 10)
+	client.Signal(signals)
+
+	var applied string
+	if err := obj.Call(Interface+".Apply", 0, "eDP-1", int32(index), "From a settings panel").Store(&applied); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !strings.HasPrefix(applied, "Applied ") || !strings.HasSuffix(applied, " to eDP-1") {
+		t.Errorf("Unexpected reply: %q", applied)
+	}
+	live, _ := sandbox.Compositor.DetectMonitors()
+
+	select {
+	case signal := <-signals:
+		changed := signal.Body[0].([]map[string]dbus.Variant)
+		if changed[0]["scale"].Value() != live[0].Scale {
+			t.Errorf("Expected eDP-1 at %v in the signal, got %v", live[0].Scale, changed[0])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected MonitorsChanged after applying")
+	}
+
+	var profiles []map[string]dbus.Variant
+	if err := obj.Call(Interface+".ListProfiles", 0).Store(&profiles); err != nil {
+		t.Fatalf("ListProfiles failed: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0]["source"].Value() != "dbus" || profiles[0]["reason"].Value() != "From a settings panel" {
+		t.Errorf("Expected the apply in the profiles, got %v", profiles)
+	}
+
+	err := obj.Call(Interface+".Apply", 0, "HDMI-A-1", int32(0), "").Err
+	if dbusErr, ok := err.(dbus.Error); !ok || dbusErr.Name != "org.freedesktop.DBus.Error.Failed" {
+		t.Errorf("Expected a failed error for an unknown monitor, got %v", err)
+	}
+
+	if err := ServeDBus(ctx, connectBus(t, address), New(services), nil); err == nil {
+		t.Error("Expected a second server to be refused the name")
+	}
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("ServeDBus failed: %v", err)
+	}
+}
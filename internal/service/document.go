@@ -0,0 +1,93 @@
+package service
+
+import (
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/history"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+)
+
+// Describe is the schema document for the monitors, with the scaling
+// manager's recommended scale for each. With withOptions its options are
+// included too.
+func Describe(monitors []monitor.Monitor, scaling monitor.ScalingManagerInterface, withOptions bool) schema.Document {
+	doc := schema.Document{SchemaVersion: schema.Version, Monitors: make([]schema.Monitor, 0, len(monitors))}
+
+	for _, mon := range monitors {
+		out := schema.Monitor{
+			Name:             mon.Name,
+			Make:             mon.Make,
+			Model:            mon.Model,
+			Width:            mon.Width,
+			Height:           mon.Height,
+			RefreshRate:      mon.RefreshRate,
+			Scale:            mon.Scale,
+			Position:         schema.Position{X: mon.Position.X, Y: mon.Position.Y},
+			Active:           mon.IsActive,
+			Primary:          mon.IsPrimary,
+			PPI:              monitor.EstimatePPI(mon),
+			RecommendedScale: scaling.GetRecommendedScale(mon),
+		}
+		if withOptions {
+			scalingOptions := scaling.GetIntelligentScalingOptions(mon)
+			out.Options = make([]schema.Option, 0, len(scalingOptions))
+			for _, option := range scalingOptions {
+				out.Options = append(out.Options, schema.Option{
+					Name:            option.DisplayName,
+					Description:     option.Description,
+					Reasoning:       option.Reasoning,
+					Recommended:     option.IsRecommended,
+					MonitorScale:    option.MonitorScale,
+					GTKScale:        option.GTKScale,
+					FontDPI:         option.FontDPI,
+					FontScale:       option.FontScale,
+					CursorSize:      option.CursorSize,
+					EffectiveWidth:  option.EffectiveWidth,
+					EffectiveHeight: option.EffectiveHeight,
+				})
+			}
+		}
+		doc.Monitors = append(doc.Monitors, out)
+	}
+
+	return doc
+}
+
+// ToMonitor is a document's monitor as the detector reported it, for
+// programs that read documents in place of detecting monitors themselves.
+func ToMonitor(m schema.Monitor) monitor.Monitor {
+	return monitor.Monitor{
+		Name:        m.Name,
+		Width:       m.Width,
+		Height:      m.Height,
+		RefreshRate: m.RefreshRate,
+		Scale:       m.Scale,
+		Position:    monitor.Position{X: m.Position.X, Y: m.Position.Y},
+		Make:        m.Make,
+		Model:       m.Model,
+		IsActive:    m.Active,
+		IsPrimary:   m.Primary,
+	}
+}
+
+// wireEntry is a history entry as the socket sends it.
+func wireEntry(entry history.Entry) client.Entry {
+	out := client.Entry{
+		ID:       entry.ID,
+		Time:     entry.Time,
+		User:     entry.User,
+		Host:     entry.Host,
+		Source:   entry.Source,
+		Action:   entry.Action,
+		Reason:   entry.Reason,
+		Monitors: make([]client.MonitorState, 0, len(entry.Monitors)),
+		Files:    make([]client.FileSnapshot, 0, len(entry.Files)),
+	}
+	for _, mon := range entry.Monitors {
+		out.Monitors = append(out.Monitors, client.MonitorState(mon))
+	}
+	for _, file := range entry.Files {
+		out.Files = append(out.Files, client.FileSnapshot(file))
+	}
+	return out
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+)
+
+// rpcWriteTimeout bounds every write, so a client that stops reading can't
+// hold up the others.
+const rpcWriteTimeout = 5 * time.Second
+
+// rpcSource is recorded in history for changes made over the socket.
+const rpcSource = "rpc"
+
+// rpcMethod runs a method with its raw params.
+type rpcMethod func(svc *Service, params json.RawMessage) (interface{}, error)
+
+// errInvalidParams marks params a method could not decode.
+var errInvalidParams = errors.New("invalid params")
+
+var rpcMethods = map[string]rpcMethod{
+	"list": func(svc *Service, _ json.RawMessage) (interface{}, error) {
+		return svc.Monitors(false)
+	},
+	"recommend": func(svc *Service, params json.RawMessage) (interface{}, error) {
+		var p client.MonitorParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return svc.Recommend(p.Monitor)
+	},
+	"apply": func(svc *Service, params json.RawMessage) (interface{}, error) {
+		var req client.ApplyRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return svc.Apply(rpcSource, req)
+	},
+	"profiles": func(svc *Service, _ json.RawMessage) (interface{}, error) {
+		return svc.Profiles()
+	},
+	"history.list": func(svc *Service, _ json.RawMessage) (interface{}, error) {
+		return svc.History()
+	},
+	"history.show": func(svc *Service, params json.RawMessage) (interface{}, error) {
+		var p client.IDParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return svc.HistoryEntry(p.ID)
+	},
+	"history.restore": func(svc *Service, params json.RawMessage) (interface{}, error) {
+		var p client.IDParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return svc.Restore(rpcSource, p.ID)
+	},
+}
+
+// decodeParams reads by-name params into v; missing params leave v as is.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+	return nil
+}
+
+// ServeRPC answers newline-delimited JSON-RPC 2.0 on listener, and sends
+// client.ChangedMethod to every connection for each document from changes,
+// until ctx ends. It closes the listener and every connection before returning.
+func ServeRPC(ctx context.Context, listener net.Listener, svc *Service, changes <-chan schema.Document) error {
+	srv := &rpcServer{svc: svc, conns: make(map[*rpcConn]struct{})}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		srv.broadcast(ctx, changes)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		srv.closeAll()
+	}()
+
+	var err error
+	for {
+		var conn net.Conn
+		conn, err = listener.Accept()
+		if err != nil {
+			break
+		}
+		c := srv.add(conn)
+		if c == nil {
+			conn.Close()
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			srv.serve(c)
+		}()
+	}
+
+	if ctx.Err() != nil {
+		err = nil
+	} else {
+		err = fmt.Errorf("failed to accept connection: %w", err)
+		listener.Close()
+		srv.closeAll()
+	}
+	wg.Wait()
+	return err
+}
+
+type rpcServer struct {
+	svc *Service
+
+	mu     sync.Mutex
+	conns  map[*rpcConn]struct{}
+	closed bool
+}
+
+// rpcConn is one client. Replies and notifications share the connection,
+// so writes are serialized.
+type rpcConn struct {
+	conn net.Conn
+
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func (c *rpcConn) send(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(rpcWriteTimeout))
+	return c.encoder.Encode(v)
+}
+
+// add tracks conn, or returns nil once the server is closing.
+func (s *rpcServer) add(conn net.Conn) *rpcConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	c := &rpcConn{conn: conn, encoder: json.NewEncoder(conn)}
+	s.conns[c] = struct{}{}
+	return c
+}
+
+func (s *rpcServer) remove(c *rpcConn) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+	c.conn.Close()
+}
+
+func (s *rpcServer) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for c := range s.conns {
+		c.conn.Close()
+	}
+}
+
+func (s *rpcServer) broadcast(ctx context.Context, changes <-chan schema.Document) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case doc, ok := <-changes:
+			if !ok {
+				return
+			}
+			params, err := json.Marshal(doc)
+			if err != nil {
+				continue
+			}
+			note := client.RPCResponse{JSONRPC: "2.0", Method: client.ChangedMethod, Params: params}
+
+			s.mu.Lock()
+			conns := make([]*rpcConn, 0, len(s.conns))
+			for c := range s.conns {
+				conns = append(conns, c)
+			}
+			s.mu.Unlock()
+
+			for _, c := range conns {
+				// A client that can't keep up is dropped; its reader sees the
+				// connection close
+				if err := c.send(note); err != nil {
+					c.conn.Close()
+				}
+			}
+		}
+	}
+}
+
+// serve reads one request, or one batch, per line until the client hangs up.
+func (s *rpcServer) serve(c *rpcConn) {
+	defer s.remove(c)
+
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		reply := s.handleLine(line)
+		if reply == nil {
+			continue
+		}
+		if err := c.send(reply); err != nil {
+			return
+		}
+	}
+}
+
+// handleLine answers a request or batch, or returns nil when nothing needs
+// an answer.
+func (s *rpcServer) handleLine(line []byte) interface{} {
+	var raw json.RawMessage
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return errorResponse(nil, client.CodeParseError, "parse error")
+	}
+
+	if raw[0] != '[' {
+		if reply, ok := s.handleRaw(raw); ok {
+			return reply
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(raw, &batch); err != nil || len(batch) == 0 {
+		return errorResponse(nil, client.CodeInvalidRequest, "invalid request")
+	}
+	replies := make([]client.RPCResponse, 0, len(batch))
+	for _, item := range batch {
+		if reply, ok := s.handleRaw(item); ok {
+			replies = append(replies, reply)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	return replies
+}
+
+// handleRaw answers one request; ok is false for a notification.
+func (s *rpcServer) handleRaw(raw json.RawMessage) (client.RPCResponse, bool) {
+	var req client.RPCRequest
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, client.CodeInvalidRequest, "invalid request"), true
+	}
+
+	reply := s.call(req)
+	if len(req.ID) == 0 {
+		return client.RPCResponse{}, false
+	}
+	return reply, true
+}
+
+func (s *rpcServer) call(req client.RPCRequest) client.RPCResponse {
+	method, ok := rpcMethods[req.Method]
+	if !ok {
+		return errorResponse(req.ID, client.CodeMethodNotFound, fmt.Sprintf("method %s not found", req.Method))
+	}
+
+	result, err := method(s.svc, req.Params)
+	if errors.Is(err, errInvalidParams) {
+		return errorResponse(req.ID, client.CodeInvalidParams, err.Error())
+	}
+	if err != nil {
+		return errorResponse(req.ID, client.CodeFailed, err.Error())
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, client.CodeFailed, fmt.Sprintf("failed to encode result: %v", err))
+	}
+	return client.RPCResponse{JSONRPC: "2.0", ID: req.ID, Result: encoded}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) client.RPCResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return client.RPCResponse{JSONRPC: "2.0", ID: id, Error: &client.Error{Code: code, Message: message}}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+)
+
+// listenRPC serves svc on a temporary socket and returns its path.
+func listenRPC(t *testing.T, svc *Service) string {
+	t.Helper()
+	path := filepath.Join(simtest.SocketDir(t, "rpc"), "rpc.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- ServeRPC(ctx, listener, svc, svc.Watch(ctx, nil, 0))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("ServeRPC failed: %v", err)
+		}
+	})
+	return path
+}
+
+// exchange sends one line and reads one back.
+func exchange(t *testing.T, conn net.Conn, reader *bufio.Reader, line string) string {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write([]byte(line + "\n")); err != nil {
+		t.Fatalf("Failed to send %s: %v", line, err)
+	}
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read the reply to %s: %v", line, err)
+	}
+	return reply
+}
+
+func TestServeRPCProtocol(t *testing.T) {
+	services, _ := simtest.Services(t, "6k-laptop")
+	path := listenRPC(t, New(services))
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	tests := []struct {
+		request string
+		code    int
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"list"`, client.CodeParseError},
+		{`{"jsonrpc":"1.0","id":1,"method":"list"}`, client.CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":1,"method":"resize"}`, client.CodeMethodNotFound},
+		{`{"jsonrpc":"2.0","id":1,"method":"apply","params":{"option":"big"}}`, client.CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"apply","params":{"monitor":"HDMI-A-1"}}`, client.CodeFailed},
+		{`[]`, client.CodeInvalidRequest},
+	}
+	for _, tt := range tests {
+		var resp client.RPCResponse
+		if err := json.Unmarshal([]byte(exchange(t, conn, reader, tt.request)), &resp); err != nil {
+			t.Fatalf("Unreadable reply to %s: %v", tt.request, err)
+		}
+		if resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s: expected error %d, got %+v", tt.request, tt.code, resp)
+		}
+	}
+
+	// Notifications get no reply, so the next line answers the batch
+	reply := exchange(t, conn, reader, `{"jsonrpc":"2.0","method":"list"}`+"\n"+
+		`[{"jsonrpc":"2.0","id":"a","method":"list"},{"jsonrpc":"2.0","method":"profiles"},{"jsonrpc":"2.0","id":"b","method":"recommend","params":{"monitor":"DP-1"}}]`)
+	var batch []client.RPCResponse
+	if err := json.Unmarshal([]byte(reply), &batch); err != nil {
+		t.Fatalf("Expected a batch reply, got %s", reply)
+	}
+	if len(batch) != 2 || string(batch[0].ID) != `"a"` || string(batch[1].ID) != `"b"` {
+		t.Fatalf("Expected replies to a and b, got %s", reply)
+	}
+	var doc struct {
+		Monitors []struct {
+			Name    string            `json:"name"`
+			Options []json.RawMessage `json:"options"`
+		} `json:"monitors"`
+	}
+	if err := json.Unmarshal(batch[1].Result, &doc); err != nil || len(doc.Monitors) != 1 || doc.Monitors[0].Name != "DP-1" || len(doc.Monitors[0].Options) == 0 {
+		t.Errorf("Expected DP-1 with options, got %s", batch[1].Result)
+	}
+
+	reply = exchange(t, conn, reader, `{"jsonrpc":"2.0","id":2,"method":"profiles"}`)
+	if !strings.Contains(reply, `"result":[]`) {
+		t.Errorf("Expected an empty result for no profiles, got %s", reply)
+	}
+}
@@ -0,0 +1,365 @@
+// Package service holds the operations the CLI offers, for the serve command
+// to expose to other programs over a long-lived app.Services: listing
+// monitors and their scaling options, applying an option, and the snapshots
+// in history. dbus.go publishes them on D-Bus and rpc.go on a JSON-RPC
+// socket.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/events"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/history"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+)
+
+// Focused names the focused monitor wherever a monitor name is accepted.
+const Focused = "focused"
+
+// SelectMonitor finds the monitor called name. An empty name is the first
+// monitor, and Focused is the one the compositor reports as focused.
+func SelectMonitor(monitors []monitor.Monitor, name string) (monitor.Monitor, error) {
+	if len(monitors) == 0 {
+		return monitor.Monitor{}, errors.New("no monitors detected")
+	}
+	if name == "" {
+		return monitors[0], nil
+	}
+	if name == Focused {
+		for _, mon := range monitors {
+			if mon.IsPrimary {
+				return mon, nil
+			}
+		}
+		return monitors[0], nil
+	}
+	for _, mon := range monitors {
+		if mon.Name == name {
+			return mon, nil
+		}
+	}
+	return monitor.Monitor{}, fmt.Errorf("monitor %s not found", name)
+}
+
+// SelectOption picks a smart scaling option by its 1-based position, or the
+// recommended one when index is 0.
+func SelectOption(options []monitor.ScalingOption, index int) (monitor.ScalingOption, error) {
+	if len(options) == 0 {
+		return monitor.ScalingOption{}, errors.New("no scaling options")
+	}
+
+	if index != 0 {
+		if index < 1 || index > len(options) {
+			return monitor.ScalingOption{}, fmt.Errorf("option %d out of range (1-%d)", index, len(options))
+		}
+		return options[index-1], nil
+	}
+
+	for _, option := range options {
+		if option.IsRecommended {
+			return option, nil
+		}
+	}
+	return options[0], nil
+}
+
+// Transaction is the change an apply request with targets asks for, on the
+// monitors as detected. Without an action it is described by its scales.
+func Transaction(monitors []monitor.Monitor, req client.ApplyRequest) (monitor.Transaction, error) {
+	tx := monitor.Transaction{
+		Description: req.Action,
+		GTKScale:    req.GTKScale,
+		FontDPI:     req.FontDPI,
+		FontScale:   req.FontScale,
+		CursorSize:  req.CursorSize,
+	}
+	var parts []string
+	for _, target := range req.Targets {
+		if target.Scale <= 0 {
+			return monitor.Transaction{}, fmt.Errorf("invalid scale %v for %s", target.Scale, target.Monitor)
+		}
+		mon, err := SelectMonitor(monitors, target.Monitor)
+		if err != nil {
+			return monitor.Transaction{}, err
+		}
+		tx.Monitors = append(tx.Monitors, monitor.MonitorTarget{Monitor: mon, Scale: target.Scale})
+		parts = append(parts, fmt.Sprintf("%.2fx to %s", target.Scale, mon.Name))
+	}
+	if tx.Description == "" {
+		tx.Description = "Apply " + strings.Join(parts, ", ")
+	}
+	return tx, nil
+}
+
+// Execute journals the monitors as they are, so the change can be restored,
+// then runs plan. source says who asked for the change.
+func Execute(services *app.Services, source string, monitors []monitor.Monitor, plan monitor.Plan, reason string) (monitor.Verification, error) {
+	if services.History != nil {
+		if _, err := services.History.Record(history.Record{
+			Source:   source,
+			Action:   plan.Description,
+			Reason:   reason,
+			Monitors: monitors,
+			Paths:    services.ConfigManager.ManagedFiles(),
+		}); err != nil {
+			return monitor.Verification{}, fmt.Errorf("failed to record history: %w", err)
+		}
+	}
+
+	verification, err := services.ConfigManager.Execute(plan)
+	if err != nil {
+		return verification, fmt.Errorf("failed to apply changes: %w", err)
+	}
+	return verification, nil
+}
+
+// Service runs operations for any number of clients. Changes are
+// serialized, and each one is checked for monitor changes straight away.
+type Service struct {
+	services *app.Services
+
+	mu      sync.Mutex
+	applied chan struct{}
+}
+
+// New creates a service.
+func New(services *app.Services) *Service {
+	return &Service{services: services, applied: make(chan struct{}, 1)}
+}
+
+// Monitors describes the detected monitors, with their scaling options when
+// withOptions is set.
+func (s *Service) Monitors(withOptions bool) (schema.Document, error) {
+	monitors, err := s.services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return schema.Document{}, fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	return Describe(monitors, s.services.ScalingManager, withOptions), nil
+}
+
+// Recommend describes the named monitor, or every monitor for an empty name,
+// with their scaling options.
+func (s *Service) Recommend(name string) (schema.Document, error) {
+	monitors, err := s.services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return schema.Document{}, fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	if name != "" {
+		target, err := SelectMonitor(monitors, name)
+		if err != nil {
+			return schema.Document{}, err
+		}
+		monitors = []monitor.Monitor{target}
+	}
+	return Describe(monitors, s.services.ScalingManager, true), nil
+}
+
+// Options lists the smart scaling options for the named monitor.
+func (s *Service) Options(name string) ([]schema.Option, error) {
+	monitors, err := s.services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	target, err := SelectMonitor(monitors, name)
+	if err != nil {
+		return nil, err
+	}
+	doc := Describe([]monitor.Monitor{target}, s.services.ScalingManager, true)
+	return doc.Monitors[0].Options, nil
+}
+
+// Apply applies a smart scaling option, or the request's targets, or only
+// plans the change for a dry run. source says who asked, for history.
+func (s *Service) Apply(source string, req client.ApplyRequest) (client.ApplyResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	monitors, err := s.services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return client.ApplyResult{}, fmt.Errorf("failed to detect monitors: %w", err)
+	}
+
+	var plan monitor.Plan
+	var result client.ApplyResult
+	if len(req.Targets) > 0 {
+		tx, err := Transaction(monitors, req)
+		if err != nil {
+			return client.ApplyResult{}, err
+		}
+		if plan, err = s.services.ConfigManager.PlanTransaction(tx); err != nil {
+			return client.ApplyResult{}, fmt.Errorf("failed to plan changes: %w", err)
+		}
+		names := make([]string, 0, len(tx.Monitors))
+		for _, target := range tx.Monitors {
+			names = append(names, target.Monitor.Name)
+		}
+		result = client.ApplyResult{Monitor: strings.Join(names, ", "), Option: tx.Description}
+	} else {
+		target, err := SelectMonitor(monitors, req.Monitor)
+		if err != nil {
+			return client.ApplyResult{}, err
+		}
+		option, err := SelectOption(s.services.ScalingManager.GetIntelligentScalingOptions(target), req.Option)
+		if err != nil {
+			return client.ApplyResult{}, fmt.Errorf("%s: %w", target.Name, err)
+		}
+		if plan, err = s.services.ConfigManager.PlanScalingOption(target, option); err != nil {
+			return client.ApplyResult{}, fmt.Errorf("failed to plan changes: %w", err)
+		}
+		result = client.ApplyResult{Monitor: target.Name, Option: option.DisplayName}
+	}
+	result.Plan = plan.String()
+	if req.DryRun {
+		return result, nil
+	}
+
+	verification, err := Execute(s.services, source, monitors, plan, req.Reason)
+	if err != nil {
+		return client.ApplyResult{}, err
+	}
+	for _, drift := range verification.Drift {
+		result.Drift = append(result.Drift, drift.String())
+	}
+
+	s.changed()
+	return result, nil
+}
+
+// Profiles lists the snapshots in history, newest first. There are no named
+// profiles yet; each snapshot is a configuration that Restore can bring back.
+func (s *Service) Profiles() ([]client.Profile, error) {
+	entries, err := s.History()
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]client.Profile, 0, len(entries))
+	for _, entry := range entries {
+		profiles = append(profiles, client.Profile{
+			ID:     entry.ID,
+			Time:   entry.Time,
+			User:   entry.User,
+			Host:   entry.Host,
+			Source: entry.Source,
+			Action: entry.Action,
+			Reason: entry.Reason,
+		})
+	}
+	return profiles, nil
+}
+
+// History lists the snapshots in history in full, newest first.
+func (s *Service) History() ([]client.Entry, error) {
+	if s.services.History == nil {
+		return nil, errors.New("history is not available")
+	}
+	entries, err := s.services.History.List()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]client.Entry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, wireEntry(entry))
+	}
+	return out, nil
+}
+
+// HistoryEntry is the snapshot with the given ID.
+func (s *Service) HistoryEntry(id string) (client.Entry, error) {
+	if s.services.History == nil {
+		return client.Entry{}, errors.New("history is not available")
+	}
+	entry, err := s.services.History.Get(id)
+	if err != nil {
+		return client.Entry{}, err
+	}
+	return wireEntry(entry), nil
+}
+
+// Restore brings back the monitor scales and config files of a snapshot,
+// first taking one of the current state. source says who asked, for history.
+func (s *Service) Restore(source, id string) (client.RestoreResult, error) {
+	if s.services.History == nil {
+		return client.RestoreResult{}, errors.New("history is not available")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live, err := s.services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		return client.RestoreResult{}, fmt.Errorf("failed to detect monitors: %w", err)
+	}
+	safety, err := s.services.History.Restore(id, source, live, s.services.ConfigManager.ApplyMonitorScale)
+	if err != nil {
+		return client.RestoreResult{}, fmt.Errorf("failed to restore %s: %w", id, err)
+	}
+
+	s.changed()
+	return client.RestoreResult{Restored: id, Safety: safety.ID}, nil
+}
+
+// changed makes Watch check the monitors now rather than at the next event.
+func (s *Service) changed() {
+	select {
+	case s.applied <- struct{}{}:
+	default:
+	}
+}
+
+// Watch sends the monitors whenever they change, checking after every
+// monitor event, every apply and every interval, until ctx ends. Detection
+// errors are skipped; the next check tries again.
+func (s *Service) Watch(ctx context.Context, changes <-chan events.Event, interval time.Duration) <-chan schema.Document {
+	docs := make(chan schema.Document)
+	last, _ := s.Monitors(false)
+
+	go func() {
+		defer close(docs)
+
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-changes:
+				if !ok {
+					changes = nil
+					continue
+				}
+				if !event.MonitorChange() {
+					continue
+				}
+			case <-s.applied:
+			case <-tick:
+			}
+
+			doc, err := s.Monitors(false)
+			if err != nil || reflect.DeepEqual(doc, last) {
+				continue
+			}
+			last = doc
+			select {
+			case docs <- doc:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return docs
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/events"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+)
+
+func TestSelectMonitor(t *testing.T) {
+	monitors := []monitor.Monitor{{Name: "eDP-1"}, {Name: "DP-1", IsPrimary: true}}
+
+	for name, expected := range map[string]string{"": "eDP-1", Focused: "DP-1", "eDP-1": "eDP-1"} {
+		mon, err := SelectMonitor(monitors, name)
+		if err != nil || mon.Name != expected {
+			t.Errorf("SelectMonitor(%q) = %s, %v; want %s", name, mon.Name, err, expected)
+		}
+	}
+	if mon, _ := SelectMonitor(monitors[:1], Focused); mon.Name != "eDP-1" {
+		t.Errorf("Expected the first monitor without a focused one, got %s", mon.Name)
+	}
+	if _, err := SelectMonitor(monitors, "HDMI-A-1"); err == nil {
+		t.Error("Expected an unknown monitor to fail")
+	}
+	if _, err := SelectMonitor(nil, ""); err == nil {
+		t.Error("Expected no monitors to fail")
+	}
+}
+
+func TestSelectOption(t *testing.T) {
+	options := []monitor.ScalingOption{{DisplayName: "1x"}, {DisplayName: "2x", IsRecommended: true}}
+
+	for index, expected := range map[int]string{0: "2x", 1: "1x", 2: "2x"} {
+		option, err := SelectOption(options, index)
+		if err != nil || option.DisplayName != expected {
+			t.Errorf("SelectOption(%d) = %s, %v; want %s", index, option.DisplayName, err, expected)
+		}
+	}
+	if option, _ := SelectOption(options[:1], 0); option.DisplayName != "1x" {
+		t.Errorf("Expected the first option without a recommended one, got %s", option.DisplayName)
+	}
+	for _, index := range []int{-1, 3} {
+		if _, err := SelectOption(options, index); err == nil {
+			t.Errorf("Expected option %d to be out of range", index)
+		}
+	}
+}
+
+func TestServiceApply(t *testing.T) {
+	services, sandbox := simtest.Services(t, "6k-laptop")
+	svc := New(services)
+
+	doc, err := svc.Monitors(false)
+	if err != nil {
+		t.Fatalf("Monitors failed: %v", err)
+	}
+	if len(doc.Monitors) != 2 || doc.Monitors[0].Options != nil {
+		t.Fatalf("Unexpected monitors: %+v", doc)
+	}
+	options, err := svc.Options("DP-1")
+	if err != nil || len(options) < 2 {
+		t.Fatalf("Expected options for DP-1, got %v, %v", options, err)
+	}
+
+	// eDP-1 starts at 2x, so pick an option that changes it
+	options, _ = svc.Options("eDP-1")
+	index := 0
+	for i, option := range options {
+		if option.MonitorScale != 2 {
+			index = i + 1
+			break
+		}
+	}
+
+	result, err := svc.Apply("test", client.ApplyRequest{Monitor: "eDP-1", Option: index, DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if !strings.HasPrefix(result.Plan, "Plan: ") || result.Monitor != "eDP-1" {
+		t.Errorf("Unexpected dry run: %+v", result)
+	}
+	if entries, _ := svc.Profiles(); len(entries) != 0 {
+		t.Error("Dry run must not be journaled")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := svc.Watch(ctx, nil, 0)
+
+	result, err = svc.Apply("test", client.ApplyRequest{Monitor: "eDP-1", Option: index, Reason: "Too big"})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	monitors, _ := sandbox.Compositor.DetectMonitors()
+	if monitors[0].Scale != options[index-1].MonitorScale || result.Option != options[index-1].Name {
+		t.Errorf("Expected eDP-1 at %v, got %v (%+v)", options[index-1].MonitorScale, monitors[0].Scale, result)
+	}
+
+	select {
+	case doc := <-changes:
+		if doc.Monitors[0].Scale != monitors[0].Scale {
+			t.Errorf("Expected the changed monitors, got %+v", doc.Monitors[0])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a change after applying")
+	}
+
+	entries, err := svc.Profiles()
+	if err != nil {
+		t.Fatalf("Profiles failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Source != "test" || entries[0].Reason != "Too big" {
+		t.Errorf("Expected the apply to be journaled, got %+v", entries)
+	}
+}
+
+func TestServiceApplyTargets(t *testing.T) {
+	services, sandbox := simtest.Services(t, "6k-laptop")
+	svc := New(services)
+
+	req := client.ApplyRequest{
+		Targets: []client.Target{{Monitor: "eDP-1", Scale: 1.5}, {Monitor: "DP-1", Scale: 1}},
+		Reason:  "Staged together",
+	}
+	result, err := svc.Apply("test", req)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.Monitor != "eDP-1, DP-1" || result.Option != "Apply 1.50x to eDP-1, 1.00x to DP-1" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	monitors, _ := sandbox.Compositor.DetectMonitors()
+	if monitors[0].Scale != 1.5 || monitors[1].Scale != 1 {
+		t.Errorf("Expected both monitors set, got %v and %v", monitors[0].Scale, monitors[1].Scale)
+	}
+	entries, _ := svc.Profiles()
+	if len(entries) != 1 || entries[0].Action != result.Option || entries[0].Reason != "Staged together" {
+		t.Errorf("Expected one journaled apply, got %+v", entries)
+	}
+
+	for _, bad := range []client.Target{{Monitor: "HDMI-A-1", Scale: 1}, {Monitor: "eDP-1"}} {
+		if _, err := svc.Apply("test", client.ApplyRequest{Targets: []client.Target{bad}}); err == nil {
+			t.Errorf("Expected %+v to be refused", bad)
+		}
+	}
+}
+
+func TestWatchIgnoresUnrelatedEvents(t *testing.T) {
+	services, sandbox := simtest.Services(t, "6k-laptop")
+	svc := New(services)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	incoming := make(chan events.Event)
+	changes := svc.Watch(ctx, incoming, 0)
+
+	// Nothing changed, so a monitor event sends nothing either
+	incoming <- eventNamed("workspace")
+	incoming <- eventNamed("focusedmon")
+	// A send only returns once the previous event has been handled
+	incoming <- eventNamed("workspace")
+
+	if _, err := sandbox.Compositor.Run(ctx, "hyprctl", "keyword", "monitor", "DP-1,preferred,auto,1"); err != nil {
+		t.Fatalf("Failed to change the simulation: %v", err)
+	}
+	incoming <- eventNamed("configreloaded")
+
+	select {
+	case doc := <-changes:
+		if doc.Monitors[1].Scale != 1 {
+			t.Errorf("Expected DP-1 at 1x, got %+v", doc.Monitors[1])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a change after the monitor event")
+	}
+}
+
+func eventNamed(name string) events.Event {
+	return events.Event{Name: name}
+}
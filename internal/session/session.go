@@ -0,0 +1,232 @@
+// Package session works out what kind of graphical session the application
+// is running in, from environment variables, compositor sockets and the
+// running processes.
+package session
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Kind int
+
+const (
+	Unknown Kind = iota
+	Hyprland
+	Sway
+	// Wlroots is any other wlroots-based Wayland compositor (river, Wayfire,
+	// labwc and so on), or a Wayland session that couldn't be identified.
+	Wlroots
+	GNOME
+	KDE
+	X11
+	TTY
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Hyprland:
+		return "Hyprland"
+	case Sway:
+		return "Sway"
+	case Wlroots:
+		return "wlroots compositor"
+	case GNOME:
+		return "GNOME"
+	case KDE:
+		return "KDE Plasma"
+	case X11:
+		return "X11"
+	case TTY:
+		return "TTY"
+	default:
+		return "unknown"
+	}
+}
+
+// Backend is the tool monitors are detected with in a session.
+type Backend string
+
+const (
+	BackendHyprctl  Backend = "hyprctl"
+	BackendWlrRandr Backend = "wlr-randr"
+	BackendNone     Backend = ""
+)
+
+// Session is the result of a probe. Evidence lists what the
+// classification was based on, for logs and the doctor report.
+type Session struct {
+	Kind     Kind
+	Evidence []string
+}
+
+// Backend picks the detection tool: hyprctl under Hyprland, wlr-randr under
+// other wlroots compositors, and nothing elsewhere.
+func (s Session) Backend() Backend {
+	switch s.Kind {
+	case Hyprland:
+		return BackendHyprctl
+	case Sway, Wlroots:
+		return BackendWlrRandr
+	default:
+		return BackendNone
+	}
+}
+
+// CanApply reports whether changes can be applied; only Hyprland is
+// supported.
+func (s Session) CanApply() bool {
+	return s.Kind == Hyprland
+}
+
+func (s Session) String() string {
+	if len(s.Evidence) == 0 {
+		return s.Kind.String()
+	}
+	return fmt.Sprintf("%s (%s)", s.Kind, strings.Join(s.Evidence, ", "))
+}
+
+// Probe holds what a classification looks at, so it can run against a fake
+// system in tests.
+type Probe struct {
+	Getenv func(string) string
+	// Dial reports whether a unix socket accepts connections.
+	Dial func(path string) error
+	// Processes returns the command names of running processes.
+	Processes  func() []string
+	RuntimeDir string
+}
+
+// DefaultProbe looks at the real system.
+func DefaultProbe() Probe {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+
+	return Probe{
+		Getenv: os.Getenv,
+		Dial: func(path string) error {
+			conn, err := net.DialTimeout("unix", path, time.Second)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+		Processes:  procProcesses,
+		RuntimeDir: runtimeDir,
+	}
+}
+
+// compositors maps process names to the session they indicate.
+var compositors = map[string]Kind{
+	"Hyprland":     Hyprland,
+	"hyprland":     Hyprland,
+	"sway":         Sway,
+	"gnome-shell":  GNOME,
+	"kwin_wayland": KDE,
+	"kwin_x11":     KDE,
+	"river":        Wlroots,
+	"wayfire":      Wlroots,
+	"labwc":        Wlroots,
+	"dwl":          Wlroots,
+	"hikari":       Wlroots,
+	"cage":         Wlroots,
+	"niri":         Wlroots,
+}
+
+// Detect classifies the session. Compositor IPC sockets are the strongest
+// evidence, then the desktop name, then running compositor processes.
+func (p Probe) Detect() Session {
+	if signature := p.Getenv("HYPRLAND_INSTANCE_SIGNATURE"); signature != "" {
+		for _, path := range []string{
+			filepath.Join(p.RuntimeDir, "hypr", signature, ".socket.sock"),
+			filepath.Join("/tmp", "hypr", signature, ".socket.sock"),
+		} {
+			if p.Dial(path) == nil {
+				return Session{Kind: Hyprland, Evidence: []string{"Hyprland IPC socket reachable"}}
+			}
+		}
+	}
+
+	if sock := p.Getenv("SWAYSOCK"); sock != "" && p.Dial(sock) == nil {
+		return Session{Kind: Sway, Evidence: []string{"SWAYSOCK reachable"}}
+	}
+
+	wayland := p.Getenv("WAYLAND_DISPLAY") != ""
+	x11 := p.Getenv("DISPLAY") != ""
+	if !wayland && !x11 {
+		return Session{Kind: TTY, Evidence: []string{"neither WAYLAND_DISPLAY nor DISPLAY is set"}}
+	}
+
+	desktop := strings.ToLower(p.Getenv("XDG_CURRENT_DESKTOP") + ":" + p.Getenv("XDG_SESSION_DESKTOP"))
+	byDesktop := Unknown
+	switch {
+	case strings.Contains(desktop, "hyprland"):
+		byDesktop = Hyprland
+	case strings.Contains(desktop, "sway"):
+		byDesktop = Sway
+	case strings.Contains(desktop, "gnome"):
+		byDesktop = GNOME
+	case strings.Contains(desktop, "kde") || strings.Contains(desktop, "plasma"):
+		byDesktop = KDE
+	}
+
+	if !wayland {
+		evidence := []string{"DISPLAY is set without WAYLAND_DISPLAY"}
+		if byDesktop != Unknown {
+			evidence = append(evidence, "desktop "+byDesktop.String())
+		}
+		return Session{Kind: X11, Evidence: evidence}
+	}
+
+	if byDesktop == GNOME || byDesktop == KDE {
+		return Session{Kind: byDesktop, Evidence: []string{"XDG_CURRENT_DESKTOP"}}
+	}
+
+	byProcess := Unknown
+	var processName string
+	if p.Processes != nil {
+		for _, name := range p.Processes() {
+			if kind, ok := compositors[name]; ok {
+				byProcess, processName = kind, name
+				break
+			}
+		}
+	}
+
+	switch {
+	case byDesktop == Hyprland || byProcess == Hyprland:
+		// Hyprland is running but its socket isn't reachable from here, so
+		// hyprctl won't work either; report it for what it is.
+		return Session{Kind: Wlroots, Evidence: []string{"Hyprland is running but its IPC socket is unreachable"}}
+	case byDesktop == Sway:
+		return Session{Kind: Sway, Evidence: []string{"XDG_CURRENT_DESKTOP"}}
+	case byProcess != Unknown:
+		return Session{Kind: byProcess, Evidence: []string{processName + " process"}}
+	}
+
+	return Session{Kind: Wlroots, Evidence: []string{"unrecognised Wayland compositor"}}
+}
+
+// procProcesses reads process names from /proc.
+func procProcesses() []string {
+	paths, err := filepath.Glob("/proc/[0-9]*/comm")
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		names = append(names, strings.TrimSpace(string(data)))
+	}
+	return names
+}
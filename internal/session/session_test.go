@@ -0,0 +1,114 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestProbeDetect(t *testing.T) {
+	runtimeDir := "/run/user/1000"
+	hyprSocket := filepath.Join(runtimeDir, "hypr", "abc", ".socket.sock")
+	swaySocket := filepath.Join(runtimeDir, "sway-ipc.1000.sock")
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		sockets   []string
+		processes []string
+		expected  Kind
+		backend   Backend
+	}{
+		{
+			name:     "hyprland with reachable socket",
+			env:      map[string]string{"HYPRLAND_INSTANCE_SIGNATURE": "abc", "WAYLAND_DISPLAY": "wayland-1"},
+			sockets:  []string{hyprSocket},
+			expected: Hyprland,
+			backend:  BackendHyprctl,
+		},
+		{
+			name:      "hyprland with stale signature",
+			env:       map[string]string{"HYPRLAND_INSTANCE_SIGNATURE": "old", "WAYLAND_DISPLAY": "wayland-1"},
+			processes: []string{"Hyprland"},
+			expected:  Wlroots,
+			backend:   BackendWlrRandr,
+		},
+		{
+			name:     "sway",
+			env:      map[string]string{"SWAYSOCK": swaySocket, "WAYLAND_DISPLAY": "wayland-1"},
+			sockets:  []string{swaySocket},
+			expected: Sway,
+			backend:  BackendWlrRandr,
+		},
+		{
+			name:      "river",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-1"},
+			processes: []string{"systemd", "river"},
+			expected:  Wlroots,
+			backend:   BackendWlrRandr,
+		},
+		{
+			name:     "unknown wayland compositor",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			expected: Wlroots,
+			backend:  BackendWlrRandr,
+		},
+		{
+			name:     "gnome",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0", "XDG_CURRENT_DESKTOP": "ubuntu:GNOME"},
+			expected: GNOME,
+			backend:  BackendNone,
+		},
+		{
+			name:      "kde by process",
+			env:       map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			processes: []string{"kwin_wayland"},
+			expected:  KDE,
+			backend:   BackendNone,
+		},
+		{
+			name:     "x11",
+			env:      map[string]string{"DISPLAY": ":0", "XDG_CURRENT_DESKTOP": "KDE"},
+			expected: X11,
+			backend:  BackendNone,
+		},
+		{
+			name:     "tty",
+			env:      map[string]string{},
+			expected: TTY,
+			backend:  BackendNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := Probe{
+				Getenv: func(key string) string { return tt.env[key] },
+				Dial: func(path string) error {
+					for _, socket := range tt.sockets {
+						if path == socket {
+							return nil
+						}
+					}
+					return errors.New("connection refused")
+				},
+				Processes:  func() []string { return tt.processes },
+				RuntimeDir: runtimeDir,
+			}
+
+			got := probe.Detect()
+			if got.Kind != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+			if got.Backend() != tt.backend {
+				t.Errorf("Expected backend %q, got %q", tt.backend, got.Backend())
+			}
+			if got.CanApply() != (tt.expected == Hyprland) {
+				t.Errorf("Only Hyprland sessions can apply changes, got CanApply() = %v", got.CanApply())
+			}
+			if len(got.Evidence) == 0 {
+				t.Error("Expected evidence for the classification")
+			}
+		})
+	}
+}
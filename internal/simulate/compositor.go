@@ -0,0 +1,300 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+// Compositor is an in-memory stand-in for Hyprland. It answers monitor
+// detection and runs the commands an apply issues, changing its monitors
+// the way hyprctl would.
+type Compositor struct {
+	mu       sync.Mutex
+	scenario *Scenario
+	monitors []*simMonitor
+	timeline []Event
+	fired    []int
+	applied  int
+	now      func() time.Time
+	start    time.Time
+}
+
+type simMonitor struct {
+	spec    MonitorSpec
+	mode    Mode
+	modes   []Mode
+	x, y    int
+	scale   float64
+	focused bool
+	placed  bool
+}
+
+// NewCompositor starts a simulation of scenario. Timeline events are timed
+// from now.
+func NewCompositor(scenario *Scenario) *Compositor {
+	return NewCompositorAt(scenario, time.Now)
+}
+
+// NewCompositorAt is NewCompositor with a clock, for tests.
+func NewCompositorAt(scenario *Scenario, now func() time.Time) *Compositor {
+	c := &Compositor{
+		scenario: scenario,
+		timeline: append([]Event(nil), scenario.Timeline...),
+		fired:    make([]int, len(scenario.Failures)),
+		now:      now,
+		start:    now(),
+	}
+	for _, spec := range scenario.Monitors {
+		c.monitors = append(c.monitors, newSimMonitor(spec))
+	}
+	sort.SliceStable(c.timeline, func(i, j int) bool { return c.timeline[i].After < c.timeline[j].After })
+	c.placeUnpositioned()
+	return c
+}
+
+func newSimMonitor(spec MonitorSpec) *simMonitor {
+	mode, _ := parseMode(spec.Mode)
+	m := &simMonitor{spec: spec, mode: mode, modes: []Mode{mode}, scale: spec.Scale, focused: spec.Focused}
+	for _, text := range spec.Modes {
+		if extra, err := parseMode(text); err == nil && extra != mode {
+			m.modes = append(m.modes, extra)
+		}
+	}
+	if m.scale == 0 {
+		m.scale = 1
+	}
+	if spec.Position != "" {
+		m.x, m.y, _ = parsePosition(spec.Position)
+		m.placed = true
+	}
+	return m
+}
+
+// placeUnpositioned puts monitors without a position to the right of the
+// others, as Hyprland's "auto" does.
+func (c *Compositor) placeUnpositioned() {
+	for _, m := range c.monitors {
+		if m.placed {
+			continue
+		}
+		right := 0
+		for _, other := range c.monitors {
+			if other == m || !other.placed {
+				continue
+			}
+			if edge := other.x + other.logicalWidth(); edge > right {
+				right = edge
+			}
+		}
+		m.x, m.y, m.placed = right, 0, true
+	}
+}
+
+func (m *simMonitor) logicalWidth() int {
+	return int(float64(m.mode.Width) / m.scale)
+}
+
+// Scenario returns the scenario being simulated.
+func (c *Compositor) Scenario() *Scenario {
+	return c.scenario
+}
+
+// Applied returns how many monitor changes have been accepted.
+func (c *Compositor) Applied() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.applied
+}
+
+// DetectMonitors returns the simulated monitors after applying any timeline
+// events that are due. It satisfies monitor.DetectorInterface.
+func (c *Compositor) DetectMonitors() ([]monitor.Monitor, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advance()
+
+	monitors := make([]monitor.Monitor, 0, len(c.monitors))
+	for _, m := range c.monitors {
+		monitors = append(monitors, monitor.Monitor{
+			Name:        m.spec.Name,
+			Width:       m.mode.Width,
+			Height:      m.mode.Height,
+			RefreshRate: m.mode.RefreshRate,
+			Scale:       m.scale,
+			Position:    monitor.Position{X: m.x, Y: m.y},
+			Make:        m.spec.Make,
+			Model:       m.spec.Model,
+			IsActive:    true,
+			IsPrimary:   m.focused,
+		})
+	}
+	return monitors, nil
+}
+
+// advance applies the timeline events that are due.
+func (c *Compositor) advance() {
+	elapsed := c.now().Sub(c.start)
+	for len(c.timeline) > 0 && time.Duration(c.timeline[0].After) <= elapsed {
+		event := c.timeline[0]
+		c.timeline = c.timeline[1:]
+		if event.Plug != nil {
+			c.remove(event.Plug.Name)
+			c.monitors = append(c.monitors, newSimMonitor(*event.Plug))
+			c.placeUnpositioned()
+		} else {
+			c.remove(event.Unplug)
+		}
+	}
+}
+
+func (c *Compositor) remove(name string) {
+	for i, m := range c.monitors {
+		if m.spec.Name == name {
+			c.monitors = append(c.monitors[:i], c.monitors[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run executes a command as if it had been run on a Hyprland system. Only
+// hyprctl changes anything; other commands (reloading Waybar, say) succeed
+// without effect unless a failure is injected for them. It satisfies
+// runner.Runner.
+func (c *Compositor) Run(ctx context.Context, name string, args ...string) (runner.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return runner.Result{ExitCode: -1}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advance()
+
+	line := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	if err := c.injectedFailure(line); err != nil {
+		return runner.Result{Stderr: []byte(err.Error() + "\n"), ExitCode: 1}, err
+	}
+
+	if name != "hyprctl" {
+		return runner.Result{}, nil
+	}
+
+	calls := []string{strings.Join(args, " ")}
+	if len(args) == 2 && args[0] == "--batch" {
+		calls = strings.Split(args[1], ";")
+	}
+
+	var output strings.Builder
+	for _, call := range calls {
+		if err := c.hyprctl(strings.Fields(call)); err != nil {
+			output.WriteString(err.Error() + "\n")
+			return runner.Result{Stdout: []byte(output.String()), ExitCode: 1}, err
+		}
+		output.WriteString("ok\n")
+	}
+	return runner.Result{Stdout: []byte(output.String())}, nil
+}
+
+func (c *Compositor) injectedFailure(line string) error {
+	for i, failure := range c.scenario.Failures {
+		if !strings.Contains(line, failure.Command) {
+			continue
+		}
+		if failure.Times > 0 && c.fired[i] >= failure.Times {
+			continue
+		}
+		c.fired[i]++
+		message := failure.Error
+		if message == "" {
+			message = "simulated failure"
+		}
+		return errors.New(message)
+	}
+	return nil
+}
+
+func (c *Compositor) hyprctl(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no hyprctl command")
+	}
+
+	switch {
+	case len(args) == 3 && args[0] == "keyword" && args[1] == "monitor":
+		return c.setMonitor(args[2])
+	case args[0] == "setcursor", args[0] == "reload", args[0] == "keyword":
+		return nil
+	}
+	return fmt.Errorf("unsupported hyprctl command %q", strings.Join(args, " "))
+}
+
+// setMonitor handles a monitor rule: NAME,MODE,POSITION,SCALE. Modes the
+// monitor doesn't offer are ignored, keeping the current one, as Hyprland
+// does.
+func (c *Compositor) setMonitor(rule string) error {
+	fields := strings.Split(rule, ",")
+	if len(fields) < 4 {
+		return fmt.Errorf("invalid monitor rule %q", rule)
+	}
+
+	var target *simMonitor
+	for _, m := range c.monitors {
+		if m.spec.Name == fields[0] {
+			target = m
+		}
+	}
+	if target == nil {
+		// Hyprland accepts rules for absent monitors and keeps them for when
+		// they are connected.
+		return nil
+	}
+
+	scale, err := strconv.ParseFloat(strings.TrimSpace(fields[3]), 64)
+	if err != nil || scale < types.MinMonitorScale || scale > types.MaxMonitorScale {
+		return fmt.Errorf("invalid scale in monitor rule %q", rule)
+	}
+
+	mode := target.mode
+	switch fields[1] {
+	case "preferred", "highres", "highrr":
+		mode = target.modes[0]
+	default:
+		if requested, err := parseMode(fields[1]); err == nil {
+			for _, available := range target.modes {
+				if available.Width == requested.Width && available.Height == requested.Height &&
+					roughlyEqual(available.RefreshRate, requested.RefreshRate) {
+					mode = available
+				}
+			}
+		}
+	}
+
+	if c.scenario.AdjustScales {
+		scale = utils.NearestValidScale(scale, mode.Width, mode.Height, types.ValidHyprlandScales)
+	}
+
+	target.mode = mode
+	target.scale = scale
+	if fields[2] != "auto" {
+		if x, y, err := parsePosition(fields[2]); err == nil {
+			target.x, target.y = x, y
+		}
+	}
+	c.applied++
+	return nil
+}
+
+func roughlyEqual(a, b float64) bool {
+	return a-b < 0.01 && b-a < 0.01
+}
@@ -0,0 +1,75 @@
+package simulate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sandbox is a simulated system: the compositor plus a throwaway home
+// directory whose config files an apply may rewrite. The user's real files
+// are never touched.
+type Sandbox struct {
+	Scenario   *Scenario
+	Compositor *Compositor
+	Dir        string
+}
+
+// NewSandbox creates the sandbox directories under a new temp dir and seeds
+// them with the scenario's files.
+func NewSandbox(scenario *Scenario) (*Sandbox, error) {
+	dir, err := os.MkdirTemp("", "omarchy-monitor-settings-simulate-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+
+	sandbox := &Sandbox{Scenario: scenario, Compositor: NewCompositor(scenario), Dir: dir}
+	for _, sub := range []string{sandbox.ConfigHome(), sandbox.StateDir(), sandbox.HomeDir()} {
+		if err := os.MkdirAll(sub, 0750); err != nil {
+			sandbox.Close()
+			return nil, fmt.Errorf("failed to create sandbox: %w", err)
+		}
+	}
+
+	for rel, content := range scenario.Files {
+		path := filepath.Join(sandbox.ConfigHome(), filepath.FromSlash(rel))
+		if !strings.HasPrefix(path, sandbox.ConfigHome()+string(os.PathSeparator)) {
+			sandbox.Close()
+			return nil, fmt.Errorf("scenario file %s is outside the config directory", rel)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			sandbox.Close()
+			return nil, fmt.Errorf("failed to seed %s: %w", rel, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			sandbox.Close()
+			return nil, fmt.Errorf("failed to seed %s: %w", rel, err)
+		}
+	}
+
+	return sandbox, nil
+}
+
+// ConfigHome stands in for $XDG_CONFIG_HOME.
+func (s *Sandbox) ConfigHome() string {
+	return filepath.Join(s.Dir, "config")
+}
+
+// StateDir stands in for the application's state directory.
+func (s *Sandbox) StateDir() string {
+	return filepath.Join(s.Dir, "state")
+}
+
+// HomeDir stands in for $HOME.
+func (s *Sandbox) HomeDir() string {
+	return filepath.Join(s.Dir, "home")
+}
+
+// Close removes the sandbox and everything written to it.
+func (s *Sandbox) Close() error {
+	if s == nil {
+		return nil
+	}
+	return os.RemoveAll(s.Dir)
+}
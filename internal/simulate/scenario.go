@@ -0,0 +1,224 @@
+// Package simulate provides a scripted compositor for exercising the TUI and
+// CLI without Hyprland. Scenarios describe the monitors, the modes they
+// support, commands that should fail and monitors that come and go over
+// time; applies change the simulated state just as they would on a real
+// compositor.
+package simulate
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed scenarios/*.yaml
+var builtin embed.FS
+
+// Scenario is a simulated system, as loaded from a YAML file.
+type Scenario struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description"`
+	Monitors    []MonitorSpec `yaml:"monitors"`
+	// Files seeds the simulated config directory, keyed by path relative to
+	// it (for example kitty/kitty.conf).
+	Files    map[string]string `yaml:"files"`
+	Failures []Failure         `yaml:"failures"`
+	Timeline []Event           `yaml:"timeline"`
+	// AdjustScales makes the compositor replace scales that don't divide the
+	// resolution evenly with the nearest one that does, as Hyprland does.
+	AdjustScales bool `yaml:"adjust_scales"`
+}
+
+// MonitorSpec is one simulated monitor. Mode and modes are written as
+// WIDTHxHEIGHT@REFRESH and position as XxY.
+type MonitorSpec struct {
+	Name     string   `yaml:"name"`
+	Make     string   `yaml:"make"`
+	Model    string   `yaml:"model"`
+	Mode     string   `yaml:"mode"`
+	Modes    []string `yaml:"modes"`
+	Position string   `yaml:"position"`
+	Scale    float64  `yaml:"scale"`
+	Focused  bool     `yaml:"focused"`
+}
+
+// Failure makes commands containing Command fail with Error. Times limits
+// how often it fires; zero means every time.
+type Failure struct {
+	Command string `yaml:"command"`
+	Error   string `yaml:"error"`
+	Times   int    `yaml:"times"`
+}
+
+// Event plugs or unplugs a monitor once After has passed since the
+// simulation started.
+type Event struct {
+	After  Duration     `yaml:"after"`
+	Plug   *MonitorSpec `yaml:"plug"`
+	Unplug string       `yaml:"unplug"`
+}
+
+// Duration is a time.Duration written the Go way in YAML ("30s", "2m").
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", node.Value, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Builtin lists the names of the scenarios shipped with the application.
+func Builtin() []string {
+	entries, err := fs.ReadDir(builtin, "scenarios")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load reads a scenario from a file, or one of the built-in scenarios by
+// name.
+func Load(nameOrPath string) (*Scenario, error) {
+	data, err := os.ReadFile(nameOrPath)
+	if errors.Is(err, fs.ErrNotExist) && !strings.ContainsRune(nameOrPath, os.PathSeparator) {
+		data, err = builtin.ReadFile(path.Join("scenarios", strings.TrimSuffix(nameOrPath, ".yaml")+".yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("scenario %q not found; built-in scenarios: %s", nameOrPath, strings.Join(Builtin(), ", "))
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse reads and validates a scenario.
+func Parse(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %q: %w", scenario.Name, err)
+	}
+	return &scenario, nil
+}
+
+func (s *Scenario) validate() error {
+	if len(s.Monitors) == 0 {
+		return errors.New("no monitors")
+	}
+
+	names := make(map[string]bool)
+	for _, spec := range s.Monitors {
+		if err := spec.validate(); err != nil {
+			return err
+		}
+		if names[spec.Name] {
+			return fmt.Errorf("monitor %s appears more than once", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+
+	for i, event := range s.Timeline {
+		switch {
+		case event.Plug != nil && event.Unplug != "":
+			return fmt.Errorf("timeline event %d both plugs and unplugs", i+1)
+		case event.Plug != nil:
+			if err := event.Plug.validate(); err != nil {
+				return fmt.Errorf("timeline event %d: %w", i+1, err)
+			}
+		case event.Unplug == "":
+			return fmt.Errorf("timeline event %d neither plugs nor unplugs", i+1)
+		}
+	}
+
+	for i, failure := range s.Failures {
+		if failure.Command == "" {
+			return fmt.Errorf("failure %d has no command to match", i+1)
+		}
+	}
+	return nil
+}
+
+func (m MonitorSpec) validate() error {
+	if m.Name == "" {
+		return errors.New("monitor without a name")
+	}
+	if _, err := parseMode(m.Mode); err != nil {
+		return fmt.Errorf("monitor %s: %w", m.Name, err)
+	}
+	for _, mode := range m.Modes {
+		if _, err := parseMode(mode); err != nil {
+			return fmt.Errorf("monitor %s: %w", m.Name, err)
+		}
+	}
+	if m.Position != "" {
+		if _, _, err := parsePosition(m.Position); err != nil {
+			return fmt.Errorf("monitor %s: %w", m.Name, err)
+		}
+	}
+	if m.Scale < 0 {
+		return fmt.Errorf("monitor %s: negative scale", m.Name)
+	}
+	return nil
+}
+
+// Mode is a resolution and refresh rate.
+type Mode struct {
+	Width       int
+	Height      int
+	RefreshRate float64
+}
+
+func (m Mode) String() string {
+	return fmt.Sprintf("%dx%d@%g", m.Width, m.Height, m.RefreshRate)
+}
+
+var modePattern = regexp.MustCompile(`^(\d+)x(\d+)(?:@([\d.]+)(?:Hz)?)?$`)
+
+func parseMode(mode string) (Mode, error) {
+	match := modePattern.FindStringSubmatch(strings.TrimSpace(mode))
+	if match == nil {
+		return Mode{}, fmt.Errorf("invalid mode %q, expected WIDTHxHEIGHT@REFRESH", mode)
+	}
+	width, _ := strconv.Atoi(match[1])
+	height, _ := strconv.Atoi(match[2])
+	refresh := 60.0
+	if match[3] != "" {
+		refresh, _ = strconv.ParseFloat(match[3], 64)
+	}
+	return Mode{Width: width, Height: height, RefreshRate: refresh}, nil
+}
+
+var positionPattern = regexp.MustCompile(`^(-?\d+)x(-?\d+)$`)
+
+func parsePosition(position string) (int, int, error) {
+	match := positionPattern.FindStringSubmatch(strings.TrimSpace(position))
+	if match == nil {
+		return 0, 0, fmt.Errorf("invalid position %q, expected XxY", position)
+	}
+	x, _ := strconv.Atoi(match[1])
+	y, _ := strconv.Atoi(match[2])
+	return x, y, nil
+}
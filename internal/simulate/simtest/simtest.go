@@ -0,0 +1,50 @@
+// Package simtest sets up simulated compositors and socket directories for
+// tests in other packages.
+package simtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate"
+)
+
+// Sandbox loads a built-in scenario, or one from a file, into a sandbox that
+// is removed when the test ends.
+func Sandbox(t testing.TB, scenario string) *simulate.Sandbox {
+	t.Helper()
+	loaded, err := simulate.Load(scenario)
+	if err != nil {
+		t.Fatalf("Failed to load scenario: %v", err)
+	}
+	sandbox, err := simulate.NewSandbox(loaded)
+	if err != nil {
+		t.Fatalf("Failed to create sandbox: %v", err)
+	}
+	t.Cleanup(func() { sandbox.Close() })
+	return sandbox
+}
+
+// Services runs against a sandbox for scenario. Unlike test mode, applying
+// really changes the simulated monitors and files.
+func Services(t testing.TB, scenario string) (*app.Services, *simulate.Sandbox) {
+	t.Helper()
+	sandbox := Sandbox(t, scenario)
+	services := app.NewSimulatedServices(&app.Config{}, sandbox)
+	t.Cleanup(func() { services.Close() })
+	return services, sandbox
+}
+
+// SocketDir is a new directory for Unix sockets, removed when the test ends.
+// Socket paths are limited to about 100 bytes, which t.TempDir can exceed,
+// so it is made directly under the temp directory.
+func SocketDir(t testing.TB, prefix string) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", prefix)
+	if err != nil {
+		t.Fatalf("Failed to create a socket dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
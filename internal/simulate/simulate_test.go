@@ -0,0 +1,206 @@
+package simulate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+)
+
+func TestBuiltinScenariosLoad(t *testing.T) {
+	names := Builtin()
+	for _, expected := range []string{"6k-laptop", "apply-fails", "portrait", "tv"} {
+		found := false
+		for _, name := range names {
+			found = found || name == expected
+		}
+		if !found {
+			t.Errorf("Expected built-in scenario %s, got %v", expected, names)
+		}
+	}
+
+	for _, name := range names {
+		scenario, err := Load(name)
+		if err != nil {
+			t.Errorf("Failed to load %s: %v", name, err)
+			continue
+		}
+		if scenario.Name != name {
+			t.Errorf("Expected scenario %s to be named after its file, got %q", name, scenario.Name)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "desk.yaml")
+	if err := os.WriteFile(path, []byte("name: desk\nmonitors:\n  - name: DP-3\n    mode: 2560x1440@144\n"), 0600); err != nil {
+		t.Fatalf("Failed to write scenario: %v", err)
+	}
+
+	scenario, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	monitors, _ := NewCompositor(scenario).DetectMonitors()
+	if len(monitors) != 1 || monitors[0].RefreshRate != 144 || monitors[0].Scale != 1 {
+		t.Errorf("Unexpected monitors: %+v", monitors)
+	}
+
+	if _, err := Load("no-such-scenario"); err == nil || !strings.Contains(err.Error(), "6k-laptop") {
+		t.Errorf("Expected a missing scenario to list the built-in ones, got %v", err)
+	}
+}
+
+func TestParseRejectsInvalidScenarios(t *testing.T) {
+	for name, content := range map[string]string{
+		"no monitors":   "name: empty\n",
+		"bad mode":      "monitors:\n  - name: DP-1\n    mode: huge\n",
+		"duplicate":     "monitors:\n  - {name: DP-1, mode: 1920x1080}\n  - {name: DP-1, mode: 1920x1080}\n",
+		"unknown field": "monitors:\n  - {name: DP-1, mode: 1920x1080, rotation: 90}\n",
+		"empty event":   "monitors:\n  - {name: DP-1, mode: 1920x1080}\ntimeline:\n  - after: 1s\n",
+		"bad duration":  "monitors:\n  - {name: DP-1, mode: 1920x1080}\ntimeline:\n  - {after: soon, unplug: DP-1}\n",
+	} {
+		if _, err := Parse([]byte(content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+var ctx = context.Background()
+
+func loadBuiltin(t *testing.T, name string) *Scenario {
+	t.Helper()
+	scenario, err := Load(name)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", name, err)
+	}
+	return scenario
+}
+
+func findMonitor(t *testing.T, c *Compositor, name string) monitor.Monitor {
+	t.Helper()
+	monitors, err := c.DetectMonitors()
+	if err != nil {
+		t.Fatalf("DetectMonitors failed: %v", err)
+	}
+	for _, m := range monitors {
+		if m.Name == name {
+			return m
+		}
+	}
+	t.Fatalf("No monitor %s in %+v", name, monitors)
+	return monitor.Monitor{}
+}
+
+func TestApplyChangesState(t *testing.T) {
+	c := NewCompositor(loadBuiltin(t, "portrait"))
+
+	if _, err := c.Run(ctx, "hyprctl", "--batch", "keyword monitor DP-1,2560x1440@60,0x0,1.00000 ; keyword monitor DP-2,1440x2560@60,2560x0,1.25000"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	dp1 := findMonitor(t, c, "DP-1")
+	if dp1.Width != 2560 || dp1.Height != 1440 || dp1.Scale != 1 {
+		t.Errorf("Expected DP-1 at 2560x1440 x1, got %+v", dp1)
+	}
+	if dp2 := findMonitor(t, c, "DP-2"); dp2.Scale != 1.25 || dp2.Height != 2560 {
+		t.Errorf("Expected portrait DP-2 at x1.25, got %+v", dp2)
+	}
+
+	// A mode the monitor doesn't offer is ignored
+	if _, err := c.Run(ctx, "hyprctl", "keyword", "monitor", "DP-1,1280x720@60,0x0,1.00000"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if dp1 := findMonitor(t, c, "DP-1"); dp1.Width != 2560 {
+		t.Errorf("Expected an unavailable mode to keep 2560x1440, got %dx%d", dp1.Width, dp1.Height)
+	}
+	if c.Applied() != 3 {
+		t.Errorf("Expected 3 applied monitor rules, got %d", c.Applied())
+	}
+}
+
+func TestAdjustScales(t *testing.T) {
+	c := NewCompositor(loadBuiltin(t, "6k-laptop"))
+
+	if _, err := c.Run(ctx, "hyprctl", "keyword", "monitor", "eDP-1,preferred,auto,1.30000"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if edp := findMonitor(t, c, "eDP-1"); edp.Scale == 1.3 {
+		t.Errorf("Expected the compositor to adjust a scale that doesn't divide 2880x1920, got %v", edp.Scale)
+	}
+}
+
+func TestInjectedFailure(t *testing.T) {
+	c := NewCompositor(loadBuiltin(t, "apply-fails"))
+	before := findMonitor(t, c, "eDP-1")
+
+	result, err := c.Run(ctx, "hyprctl", "keyword", "monitor", "eDP-1,preferred,auto,2.00000")
+	if err == nil || result.ExitCode != 1 || !strings.Contains(string(result.Stderr), "simulated") {
+		t.Fatalf("Expected the first apply to fail, got %v (%+v)", err, result)
+	}
+	if after := findMonitor(t, c, "eDP-1"); after.Scale != before.Scale {
+		t.Errorf("A failed apply must not change state, got scale %v", after.Scale)
+	}
+
+	if _, err := c.Run(ctx, "hyprctl", "keyword", "monitor", "eDP-1,preferred,auto,2.00000"); err != nil {
+		t.Fatalf("Expected the failure to fire once, got %v", err)
+	}
+	if after := findMonitor(t, c, "eDP-1"); after.Scale != 2 {
+		t.Errorf("Expected scale 2 after the retry, got %v", after.Scale)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	now := start
+	c := NewCompositorAt(loadBuiltin(t, "tv"), func() time.Time { return now })
+
+	count := func() int {
+		monitors, _ := c.DetectMonitors()
+		return len(monitors)
+	}
+
+	if count() != 1 {
+		t.Fatalf("Expected only the laptop at first, got %d monitors", count())
+	}
+
+	now = start.Add(31 * time.Second)
+	tv := findMonitor(t, c, "HDMI-A-1")
+	if tv.Width != 3840 || tv.Position.X != 1920 {
+		t.Errorf("Expected the TV at 3840 wide right of the laptop, got %+v", tv)
+	}
+
+	now = start.Add(11 * time.Minute)
+	if count() != 1 {
+		t.Errorf("Expected the TV to be unplugged again, got %d monitors", count())
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	sandbox, err := NewSandbox(loadBuiltin(t, "6k-laptop"))
+	if err != nil {
+		t.Fatalf("NewSandbox failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(sandbox.ConfigHome(), "kitty", "kitty.conf"))
+	if err != nil || !strings.Contains(string(data), "font_size 11.0") {
+		t.Errorf("Expected kitty.conf to be seeded, got %q (%v)", data, err)
+	}
+
+	if err := sandbox.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(sandbox.Dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the sandbox to be removed, got %v", err)
+	}
+
+	escape := &Scenario{Name: "escape", Monitors: []MonitorSpec{{Name: "DP-1", Mode: "1920x1080"}},
+		Files: map[string]string{"../outside": "x"}}
+	if _, err := NewSandbox(escape); err == nil {
+		t.Error("Expected files outside the config directory to be rejected")
+	}
+}
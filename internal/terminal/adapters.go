@@ -0,0 +1,301 @@
+package terminal
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	alacrittySizePattern   = regexp.MustCompile(`^(\s*size\s*=\s*)([0-9]+(?:\.[0-9]+)?)(.*)$`)
+	alacrittyDottedPattern = regexp.MustCompile(`^(\s*font\.size\s*=\s*)([0-9]+(?:\.[0-9]+)?)(.*)$`)
+	kittySizePattern       = regexp.MustCompile(`^(\s*font_size\s+)([0-9]+(?:\.[0-9]+)?)(.*)$`)
+	footFontPattern        = regexp.MustCompile(`^(\s*font(?:-bold|-italic|-bold-italic)?\s*=\s*)(.*)$`)
+	footSizePattern        = regexp.MustCompile(`:size=([0-9]+(?:\.[0-9]+)?)`)
+	ghosttySizePattern     = regexp.MustCompile(`^(\s*font-size\s*=\s*)([0-9]+(?:\.[0-9]+)?)(.*)$`)
+	sectionHeaderPattern   = regexp.MustCompile(`^\s*\[\s*([^\]]+?)\s*\]`)
+	commentPrefixes        = []string{"#", ";"}
+)
+
+// Alacritty edits font.size in alacritty.toml.
+type Alacritty struct {
+	configHome string
+}
+
+func (a *Alacritty) Name() string { return "Alacritty" }
+
+func (a *Alacritty) ConfigPath() string {
+	return filepath.Join(a.configHome, "alacritty", "alacritty.toml")
+}
+
+func (a *Alacritty) DefaultFontSize() float64 { return 11.25 }
+
+func (a *Alacritty) FontSize(content string) (float64, bool) {
+	var size float64
+	found := false
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		if name, ok := sectionName(line); ok {
+			section = name
+			continue
+		}
+		if section == "font" {
+			if match := alacrittySizePattern.FindStringSubmatch(line); match != nil {
+				size, found = parseSize(match[2])
+			}
+		}
+		if section == "" {
+			if match := alacrittyDottedPattern.FindStringSubmatch(line); match != nil {
+				size, found = parseSize(match[2])
+			}
+		}
+	}
+	return size, found
+}
+
+func (a *Alacritty) SetFontSize(content string, size float64) string {
+	lines := strings.Split(content, "\n")
+	value := formatSize(size, true)
+	section := ""
+	fontHeader := -1
+	replaced := false
+
+	for i, line := range lines {
+		if name, ok := sectionName(line); ok {
+			section = name
+			if name == "font" {
+				fontHeader = i
+			}
+			continue
+		}
+		if section == "font" && alacrittySizePattern.MatchString(line) {
+			lines[i] = alacrittySizePattern.ReplaceAllString(line, "${1}"+value+"${3}")
+			replaced = true
+		}
+		if section == "" && alacrittyDottedPattern.MatchString(line) {
+			lines[i] = alacrittyDottedPattern.ReplaceAllString(line, "${1}"+value+"${3}")
+			replaced = true
+		}
+	}
+
+	if replaced {
+		return strings.Join(lines, "\n")
+	}
+	if fontHeader >= 0 {
+		return strings.Join(insertLine(lines, fontHeader+1, "size = "+value), "\n")
+	}
+	return appendBlock(content, "[font]", "size = "+value)
+}
+
+// Kitty edits font_size in kitty.conf.
+type Kitty struct {
+	configHome string
+}
+
+func (k *Kitty) Name() string { return "Kitty" }
+
+func (k *Kitty) ConfigPath() string {
+	return filepath.Join(k.configHome, "kitty", "kitty.conf")
+}
+
+func (k *Kitty) DefaultFontSize() float64 { return 11.0 }
+
+func (k *Kitty) FontSize(content string) (float64, bool) {
+	var size float64
+	found := false
+	// kitty lets the last occurrence win, so keep scanning.
+	for _, line := range strings.Split(content, "\n") {
+		if match := kittySizePattern.FindStringSubmatch(line); match != nil {
+			size, found = parseSize(match[2])
+		}
+	}
+	return size, found
+}
+
+func (k *Kitty) SetFontSize(content string, size float64) string {
+	lines := strings.Split(content, "\n")
+	value := formatSize(size, false)
+	replaced := false
+
+	for i, line := range lines {
+		if kittySizePattern.MatchString(line) {
+			lines[i] = kittySizePattern.ReplaceAllString(line, "${1}"+value+"${3}")
+			replaced = true
+		}
+	}
+
+	if replaced {
+		return strings.Join(lines, "\n")
+	}
+	return appendBlock(content, "font_size "+value)
+}
+
+// Foot edits the :size= attribute of the font entries in foot.ini.
+type Foot struct {
+	configHome string
+}
+
+func (f *Foot) Name() string { return "Foot" }
+
+func (f *Foot) ConfigPath() string {
+	return filepath.Join(f.configHome, "foot", "foot.ini")
+}
+
+func (f *Foot) DefaultFontSize() float64 { return 8.0 }
+
+func (f *Foot) FontSize(content string) (float64, bool) {
+	section := "main"
+	for _, line := range strings.Split(content, "\n") {
+		if name, ok := sectionName(line); ok {
+			section = name
+			continue
+		}
+		if section != "main" || isComment(line) {
+			continue
+		}
+		if match := footFontPattern.FindStringSubmatch(line); match != nil {
+			if size := footSizePattern.FindStringSubmatch(match[2]); size != nil {
+				return parseSize(size[1])
+			}
+		}
+	}
+	return 0, false
+}
+
+func (f *Foot) SetFontSize(content string, size float64) string {
+	lines := strings.Split(content, "\n")
+	value := formatSize(size, false)
+	section := "main"
+	mainHeader := -1
+	replaced := false
+
+	for i, line := range lines {
+		if name, ok := sectionName(line); ok {
+			section = name
+			if name == "main" {
+				mainHeader = i
+			}
+			continue
+		}
+		if section != "main" || isComment(line) {
+			continue
+		}
+		match := footFontPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		fonts := strings.Split(match[2], ",")
+		for j, font := range fonts {
+			if footSizePattern.MatchString(font) {
+				fonts[j] = footSizePattern.ReplaceAllString(font, ":size="+value)
+			} else {
+				trimmed := strings.TrimRight(font, " \t")
+				fonts[j] = trimmed + ":size=" + value + font[len(trimmed):]
+			}
+		}
+		lines[i] = match[1] + strings.Join(fonts, ",")
+		replaced = true
+	}
+
+	if replaced {
+		return strings.Join(lines, "\n")
+	}
+
+	entry := "font=monospace:size=" + value
+	if mainHeader >= 0 {
+		return strings.Join(insertLine(lines, mainHeader+1, entry), "\n")
+	}
+	// Keys before the first section header belong to [main].
+	return strings.Join(insertLine(lines, 0, entry), "\n")
+}
+
+// Ghostty edits font-size in ghostty's config.
+type Ghostty struct {
+	configHome string
+}
+
+func (g *Ghostty) Name() string { return "Ghostty" }
+
+func (g *Ghostty) ConfigPath() string {
+	return filepath.Join(g.configHome, "ghostty", "config")
+}
+
+func (g *Ghostty) DefaultFontSize() float64 { return 13.0 }
+
+func (g *Ghostty) FontSize(content string) (float64, bool) {
+	var size float64
+	found := false
+	for _, line := range strings.Split(content, "\n") {
+		if match := ghosttySizePattern.FindStringSubmatch(line); match != nil {
+			size, found = parseSize(match[2])
+		}
+	}
+	return size, found
+}
+
+func (g *Ghostty) SetFontSize(content string, size float64) string {
+	lines := strings.Split(content, "\n")
+	value := formatSize(size, false)
+	replaced := false
+
+	for i, line := range lines {
+		if ghosttySizePattern.MatchString(line) {
+			lines[i] = ghosttySizePattern.ReplaceAllString(line, "${1}"+value+"${3}")
+			replaced = true
+		}
+	}
+
+	if replaced {
+		return strings.Join(lines, "\n")
+	}
+	return appendBlock(content, "font-size = "+value)
+}
+
+func sectionName(line string) (string, bool) {
+	if isComment(line) {
+		return "", false
+	}
+	match := sectionHeaderPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+	return strings.Trim(match[1], `"'`), true
+}
+
+func isComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range commentPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSize(s string) (float64, bool) {
+	size, err := strconv.ParseFloat(s, 64)
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+func insertLine(lines []string, index int, line string) []string {
+	lines = append(lines, "")
+	copy(lines[index+1:], lines[index:])
+	lines[index] = line
+	return lines
+}
+
+func appendBlock(content string, lines ...string) string {
+	block := strings.Join(lines, "\n") + "\n"
+	if content == "" {
+		return block
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + "\n" + block
+}
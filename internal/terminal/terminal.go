@@ -0,0 +1,270 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+// Adapter reads and rewrites the font size in one terminal emulator's config
+// file. Implementations edit the file line by line so that comments and
+// formatting survive untouched.
+type Adapter interface {
+	Name() string
+	ConfigPath() string
+	DefaultFontSize() float64
+	FontSize(content string) (float64, bool)
+	SetFontSize(content string, size float64) string
+}
+
+// Change describes a pending font size update for a single terminal.
+type Change struct {
+	Terminal string
+	Path     string
+	BaseSize float64
+	FromSize float64
+	ToSize   float64
+	Before   string
+	After    string
+}
+
+type baseline struct {
+	Base    float64 `json:"base"`
+	Applied float64 `json:"applied"`
+}
+
+type Manager struct {
+	adapters     []Adapter
+	baselinePath string
+}
+
+func NewManager(configHome, stateDir string) *Manager {
+	return &Manager{
+		adapters: []Adapter{
+			&Alacritty{configHome: configHome},
+			&Kitty{configHome: configHome},
+			&Foot{configHome: configHome},
+			&Ghostty{configHome: configHome},
+		},
+		baselinePath: filepath.Join(stateDir, "font-baselines.json"),
+	}
+}
+
+func NewDefaultManager() *Manager {
+	return NewManager(utils.ConfigHome(), utils.StateDir())
+}
+
+func (m *Manager) Adapters() []Adapter {
+	return m.adapters
+}
+
+// Paths lists every file ApplyFontScale may write, including the baseline
+// state file.
+func (m *Manager) Paths() []string {
+	paths := make([]string, 0, len(m.adapters)+1)
+	for _, adapter := range m.adapters {
+		paths = append(paths, adapter.ConfigPath())
+	}
+	return append(paths, m.baselinePath)
+}
+
+// Detected returns the adapters whose config file exists on disk.
+func (m *Manager) Detected() []Adapter {
+	var detected []Adapter
+	for _, adapter := range m.adapters {
+		if utils.FileExists(adapter.ConfigPath()) {
+			detected = append(detected, adapter)
+		}
+	}
+	return detected
+}
+
+// Changes computes the font size updates for every detected terminal without
+// writing anything. Sizes are always derived from the remembered baseline so
+// that applying the same scale twice is a no-op.
+func (m *Manager) Changes(scale float64) ([]Change, error) {
+	if scale <= 0 {
+		return nil, fmt.Errorf("invalid font scale: %.2f", scale)
+	}
+
+	baselines, err := m.loadBaselines()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, adapter := range m.Detected() {
+		data, err := os.ReadFile(adapter.ConfigPath())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s config: %w", adapter.Name(), err)
+		}
+		content := string(data)
+
+		current, ok := adapter.FontSize(content)
+		if !ok {
+			current = adapter.DefaultFontSize()
+		}
+
+		base := current
+		if b, exists := baselines[adapter.Name()]; exists && sameSize(b.Applied, current) {
+			base = b.Base
+		}
+
+		target := roundSize(base * scale)
+		after := content
+		if !sameSize(target, current) {
+			after = adapter.SetFontSize(content, target)
+		}
+
+		changes = append(changes, Change{
+			Terminal: adapter.Name(),
+			Path:     adapter.ConfigPath(),
+			BaseSize: base,
+			FromSize: current,
+			ToSize:   target,
+			Before:   content,
+			After:    after,
+		})
+	}
+
+	return changes, nil
+}
+
+// CurrentScale is the font scale the terminals are at relative to their
+// baselines, as the first detected terminal has it. A terminal without a
+// baseline, or edited since one was recorded, is at 1.
+func (m *Manager) CurrentScale() (float64, error) {
+	detected := m.Detected()
+	if len(detected) == 0 {
+		return 1, nil
+	}
+	adapter := detected[0]
+
+	baselines, err := m.loadBaselines()
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(adapter.ConfigPath())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s config: %w", adapter.Name(), err)
+	}
+	current, ok := adapter.FontSize(string(data))
+	if !ok {
+		current = adapter.DefaultFontSize()
+	}
+
+	b, exists := baselines[adapter.Name()]
+	if !exists || !sameSize(b.Applied, current) || b.Base <= 0 {
+		return 1, nil
+	}
+	return current / b.Base, nil
+}
+
+// Commit writes the given changes to disk and records their baselines.
+func (m *Manager) Commit(changes []Change) error {
+	before, after, err := m.BaselineUpdate(changes)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.After != change.Before {
+			if err := utils.WriteFileAtomic(change.Path, []byte(change.After), 0644); err != nil {
+				return fmt.Errorf("failed to update %s config: %w", change.Terminal, err)
+			}
+		}
+	}
+
+	if after == before {
+		return nil
+	}
+	return utils.WriteFileAtomic(m.baselinePath, []byte(after), 0600)
+}
+
+func (m *Manager) BaselinePath() string {
+	return m.baselinePath
+}
+
+// BaselineUpdate returns the current and updated content of the baseline
+// state file once changes have been applied.
+func (m *Manager) BaselineUpdate(changes []Change) (string, string, error) {
+	before, err := readOptional(m.baselinePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read font baselines: %w", err)
+	}
+
+	baselines, err := m.loadBaselines()
+	if err != nil {
+		return "", "", err
+	}
+	for _, change := range changes {
+		baselines[change.Terminal] = baseline{Base: change.BaseSize, Applied: change.ToSize}
+	}
+
+	data, err := json.MarshalIndent(baselines, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode font baselines: %w", err)
+	}
+	return before, string(data) + "\n", nil
+}
+
+// ApplyFontScale scales every detected terminal's font size relative to its
+// baseline and writes the result.
+func (m *Manager) ApplyFontScale(scale float64) ([]Change, error) {
+	changes, err := m.Changes(scale)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Commit(changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (m *Manager) loadBaselines() (map[string]baseline, error) {
+	baselines := make(map[string]baseline)
+
+	data, err := os.ReadFile(m.baselinePath)
+	if os.IsNotExist(err) {
+		return baselines, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font baselines: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return nil, fmt.Errorf("failed to parse font baselines: %w", err)
+	}
+	return baselines, nil
+}
+
+func readOptional(path string) (string, error) {
+	data, err := os.ReadFile(path) // nosec G304
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func roundSize(size float64) float64 {
+	return math.Round(size*10) / 10
+}
+
+func sameSize(a, b float64) bool {
+	return math.Abs(a-b) < 0.05
+}
+
+func formatSize(size float64, alwaysDecimal bool) string {
+	if alwaysDecimal {
+		return strconv.FormatFloat(size, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(size, 'f', -1, 64)
+}
@@ -0,0 +1,266 @@
+package terminal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func readConfig(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	return string(data)
+}
+
+func TestAdapterFontSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		adapter  Adapter
+		content  string
+		expected float64
+		found    bool
+	}{
+		{
+			name:     "alacritty font section",
+			adapter:  &Alacritty{},
+			content:  "[window]\nopacity = 0.9\n\n[font]\nnormal = { family = \"JetBrainsMono\" }\nsize = 9.5 # small\n",
+			expected: 9.5,
+			found:    true,
+		},
+		{
+			name:     "alacritty dotted key",
+			adapter:  &Alacritty{},
+			content:  "font.size = 12\n",
+			expected: 12,
+			found:    true,
+		},
+		{
+			name:     "alacritty ignores other sections",
+			adapter:  &Alacritty{},
+			content:  "[window.padding]\nsize = 4\n",
+			expected: 0,
+			found:    false,
+		},
+		{
+			name:     "kitty last occurrence wins",
+			adapter:  &Kitty{},
+			content:  "# font_size 20\nfont_size 10\nfont_size 11.5\n",
+			expected: 11.5,
+			found:    true,
+		},
+		{
+			name:     "foot main section",
+			adapter:  &Foot{},
+			content:  "[main]\nfont=JetBrainsMono Nerd Font:size=10,Noto Color Emoji:size=10\n[colors]\n",
+			expected: 10,
+			found:    true,
+		},
+		{
+			name:     "foot without size",
+			adapter:  &Foot{},
+			content:  "[main]\nfont=monospace\n",
+			expected: 0,
+			found:    false,
+		},
+		{
+			name:     "ghostty",
+			adapter:  &Ghostty{},
+			content:  "theme = tokyonight\nfont-size = 14\n",
+			expected: 14,
+			found:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, found := tt.adapter.FontSize(tt.content)
+			if found != tt.found {
+				t.Fatalf("Expected found=%v, got %v", tt.found, found)
+			}
+			if size != tt.expected {
+				t.Errorf("Expected size %.2f, got %.2f", tt.expected, size)
+			}
+		})
+	}
+}
+
+func TestAdapterSetFontSizePreservesFormatting(t *testing.T) {
+	tests := []struct {
+		name     string
+		adapter  Adapter
+		content  string
+		size     float64
+		expected string
+	}{
+		{
+			name:     "alacritty keeps trailing comment",
+			adapter:  &Alacritty{},
+			content:  "# My config\n[font]\nsize   =  9.5 # small\n\n[window]\nopacity = 0.9\n",
+			size:     14.25,
+			expected: "# My config\n[font]\nsize   =  14.2 # small\n\n[window]\nopacity = 0.9\n",
+		},
+		{
+			name:     "alacritty adds size to existing font section",
+			adapter:  &Alacritty{},
+			content:  "[font]\nnormal = { family = \"Mono\" }\n",
+			size:     12,
+			expected: "[font]\nsize = 12.0\nnormal = { family = \"Mono\" }\n",
+		},
+		{
+			name:     "alacritty appends font section",
+			adapter:  &Alacritty{},
+			content:  "[window]\nopacity = 0.9\n",
+			size:     12,
+			expected: "[window]\nopacity = 0.9\n\n[font]\nsize = 12.0\n",
+		},
+		{
+			name:     "kitty replaces value only",
+			adapter:  &Kitty{},
+			content:  "# font_size 20\nfont_size   10.0\n",
+			size:     15,
+			expected: "# font_size 20\nfont_size   15\n",
+		},
+		{
+			name:     "foot updates every font",
+			adapter:  &Foot{},
+			content:  "# foot\n[main]\nfont=Mono:size=10,Emoji\n\n[colors]\nalpha=0.9\n",
+			size:     15,
+			expected: "# foot\n[main]\nfont=Mono:size=15,Emoji:size=15\n\n[colors]\nalpha=0.9\n",
+		},
+		{
+			name:     "ghostty appends when missing",
+			adapter:  &Ghostty{},
+			content:  "theme = tokyonight",
+			size:     16,
+			expected: "theme = tokyonight\n\nfont-size = 16\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.adapter.SetFontSize(tt.content, tt.size)
+			if got != tt.expected {
+				t.Errorf("Unexpected result:\n--- got ---\n%s\n--- expected ---\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManagerDetectsExistingConfigs(t *testing.T) {
+	configHome := t.TempDir()
+	manager := NewManager(configHome, t.TempDir())
+
+	if detected := manager.Detected(); len(detected) != 0 {
+		t.Fatalf("Expected no terminals detected, got %d", len(detected))
+	}
+
+	writeConfig(t, filepath.Join(configHome, "kitty", "kitty.conf"), "font_size 11\n")
+	writeConfig(t, filepath.Join(configHome, "ghostty", "config"), "font-size = 13\n")
+
+	detected := manager.Detected()
+	if len(detected) != 2 {
+		t.Fatalf("Expected 2 terminals detected, got %d", len(detected))
+	}
+	if detected[0].Name() != "Kitty" || detected[1].Name() != "Ghostty" {
+		t.Errorf("Unexpected detection order: %s, %s", detected[0].Name(), detected[1].Name())
+	}
+}
+
+func TestManagerApplyDoesNotCompound(t *testing.T) {
+	configHome := t.TempDir()
+	manager := NewManager(configHome, t.TempDir())
+
+	kittyPath := filepath.Join(configHome, "kitty", "kitty.conf")
+	writeConfig(t, kittyPath, "# fonts\nfont_size 10\n")
+
+	for i := 0; i < 3; i++ {
+		if _, err := manager.ApplyFontScale(1.5); err != nil {
+			t.Fatalf("ApplyFontScale failed: %v", err)
+		}
+	}
+	if got := readConfig(t, kittyPath); got != "# fonts\nfont_size 15\n" {
+		t.Errorf("Repeated applies should not compound, got:\n%s", got)
+	}
+
+	if _, err := manager.ApplyFontScale(1.0); err != nil {
+		t.Fatalf("ApplyFontScale failed: %v", err)
+	}
+	if got := readConfig(t, kittyPath); got != "# fonts\nfont_size 10\n" {
+		t.Errorf("Scale 1.0 should restore the baseline, got:\n%s", got)
+	}
+}
+
+func TestManagerRebaselinesAfterManualEdit(t *testing.T) {
+	configHome := t.TempDir()
+	manager := NewManager(configHome, t.TempDir())
+
+	path := filepath.Join(configHome, "ghostty", "config")
+	writeConfig(t, path, "font-size = 10\n")
+
+	if _, err := manager.ApplyFontScale(2.0); err != nil {
+		t.Fatalf("ApplyFontScale failed: %v", err)
+	}
+
+	// The user picks a new size by hand; that becomes the new baseline.
+	writeConfig(t, path, "font-size = 12\n")
+
+	changes, err := manager.ApplyFontScale(1.5)
+	if err != nil {
+		t.Fatalf("ApplyFontScale failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].BaseSize != 12 || changes[0].ToSize != 18 {
+		t.Errorf("Expected rebaseline to 12 -> 18, got %+v", changes)
+	}
+	if got := readConfig(t, path); !strings.Contains(got, "font-size = 18") {
+		t.Errorf("Expected font-size 18, got:\n%s", got)
+	}
+}
+
+func TestManagerCurrentScale(t *testing.T) {
+	configHome := t.TempDir()
+	manager := NewManager(configHome, t.TempDir())
+
+	if scale, err := manager.CurrentScale(); err != nil || scale != 1 {
+		t.Errorf("Expected 1 without terminals, got %v, %v", scale, err)
+	}
+
+	path := filepath.Join(configHome, "kitty", "kitty.conf")
+	writeConfig(t, path, "font_size 10\n")
+	if scale, err := manager.CurrentScale(); err != nil || scale != 1 {
+		t.Errorf("Expected 1 before any apply, got %v, %v", scale, err)
+	}
+
+	if _, err := manager.ApplyFontScale(1.5); err != nil {
+		t.Fatalf("ApplyFontScale failed: %v", err)
+	}
+	if scale, err := manager.CurrentScale(); err != nil || scale != 1.5 {
+		t.Errorf("Expected 1.5 after applying it, got %v, %v", scale, err)
+	}
+
+	// A size picked by hand is the new baseline
+	writeConfig(t, path, "font_size 12\n")
+	if scale, err := manager.CurrentScale(); err != nil || scale != 1 {
+		t.Errorf("Expected 1 after a manual edit, got %v, %v", scale, err)
+	}
+}
+
+func TestManagerRejectsInvalidScale(t *testing.T) {
+	manager := NewManager(t.TempDir(), t.TempDir())
+	if _, err := manager.Changes(0); err == nil {
+		t.Error("Expected error for zero scale")
+	}
+}
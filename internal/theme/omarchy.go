@@ -0,0 +1,143 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// terminalColors is a terminal colour scheme: background, foreground and
+// the 16 ANSI colours, as Omarchy themes define them.
+type terminalColors struct {
+	Background string
+	Foreground string
+	ANSI       [16]string
+}
+
+var ansiNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// LoadOmarchy reads the palette of the Omarchy theme in dir, from its
+// colors.toml or, for themes without one, its alacritty.toml.
+func LoadOmarchy(dir string) (Palette, error) {
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Palette{}, ErrNoOmarchyTheme
+		}
+		return Palette{}, fmt.Errorf("failed to read Omarchy theme: %w", err)
+	}
+
+	var (
+		colors terminalColors
+		err    error
+	)
+	if _, statErr := os.Stat(filepath.Join(dir, "colors.toml")); statErr == nil {
+		colors, err = readColorsTOML(filepath.Join(dir, "colors.toml"))
+	} else {
+		colors, err = readAlacritty(filepath.Join(dir, "alacritty.toml"))
+	}
+	if err != nil {
+		return Palette{}, fmt.Errorf("failed to read Omarchy theme: %w", err)
+	}
+
+	palette, err := colors.palette()
+	if err != nil {
+		return Palette{}, fmt.Errorf("invalid Omarchy theme %s: %w", dir, err)
+	}
+
+	// current/theme links to themes/<name>
+	palette.Name = filepath.Base(dir)
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		palette.Name = filepath.Base(resolved)
+	}
+	palette.Source = Omarchy
+	return palette, nil
+}
+
+// readColorsTOML reads an Omarchy colors.toml: background, foreground and
+// color0 to color15.
+func readColorsTOML(path string) (terminalColors, error) {
+	var file map[string]interface{}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return terminalColors{}, err
+	}
+
+	str := func(key string) string {
+		value, _ := file[key].(string)
+		return value
+	}
+	colors := terminalColors{Background: str("background"), Foreground: str("foreground")}
+	for i := range colors.ANSI {
+		colors.ANSI[i] = str(fmt.Sprintf("color%d", i))
+	}
+	return colors, nil
+}
+
+// readAlacritty reads the [colors] tables of an alacritty.toml.
+func readAlacritty(path string) (terminalColors, error) {
+	var file struct {
+		Colors struct {
+			Primary map[string]string `toml:"primary"`
+			Normal  map[string]string `toml:"normal"`
+			Bright  map[string]string `toml:"bright"`
+		} `toml:"colors"`
+	}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return terminalColors{}, err
+	}
+
+	colors := terminalColors{
+		Background: file.Colors.Primary["background"],
+		Foreground: file.Colors.Primary["foreground"],
+	}
+	for i, name := range ansiNames {
+		colors.ANSI[i] = file.Colors.Normal[name]
+		colors.ANSI[i+8] = file.Colors.Bright[name]
+	}
+	return colors, nil
+}
+
+// palette maps a terminal scheme onto the TUI's colours the same way the
+// terminal palette maps the ANSI indexes; missing colours fall back to the
+// indexes.
+func (c terminalColors) palette() (Palette, error) {
+	palette := Terminal()
+
+	set := func(dest *lipgloss.Color, value string) error {
+		if value == "" {
+			return nil
+		}
+		color, err := parseColor(value)
+		if err != nil {
+			return err
+		}
+		*dest = color
+		return nil
+	}
+
+	for _, field := range []struct {
+		dest  *lipgloss.Color
+		value string
+	}{
+		{&palette.Background, c.Background},
+		{&palette.Foreground, c.Foreground},
+		{&palette.Surface, c.ANSI[0]},
+		{&palette.Float, c.ANSI[8]},
+		{&palette.Comment, c.ANSI[8]},
+		{&palette.Subtle, c.ANSI[7]},
+		{&palette.Red, c.ANSI[1]},
+		{&palette.Green, c.ANSI[2]},
+		{&palette.Yellow, c.ANSI[3]},
+		{&palette.Blue, c.ANSI[4]},
+		{&palette.Magenta, c.ANSI[5]},
+		{&palette.Cyan, c.ANSI[6]},
+	} {
+		if err := set(field.dest, field.value); err != nil {
+			return Palette{}, err
+		}
+	}
+	return palette, nil
+}
@@ -0,0 +1,76 @@
+package theme
+
+import "github.com/charmbracelet/lipgloss"
+
+// Styles is the style registry: the palette's colours plus the styles
+// shared across screens, all built from one palette.
+type Styles struct {
+	Palette
+
+	Header     lipgloss.Style
+	Footer     lipgloss.Style
+	Title      lipgloss.Style
+	Selected   lipgloss.Style
+	Unselected lipgloss.Style
+	Help       lipgloss.Style
+	Error      lipgloss.Style
+	Success    lipgloss.Style
+}
+
+// NewStyles builds the registry for a palette.
+func NewStyles(p Palette) *Styles {
+	return &Styles{
+		Palette: p,
+
+		Header: lipgloss.NewStyle().
+			Background(p.Surface).
+			Foreground(p.Foreground).
+			Bold(true).
+			Padding(1, 2).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(p.Blue),
+
+		Footer: lipgloss.NewStyle().
+			Background(p.Surface).
+			Foreground(p.Comment).
+			Padding(0, 1),
+
+		Title: lipgloss.NewStyle().
+			Foreground(p.Blue).
+			Bold(true).
+			Underline(true),
+
+		Selected: lipgloss.NewStyle().
+			Foreground(p.Blue).
+			Bold(true),
+
+		Unselected: lipgloss.NewStyle().
+			Foreground(p.Foreground),
+
+		Help: lipgloss.NewStyle().
+			Foreground(p.Comment).
+			Italic(true),
+
+		Error: lipgloss.NewStyle().
+			Foreground(p.Red).
+			Bold(true),
+
+		Success: lipgloss.NewStyle().
+			Foreground(p.Green).
+			Bold(true),
+	}
+}
+
+// Description says which palette is in use, for the settings screen.
+func (p Palette) Description() string {
+	switch p.Source {
+	case "built-in":
+		return p.Name
+	case Omarchy:
+		return "Omarchy (" + p.Name + ")"
+	case "NO_COLOR":
+		return "no colour (NO_COLOR is set)"
+	default:
+		return p.Name + " (" + p.Source + ")"
+	}
+}
@@ -0,0 +1,260 @@
+// Package theme holds the TUI's colours. A palette comes from a built-in
+// theme, a TOML file, or the active Omarchy theme, and NO_COLOR turns colour
+// off whatever was chosen. Styles is the registry every render function
+// draws with, so a palette change reaches every screen.
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/ui"
+)
+
+// Palette is the set of colours the TUI uses. An empty colour means the
+// terminal's own foreground or background.
+type Palette struct {
+	// Name identifies the palette, for example "terminal" or an Omarchy
+	// theme's name.
+	Name string
+	// Source says where the palette came from: "built-in", "omarchy",
+	// "NO_COLOR" or a file path.
+	Source string
+
+	Background lipgloss.Color
+	Surface    lipgloss.Color
+	Float      lipgloss.Color
+
+	Foreground lipgloss.Color
+	Comment    lipgloss.Color
+	Subtle     lipgloss.Color
+
+	Blue    lipgloss.Color
+	Cyan    lipgloss.Color
+	Green   lipgloss.Color
+	Yellow  lipgloss.Color
+	Red     lipgloss.Color
+	Magenta lipgloss.Color
+}
+
+// Built-in palette names.
+const (
+	Auto         = "auto"
+	Omarchy      = "omarchy"
+	NameTerminal = "terminal"
+	HighContrast = "high-contrast"
+	NoColor      = "no-color"
+)
+
+// Terminal uses the terminal's 16 ANSI colours, as pkg/ui assigns them, so
+// it follows whatever colour scheme the terminal has.
+func Terminal() Palette {
+	return Palette{
+		Name:   NameTerminal,
+		Source: "built-in",
+
+		Background: lipgloss.Color(""),
+		Surface:    lipgloss.Color("0"),
+		Float:      lipgloss.Color("8"),
+
+		Foreground: lipgloss.Color(""),
+		Comment:    ui.ColorComment,
+		Subtle:     ui.ColorSubtle,
+
+		Blue:    ui.ColorBlue,
+		Cyan:    ui.ColorCyan,
+		Green:   ui.ColorGreen,
+		Yellow:  ui.ColorYellow,
+		Red:     ui.ColorRed,
+		Magenta: ui.ColorMagenta,
+	}
+}
+
+// HighContrastPalette uses the bright ANSI colours and never dims text.
+func HighContrastPalette() Palette {
+	return Palette{
+		Name:   HighContrast,
+		Source: "built-in",
+
+		Background: lipgloss.Color(""),
+		Surface:    lipgloss.Color("0"),
+		Float:      lipgloss.Color("7"),
+
+		Foreground: lipgloss.Color("15"),
+		Comment:    lipgloss.Color("7"),
+		Subtle:     lipgloss.Color("15"),
+
+		Blue:    lipgloss.Color("12"),
+		Cyan:    lipgloss.Color("14"),
+		Green:   lipgloss.Color("10"),
+		Yellow:  lipgloss.Color("11"),
+		Red:     lipgloss.Color("9"),
+		Magenta: lipgloss.Color("13"),
+	}
+}
+
+// NoColorPalette leaves every colour to the terminal; bold and italic still
+// show.
+func NoColorPalette() Palette {
+	return Palette{Name: NoColor, Source: "built-in"}
+}
+
+// Builtin lists the names Resolve accepts besides file paths.
+func Builtin() []string {
+	return []string{Auto, HighContrast, NoColor, Omarchy, NameTerminal}
+}
+
+// OmarchyDir is the active Omarchy theme, a symlink into
+// ~/.config/omarchy/themes.
+func OmarchyDir(configHome string) string {
+	return filepath.Join(configHome, "omarchy", "current", "theme")
+}
+
+// ErrNoOmarchyTheme is returned when the omarchy theme is asked for but
+// there is no active Omarchy theme.
+var ErrNoOmarchyTheme = errors.New("no active Omarchy theme")
+
+// Resolve picks the palette for a theme setting:
+//   - "auto" or empty uses the active Omarchy theme if there is one, and the
+//     terminal's colours otherwise;
+//   - "omarchy" requires the Omarchy theme;
+//   - "terminal", "high-contrast" and "no-color" are built in;
+//   - anything else is a path to a TOML palette.
+//
+// A non-empty NO_COLOR overrides all of them.
+func Resolve(name, omarchyDir string, getenv func(string) string) (Palette, error) {
+	if getenv("NO_COLOR") != "" {
+		palette := NoColorPalette()
+		palette.Source = "NO_COLOR"
+		return palette, nil
+	}
+
+	switch name {
+	case "", Auto:
+		palette, err := LoadOmarchy(omarchyDir)
+		if errors.Is(err, ErrNoOmarchyTheme) {
+			return Terminal(), nil
+		}
+		return palette, err
+	case Omarchy:
+		return LoadOmarchy(omarchyDir)
+	case NameTerminal:
+		return Terminal(), nil
+	case HighContrast:
+		return HighContrastPalette(), nil
+	case NoColor:
+		return NoColorPalette(), nil
+	}
+
+	if !strings.HasSuffix(name, ".toml") {
+		return Palette{}, fmt.Errorf("unknown theme %q (available: %s, or a .toml file)", name, strings.Join(Builtin(), ", "))
+	}
+	return LoadFile(name)
+}
+
+// paletteFile is a TOML palette. Colours are "#rrggbb" or ANSI indexes;
+// any left out keep the terminal palette's.
+type paletteFile struct {
+	Name       string `toml:"name"`
+	Background string `toml:"background"`
+	Surface    string `toml:"surface"`
+	Float      string `toml:"float"`
+	Foreground string `toml:"foreground"`
+	Comment    string `toml:"comment"`
+	Subtle     string `toml:"subtle"`
+	Blue       string `toml:"blue"`
+	Cyan       string `toml:"cyan"`
+	Green      string `toml:"green"`
+	Yellow     string `toml:"yellow"`
+	Red        string `toml:"red"`
+	Magenta    string `toml:"magenta"`
+}
+
+// LoadFile reads a TOML palette.
+func LoadFile(path string) (Palette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Palette{}, fmt.Errorf("failed to read theme: %w", err)
+	}
+	palette, err := Parse(data)
+	if err != nil {
+		return Palette{}, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	if palette.Name == "" {
+		palette.Name = strings.TrimSuffix(filepath.Base(path), ".toml")
+	}
+	palette.Source = path
+	return palette, nil
+}
+
+// Parse reads a TOML palette, rejecting unknown keys and malformed colours.
+func Parse(data []byte) (Palette, error) {
+	var file paletteFile
+	meta, err := toml.Decode(string(data), &file)
+	if err != nil {
+		return Palette{}, fmt.Errorf("failed to parse theme: %w", err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		sort.Strings(keys)
+		return Palette{}, fmt.Errorf("unknown keys: %s", strings.Join(keys, ", "))
+	}
+
+	palette := Terminal()
+	palette.Name = file.Name
+	for _, field := range []struct {
+		key   string
+		value string
+		dest  *lipgloss.Color
+	}{
+		{"background", file.Background, &palette.Background},
+		{"surface", file.Surface, &palette.Surface},
+		{"float", file.Float, &palette.Float},
+		{"foreground", file.Foreground, &palette.Foreground},
+		{"comment", file.Comment, &palette.Comment},
+		{"subtle", file.Subtle, &palette.Subtle},
+		{"blue", file.Blue, &palette.Blue},
+		{"cyan", file.Cyan, &palette.Cyan},
+		{"green", file.Green, &palette.Green},
+		{"yellow", file.Yellow, &palette.Yellow},
+		{"red", file.Red, &palette.Red},
+		{"magenta", file.Magenta, &palette.Magenta},
+	} {
+		if field.value == "" {
+			continue
+		}
+		color, err := parseColor(field.value)
+		if err != nil {
+			return Palette{}, fmt.Errorf("%s: %w", field.key, err)
+		}
+		*field.dest = color
+	}
+	return palette, nil
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func parseColor(value string) (lipgloss.Color, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "0x") {
+		value = "#" + value[2:]
+	}
+	if hexColor.MatchString(value) {
+		return lipgloss.Color(strings.ToLower(value)), nil
+	}
+	if index, err := strconv.Atoi(value); err == nil && index >= 0 && index <= 255 {
+		return lipgloss.Color(value), nil
+	}
+	return "", fmt.Errorf("%q is not a #rrggbb colour or an ANSI index", value)
+}
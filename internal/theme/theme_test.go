@@ -0,0 +1,209 @@
+package theme
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestResolveBuiltin(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "none")
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", NameTerminal},
+		{Auto, NameTerminal},
+		{NameTerminal, NameTerminal},
+		{HighContrast, HighContrast},
+		{NoColor, NoColor},
+	}
+	for _, tt := range tests {
+		palette, err := Resolve(tt.name, missing, env(nil))
+		if err != nil {
+			t.Errorf("Resolve(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if palette.Name != tt.expected {
+			t.Errorf("Resolve(%q) = %s, expected %s", tt.name, palette.Name, tt.expected)
+		}
+	}
+
+	if _, err := Resolve(Omarchy, missing, env(nil)); !errors.Is(err, ErrNoOmarchyTheme) {
+		t.Errorf("Expected ErrNoOmarchyTheme without an Omarchy theme, got %v", err)
+	}
+	if _, err := Resolve("dracula", missing, env(nil)); err == nil {
+		t.Error("Expected an unknown theme name to be rejected")
+	}
+}
+
+func TestResolveNoColor(t *testing.T) {
+	palette, err := Resolve(HighContrast, "", env(map[string]string{"NO_COLOR": "1"}))
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if palette.Name != NoColor || palette.Source != "NO_COLOR" {
+		t.Errorf("Expected NO_COLOR to win, got %s from %s", palette.Name, palette.Source)
+	}
+	if palette.Blue != "" || palette.Foreground != "" {
+		t.Errorf("Expected no colours, got %+v", palette)
+	}
+}
+
+func TestLoadOmarchyColorsTOML(t *testing.T) {
+	root := t.TempDir()
+	themeDir := filepath.Join(root, "omarchy", "themes", "tokyo-night")
+	writeFile(t, filepath.Join(themeDir, "colors.toml"), `
+background = "#1a1b26"
+foreground = "#c0caf5"
+color0 = "#15161e"
+color1 = "#f7768e"
+color2 = "#9ece6a"
+color3 = "#e0af68"
+color4 = "#7aa2f7"
+color5 = "#bb9af7"
+color6 = "#7dcfff"
+color7 = "#a9b1d6"
+color8 = "#414868"
+`)
+	current := filepath.Join(root, "omarchy", "current")
+	if err := os.MkdirAll(current, 0755); err != nil {
+		t.Fatalf("Failed to create %s: %v", current, err)
+	}
+	if err := os.Symlink(themeDir, OmarchyDir(root)); err != nil {
+		t.Fatalf("Failed to link theme: %v", err)
+	}
+
+	palette, err := Resolve(Auto, OmarchyDir(root), env(nil))
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if palette.Name != "tokyo-night" || palette.Source != Omarchy {
+		t.Errorf("Expected the linked theme's name, got %s from %s", palette.Name, palette.Source)
+	}
+	for _, tt := range []struct {
+		name   string
+		actual lipgloss.Color
+		want   lipgloss.Color
+	}{
+		{"background", palette.Background, "#1a1b26"},
+		{"blue", palette.Blue, "#7aa2f7"},
+		{"red", palette.Red, "#f7768e"},
+		{"comment", palette.Comment, "#414868"},
+		{"subtle", palette.Subtle, "#a9b1d6"},
+	} {
+		if tt.actual != tt.want {
+			t.Errorf("Expected %s to be %s, got %s", tt.name, tt.want, tt.actual)
+		}
+	}
+}
+
+func TestLoadOmarchyAlacritty(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nord")
+	writeFile(t, filepath.Join(dir, "alacritty.toml"), `
+[colors.primary]
+background = "0x2e3440"
+foreground = "0xd8dee9"
+
+[colors.normal]
+blue = "0x81a1c1"
+green = "0xa3be8c"
+
+[colors.bright]
+black = "0x4c566a"
+`)
+
+	palette, err := LoadOmarchy(dir)
+	if err != nil {
+		t.Fatalf("LoadOmarchy failed: %v", err)
+	}
+	if palette.Name != "nord" {
+		t.Errorf("Expected the directory name, got %s", palette.Name)
+	}
+	if palette.Background != "#2e3440" || palette.Blue != "#81a1c1" || palette.Comment != "#4c566a" {
+		t.Errorf("Unexpected palette: %+v", palette)
+	}
+	if palette.Red != Terminal().Red {
+		t.Errorf("Expected a missing colour to keep the terminal's, got %s", palette.Red)
+	}
+}
+
+func TestLoadOmarchyInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "colors.toml"), `color4 = "blue"`)
+	if _, err := LoadOmarchy(dir); err == nil {
+		t.Error("Expected a malformed colour to be rejected")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mine.toml")
+	writeFile(t, path, "blue = \"#0000FF\"\nred = \"9\"\n")
+
+	palette, err := Resolve(path, "", env(nil))
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if palette.Name != "mine" || palette.Source != path {
+		t.Errorf("Expected the file's name and path, got %s from %s", palette.Name, palette.Source)
+	}
+	if palette.Blue != "#0000ff" || palette.Red != "9" || palette.Green != Terminal().Green {
+		t.Errorf("Unexpected palette: %+v", palette)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{"unknown key", "blu = \"4\"\n", "unknown keys: blu"},
+		{"bad hex", "blue = \"#00f\"\n", "blue"},
+		{"index out of range", "red = \"256\"\n", "red"},
+		{"not toml", "blue = \n", "failed to parse theme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("Expected an error mentioning %q, got %v", tt.errText, err)
+			}
+		})
+	}
+}
+
+func TestDescription(t *testing.T) {
+	tests := []struct {
+		palette  Palette
+		expected string
+	}{
+		{HighContrastPalette(), "high-contrast"},
+		{Palette{Name: "nord", Source: Omarchy}, "Omarchy (nord)"},
+		{Palette{Name: NoColor, Source: "NO_COLOR"}, "no colour (NO_COLOR is set)"},
+		{Palette{Name: "mine", Source: "/tmp/mine.toml"}, "mine (/tmp/mine.toml)"},
+	}
+	for _, tt := range tests {
+		if actual := tt.palette.Description(); actual != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/keymap"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
+)
+
+// manualSettings are the three manual scaling controls.
+type manualSettings struct {
+	Scale float64
+	GTK   int
+	DPI   int
+}
+
+// manualSettings returns what the manual scaling controls show, the draft
+// of the selected monitor.
+func (m Model) manualSettings() manualSettings {
+	return manualSettings{Scale: m.manualMonitorScale, GTK: m.manualGTKScale, DPI: m.manualFontDPI}
+}
+
+func (m *Model) setManualSettings(s manualSettings) {
+	m.manualMonitorScale, m.manualGTKScale, m.manualFontDPI = s.Scale, s.GTK, s.DPI
+}
+
+// detectedSettings is where a monitor's draft starts: its detected scale,
+// and the GTK scale and font DPI the session uses. Those two are the last
+// applied values, or else GDK_SCALE and XFT_DPI from the environment, or
+// else the defaults.
+func (m Model) detectedSettings(mon monitor.Monitor) manualSettings {
+	settings := manualSettings{Scale: mon.Scale, GTK: 1, DPI: types.BaseDPI}
+	if settings.Scale <= 0 {
+		settings.Scale = 1.0
+	}
+	if !m.isDemoMode && m.getenv != nil {
+		if gtk, err := strconv.Atoi(m.getenv("GDK_SCALE")); err == nil && gtk >= types.MinGTKScale && gtk <= types.MaxGTKScale {
+			settings.GTK = gtk
+		}
+		if dpi, err := strconv.Atoi(m.getenv("XFT_DPI")); err == nil && dpi >= types.MinFontDPI && dpi <= types.MaxFontDPI {
+			settings.DPI = dpi
+		}
+	}
+	if m.appliedGTKScale > 0 {
+		settings.GTK = m.appliedGTKScale
+	}
+	if m.appliedFontDPI > 0 {
+		settings.DPI = m.appliedFontDPI
+	}
+	return settings
+}
+
+// draftFor returns the monitor's draft, or its detected settings when it
+// has none.
+func (m Model) draftFor(mon monitor.Monitor) (manualSettings, bool) {
+	if draft, ok := m.drafts[mon.Name]; ok {
+		return draft, true
+	}
+	return m.detectedSettings(mon), false
+}
+
+// modified reports whether the named monitor has a draft that hasn't been
+// applied.
+func (m Model) modified(name string) bool {
+	_, ok := m.drafts[name]
+	return ok
+}
+
+// loadDraft puts the selected monitor's draft in the manual controls.
+func (m *Model) loadDraft() {
+	if m.selectedMonitor >= len(m.monitors) {
+		return
+	}
+	draft, _ := m.draftFor(m.monitors[m.selectedMonitor])
+	m.setManualSettings(draft)
+}
+
+// draftsCopy copies the drafts. The map is never changed in place, so
+// copies of the model and the undo stack can hold on to it.
+func (m Model) draftsCopy() map[string]manualSettings {
+	drafts := make(map[string]manualSettings, len(m.drafts))
+	for name, draft := range m.drafts {
+		drafts[name] = draft
+	}
+	return drafts
+}
+
+// setDraft sets the named monitor's draft. A draft that's back at the
+// detected settings is dropped, so the monitor no longer shows as modified.
+func (m *Model) setDraft(name string, draft manualSettings) {
+	drafts := m.draftsCopy()
+	drafts[name] = draft
+	for _, mon := range m.monitors {
+		if mon.Name == name && m.detectedSettings(mon) == draft {
+			delete(drafts, name)
+		}
+	}
+	m.drafts = drafts
+	if m.selectedMonitor < len(m.monitors) && m.monitors[m.selectedMonitor].Name == name {
+		m.setManualSettings(draft)
+	}
+}
+
+// setDrafts replaces every draft, and the manual controls when they're on
+// screen.
+func (m *Model) setDrafts(drafts map[string]manualSettings) {
+	m.drafts = drafts
+	if m.mode == ModeManualScaling {
+		m.loadDraft()
+	}
+}
+
+// editsDrafts reports whether drafts can be applied or discarded from the
+// current screen.
+func (m Model) editsDrafts() bool {
+	switch m.mode {
+	case ModeDashboard, ModeMonitorSelection, ModeManualScaling:
+		return true
+	}
+	return false
+}
+
+// draftOption is a draft as a scaling option to apply.
+func draftOption(draft manualSettings) monitor.ScalingOption {
+	return monitor.ScalingOption{
+		MonitorScale: draft.Scale,
+		GTKScale:     draft.GTK,
+		FontDPI:      draft.DPI,
+		DisplayName:  "Draft",
+		Description:  "Edited manual scaling values",
+	}
+}
+
+// draftedChanges lists the drafted monitors in detection order, as staged
+// changes.
+func (m Model) draftedChanges() []stagedChange {
+	var changes []stagedChange
+	for _, mon := range m.monitors {
+		if draft, ok := m.drafts[mon.Name]; ok {
+			changes = append(changes, stagedChange{Monitor: mon, Option: draftOption(draft)})
+		}
+	}
+	return changes
+}
+
+// applyDrafts confirms every draft in one transaction. GTK scale and font
+// DPI are global, so they come from the selected monitor's draft, or the
+// last drafted monitor's when the selected one has none.
+func (m Model) applyDrafts() Model {
+	changes := m.draftedChanges()
+	if len(changes) == 0 {
+		return m
+	}
+	pending := changes[len(changes)-1]
+	for _, change := range changes {
+		if m.selectedMonitor < len(m.monitors) && change.Monitor.Name == m.monitors[m.selectedMonitor].Name {
+			pending = change
+		}
+	}
+	m.confirmationAction = ConfirmDrafts
+	m.pendingMonitor = pending.Monitor
+	m.pendingOption = pending.Option
+	m.preparePlan()
+	m.mode = ModeConfirmation
+	return m
+}
+
+// discardDrafts drops every draft, as an undoable change.
+func (m *Model) discardDrafts() {
+	if len(m.drafts) == 0 {
+		return
+	}
+	before := m.drafts
+	m.setDrafts(nil)
+	m.history.push(draftsEdit{before: before, description: fmt.Sprintf("Discard %d draft(s)", len(before))})
+}
+
+// draftsHint counts the drafts and names the keys that apply or discard them.
+func (m Model) draftsHint() string {
+	key := lipgloss.NewStyle().Foreground(m.styles.Magenta).Bold(true)
+	text := lipgloss.NewStyle().Foreground(m.styles.Subtle)
+	return lipgloss.NewStyle().Foreground(m.styles.Magenta).Render(fmt.Sprintf("✎ %d draft(s)  ", len(m.drafts))) +
+		key.Render(keymap.Short(m.keys.ApplyDrafts)) + text.Render(" apply all drafts  ") +
+		key.Render(keymap.Short(m.keys.DiscardDrafts)) + text.Render(" discard drafts")
+}
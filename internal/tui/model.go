@@ -1,35 +1,42 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/history"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/keymap"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/logging"
 	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/theme"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/zone"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
 	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
 	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
 )
 
-var (
-	colorBackground = lipgloss.Color("")
-	colorSurface    = lipgloss.Color("0")
-	colorFloat      = lipgloss.Color("8")
-
-	colorForeground = lipgloss.Color("")
-	colorComment    = lipgloss.Color("8")
-	colorSubtle     = lipgloss.Color("7")
-
-	colorBlue    = lipgloss.Color("4")
-	colorCyan    = lipgloss.Color("6")
-	colorGreen   = lipgloss.Color("2")
-	colorYellow  = lipgloss.Color("3")
-	colorRed     = lipgloss.Color("1")
-	colorMagenta = lipgloss.Color("5")
-)
+// themeInfo describes the palette in use; the terminal palette is described
+// by what the terminal supports, since its colours are the terminal's.
+func (m Model) themeInfo() string {
+	if m.styles.Name != theme.NameTerminal || m.styles.Source != "built-in" {
+		return m.styles.Description()
+	}
+	if m.cachedTerminalTheme != "" {
+		return m.cachedTerminalTheme
+	}
+	return getTerminalThemeInfo()
+}
 
 func getTerminalThemeInfo() string {
 	termOutput := termenv.NewOutput(os.Stdout)
@@ -68,6 +75,11 @@ const (
 	ModeSettings
 	ModeHelp
 	ModeConfirmation
+	ModeWaybarPreview
+	ModeHistory
+	ModeDrift
+	ModeLogs
+	ModeScalingComparison
 )
 
 type ConfirmationAction int
@@ -76,6 +88,7 @@ const (
 	ConfirmNone ConfirmationAction = iota
 	ConfirmSmartScaling
 	ConfirmManualScaling
+	ConfirmDrafts
 )
 
 type Monitor struct {
@@ -117,23 +130,80 @@ type Model struct {
 	confirmationAction ConfirmationAction
 	pendingOption      monitor.ScalingOption
 	pendingMonitor     monitor.Monitor
+	pendingPlan        monitor.Plan
+	pendingPlanErr     error
+
+	// staged holds per-monitor changes confirmed together with the pending one
+	staged []stagedChange
+
+	// verification is what the compositor actually did after the last apply
+	verification  monitor.Verification
+	selectedDrift int
+	driftStatus   string
+
+	waybarPlan monitor.Plan
+	// waybarErr is why writing waybarPlan failed; the preview stays open
+	// to show it
+	waybarErr error
+
+	historyEntries  []history.Entry
+	selectedHistory int
+	historyStatus   string
 
 	isDemoMode bool
-	ready      bool
+
+	keys *keymap.KeyMap
+
+	// load is where monitor detection stands; nothing but progress or the
+	// error is shown until it's ready
+	load    loadState
+	loadErr error
+	// refreshing is set while the dashboard re-detects monitors in the
+	// background, keeping the current ones on screen
+	refreshing    bool
+	refreshStatus string
+	spinnerFrame  int
 
 	services *app.Services
+	logger   *logging.Logger
+
+	// daemon is a running serve command the monitors come from, so changes
+	// made through it show up here; nil to detect them directly
+	daemon *client.Client
+	// daemonPending holds monitors the daemon announced away from the
+	// dashboard, shown once the user is back there
+	daemonPending []monitor.Monitor
+
+	// logOffset is how many lines the log viewer is scrolled up from the end
+	logOffset int
 
 	cachedTerminalTheme string
 	cachedCommandStatus map[string]bool
 
-	headerStyle     lipgloss.Style
-	footerStyle     lipgloss.Style
-	titleStyle      lipgloss.Style
-	selectedStyle   lipgloss.Style
-	unselectedStyle lipgloss.Style
-	helpStyle       lipgloss.Style
-	errorStyle      lipgloss.Style
-	successStyle    lipgloss.Style
+	// styles is the registry every render function draws with
+	styles *theme.Styles
+
+	// zones records where clickable elements were drawn in the last view
+	zones *zone.Manager
+	// scrollers holds each panel's scroll position
+	scrollers scrollers
+	// layoutPass, set only while layout renders the screen, collects what
+	// each panel is drawn with
+	layoutPass *layoutPass
+
+	// history is the undo stack of manual edits, staging and applies
+	history undoStack
+	// appliedGTKScale and appliedFontDPI are the values the last apply in
+	// this session set, zero before the first, so undo can put them back
+	appliedGTKScale int
+	appliedFontDPI  int
+
+	// drafts holds each monitor's edited manual settings by monitor name,
+	// until they're applied or discarded. The manual controls edit the
+	// selected monitor's draft.
+	drafts map[string]manualSettings
+	// getenv reads the session's GDK_SCALE and XFT_DPI to seed drafts
+	getenv func(string) string
 }
 
 func NewModel() Model {
@@ -156,12 +226,15 @@ func NewModelWithServices(services *app.Services) Model {
 			"Monitor Selection",
 			"Smart Scaling",
 			"Manual Scaling",
+			"History",
+			"Logs",
 			"Settings",
 			"Help",
 			"Exit",
 		},
 		isDemoMode: true,
 		services:   services,
+		logger:     services.Logger,
 
 		manualMonitorScale:    1.0,
 		manualGTKScale:        1,
@@ -169,9 +242,24 @@ func NewModelWithServices(services *app.Services) Model {
 		selectedManualControl: 0,
 
 		cachedCommandStatus: make(map[string]bool),
+		zones:               zone.New(),
+		getenv:              os.Getenv,
+	}
+
+	if m.logger == nil {
+		m.logger = logging.Discard()
+	}
+
+	m.keys = keymap.Default()
+	if services.Config != nil && services.Config.KeyMap != nil {
+		m.keys = services.Config.KeyMap
 	}
 
-	m.initStyles()
+	palette := theme.Terminal()
+	if services.Config != nil && services.Config.Theme != nil {
+		palette = *services.Config.Theme
+	}
+	m.styles = theme.NewStyles(palette)
 
 	m.cachedTerminalTheme = getTerminalThemeInfo()
 
@@ -180,104 +268,257 @@ func NewModelWithServices(services *app.Services) Model {
 		m.cachedCommandStatus[cmd] = utils.CommandExists(cmd)
 	}
 
-	m.loadMonitors()
+	return m
+}
 
-	if len(m.monitors) > 0 {
-		m.scalingOptions = services.ScalingManager.GetIntelligentScalingOptions(m.monitors[0])
+// loadState is the progress of monitor detection, which runs in a tea.Cmd
+// so the UI can draw before the compositor answers.
+type loadState int
+
+const (
+	loadDetecting loadState = iota
+	loadFailed
+	loadReady
+)
+
+// monitorsDetectedMsg carries the result of a detection started by
+// detectMonitors.
+type monitorsDetectedMsg struct {
+	monitors []monitor.Monitor
+	err      error
+}
+
+// detectMonitors runs detection off the update loop, asking the daemon
+// first when there is one.
+func (m Model) detectMonitors() tea.Cmd {
+	detector := m.services.MonitorDetector
+	daemon := m.daemon
+	logger := m.logger
+	return func() tea.Msg {
+		if daemon != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+			defer cancel()
+			doc, err := daemon.List(ctx)
+			if err == nil {
+				return monitorsDetectedMsg{monitors: documentMonitors(doc)}
+			}
+			logger.Warn("daemon detection failed, detecting directly", "error", err)
+		}
+		monitors, err := detector.DetectMonitors()
+		return monitorsDetectedMsg{monitors: monitors, err: err}
 	}
+}
+
+// daemonTimeout bounds a call to the daemon before falling back to
+// detecting directly.
+const daemonTimeout = 5 * time.Second
 
+// WithDaemon takes monitors from a running serve command and follows the
+// changes it announces, so the TUI and the daemon share state.
+func (m Model) WithDaemon(daemon *client.Client) Model {
+	m.daemon = daemon
 	return m
 }
 
-func (m *Model) initStyles() {
-	m.headerStyle = lipgloss.NewStyle().
-		Background(colorSurface).
-		Foreground(colorForeground).
-		Bold(true).
-		Padding(1, 2).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorBlue)
+// daemonChangedMsg carries the monitors after the daemon saw them change.
+type daemonChangedMsg struct {
+	monitors []monitor.Monitor
+}
 
-	m.footerStyle = lipgloss.NewStyle().
-		Background(colorSurface).
-		Foreground(colorComment).
-		Padding(0, 1)
+// daemonClosedMsg says the daemon went away.
+type daemonClosedMsg struct{}
 
-	m.titleStyle = lipgloss.NewStyle().
-		Foreground(colorBlue).
-		Bold(true).
-		Underline(true)
+// waitForDaemon waits for the next change the daemon announces.
+func (m Model) waitForDaemon() tea.Cmd {
+	if m.daemon == nil {
+		return nil
+	}
+	changes := m.daemon.Changes()
+	return func() tea.Msg {
+		doc, ok := <-changes
+		if !ok {
+			return daemonClosedMsg{}
+		}
+		return daemonChangedMsg{monitors: documentMonitors(doc)}
+	}
+}
 
-	m.selectedStyle = lipgloss.NewStyle().
-		Foreground(colorBlue).
-		Bold(true)
+func documentMonitors(doc client.Document) []monitor.Monitor {
+	monitors := make([]monitor.Monitor, 0, len(doc.Monitors))
+	for _, mon := range doc.Monitors {
+		monitors = append(monitors, service.ToMonitor(mon))
+	}
+	return monitors
+}
 
-	m.unselectedStyle = lipgloss.NewStyle().
-		Foreground(colorForeground)
+// followDaemon shows monitors the daemon announced, unless detection is
+// still settling, then waits for the next change. Away from the dashboard
+// they wait until the user is back, so they can't replace the monitors a
+// change is being made, confirmed or reviewed against.
+func (m Model) followDaemon(msg daemonChangedMsg) (Model, tea.Cmd) {
+	if m.load == loadReady && !m.refreshing {
+		m.logger.Info("monitors changed by the daemon", "count", len(msg.monitors))
+		if m.mode == ModeDashboard {
+			m = m.showDaemonMonitors(msg.monitors)
+		} else {
+			m.daemonPending = msg.monitors
+		}
+	}
+	return m, m.waitForDaemon()
+}
 
-	m.helpStyle = lipgloss.NewStyle().
-		Foreground(colorComment).
-		Italic(true)
+func (m Model) showDaemonMonitors(monitors []monitor.Monitor) Model {
+	m = m.useMonitors(monitors, m.isDemoMode)
+	m.refreshStatus = "Monitors changed"
+	return m
+}
 
-	m.errorStyle = lipgloss.NewStyle().
-		Foreground(colorRed).
-		Bold(true)
+// spinnerTickMsg advances the spinner while detection runs.
+type spinnerTickMsg struct{}
 
-	m.successStyle = lipgloss.NewStyle().
-		Foreground(colorGreen).
-		Bold(true)
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+func tickSpinner() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return spinnerTickMsg{} })
 }
 
-func (m *Model) loadMonitors() {
-	monitors, err := m.services.MonitorDetector.DetectMonitors()
+func (m Model) spinner() string {
+	return spinnerFrames[m.spinnerFrame%len(spinnerFrames)]
+}
 
-	if m.services.Config.DebugMode {
-		fmt.Printf("DEBUG: DetectMonitors returned %d monitors, error: %v\n", len(monitors), err)
+// startDetection re-runs detection, either from the error screen or as a
+// refresh from the dashboard.
+func (m Model) startDetection() (Model, tea.Cmd) {
+	if m.load == loadReady {
+		m.refreshing = true
+		m.refreshStatus = ""
+	} else {
+		m.load = loadDetecting
 	}
+	m.loadErr = nil
+	return m, tea.Batch(m.detectMonitors(), tickSpinner())
+}
 
-	if err != nil {
-		if m.services.Config.DebugMode {
-			fmt.Printf("DEBUG: Setting demo mode due to detection error\n")
-		}
-		m.isDemoMode = true
+// fallbackMonitors are the demo monitors offered when detection fails; they
+// aren't offered when live mode is forced.
+func (m Model) fallbackMonitors() ([]monitor.Monitor, bool) {
+	if m.services.Config.ForceLiveMode {
+		return nil, false
+	}
+	detector, ok := m.services.MonitorDetector.(*monitor.Detector)
+	if !ok {
+		return nil, false
+	}
+	return detector.GetFallbackMonitors(), true
+}
 
-		if detector, ok := m.services.MonitorDetector.(*monitor.Detector); ok {
-			monitors = detector.GetFallbackMonitors()
+// finishDetection settles demo mode from a detection result: the resolved
+// mode decides, except that a session without a detection tool falls back to
+// demo monitors unless live mode is forced. Any other failure shows the error
+// screen, or keeps the current monitors if this was a refresh.
+func (m Model) finishDetection(msg monitorsDetectedMsg) Model {
+	m.logger.Debug("detect monitors returned", "count", len(msg.monitors), "error", msg.err)
+
+	if msg.err != nil {
+		if m.refreshing {
+			m.logger.Warn("monitor refresh failed", "error", msg.err)
+			m.refreshing = false
+			m.loadErr = msg.err
+			return m
 		}
-	} else {
-		if m.services.Config.DebugMode {
-			fmt.Printf("DEBUG: Setting live mode - detected real monitors\n")
+		if errors.Is(msg.err, monitor.ErrNoBackend) && !m.services.Config.ForceLiveMode {
+			m.logger.Debug("setting demo mode due to detection error")
+			fallback, _ := m.fallbackMonitors()
+			return m.useMonitors(fallback, true)
 		}
-		m.isDemoMode = false
+		m.logger.Error("monitor detection failed", "error", msg.err)
+		m.load = loadFailed
+		m.loadErr = msg.err
+		m.isDemoMode = !m.services.Config.ForceLiveMode
+		return m
 	}
 
-	if m.services.Config.ForceLiveMode {
-		if m.services.Config.DebugMode {
-			fmt.Printf("DEBUG: Force-live mode enabled, overriding demo mode\n")
-		}
-		m.isDemoMode = false
+	demo := m.services.Mode.Demo
+	m.logger.Debug("using resolved mode", "demo", demo, "reason", m.services.Mode.Reason)
+	if m.refreshing {
+		m.refreshing = false
+		m.refreshStatus = fmt.Sprintf("Found %d monitor(s)", len(msg.monitors))
+		// A refresh doesn't change the mode settled at startup
+		demo = m.isDemoMode
 	}
+	return m.useMonitors(msg.monitors, demo)
+}
 
-	if m.services.Config.DebugMode {
-		fmt.Printf("DEBUG: Final state - isDemoMode: %v, monitor count: %d\n", m.isDemoMode, len(monitors))
+func (m Model) useMonitors(monitors []monitor.Monitor, demo bool) Model {
+	m.isDemoMode = demo
+	if m.services.Config.ForceLiveMode {
+		m.logger.Debug("force-live mode enabled, overriding demo mode")
+		m.isDemoMode = false
 	}
 
+	m.logger.Info("loaded monitors", "demo", m.isDemoMode, "count", len(monitors))
 	m.monitors = monitors
+	m.daemonPending = nil
+	m.load = loadReady
+	m.loadErr = nil
+
+	if m.selectedMonitor >= len(m.monitors) {
+		m.selectedMonitor = 0
+	}
+	if len(m.monitors) > 0 {
+		m.scalingOptions = m.services.ScalingManager.GetIntelligentScalingOptions(m.monitors[m.selectedMonitor])
+	}
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.EnterAltScreen
+	return tea.Batch(tea.EnterAltScreen, m.detectMonitors(), tickSpinner(), m.waitForDaemon())
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	if next, ok := updated.(Model); ok {
+		return next.layout(), cmd
+	}
+	return updated, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		return m.handleKeyPress(msg)
+		return m.enteredMode(m.mode)(m.handleKeyPress(msg))
+
+	case tea.MouseMsg:
+		return m.enteredMode(m.mode)(m.handleMouse(msg))
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.ready = true
+		return m, nil
+
+	case monitorsDetectedMsg:
+		return m.finishDetection(msg), nil
+
+	case daemonChangedMsg:
+		return m.followDaemon(msg)
+
+	case daemonClosedMsg:
+		m.logger.Warn("daemon connection closed, detecting directly")
+		m.daemon = nil
+		return m, nil
+
+	case spinnerTickMsg:
+		if m.load == loadDetecting || m.refreshing {
+			m.spinnerFrame++
+			return m, tickSpinner()
+		}
+		return m, nil
+
+	case logTickMsg:
+		// Keep redrawing while the log viewer is open so new entries show up
+		if m.mode == ModeLogs {
+			return m, tickLogs()
+		}
 		return m, nil
 
 	default:
@@ -285,12 +526,76 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// enteredMode wraps the result of handling an input so that a screen
+// entered from prev starts scrolled to the top, and the manual controls
+// start at the selected monitor's draft.
+func (m Model) enteredMode(prev AppMode) func(tea.Model, tea.Cmd) (tea.Model, tea.Cmd) {
+	return func(updated tea.Model, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+		next, ok := updated.(Model)
+		if !ok || next.mode == prev {
+			return updated, cmd
+		}
+		next.resetScroll()
+		if next.mode == ModeManualScaling {
+			next.loadDraft()
+		}
+		if next.mode == ModeDashboard && next.daemonPending != nil {
+			next = next.showDaemonMonitors(next.daemonPending)
+		}
+		return next, cmd
+	}
+}
+
+// handleLoadingKey handles keys while detection runs or has failed: quit,
+// retry, or carry on with demo monitors.
+func (m Model) handleLoadingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == keymap.ForceQuit, key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Refresh):
+		if m.load == loadFailed {
+			return m.startDetection()
+		}
+	case key.Matches(msg, m.keys.Demo):
+		if fallback, ok := m.fallbackMonitors(); ok && m.load == loadFailed {
+			return m.useMonitors(fallback, true), nil
+		}
+	}
+	return m, nil
+}
+
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	if m.load != loadReady {
+		return m.handleLoadingKey(msg)
+	}
+	if msg.String() == keymap.ForceQuit {
 		return m, tea.Quit
+	}
+	for _, action := range keymap.Actions {
+		if key.Matches(msg, action.Binding(m.keys)) {
+			return m.perform(action.Name)
+		}
+	}
+	return m, nil
+}
+
+// perform carries out a keymap action on the current screen. Keys and the
+// mouse both end up here.
+func (m Model) perform(action string) (tea.Model, tea.Cmd) {
+	switch action {
+	case "quit":
+		return m, tea.Quit
+
+	case "pageup":
+		m.scrollers.main.ViewUp()
 
-	case "up", "k":
+	case "pagedown":
+		m.scrollers.main.ViewDown()
+
+	case "up":
+		if m.scrollsWithArrows() {
+			m.scrollers.main.LineUp(1)
+		}
 		switch m.mode {
 		case ModeDashboard:
 			if m.selectedOption > 0 {
@@ -298,13 +603,9 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		case ModeMonitorSelection:
 			if m.selectedMonitor > 0 {
-				m.selectedMonitor--
-				if len(m.monitors) > 0 && m.selectedMonitor < len(m.monitors) {
-					m.scalingOptions = m.services.ScalingManager.GetIntelligentScalingOptions(m.monitors[m.selectedMonitor])
-					m.selectedScalingOpt = 0
-				}
+				m.selectMonitor(m.selectedMonitor - 1)
 			}
-		case ModeScalingOptions:
+		case ModeScalingOptions, ModeScalingComparison:
 			if m.selectedScalingOpt > 0 {
 				m.selectedScalingOpt--
 			}
@@ -312,9 +613,24 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.selectedManualControl > 0 {
 				m.selectedManualControl--
 			}
+		case ModeHistory:
+			if m.selectedHistory > 0 {
+				m.selectedHistory--
+			}
+		case ModeDrift:
+			if m.selectedDrift > 0 {
+				m.selectedDrift--
+			}
+		case ModeLogs:
+			if m.logOffset < len(m.logger.Recent())-1 {
+				m.logOffset++
+			}
 		}
 
-	case "down", "j":
+	case "down":
+		if m.scrollsWithArrows() {
+			m.scrollers.main.LineDown(1)
+		}
 		switch m.mode {
 		case ModeDashboard:
 			if m.selectedOption < len(m.menuItems)-1 {
@@ -322,13 +638,9 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		case ModeMonitorSelection:
 			if m.selectedMonitor < len(m.monitors)-1 {
-				m.selectedMonitor++
-				if len(m.monitors) > 0 && m.selectedMonitor < len(m.monitors) {
-					m.scalingOptions = m.services.ScalingManager.GetIntelligentScalingOptions(m.monitors[m.selectedMonitor])
-					m.selectedScalingOpt = 0
-				}
+				m.selectMonitor(m.selectedMonitor + 1)
 			}
-		case ModeScalingOptions:
+		case ModeScalingOptions, ModeScalingComparison:
 			if m.selectedScalingOpt < len(m.scalingOptions)-1 {
 				m.selectedScalingOpt++
 			}
@@ -336,10 +648,26 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.selectedManualControl < 2 {
 				m.selectedManualControl++
 			}
+		case ModeHistory:
+			if m.selectedHistory < len(m.historyEntries)-1 {
+				m.selectedHistory++
+			}
+		case ModeDrift:
+			if m.selectedDrift < len(m.driftChoices())-1 {
+				m.selectedDrift++
+			}
+		case ModeLogs:
+			if m.logOffset > 0 {
+				m.logOffset--
+			}
 		}
 
 	case "left":
+		if m.mode == ModeScalingComparison && m.selectedScalingOpt > 0 {
+			m.selectedScalingOpt--
+		}
 		if m.mode == ModeManualScaling {
+			before := m.manualSettings()
 			switch m.selectedManualControl {
 			case 0:
 				m.manualMonitorScale = utils.FindNextValidScale(m.manualMonitorScale, false, types.ValidHyprlandScales)
@@ -355,10 +683,15 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
+			m.recordManual(before)
 		}
 
 	case "right":
+		if m.mode == ModeScalingComparison && m.selectedScalingOpt < len(m.scalingOptions)-1 {
+			m.selectedScalingOpt++
+		}
 		if m.mode == ModeManualScaling {
+			before := m.manualSettings()
 			switch m.selectedManualControl {
 			case 0:
 				m.manualMonitorScale = utils.FindNextValidScale(m.manualMonitorScale, true, types.ValidHyprlandScales)
@@ -374,19 +707,21 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
+			m.recordManual(before)
 		}
 
-	case "enter", " ":
+	case "select":
 		if m.mode == ModeMonitorSelection {
 			m.mode = ModeDashboard
 			m.selectedOption = 0
 			return m, nil
-		} else if m.mode == ModeScalingOptions && len(m.scalingOptions) > 0 && m.selectedScalingOpt < len(m.scalingOptions) {
+		} else if (m.mode == ModeScalingOptions || m.mode == ModeScalingComparison) && len(m.scalingOptions) > 0 && m.selectedScalingOpt < len(m.scalingOptions) {
 			selectedOption := m.scalingOptions[m.selectedScalingOpt]
 			if len(m.monitors) > 0 && m.selectedMonitor < len(m.monitors) {
 				m.confirmationAction = ConfirmSmartScaling
 				m.pendingOption = selectedOption
 				m.pendingMonitor = m.monitors[m.selectedMonitor]
+				m.preparePlan()
 				m.mode = ModeConfirmation
 			}
 			return m, nil
@@ -394,50 +729,136 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if len(m.monitors) > 0 && m.selectedMonitor < len(m.monitors) {
 				m.confirmationAction = ConfirmManualScaling
 				m.pendingMonitor = m.monitors[m.selectedMonitor]
-				m.pendingOption = monitor.ScalingOption{
-					MonitorScale: m.manualMonitorScale,
-					GTKScale:     m.manualGTKScale,
-					FontDPI:      m.manualFontDPI,
-					DisplayName:  "Manual Settings",
-					Description:  "Custom scaling values",
-				}
+				m.pendingOption = m.manualOption()
+				m.preparePlan()
 				m.mode = ModeConfirmation
 			}
 			return m, nil
 		} else if m.mode == ModeConfirmation {
-			switch m.confirmationAction {
-			case ConfirmSmartScaling:
-				_ = m.services.ConfigManager.ApplyCompleteScalingOption(m.pendingMonitor, m.pendingOption)
-				// Update the local monitor data with new scale
-				if m.selectedMonitor < len(m.monitors) {
-					m.monitors[m.selectedMonitor].Scale = m.pendingOption.MonitorScale
-				}
-			case ConfirmManualScaling:
-				_ = m.services.ConfigManager.ApplyMonitorScale(m.pendingMonitor, m.manualMonitorScale)
-				_ = m.services.ConfigManager.ApplyGTKScale(m.manualGTKScale)
-				_ = m.services.ConfigManager.ApplyFontDPI(m.manualFontDPI)
-				// Update the local monitor data with new scale
-				if m.selectedMonitor < len(m.monitors) {
-					m.monitors[m.selectedMonitor].Scale = m.manualMonitorScale
-				}
+			if m.pendingPlanErr != nil {
+				return m, nil
 			}
-			m.confirmationAction = ConfirmNone
+			tx := m.pendingTransaction()
+			inverse := m.inverse(tx)
+			verification, err := m.commitTransaction(tx, m.pendingPlan, m.pendingReason())
+			if err != nil {
+				// Nothing was changed or everything was rolled back; stay here
+				// so the error is visible
+				m.pendingPlanErr = err
+				return m, nil
+			}
+			// Update the local monitor data with new scales
+			m.applied(tx)
+			if m.confirmationAction != ConfirmDrafts {
+				m.staged = nil
+			}
+			if m.confirmationAction == ConfirmManualScaling && m.selectedMonitor < len(m.monitors) {
+				m.monitors[m.selectedMonitor].Scale = m.manualMonitorScale
+			}
+			m.history.push(applyEdit{before: inverse, after: tx})
+			if verification.HasDrift() {
+				m.verification = verification
+				m.selectedDrift = 0
+				m.driftStatus = ""
+				m.mode = ModeDrift
+				return m, nil
+			}
+			return m.finishApply()
+		} else if m.mode == ModeDrift {
+			return m.resolveDrift(m.selectedDrift)
+		} else if m.mode == ModeHistory {
+			m.restoreHistory()
+			return m, nil
+		} else if m.mode == ModeWaybarPreview {
+			if m.waybarErr != nil {
+				return m, nil
+			}
+			if err := m.recordHistory(m.waybarPlan.Description, "Match Waybar to the new font scale"); err != nil {
+				m.waybarErr = err
+				return m, nil
+			}
+			if _, err := m.executePlan(m.waybarPlan); err != nil {
+				// The write was rolled back; stay here so the error is visible
+				m.waybarErr = err
+				return m, nil
+			}
+			m.waybarPlan = monitor.Plan{}
 			m.mode = ModeDashboard
 			m.selectedOption = 0
 			return m, nil
 		}
 		return m.handleSelection()
 
-	case "m":
+	case "manual":
 		if m.mode == ModeScalingOptions {
 			m.mode = ModeManualScaling
 			return m, nil
 		}
 
-	case "h", "?":
+	case "compare":
+		if m.mode == ModeScalingOptions && len(m.scalingOptions) > 0 {
+			m.mode = ModeScalingComparison
+			return m, nil
+		}
+
+	case "stage":
+		if len(m.monitors) == 0 || m.selectedMonitor >= len(m.monitors) {
+			return m, nil
+		}
+		mon := m.monitors[m.selectedMonitor]
+		before := m.stagedCopy()
+		if m.mode == ModeScalingOptions && m.selectedScalingOpt < len(m.scalingOptions) {
+			m.stage(mon, m.scalingOptions[m.selectedScalingOpt])
+			m.mode = ModeMonitorSelection
+		} else if m.mode == ModeManualScaling {
+			m.stage(mon, m.manualOption())
+			m.mode = ModeMonitorSelection
+		} else {
+			return m, nil
+		}
+		change, _ := m.stagedFor(mon.Name)
+		m.history.push(stageEdit{before: before, after: m.stagedCopy(),
+			description: fmt.Sprintf("Stage %.2fx for %s", change.Option.MonitorScale, mon.Name)})
+
+	case "refresh":
+		if m.mode == ModeDashboard && !m.refreshing {
+			return m.startDetection()
+		}
+
+	case "unstage":
+		if m.mode == ModeMonitorSelection && m.selectedMonitor < len(m.monitors) {
+			name := m.monitors[m.selectedMonitor].Name
+			if _, ok := m.stagedFor(name); ok {
+				before := m.stagedCopy()
+				m.unstage(name)
+				m.history.push(stageEdit{before: before, after: m.stagedCopy(), description: "Unstage " + name})
+			}
+		}
+
+	case "applydrafts":
+		if m.editsDrafts() {
+			return m.applyDrafts(), nil
+		}
+
+	case "discarddrafts":
+		if m.editsDrafts() {
+			m.discardDrafts()
+		}
+
+	case "undo":
+		if m.undoable() {
+			m.undo()
+		}
+
+	case "redo":
+		if m.undoable() {
+			m.redo()
+		}
+
+	case "help":
 		m.mode = ModeHelp
 
-	case "esc":
+	case "back":
 		switch m.mode {
 		case ModeManualScaling:
 			m.mode = ModeDashboard
@@ -452,6 +873,16 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.mode = ModeDashboard
 			}
 			m.confirmationAction = ConfirmNone
+		case ModeWaybarPreview:
+			m.waybarPlan = monitor.Plan{}
+			m.waybarErr = nil
+			m.mode = ModeDashboard
+			m.selectedOption = 0
+		case ModeDrift:
+			// Leaving without a choice keeps what the compositor did
+			return m.resolveDrift(0)
+		case ModeScalingComparison:
+			m.mode = ModeScalingOptions
 		default:
 			m.mode = ModeDashboard
 			m.selectedOption = 0
@@ -461,136 +892,542 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) handleSelection() (tea.Model, tea.Cmd) {
-	switch m.selectedOption {
-	case 0:
-		m.mode = ModeDashboard
-	case 1:
-		m.mode = ModeMonitorSelection
-	case 2:
-		m.mode = ModeScalingOptions
-	case 3:
-		m.mode = ModeManualScaling
-	case 4:
-		m.mode = ModeSettings
-	case 5:
-		m.mode = ModeHelp
-	case 6:
-		return m, tea.Quit
+// selectMonitor makes monitor i the one scaling changes apply to.
+func (m *Model) selectMonitor(i int) {
+	if i < 0 || i >= len(m.monitors) {
+		return
 	}
+	m.selectedMonitor = i
+	m.scalingOptions = m.services.ScalingManager.GetIntelligentScalingOptions(m.monitors[i])
+	m.selectedScalingOpt = 0
+}
 
-	return m, nil
+// stagedChange is a monitor's scale waiting to be applied in the same
+// transaction as the other staged monitors.
+type stagedChange struct {
+	Monitor monitor.Monitor
+	Option  monitor.ScalingOption
 }
 
-func (m Model) View() string {
-	if m.width < types.MinTerminalWidth || m.height < types.MinTerminalHeight {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center, lipgloss.Center).
-			Foreground(colorRed).
-			Render(types.ErrTerminalTooSmall)
+// stage queues a scale for mon, replacing anything already staged for it.
+func (m *Model) stage(mon monitor.Monitor, option monitor.ScalingOption) {
+	m.unstage(mon.Name)
+	m.staged = append(m.staged, stagedChange{Monitor: mon, Option: option})
+}
+
+// stagedCopy copies the staged changes, since unstage edits the slice in
+// place and the undo stack keeps earlier versions of it.
+func (m Model) stagedCopy() []stagedChange {
+	return append([]stagedChange(nil), m.staged...)
+}
+
+func (m *Model) unstage(name string) {
+	for i, change := range m.staged {
+		if change.Monitor.Name == name {
+			m.staged = append(m.staged[:i], m.staged[i+1:]...)
+			return
+		}
 	}
+}
 
-	if !m.ready {
-		return lipgloss.NewStyle().
-			Width(m.width).
-			Height(m.height).
-			Align(lipgloss.Center, lipgloss.Center).
-			Foreground(colorBlue).
-			Render("Initializing stunning TUI...")
+func (m Model) stagedFor(name string) (stagedChange, bool) {
+	for _, change := range m.staged {
+		if change.Monitor.Name == name {
+			return change, true
+		}
 	}
+	return stagedChange{}, false
+}
 
-	headerHeight := 7
-	footerHeight := 2
-	contentHeight := m.height - headerHeight - footerHeight - 2
+func (m Model) manualOption() monitor.ScalingOption {
+	return monitor.ScalingOption{
+		MonitorScale: m.manualMonitorScale,
+		GTKScale:     m.manualGTKScale,
+		FontDPI:      m.manualFontDPI,
+		DisplayName:  "Manual Settings",
+		Description:  "Custom scaling values",
+	}
+}
 
-	if contentHeight < 10 {
-		contentHeight = 10
+// pendingTransaction combines the staged monitors, or the drafted ones when
+// applying drafts, with the pending one. The pending option also supplies the
+// global GTK, DPI, font and cursor settings.
+func (m Model) pendingTransaction() monitor.Transaction {
+	option := m.pendingOption
+	tx := monitor.Transaction{
+		GTKScale:   option.GTKScale,
+		FontDPI:    option.FontDPI,
+		FontScale:  option.FontScale,
+		CursorSize: option.CursorSize,
 	}
 
-	var content string
+	changes := m.staged
+	if m.confirmationAction == ConfirmDrafts {
+		changes = m.draftedChanges()
+	}
 
-	switch m.mode {
-	case ModeDashboard:
-		content = m.renderDashboard(contentHeight)
-	case ModeMonitorSelection:
-		content = m.renderMonitorSelection(contentHeight)
-	case ModeScalingOptions:
-		content = m.renderScalingOptions(contentHeight)
-	case ModeManualScaling:
-		content = m.renderManualScaling(contentHeight)
-	case ModeSettings:
-		content = m.renderSettings(contentHeight)
-	case ModeHelp:
-		content = m.renderHelp(contentHeight)
-	case ModeConfirmation:
-		content = m.renderConfirmation(contentHeight)
-	default:
-		content = m.renderDashboard(contentHeight)
+	var parts []string
+	for _, change := range changes {
+		if change.Monitor.Name == m.pendingMonitor.Name {
+			continue
+		}
+		tx.Monitors = append(tx.Monitors, monitor.MonitorTarget{Monitor: change.Monitor, Scale: change.Option.MonitorScale})
+		parts = append(parts, fmt.Sprintf("%s to %s", change.Option.DisplayName, change.Monitor.Name))
 	}
+	tx.Monitors = append(tx.Monitors, monitor.MonitorTarget{Monitor: m.pendingMonitor, Scale: option.MonitorScale})
+	parts = append(parts, fmt.Sprintf("%s to %s", option.DisplayName, m.pendingMonitor.Name))
 
-	header := m.renderHeader()
-	footer := m.renderFooter()
+	tx.Description = "Apply " + strings.Join(parts, ", ")
+	return tx
+}
 
-	styledContent := lipgloss.NewStyle().
-		Width(m.width-4).
-		Height(contentHeight).
-		Margin(1, 2).
-		Render(content)
+// preparePlan computes exactly what confirming the pending change will do so
+// the confirmation screen can show it.
+func (m *Model) preparePlan() {
+	m.pendingPlan, m.pendingPlanErr = m.services.ConfigManager.PlanTransaction(m.pendingTransaction())
+}
 
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		header,
-		styledContent,
-		footer,
-	)
+// executePlan runs a reviewed plan. Demo mode only simulates changes.
+func (m *Model) executePlan(plan monitor.Plan) (monitor.Verification, error) {
+	if m.isDemoMode {
+		return monitor.Verification{}, nil
+	}
+	return m.services.ConfigManager.Execute(plan)
 }
 
-func (m Model) renderHeader() string {
-	availableWidth := m.width - 8
-	leftWidth := availableWidth * 2 / 5
-	rightWidth := availableWidth - leftWidth - 4
+// executeTransaction applies tx, whose plan is plan. With a daemon the
+// daemon applies it, so it records the change and tells its other clients,
+// and the monitors it then reports are checked for drift.
+func (m *Model) executeTransaction(tx monitor.Transaction, plan monitor.Plan, reason string) (monitor.Verification, error) {
+	if m.daemon == nil || m.isDemoMode {
+		return m.executePlan(plan)
+	}
 
-	if leftWidth < 25 {
-		leftWidth = 25
+	req := client.ApplyRequest{
+		Action:     tx.Description,
+		Reason:     reason,
+		GTKScale:   tx.GTKScale,
+		FontDPI:    tx.FontDPI,
+		FontScale:  tx.FontScale,
+		CursorSize: tx.CursorSize,
 	}
-	if rightWidth < 30 {
-		rightWidth = 30
+	for _, target := range tx.Monitors {
+		req.Targets = append(req.Targets, client.Target{Monitor: target.Monitor.Name, Scale: target.Scale})
 	}
 
-	totalHeaderWidth := leftWidth + 2 + rightWidth + 2
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+	if _, err := m.daemon.Apply(ctx, req); err != nil {
+		return monitor.Verification{}, err
+	}
+	doc, err := m.daemon.List(ctx)
+	if err != nil {
+		return monitor.Verification{}, fmt.Errorf("failed to verify monitors: %w", err)
+	}
+	return monitor.Compare(tx.Monitors, documentMonitors(doc))
+}
 
-	return lipgloss.NewStyle().
-		Width(totalHeaderWidth).
-		Background(colorBackground).
-		Foreground(colorBlue).
+// finishApply moves on once the monitors are settled. Smart scaling offers
+// to scale Waybar too; Waybar is only touched after the user has reviewed the
+// diff.
+func (m Model) finishApply() (tea.Model, tea.Cmd) {
+	if m.confirmationAction == ConfirmSmartScaling {
+		if plan, err := m.services.ConfigManager.PlanWaybar(m.pendingOption.FontScale); err == nil && len(plan.ConfigFiles()) > 0 {
+			m.waybarPlan = plan
+			m.waybarErr = nil
+			m.confirmationAction = ConfirmNone
+			m.mode = ModeWaybarPreview
+			return m, nil
+		}
+	}
+	m.confirmationAction = ConfirmNone
+	m.mode = ModeDashboard
+	m.selectedOption = 0
+	return m, nil
+}
+
+// driftChoices lists what the user can do about drift: keep what the
+// compositor picked, or, when a scale was adjusted, switch to the nearest
+// scale that suits the monitor's resolution.
+func (m Model) driftChoices() []string {
+	choices := []string{"Accept the compositor's values"}
+	if len(m.verification.ScaleDrift()) > 0 {
+		choices = append(choices, "Use the nearest valid scale")
+	}
+	return choices
+}
+
+func nearestValidScale(d monitor.Drift) float64 {
+	return utils.NearestValidScale(d.Target.Scale, d.Current.Width, d.Current.Height, types.ValidHyprlandScales)
+}
+
+func (m Model) resolveDrift(choice int) (tea.Model, tea.Cmd) {
+	// Keep the local view in line with what the compositor really did, so
+	// undoing the nearest scale goes back to it
+	for _, live := range m.verification.Monitors {
+		for i := range m.monitors {
+			if m.monitors[i].Name == live.Name {
+				m.monitors[i].Scale = live.Scale
+				m.monitors[i].Width = live.Width
+				m.monitors[i].Height = live.Height
+				m.monitors[i].RefreshRate = live.RefreshRate
+				m.monitors[i].Position = live.Position
+			}
+		}
+	}
+
+	if choice == 1 && len(m.verification.ScaleDrift()) > 0 {
+		tx := monitor.Transaction{Description: "Use the nearest valid scale"}
+		for _, d := range m.verification.ScaleDrift() {
+			tx.Monitors = append(tx.Monitors, monitor.MonitorTarget{Monitor: d.Current, Scale: nearestValidScale(d)})
+		}
+
+		inverse := m.inverse(tx)
+		verification, err := m.applyTransaction(tx, "The compositor adjusted the requested scale")
+		if err != nil {
+			m.driftStatus = fmt.Sprintf("❌ %v", err)
+			return m, nil
+		}
+		for _, target := range tx.Monitors {
+			m.setMonitorScale(target.Monitor.Name, target.Scale)
+		}
+		m.history.push(applyEdit{before: inverse, after: tx})
+		if verification.HasDrift() {
+			// Still not what was asked for; let the user decide again
+			m.verification = verification
+			m.selectedDrift = 0
+			m.driftStatus = "The compositor adjusted the nearest valid scale as well"
+			return m, nil
+		}
+	}
+
+	m.verification = monitor.Verification{}
+	m.driftStatus = ""
+	return m.finishApply()
+}
+
+// applyTransaction plans tx and commits it; reason says why, for history.
+func (m *Model) applyTransaction(tx monitor.Transaction, reason string) (monitor.Verification, error) {
+	plan, err := m.services.ConfigManager.PlanTransaction(tx)
+	if err != nil {
+		return monitor.Verification{}, err
+	}
+	return m.commitTransaction(tx, plan, reason)
+}
+
+// commitTransaction journals the live state, then applies tx, whose plan is
+// plan. A daemon records the changes it applies itself, and nothing is
+// changed when the snapshot can't be taken.
+func (m *Model) commitTransaction(tx monitor.Transaction, plan monitor.Plan, reason string) (monitor.Verification, error) {
+	if m.daemon == nil {
+		if err := m.recordHistory(tx.Description, reason); err != nil {
+			return monitor.Verification{}, err
+		}
+	}
+	return m.executeTransaction(tx, plan, reason)
+}
+
+func (m *Model) setMonitorScale(name string, scale float64) {
+	for i := range m.monitors {
+		if m.monitors[i].Name == name {
+			m.monitors[i].Scale = scale
+		}
+	}
+}
+
+// pendingReason explains the pending change for history.
+func (m Model) pendingReason() string {
+	if m.confirmationAction == ConfirmManualScaling {
+		return fmt.Sprintf("Manual scaling: monitor %.2fx, GTK %dx, font DPI %d",
+			m.pendingOption.MonitorScale, m.pendingOption.GTKScale, m.pendingOption.FontDPI)
+	}
+	return m.pendingOption.Reasoning
+}
+
+// recordHistory journals the live state before a change is applied. A
+// change must not go ahead when this fails, as it couldn't be restored.
+// Demo and test mode change nothing, so there is nothing to record.
+func (m *Model) recordHistory(action, reason string) error {
+	if m.isDemoMode || m.services.Config.IsTestMode || m.services.History == nil {
+		return nil
+	}
+
+	monitors, err := m.services.MonitorDetector.DetectMonitors()
+	if err != nil {
+		monitors = m.monitors
+	}
+
+	if _, err := m.services.History.Record(history.Record{
+		Source:   "tui",
+		Action:   action,
+		Reason:   reason,
+		Monitors: monitors,
+		Paths:    m.services.ConfigManager.ManagedFiles(),
+	}); err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+func (m *Model) loadHistory() {
+	m.historyEntries = nil
+	m.selectedHistory = 0
+	m.historyStatus = ""
+
+	if m.services.History == nil {
+		return
+	}
+
+	entries, err := m.services.History.List()
+	if err != nil {
+		m.historyStatus = fmt.Sprintf("❌ %v", err)
+		return
+	}
+	m.historyEntries = entries
+}
+
+func (m *Model) restoreHistory() {
+	if len(m.historyEntries) == 0 || m.selectedHistory >= len(m.historyEntries) {
+		return
+	}
+	entry := m.historyEntries[m.selectedHistory]
+
+	if m.isDemoMode {
+		m.historyStatus = fmt.Sprintf("📱 Demo: Would restore %s", entry.ID)
+		return
+	}
+
+	var err error
+	if m.daemon != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+		_, err = m.daemon.Restore(ctx, entry.ID)
+		cancel()
+	} else {
+		_, err = m.services.History.Restore(entry.ID, "tui", m.monitors, m.services.ConfigManager.ApplyMonitorScale)
+	}
+	m.loadHistory()
+	if err != nil {
+		m.historyStatus = fmt.Sprintf("❌ Restore failed: %v", err)
+		return
+	}
+
+	for i, mon := range m.monitors {
+		for _, state := range entry.Monitors {
+			if state.Name == mon.Name {
+				m.monitors[i].Scale = state.Scale
+			}
+		}
+	}
+	m.historyStatus = fmt.Sprintf("✅ Restored %s", entry.ID)
+}
+
+// logTickMsg refreshes the log viewer.
+type logTickMsg struct{}
+
+func tickLogs() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return logTickMsg{} })
+}
+
+func (m Model) handleSelection() (tea.Model, tea.Cmd) {
+	switch m.selectedOption {
+	case 0:
+		m.mode = ModeDashboard
+	case 1:
+		m.mode = ModeMonitorSelection
+	case 2:
+		m.mode = ModeScalingOptions
+	case 3:
+		m.mode = ModeManualScaling
+	case 4:
+		m.mode = ModeHistory
+		m.loadHistory()
+	case 5:
+		m.mode = ModeLogs
+		m.logOffset = 0
+		return m, tickLogs()
+	case 6:
+		m.mode = ModeSettings
+	case 7:
+		m.mode = ModeHelp
+	case 8:
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// View draws the current screen and records where its clickable elements
+// landed.
+func (m Model) View() string {
+	return m.zones.Scan(m.render())
+}
+
+func (m Model) render() string {
+	if m.width < types.MinTerminalWidth || m.height < types.MinTerminalHeight {
+		return lipgloss.NewStyle().
+			Width(m.width).
+			Height(m.height).
+			Align(lipgloss.Center, lipgloss.Center).
+			Foreground(m.styles.Red).
+			Render(types.ErrTerminalTooSmall)
+	}
+
+	if m.load != loadReady {
+		return m.renderLoading()
+	}
+
+	header := m.renderHeader()
+	footer := m.renderFooter()
+
+	// The content fills what the header, the footer and its own margin leave,
+	// and scrolls when it needs more
+	contentHeight := m.height - lipgloss.Height(header) - lipgloss.Height(footer) - 2
+	if contentHeight < 6 {
+		contentHeight = 6
+	}
+
+	// The undo panel takes its width from the right of the screen
+	screen := m
+	if m.showsUndoPanel() {
+		screen.width -= undoPanelWidth + 3
+	}
+
+	var content string
+
+	switch m.mode {
+	case ModeDashboard:
+		content = m.renderDashboard(contentHeight)
+	case ModeMonitorSelection:
+		content = screen.renderMonitorSelection(contentHeight)
+	case ModeScalingOptions:
+		content = m.renderScalingOptions(contentHeight)
+	case ModeManualScaling:
+		content = screen.renderManualScaling(contentHeight)
+	case ModeSettings:
+		content = m.renderSettings(contentHeight)
+	case ModeHelp:
+		content = m.renderHelp(contentHeight)
+	case ModeConfirmation:
+		content = m.renderConfirmation(contentHeight)
+	case ModeWaybarPreview:
+		content = m.renderWaybarPreview(contentHeight)
+	case ModeHistory:
+		content = m.renderHistory(contentHeight)
+	case ModeDrift:
+		content = m.renderDrift(contentHeight)
+	case ModeLogs:
+		content = m.renderLogs(contentHeight)
+	case ModeScalingComparison:
+		content = m.renderScalingComparison(contentHeight)
+	default:
+		content = m.renderDashboard(contentHeight)
+	}
+	if m.showsUndoPanel() {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, content, " ", m.renderUndoPanel(contentHeight))
+	}
+
+	styledContent := lipgloss.NewStyle().
+		Width(m.width-4).
+		Height(contentHeight).
+		Margin(1, 2).
+		Render(content)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		styledContent,
+		footer,
+	)
+}
+
+// barPadding is the vertical padding of the header and footer, dropped on
+// short terminals to leave the content more room.
+func (m Model) barPadding() int {
+	if m.height < 30 {
+		return 0
+	}
+	return 1
+}
+
+func (m Model) renderHeader() string {
+	availableWidth := m.width - 8
+	leftWidth := availableWidth * 2 / 5
+	rightWidth := availableWidth - leftWidth - 4
+
+	if leftWidth < 25 {
+		leftWidth = 25
+	}
+	if rightWidth < 30 {
+		rightWidth = 30
+	}
+
+	totalHeaderWidth := leftWidth + 2 + rightWidth + 2
+
+	return lipgloss.NewStyle().
+		Width(totalHeaderWidth).
+		Background(m.styles.Background).
+		Foreground(m.styles.Blue).
 		Bold(true).
 		Align(lipgloss.Center).
-		Padding(1, 2).
+		Padding(m.barPadding(), 2).
 		Margin(0, 2).
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorComment).
+		BorderForeground(m.styles.Comment).
 		Render("Display Settings")
 }
 
+// renderLoading shows detection progress, or why it failed and what can be
+// done about it.
+func (m Model) renderLoading() string {
+	var content []string
+
+	if m.load == loadDetecting {
+		content = append(content,
+			lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render(m.spinner()+" Detecting monitors..."),
+			"",
+			lipgloss.NewStyle().Foreground(m.styles.Comment).Render(m.services.Mode.Reason),
+		)
+	} else {
+		keyStyle := lipgloss.NewStyle().Bold(true)
+		content = append(content,
+			m.styles.Error.Render("✗ Monitor detection failed"),
+			"",
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Width(m.width*2/3).Render(m.loadErr.Error()),
+			"",
+			keyStyle.Foreground(m.styles.Green).Render(keymap.Short(m.keys.Refresh))+lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" retry"),
+		)
+		if _, ok := m.fallbackMonitors(); ok && len(m.keys.Demo.Keys()) > 0 {
+			content = append(content,
+				keyStyle.Foreground(m.styles.Yellow).Render(keymap.Short(m.keys.Demo))+lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" continue with demo monitors"))
+		}
+		content = append(content,
+			keyStyle.Foreground(m.styles.Red).Render(keymap.Short(m.keys.Quit))+lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" quit"))
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Render(lipgloss.JoinVertical(lipgloss.Center, content...))
+}
+
 func (m Model) renderFooter() string {
 	keyStyle := lipgloss.NewStyle().
-		Background(colorBackground).
-		Foreground(colorBlue).
+		Background(m.styles.Background).
+		Foreground(m.styles.Blue).
 		Bold(true).
 		Padding(0, 1).
 		Margin(0, 1)
 
 	textStyle := lipgloss.NewStyle().
-		Foreground(colorSubtle)
+		Foreground(m.styles.Subtle)
 
 	controls := []string{
-		keyStyle.Foreground(colorGreen).Render("↑↓") + textStyle.Render("navigate"),
-		keyStyle.Foreground(colorBlue).Render("⏎") + textStyle.Render("select"),
-		keyStyle.Foreground(colorYellow).Render("h") + textStyle.Render("help"),
-		keyStyle.Foreground(colorMagenta).Render("esc") + textStyle.Render("back"),
-		keyStyle.Foreground(colorRed).Render("q") + textStyle.Render("quit"),
+		keyStyle.Foreground(m.styles.Green).Render(keymap.Pair(m.keys.Up, m.keys.Down)) + textStyle.Render("navigate"),
+		keyStyle.Foreground(m.styles.Blue).Render(keymap.Short(m.keys.Select)) + textStyle.Render("select"),
+		keyStyle.Foreground(m.styles.Yellow).Render(keymap.Short(m.keys.Help)) + textStyle.Render("help"),
+		keyStyle.Foreground(m.styles.Magenta).Render(keymap.Short(m.keys.Back)) + textStyle.Render("back"),
+		keyStyle.Foreground(m.styles.Red).Render(keymap.Short(m.keys.Quit)) + textStyle.Render("quit"),
 	}
 
 	helpText := strings.Join(controls, "  ")
@@ -610,12 +1447,12 @@ func (m Model) renderFooter() string {
 
 	return lipgloss.NewStyle().
 		Width(totalFooterWidth).
-		Background(colorBackground).
+		Background(m.styles.Background).
 		Align(lipgloss.Center).
-		Padding(1, 2).
+		Padding(m.barPadding(), 2).
 		Margin(0, 2).
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorComment).
+		BorderForeground(m.styles.Comment).
 		Render(helpText)
 }
 
@@ -633,16 +1470,17 @@ func (m Model) renderDashboard(contentHeight int) string {
 
 	var leftPanel []string
 	var rightPanel []string
+	var menuFocus, monitorFocus span
 
 	leftPanel = append(leftPanel,
 		lipgloss.NewStyle().
-			Foreground(colorBlue).
+			Foreground(m.styles.Blue).
 			Bold(true).
 			Render("Navigation"),
 	)
 	leftPanel = append(leftPanel, "")
 
-	menuColors := []lipgloss.Color{colorBlue, colorCyan, colorGreen, colorYellow, colorRed, colorMagenta}
+	menuColors := []lipgloss.Color{m.styles.Blue, m.styles.Cyan, m.styles.Green, m.styles.Yellow, m.styles.Red, m.styles.Magenta}
 
 	for i, item := range m.menuItems {
 		color := menuColors[i%len(menuColors)]
@@ -660,44 +1498,43 @@ func (m Model) renderDashboard(contentHeight int) string {
 			line = fmt.Sprintf("%s %s", selector, text)
 		} else {
 			text := lipgloss.NewStyle().
-				Foreground(colorSubtle).
+				Foreground(m.styles.Subtle).
 				Render(item)
 			line = fmt.Sprintf("  %s", text)
 		}
-		leftPanel = append(leftPanel, line)
+		if i == m.selectedOption {
+			menuFocus = span{len(leftPanel), len(leftPanel) + 1}
+		}
+		leftPanel = append(leftPanel, m.row(zoneMenu, i, leftWidth-4, line))
 		leftPanel = append(leftPanel, "")
 	}
 
 	rightPanel = append(rightPanel,
 		lipgloss.NewStyle().
-			Foreground(colorCyan).
+			Foreground(m.styles.Cyan).
 			Bold(true).
 			Render("Display Overview"),
 	)
 	rightPanel = append(rightPanel, "")
 
-	monitorColors := []lipgloss.Color{colorGreen, colorBlue, colorYellow, colorMagenta}
+	monitorColors := []lipgloss.Color{m.styles.Green, m.styles.Blue, m.styles.Yellow, m.styles.Magenta}
 
 	for i, monitor := range m.monitors {
-		if i >= len(monitorColors) {
-			break
-		}
-
-		color := monitorColors[i]
+		color := monitorColors[i%len(monitorColors)]
 
 		var statusIcon string
 		var statusStyle lipgloss.Style
 		if monitor.IsActive {
 			if monitor.IsPrimary {
 				statusIcon = "●"
-				statusStyle = lipgloss.NewStyle().Foreground(colorGreen)
+				statusStyle = lipgloss.NewStyle().Foreground(m.styles.Green)
 			} else {
 				statusIcon = "○"
-				statusStyle = lipgloss.NewStyle().Foreground(colorBlue)
+				statusStyle = lipgloss.NewStyle().Foreground(m.styles.Blue)
 			}
 		} else {
 			statusIcon = "◦"
-			statusStyle = lipgloss.NewStyle().Foreground(colorComment)
+			statusStyle = lipgloss.NewStyle().Foreground(m.styles.Comment)
 		}
 
 		header := fmt.Sprintf("%s %s",
@@ -707,55 +1544,66 @@ func (m Model) renderDashboard(contentHeight int) string {
 
 		if i == m.selectedMonitor {
 			selectedIndicator := lipgloss.NewStyle().
-				Foreground(colorYellow).
+				Foreground(m.styles.Yellow).
 				Bold(true).
 				Render(" 👆 CURRENT")
 			header = header + selectedIndicator
 		}
+		if m.modified(monitor.Name) {
+			header += lipgloss.NewStyle().Foreground(m.styles.Magenta).Bold(true).Render(" ✎ modified")
+		}
 
 		details := []string{
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(fmt.Sprintf("  %s %s", monitor.Make, monitor.Model)),
-			lipgloss.NewStyle().Foreground(colorComment).Render(fmt.Sprintf("  %s @ %.0fHz", utils.FormatResolution(monitor.Width, monitor.Height), monitor.RefreshRate)),
-			lipgloss.NewStyle().Foreground(colorComment).Render(fmt.Sprintf("  Scale: %.1fx", monitor.Scale)),
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(fmt.Sprintf("  %s %s", monitor.Make, monitor.Model)),
+			lipgloss.NewStyle().Foreground(m.styles.Comment).Render(fmt.Sprintf("  %s @ %.0fHz", utils.FormatResolution(monitor.Width, monitor.Height), monitor.RefreshRate)),
+			lipgloss.NewStyle().Foreground(m.styles.Comment).Render(fmt.Sprintf("  Scale: %.1fx", monitor.Scale)),
+		}
+		if draft, ok := m.draftFor(monitor); ok {
+			details = append(details, lipgloss.NewStyle().Foreground(m.styles.Magenta).Render(
+				fmt.Sprintf("  Draft: %.2fx · GTK %dx · %d DPI", draft.Scale, draft.GTK, draft.DPI)))
 		}
 
 		if i == m.selectedMonitor {
 			details = append(details, lipgloss.NewStyle().
-				Foreground(colorYellow).
+				Foreground(m.styles.Yellow).
 				Italic(true).
 				Render("  → Scaling changes will apply here"))
 		}
 
+		if i == m.selectedMonitor {
+			monitorFocus = span{len(rightPanel), len(rightPanel) + len(details) + 1}
+		}
 		rightPanel = append(rightPanel, header)
 		rightPanel = append(rightPanel, details...)
 		rightPanel = append(rightPanel, "")
 	}
 
+	if len(m.drafts) > 0 {
+		rightPanel = append(rightPanel, m.draftsHint())
+		rightPanel = append(rightPanel, "")
+	}
+
 	if m.isDemoMode {
 		demoNotice := lipgloss.NewStyle().
-			Foreground(colorYellow).
+			Foreground(m.styles.Yellow).
 			Italic(true).
 			Render("📱 Demo Mode Active")
 		rightPanel = append(rightPanel, demoNotice)
 	}
 
-	leftContent := lipgloss.NewStyle().
-		Width(leftWidth).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorBlue).
-		Render(strings.Join(leftPanel, "\n"))
-
-	rightContent := lipgloss.NewStyle().
-		Width(rightWidth).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorCyan).
-		Render(strings.Join(rightPanel, "\n"))
+	switch {
+	case m.refreshing:
+		rightPanel = append(rightPanel, lipgloss.NewStyle().
+			Foreground(m.styles.Blue).
+			Render(m.spinner()+" Refreshing monitors..."))
+	case m.loadErr != nil:
+		rightPanel = append(rightPanel, m.styles.Error.Render("Refresh failed: "+m.loadErr.Error()))
+	case m.refreshStatus != "":
+		rightPanel = append(rightPanel, m.styles.Success.Render(m.refreshStatus))
+	}
+
+	leftContent := m.panel(panelMenu, leftWidth, contentHeight, m.styles.Blue, leftPanel, menuFocus)
+	rightContent := m.panel(panelMain, rightWidth, contentHeight, m.styles.Cyan, rightPanel, monitorFocus)
 
 	return lipgloss.JoinHorizontal(
 		lipgloss.Top,
@@ -767,46 +1615,51 @@ func (m Model) renderDashboard(contentHeight int) string {
 
 func (m Model) renderMonitorSelection(contentHeight int) string {
 	var content []string
+	var focus span
 
 	title := lipgloss.NewStyle().
-		Foreground(colorBlue).
+		Foreground(m.styles.Blue).
 		Bold(true).
 		Render("Monitor Selection")
 
 	subtitle := lipgloss.NewStyle().
-		Foreground(colorSubtle).
+		Foreground(m.styles.Subtle).
 		Render("Choose a display to configure")
 
 	content = append(content, title)
 	content = append(content, subtitle)
 	content = append(content, "")
 
-	monitorColors := []lipgloss.Color{colorGreen, colorBlue, colorYellow, colorMagenta}
+	monitorColors := []lipgloss.Color{m.styles.Green, m.styles.Blue, m.styles.Yellow, m.styles.Magenta}
 
 	for i, monitor := range m.monitors {
-		if i >= len(monitorColors) {
-			break
-		}
-
-		color := monitorColors[i]
+		color := monitorColors[i%len(monitorColors)]
 
 		var statusText string
 		var statusStyle lipgloss.Style
 		if monitor.IsActive {
 			if monitor.IsPrimary {
 				statusText = "PRIMARY"
-				statusStyle = lipgloss.NewStyle().Background(colorGreen).Foreground(colorBackground).Bold(true).Padding(0, 1)
+				statusStyle = lipgloss.NewStyle().Background(m.styles.Green).Foreground(m.styles.Background).Bold(true).Padding(0, 1)
 			} else {
 				statusText = "ACTIVE"
-				statusStyle = lipgloss.NewStyle().Background(colorBlue).Foreground(colorBackground).Bold(true).Padding(0, 1)
+				statusStyle = lipgloss.NewStyle().Background(m.styles.Blue).Foreground(m.styles.Background).Bold(true).Padding(0, 1)
 			}
 		} else {
 			statusText = "INACTIVE"
-			statusStyle = lipgloss.NewStyle().Background(colorComment).Foreground(colorBackground).Bold(true).Padding(0, 1)
+			statusStyle = lipgloss.NewStyle().Background(m.styles.Comment).Foreground(m.styles.Background).Bold(true).Padding(0, 1)
 		}
 
 		nameStyle := lipgloss.NewStyle().Foreground(color).Bold(true)
-		detailStyle := lipgloss.NewStyle().Foreground(colorSubtle)
+		detailStyle := lipgloss.NewStyle().Foreground(m.styles.Subtle)
+
+		var badge string
+		if change, ok := m.stagedFor(monitor.Name); ok {
+			badge = " " + lipgloss.NewStyle().Foreground(m.styles.Yellow).Bold(true).Render(fmt.Sprintf("● staged %.2fx", change.Option.MonitorScale))
+		}
+		if draft, ok := m.draftFor(monitor); ok {
+			badge += " " + lipgloss.NewStyle().Foreground(m.styles.Magenta).Bold(true).Render(fmt.Sprintf("✎ draft %.2fx", draft.Scale))
+		}
 
 		var card string
 		if i == m.selectedMonitor {
@@ -814,55 +1667,65 @@ func (m Model) renderMonitorSelection(contentHeight int) string {
 			card = fmt.Sprintf("%s%s %s\n  %s\n  %s",
 				selector,
 				nameStyle.Render(monitor.Name),
-				statusStyle.Render(statusText),
+				statusStyle.Render(statusText)+badge,
 				detailStyle.Render(fmt.Sprintf("%s %s", monitor.Make, monitor.Model)),
 				detailStyle.Render(fmt.Sprintf("%s @ %.0fHz", utils.FormatResolution(monitor.Width, monitor.Height), monitor.RefreshRate)),
 			)
 		} else {
 			card = fmt.Sprintf("  %s %s\n    %s\n    %s",
 				nameStyle.Render(monitor.Name),
-				statusStyle.Render(statusText),
+				statusStyle.Render(statusText)+badge,
 				detailStyle.Render(fmt.Sprintf("%s %s", monitor.Make, monitor.Model)),
 				detailStyle.Render(fmt.Sprintf("%s @ %.0fHz", utils.FormatResolution(monitor.Width, monitor.Height), monitor.RefreshRate)),
 			)
 		}
 
-		content = append(content, card)
+		if i == m.selectedMonitor {
+			focus = span{len(content), len(content) + 1}
+		}
+		content = append(content, m.row(zoneMonitor, i, m.width-12, card))
 		content = append(content, "")
 	}
 
 	instructions := []string{
-		lipgloss.NewStyle().Foreground(colorYellow).Render("⏎") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" Select monitor and return to dashboard"),
-		lipgloss.NewStyle().Foreground(colorMagenta).Render("esc") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" Return to main menu"),
+		lipgloss.NewStyle().Foreground(m.styles.Yellow).Render("⏎") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" Select monitor and return to dashboard"),
+		lipgloss.NewStyle().Foreground(m.styles.Magenta).Render("esc") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" Return to main menu"),
+	}
+	if len(m.staged) > 0 {
+		instructions = append(instructions, lipgloss.NewStyle().Foreground(m.styles.Red).Render("x")+
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" Unstage"))
 	}
 
 	note := lipgloss.NewStyle().
-		Foreground(colorComment).
+		Foreground(m.styles.Comment).
 		Italic(true).
 		Render("💡 Selected monitor will be marked as CURRENT on the dashboard")
+	if len(m.staged) > 0 {
+		note = lipgloss.NewStyle().
+			Foreground(m.styles.Yellow).
+			Italic(true).
+			Render(fmt.Sprintf("📦 %d staged - pick the next monitor, then choose its scaling and press ⏎ to apply all at once", len(m.staged)))
+	}
 
 	content = append(content, "")
 	content = append(content, strings.Join(instructions, "  "))
+	if len(m.drafts) > 0 {
+		content = append(content, m.draftsHint())
+	}
 	content = append(content, "")
 	content = append(content, note)
 
-	return lipgloss.NewStyle().
-		Width(m.width - 8).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorBlue).
-		Render(strings.Join(content, "\n"))
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Blue, content, focus)
 }
 
 func (m Model) renderScalingOptions(contentHeight int) string {
 	var content []string
+	var focus span
 
 	title := lipgloss.NewStyle().
-		Foreground(colorGreen).
+		Foreground(m.styles.Green).
 		Bold(true).
 		Render("🧠 Smart Scaling Recommendations")
 
@@ -877,17 +1740,17 @@ func (m Model) renderScalingOptions(contentHeight int) string {
 			selectedMonitor.Width, selectedMonitor.Height, selectedMonitor.RefreshRate)
 
 		monitorCard := lipgloss.NewStyle().
-			Background(colorSurface).
-			Foreground(colorForeground).
+			Background(m.styles.Surface).
+			Foreground(m.styles.Foreground).
 			Padding(1, 2).
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorYellow).
+			BorderForeground(m.styles.Yellow).
 			Render(monitorInfo)
 
 		content = append(content, monitorCard)
 		content = append(content, "")
 
-		recTitle := lipgloss.NewStyle().Foreground(colorCyan).Bold(true).Render("🎯 Available Options")
+		recTitle := lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true).Render("🎯 Available Options")
 		content = append(content, recTitle)
 		content = append(content, "")
 
@@ -895,7 +1758,7 @@ func (m Model) renderScalingOptions(contentHeight int) string {
 			var line string
 
 			if i == m.selectedScalingOpt {
-				line = lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render("▶ ")
+				line = lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("▶ ")
 			} else {
 				line = "  "
 			}
@@ -906,45 +1769,51 @@ func (m Model) renderScalingOptions(contentHeight int) string {
 			}
 
 			if i == m.selectedScalingOpt {
-				line += lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render(optionName)
+				line += lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render(optionName)
 			} else {
-				line += lipgloss.NewStyle().Foreground(colorForeground).Render(optionName)
+				line += lipgloss.NewStyle().Foreground(m.styles.Foreground).Render(optionName)
 			}
 
-			content = append(content, line)
-
 			description := fmt.Sprintf("    %s", option.Description)
-			content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(description))
 
 			details := fmt.Sprintf("    Monitor: %.1fx • GTK: %dx • Font DPI: %d • Result: %dx%d",
 				option.MonitorScale, option.GTKScale, option.FontDPI,
 				option.EffectiveWidth, option.EffectiveHeight)
-			content = append(content, lipgloss.NewStyle().Foreground(colorComment).Render(details))
 
 			reasoning := fmt.Sprintf("    💡 %s", option.Reasoning)
-			content = append(content, lipgloss.NewStyle().Foreground(colorComment).Italic(true).Render(reasoning))
+
+			if i == m.selectedScalingOpt {
+				focus = span{len(content), len(content) + 1}
+			}
+			content = append(content, m.row(zoneOption, i, m.width-12, strings.Join([]string{
+				line,
+				lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(description),
+				lipgloss.NewStyle().Foreground(m.styles.Comment).Render(details),
+				lipgloss.NewStyle().Foreground(m.styles.Comment).Italic(true).Render(reasoning),
+			}, "\n")))
 
 			content = append(content, "")
 		}
 
 		content = append(content, "")
-		explainTitle := lipgloss.NewStyle().Foreground(colorMagenta).Bold(true).Render("📚 What Each Setting Does")
+		explainTitle := lipgloss.NewStyle().Foreground(m.styles.Magenta).Bold(true).Render("📚 What Each Setting Does")
 		content = append(content, explainTitle)
 
 		explainItems := []string{
 			"Monitor Scale: Changes compositor-level scaling (immediate effect)",
 			"GTK Scale: Scales GTK applications (requires logout/login)",
 			"Font DPI: Fine-grained text scaling (affects most apps)",
+			"Cursor Size: Matches the pointer to the scale (Hyprland, XWayland, GTK)",
 		}
 
 		for _, item := range explainItems {
-			content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render("  "+item))
+			content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render("  "+item))
 		}
 
 		if m.isDemoMode {
 			content = append(content, "")
 			demoNotice := lipgloss.NewStyle().
-				Foreground(colorYellow).
+				Foreground(m.styles.Yellow).
 				Render("📱 Demo Mode: Changes will be simulated")
 			content = append(content, demoNotice)
 		}
@@ -952,144 +1821,444 @@ func (m Model) renderScalingOptions(contentHeight int) string {
 
 	content = append(content, "")
 	instructions := []string{
-		lipgloss.NewStyle().Foreground(colorGreen).Render("↑↓") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" select"),
-		lipgloss.NewStyle().Foreground(colorBlue).Render("⏎") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" apply"),
-		lipgloss.NewStyle().Foreground(colorYellow).Render("m") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" manual"),
-		lipgloss.NewStyle().Foreground(colorMagenta).Render("esc") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" back"),
+		lipgloss.NewStyle().Foreground(m.styles.Green).Render("↑↓") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" select"),
+		lipgloss.NewStyle().Foreground(m.styles.Blue).Render("⏎") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" apply"),
+		lipgloss.NewStyle().Foreground(m.styles.Yellow).Render("m") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" manual"),
+		lipgloss.NewStyle().Foreground(m.styles.Cyan).Render("c") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" compare"),
+		lipgloss.NewStyle().Foreground(m.styles.Cyan).Render("s") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" stage"),
+		lipgloss.NewStyle().Foreground(m.styles.Magenta).Render("esc") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" back"),
 	}
 
 	content = append(content, strings.Join(instructions, "  "))
 
-	return lipgloss.NewStyle().
-		Width(m.width - 8).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorGreen).
-		Render(strings.Join(content, "\n"))
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Green, content, focus)
 }
 
-func (m Model) renderManualScaling(contentHeight int) string {
-	var content []string
+// Rough logical sizes used by the scaling comparison: an 80x24 terminal with
+// an 11pt monospace font, and a typical browser window.
+const (
+	terminalWindowWidth  = 80*8 + 16
+	terminalWindowHeight = 24*17 + 16
+	browserWindowWidth   = 1280
+)
 
-	title := lipgloss.NewStyle().
-		Foreground(colorMagenta).
-		Bold(true).
-		Render("🔧 Manual Scaling Controls")
+// scaleComparison describes the desktop a monitor would have at one scale.
+type scaleComparison struct {
+	Name            string
+	Scale           float64
+	EffectiveWidth  int
+	EffectiveHeight int
+	// Terminals is how many 80x24 terminals tile the desktop
+	Terminals int
+	// BrowserShare is the fraction of the width a 1280px browser takes; more
+	// than 1 means it doesn't fit
+	BrowserShare float64
+	// RealEstate is the percentage of the native resolution left
+	RealEstate float64
+	// TextSize is how big text is compared to the current scale
+	TextSize float64
+}
 
-	content = append(content, title)
-	content = append(content, "")
+// compareScales returns the monitor's current desktop followed by one entry
+// per scaling option.
+func compareScales(mon monitor.Monitor, options []monitor.ScalingOption) []scaleComparison {
+	currentScale := mon.Scale
+	if currentScale <= 0 {
+		currentScale = 1
+	}
+	width, height := utils.CalculateEffectiveResolution(mon.Width, mon.Height, currentScale)
+
+	entry := func(name string, scale float64, width, height int) scaleComparison {
+		c := scaleComparison{
+			Name:            name,
+			Scale:           scale,
+			EffectiveWidth:  width,
+			EffectiveHeight: height,
+			Terminals:       (width / terminalWindowWidth) * (height / terminalWindowHeight),
+			RealEstate:      utils.CalculateScreenRealEstate(scale),
+			TextSize:        scale / currentScale,
+		}
+		if width > 0 {
+			c.BrowserShare = float64(browserWindowWidth) / float64(width)
+		}
+		return c
+	}
 
-	if len(m.monitors) == 0 {
-		content = append(content, lipgloss.NewStyle().
-			Foreground(colorRed).
-			Render("No monitors detected. Please go back and check monitor selection."))
-		return lipgloss.NewStyle().
-			Width(m.width - 8).
-			Height(contentHeight - 2).
-			Padding(2).
-			Background(colorBackground).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorMagenta).
-			Render(strings.Join(content, "\n"))
+	comparisons := []scaleComparison{entry("Current", currentScale, width, height)}
+	for _, option := range options {
+		comparisons = append(comparisons, entry(option.DisplayName, option.MonitorScale, option.EffectiveWidth, option.EffectiveHeight))
 	}
+	return comparisons
+}
 
-	if m.selectedMonitor >= len(m.monitors) {
-		content = append(content, lipgloss.NewStyle().
-			Foreground(colorRed).
-			Render("Invalid monitor selection. Please go back and select a monitor."))
-		return lipgloss.NewStyle().
-			Width(m.width - 8).
-			Height(contentHeight - 2).
-			Padding(2).
-			Background(colorBackground).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorMagenta).
-			Render(strings.Join(content, "\n"))
+// renderDesktopMock draws a desktop of c's effective size at pxPerCell
+// logical pixels per column, with a row of terminals along the top and a
+// browser window below. Rows are twice as tall as columns are wide.
+func (m Model) renderDesktopMock(c scaleComparison, pxPerCell float64, maxRows int) string {
+	cols := int(float64(c.EffectiveWidth) / pxPerCell)
+	rows := int(float64(c.EffectiveHeight) / pxPerCell / 2)
+	if cols < 4 {
+		cols = 4
+	}
+	if rows < 3 {
+		rows = 3
+	}
+	if rows > maxRows {
+		rows = maxRows
 	}
 
-	selectedMonitor := m.monitors[m.selectedMonitor]
+	terminalCols := int(math.Round(float64(terminalWindowWidth)/pxPerCell)) - 1
+	terminalRows := int(float64(terminalWindowHeight) / pxPerCell / 2)
+	if terminalCols < 1 {
+		terminalCols = 1
+	}
+	if terminalRows < 1 {
+		terminalRows = 1
+	}
+	if terminalRows > rows-1 {
+		terminalRows = rows - 1
+	}
 
-	monitorInfo := fmt.Sprintf("%s %s %s - %dx%d@%.0fHz (Current: %.2fx)",
-		selectedMonitor.Name, selectedMonitor.Make, selectedMonitor.Model,
-		selectedMonitor.Width, selectedMonitor.Height, selectedMonitor.RefreshRate, selectedMonitor.Scale)
+	// Draw as many terminals as really fit, which rounding to cells could
+	// otherwise change, and none on a desktop too narrow for one. Rounding
+	// can still make the row a cell or two wider than the desktop, so it is
+	// cut to fit.
+	terminalsAcross := c.EffectiveWidth / terminalWindowWidth
+	terminalRow := []rune(strings.Repeat(strings.Repeat("▒", terminalCols)+" ", terminalsAcross))
+	if len(terminalRow) > cols {
+		terminalRow = terminalRow[:cols]
+	}
+	terminalLine := lipgloss.NewStyle().Foreground(m.styles.Green).Render(string(terminalRow))
 
-	monitorCard := lipgloss.NewStyle().
-		Background(colorSurface).
-		Foreground(colorForeground).
-		Padding(1, 2).
+	browserCols := int(float64(browserWindowWidth) / pxPerCell)
+	browserColor := m.styles.Blue
+	if browserCols > cols {
+		browserCols = cols
+		browserColor = m.styles.Red
+	}
+	browserLine := lipgloss.NewStyle().Foreground(browserColor).Render(strings.Repeat("░", browserCols))
+
+	lines := make([]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		if i < terminalRows {
+			lines = append(lines, terminalLine)
+		} else {
+			lines = append(lines, browserLine)
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(cols).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(m.styles.Comment).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderComparisonColumn describes one scale, highlighting what differs from
+// the current one.
+func (m Model) renderComparisonColumn(c, current scaleComparison, isCurrent, selected bool, mock string, mockHeight, width int) string {
+	label := lipgloss.NewStyle().Foreground(m.styles.Subtle)
+	same := lipgloss.NewStyle().Foreground(m.styles.Foreground)
+	better := lipgloss.NewStyle().Foreground(m.styles.Green).Bold(true)
+	worse := lipgloss.NewStyle().Foreground(m.styles.Yellow).Bold(true)
+
+	// highlight marks a value that differs from the current scale, green when
+	// it gives more room
+	highlight := func(text string, diff float64) string {
+		switch {
+		case isCurrent || diff == 0:
+			return same.Render(text)
+		case diff > 0:
+			return better.Render(text)
+		default:
+			return worse.Render(text)
+		}
+	}
+
+	titleColor := m.styles.Foreground
+	if selected {
+		titleColor = m.styles.Blue
+	}
+	name := lipgloss.NewStyle().Foreground(titleColor).Bold(true).Render(c.Name)
+	if !isCurrent && c.Scale == current.Scale {
+		name += label.Render(" • current")
+	}
+	lines := []string{
+		name,
+		label.Render(fmt.Sprintf("%.2fx • %s", c.Scale, utils.FormatResolution(c.EffectiveWidth, c.EffectiveHeight))),
+		"",
+		// Pad every mock to the tallest so the figures below line up
+		lipgloss.NewStyle().Height(mockHeight).Render(mock),
+		"",
+	}
+
+	terminals := fmt.Sprintf("%d", c.Terminals)
+	if !isCurrent && c.Terminals != current.Terminals {
+		terminals += fmt.Sprintf(" (%+d)", c.Terminals-current.Terminals)
+	}
+	lines = append(lines, label.Render("Terminals  ")+highlight(terminals, float64(c.Terminals-current.Terminals)))
+
+	browser := fmt.Sprintf("%.0f%% wide", c.BrowserShare*100)
+	if c.BrowserShare > 1 {
+		browser = "doesn't fit"
+	}
+	lines = append(lines, label.Render("Browser    ")+highlight(browser, current.BrowserShare-c.BrowserShare))
+
+	lines = append(lines, label.Render("Space      ")+highlight(fmt.Sprintf("%.0f%% of native", c.RealEstate), c.RealEstate-current.RealEstate))
+
+	text := fmt.Sprintf("%.0f%%", c.TextSize*100)
+	switch {
+	case isCurrent:
+		text += " (now)"
+	case c.TextSize > current.TextSize:
+		text += " larger"
+	case c.TextSize < current.TextSize:
+		text += " smaller"
+	}
+	// Larger text isn't better or worse, just different
+	textStyle := same
+	if !isCurrent && c.TextSize != current.TextSize {
+		textStyle = lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true)
+	}
+	lines = append(lines, label.Render("Text       ")+textStyle.Render(text))
+
+	borderColor := m.styles.Comment
+	if selected {
+		borderColor = m.styles.Blue
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		Padding(0, 1).
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorYellow).
+		BorderForeground(borderColor).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderScalingComparison shows the current scale and the candidates side by
+// side, each with a mock of the desktop drawn to the same scale.
+func (m Model) renderScalingComparison(contentHeight int) string {
+	var content []string
+
+	title := lipgloss.NewStyle().
+		Foreground(m.styles.Green).
+		Bold(true).
+		Render("🔍 Compare Scaling Options")
+	content = append(content, title)
+	content = append(content, "")
+
+	if len(m.monitors) > 0 && m.selectedMonitor < len(m.monitors) && len(m.scalingOptions) > 0 {
+		selectedMonitor := m.monitors[m.selectedMonitor]
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(
+			fmt.Sprintf("%s %s %s - %s", selectedMonitor.Name, selectedMonitor.Make, selectedMonitor.Model,
+				utils.FormatResolution(selectedMonitor.Width, selectedMonitor.Height))))
+		content = append(content, "")
+
+		comparisons := compareScales(selectedMonitor, m.scalingOptions)
+
+		// Show the current scale and as many candidates as fit, keeping the
+		// selected one in view
+		available := m.width - 8 - 6
+		columnWidth := 26
+		visible := available/(columnWidth+4) - 1
+		if visible < 1 {
+			visible = 1
+		}
+		if visible > len(m.scalingOptions) {
+			visible = len(m.scalingOptions)
+		}
+		if extra := available/(visible+1) - 4; extra > columnWidth {
+			columnWidth = extra
+		}
+		first := 0
+		if m.selectedScalingOpt >= visible {
+			first = m.selectedScalingOpt - visible + 1
+		}
+
+		// Every mock uses the same pixels per cell, so the widest desktop
+		// fills its column
+		widest := 1
+		for _, c := range comparisons {
+			if c.EffectiveWidth > widest {
+				widest = c.EffectiveWidth
+			}
+		}
+		pxPerCell := float64(widest) / float64(columnWidth-4)
+		maxRows := contentHeight - 24
+		if maxRows < 3 {
+			maxRows = 3
+		}
+
+		shown := append([]scaleComparison{comparisons[0]}, comparisons[first+1:first+visible+1]...)
+		mocks := make([]string, len(shown))
+		mockHeight := 0
+		for i, c := range shown {
+			mocks[i] = m.renderDesktopMock(c, pxPerCell, maxRows)
+			if h := lipgloss.Height(mocks[i]); h > mockHeight {
+				mockHeight = h
+			}
+		}
+
+		current := comparisons[0]
+		columns := []string{m.renderComparisonColumn(current, current, true, false, mocks[0], mockHeight, columnWidth)}
+		for i, c := range shown[1:] {
+			column := m.renderComparisonColumn(c, current, false, first+i == m.selectedScalingOpt,
+				mocks[i+1], mockHeight, columnWidth)
+			columns = append(columns, m.zones.Mark(zoneID(zoneOption, first+i), column))
+		}
+		content = append(content, lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+
+		if visible < len(m.scalingOptions) {
+			content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+				fmt.Sprintf("Showing %d-%d of %d options", first+1, first+visible, len(m.scalingOptions))))
+		}
+
+		content = append(content, "")
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Italic(true).Render(
+			"▒ 80x24 terminals  ░ 1280px browser  • green: more room, yellow: less"))
+	}
+
+	content = append(content, "")
+	instructions := []string{
+		lipgloss.NewStyle().Foreground(m.styles.Green).Render("←→") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" select"),
+		lipgloss.NewStyle().Foreground(m.styles.Blue).Render("⏎") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" apply"),
+		lipgloss.NewStyle().Foreground(m.styles.Magenta).Render("esc") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" back"),
+	}
+	content = append(content, strings.Join(instructions, "  "))
+
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Green, content, span{})
+}
+
+func (m Model) renderManualScaling(contentHeight int) string {
+	var content []string
+	var focus span
+
+	title := lipgloss.NewStyle().
+		Foreground(m.styles.Magenta).
+		Bold(true).
+		Render("🔧 Manual Scaling Controls")
+
+	content = append(content, title)
+	content = append(content, "")
+
+	if len(m.monitors) == 0 {
+		content = append(content, lipgloss.NewStyle().
+			Foreground(m.styles.Red).
+			Render("No monitors detected. Please go back and check monitor selection."))
+		return m.panel(panelMain, m.width-8, contentHeight, m.styles.Magenta, content, span{})
+	}
+
+	if m.selectedMonitor >= len(m.monitors) {
+		content = append(content, lipgloss.NewStyle().
+			Foreground(m.styles.Red).
+			Render("Invalid monitor selection. Please go back and select a monitor."))
+		return m.panel(panelMain, m.width-8, contentHeight, m.styles.Magenta, content, span{})
+	}
+
+	selectedMonitor := m.monitors[m.selectedMonitor]
+
+	monitorInfo := fmt.Sprintf("%s %s %s - %dx%d@%.0fHz (Current: %.2fx)",
+		selectedMonitor.Name, selectedMonitor.Make, selectedMonitor.Model,
+		selectedMonitor.Width, selectedMonitor.Height, selectedMonitor.RefreshRate, selectedMonitor.Scale)
+
+	monitorCard := lipgloss.NewStyle().
+		Background(m.styles.Surface).
+		Foreground(m.styles.Foreground).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.styles.Yellow).
 		Render(monitorInfo)
 
 	content = append(content, monitorCard)
+	if m.modified(selectedMonitor.Name) {
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Magenta).Italic(true).Render(
+			"✎ Draft for "+selectedMonitor.Name+" - kept when you switch monitors"))
+	}
 	content = append(content, "")
 
-	controlsTitle := lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render("⚙️ Scaling Controls")
+	controlsTitle := lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("⚙️ Scaling Controls")
 	content = append(content, controlsTitle)
 	content = append(content, "")
 
-	monitorScaleStyle := lipgloss.NewStyle().Foreground(colorBlue).Bold(true)
-	monitorScaleValueStyle := lipgloss.NewStyle().Foreground(colorGreen)
-	monitorScaleDescStyle := lipgloss.NewStyle().Foreground(colorSubtle)
+	monitorScaleStyle := lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true)
+	monitorScaleValueStyle := lipgloss.NewStyle().Foreground(m.styles.Green)
+	monitorScaleDescStyle := lipgloss.NewStyle().Foreground(m.styles.Subtle)
 
 	if m.selectedManualControl == 0 {
-		monitorScaleStyle = monitorScaleStyle.Background(colorSurface).Padding(0, 1)
-		monitorScaleValueStyle = monitorScaleValueStyle.Background(colorSurface).Padding(0, 1)
-		monitorScaleDescStyle = monitorScaleDescStyle.Background(colorSurface).Padding(0, 1)
+		monitorScaleStyle = monitorScaleStyle.Background(m.styles.Surface).Padding(0, 1)
+		monitorScaleValueStyle = monitorScaleValueStyle.Background(m.styles.Surface).Padding(0, 1)
+		monitorScaleDescStyle = monitorScaleDescStyle.Background(m.styles.Surface).Padding(0, 1)
 	}
 
 	monitorScaleLabel := monitorScaleStyle.Render("1. Monitor Scale (Compositor-level)")
-	content = append(content, monitorScaleLabel)
 	monitorScaleValue := fmt.Sprintf("   Current: %.3fx (Valid: 1.0x, 1.25x, 1.33x, 1.5x, 1.67x, 1.75x, 2.0x, 2.25x, 2.5x, 3.0x)", m.manualMonitorScale)
-	content = append(content, monitorScaleValueStyle.Render(monitorScaleValue))
-	content = append(content, monitorScaleDescStyle.Render("   Scales everything immediately. Works with all apps."))
+	if m.selectedManualControl == 0 {
+		focus = span{len(content), len(content) + 1}
+	}
+	content = append(content, m.row(zoneControl, 0, m.width-12, strings.Join([]string{
+		monitorScaleLabel,
+		monitorScaleValueStyle.Render(monitorScaleValue),
+		monitorScaleDescStyle.Render("   Scales everything immediately. Works with all apps."),
+	}, "\n")))
 	content = append(content, "")
 
-	gtkScaleStyle := lipgloss.NewStyle().Foreground(colorCyan).Bold(true)
-	gtkScaleValueStyle := lipgloss.NewStyle().Foreground(colorGreen)
-	gtkScaleDescStyle := lipgloss.NewStyle().Foreground(colorSubtle)
+	gtkScaleStyle := lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true)
+	gtkScaleValueStyle := lipgloss.NewStyle().Foreground(m.styles.Green)
+	gtkScaleDescStyle := lipgloss.NewStyle().Foreground(m.styles.Subtle)
 
 	if m.selectedManualControl == 1 {
-		gtkScaleStyle = gtkScaleStyle.Background(colorSurface).Padding(0, 1)
-		gtkScaleValueStyle = gtkScaleValueStyle.Background(colorSurface).Padding(0, 1)
-		gtkScaleDescStyle = gtkScaleDescStyle.Background(colorSurface).Padding(0, 1)
+		gtkScaleStyle = gtkScaleStyle.Background(m.styles.Surface).Padding(0, 1)
+		gtkScaleValueStyle = gtkScaleValueStyle.Background(m.styles.Surface).Padding(0, 1)
+		gtkScaleDescStyle = gtkScaleDescStyle.Background(m.styles.Surface).Padding(0, 1)
 	}
 
 	gtkScaleLabel := gtkScaleStyle.Render("2. GTK Scale (Application-level)")
-	content = append(content, gtkScaleLabel)
 	gtkScaleValue := fmt.Sprintf("   Current: %dx (Range: 1x - 3x, Integer only)", m.manualGTKScale)
-	content = append(content, gtkScaleValueStyle.Render(gtkScaleValue))
-	content = append(content, gtkScaleDescStyle.Render("   Scales GTK apps (most Linux apps). Requires logout."))
+	if m.selectedManualControl == 1 {
+		focus = span{len(content), len(content) + 1}
+	}
+	content = append(content, m.row(zoneControl, 1, m.width-12, strings.Join([]string{
+		gtkScaleLabel,
+		gtkScaleValueStyle.Render(gtkScaleValue),
+		gtkScaleDescStyle.Render("   Scales GTK apps (most Linux apps). Requires logout."),
+	}, "\n")))
 	content = append(content, "")
 
-	fontDPIStyle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true)
-	fontDPIValueStyle := lipgloss.NewStyle().Foreground(colorGreen)
-	fontDPIDescStyle := lipgloss.NewStyle().Foreground(colorSubtle)
+	fontDPIStyle := lipgloss.NewStyle().Foreground(m.styles.Yellow).Bold(true)
+	fontDPIValueStyle := lipgloss.NewStyle().Foreground(m.styles.Green)
+	fontDPIDescStyle := lipgloss.NewStyle().Foreground(m.styles.Subtle)
 
 	if m.selectedManualControl == 2 {
-		fontDPIStyle = fontDPIStyle.Background(colorSurface).Padding(0, 1)
-		fontDPIValueStyle = fontDPIValueStyle.Background(colorSurface).Padding(0, 1)
-		fontDPIDescStyle = fontDPIDescStyle.Background(colorSurface).Padding(0, 1)
+		fontDPIStyle = fontDPIStyle.Background(m.styles.Surface).Padding(0, 1)
+		fontDPIValueStyle = fontDPIValueStyle.Background(m.styles.Surface).Padding(0, 1)
+		fontDPIDescStyle = fontDPIDescStyle.Background(m.styles.Surface).Padding(0, 1)
 	}
 
 	fontDPILabel := fontDPIStyle.Render("3. Font DPI (Text rendering)")
-	content = append(content, fontDPILabel)
 	fontDPIValue := fmt.Sprintf("   Current: %d (Range: 72 - 288, Step: 12)", m.manualFontDPI)
-	content = append(content, fontDPIValueStyle.Render(fontDPIValue))
-	content = append(content, fontDPIDescStyle.Render("   Fine-grained text scaling. Works with most applications."))
+	if m.selectedManualControl == 2 {
+		focus = span{len(content), len(content) + 1}
+	}
+	content = append(content, m.row(zoneControl, 2, m.width-12, strings.Join([]string{
+		fontDPILabel,
+		fontDPIValueStyle.Render(fontDPIValue),
+		fontDPIDescStyle.Render("   Fine-grained text scaling. Works with most applications."),
+	}, "\n")))
 	content = append(content, "")
 
 	effectiveWidth, effectiveHeight := utils.CalculateEffectiveResolution(selectedMonitor.Width, selectedMonitor.Height, m.manualMonitorScale)
 	screenRealEstate := utils.CalculateScreenRealEstate(m.manualMonitorScale)
 	fontMultiplier := utils.CalculateFontMultiplier(m.manualFontDPI, types.BaseDPI)
 
-	resultsTitle := lipgloss.NewStyle().Foreground(colorGreen).Bold(true).Render("📊 Preview Results")
+	resultsTitle := lipgloss.NewStyle().Foreground(m.styles.Green).Bold(true).Render("📊 Preview Results")
 	content = append(content, resultsTitle)
 	content = append(content, fmt.Sprintf("  Effective Resolution: %dx%d", effectiveWidth, effectiveHeight))
 	content = append(content, fmt.Sprintf("  Screen Real Estate: %.0f%%", screenRealEstate))
@@ -1098,73 +2267,80 @@ func (m Model) renderManualScaling(contentHeight int) string {
 	if m.isDemoMode {
 		content = append(content, "")
 		demoNotice := lipgloss.NewStyle().
-			Foreground(colorYellow).
+			Foreground(m.styles.Yellow).
 			Render("📱 Demo Mode: Use ⏎ to preview changes")
 		content = append(content, demoNotice)
 	}
 
 	content = append(content, "")
 	instructions := []string{
-		lipgloss.NewStyle().Foreground(colorGreen).Render("↑↓") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" select control"),
-		lipgloss.NewStyle().Foreground(colorCyan).Render("←→") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" adjust value"),
-		lipgloss.NewStyle().Foreground(colorYellow).Render("⏎") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" apply all"),
-		lipgloss.NewStyle().Foreground(colorMagenta).Render("esc") +
-			lipgloss.NewStyle().Foreground(colorSubtle).Render(" back"),
+		lipgloss.NewStyle().Foreground(m.styles.Green).Render("↑↓") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" select control"),
+		lipgloss.NewStyle().Foreground(m.styles.Cyan).Render("←→") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" adjust value"),
+		lipgloss.NewStyle().Foreground(m.styles.Yellow).Render("⏎") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" apply all"),
+		lipgloss.NewStyle().Foreground(m.styles.Blue).Render("s") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" stage"),
+		lipgloss.NewStyle().Foreground(m.styles.Magenta).Render("esc") +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(" back"),
 	}
 
 	content = append(content, strings.Join(instructions, "  "))
+	if len(m.drafts) > 0 {
+		content = append(content, m.draftsHint())
+	}
 
-	return lipgloss.NewStyle().
-		Width(m.width - 8).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorMagenta).
-		Render(strings.Join(content, "\n"))
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Magenta, content, focus)
 }
 
 func (m Model) renderSettings(contentHeight int) string {
 	var content []string
 
 	title := lipgloss.NewStyle().
-		Foreground(colorMagenta).
+		Foreground(m.styles.Magenta).
 		Bold(true).
 		Render("⚙️ Application Settings")
 
 	content = append(content, title)
 	content = append(content, "")
 
-	appTitle := lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render("📱 Application Info")
+	appTitle := lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("📱 Application Info")
 	content = append(content, appTitle)
 	content = append(content, "")
 
-	themeInfo := m.cachedTerminalTheme
-	if themeInfo == "" {
-		themeInfo = getTerminalThemeInfo()
-	}
+	themeInfo := m.themeInfo()
 
 	appItems := []string{
-		fmt.Sprintf("  Version: %s", lipgloss.NewStyle().Foreground(colorGreen).Render("1.0.0")),
-		fmt.Sprintf("  Theme: %s", lipgloss.NewStyle().Foreground(colorMagenta).Render(themeInfo)),
+		fmt.Sprintf("  Version: %s", lipgloss.NewStyle().Foreground(m.styles.Green).Render("1.0.0")),
+		fmt.Sprintf("  Theme: %s", lipgloss.NewStyle().Foreground(m.styles.Magenta).Render(themeInfo)),
 		fmt.Sprintf("  Mode: %s", func() string {
+			if m.services.Mode.Simulation != "" {
+				return lipgloss.NewStyle().Foreground(m.styles.Yellow).Render("Simulated")
+			}
 			if m.isDemoMode {
-				return lipgloss.NewStyle().Foreground(colorYellow).Render("Demo")
+				return lipgloss.NewStyle().Foreground(m.styles.Yellow).Render("Demo")
 			}
-			return lipgloss.NewStyle().Foreground(colorGreen).Render("Live")
+			return lipgloss.NewStyle().Foreground(m.styles.Green).Render("Live")
+		}()),
+		fmt.Sprintf("  Session: %s", func() string {
+			if m.services.Mode.Simulation != "" {
+				return lipgloss.NewStyle().Foreground(m.styles.Cyan).Render("simulated (" + m.services.Mode.Simulation + ")")
+			}
+			if !m.services.Mode.Probed {
+				return lipgloss.NewStyle().Foreground(m.styles.Comment).Render("not checked (--no-hyprland-check)")
+			}
+			return lipgloss.NewStyle().Foreground(m.styles.Cyan).Render(m.services.Mode.Session.Kind.String())
 		}()),
 	}
 
 	for _, item := range appItems {
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(item))
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(item))
 	}
 
 	content = append(content, "")
 
-	detectionTitle := lipgloss.NewStyle().Foreground(colorCyan).Bold(true).Render("🔍 Detection Methods")
+	detectionTitle := lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true).Render("🔍 Detection Methods")
 	content = append(content, detectionTitle)
 	content = append(content, "")
 
@@ -1177,64 +2353,58 @@ func (m Model) renderSettings(contentHeight int) string {
 		if m.cachedCommandStatus != nil {
 			if available, exists := m.cachedCommandStatus[cmd]; exists {
 				if available {
-					status = lipgloss.NewStyle().Foreground(colorGreen).Render("✓ Available")
+					status = lipgloss.NewStyle().Foreground(m.styles.Green).Render("✓ Available")
 				} else {
-					status = lipgloss.NewStyle().Foreground(colorRed).Render("✗ Not found")
+					status = lipgloss.NewStyle().Foreground(m.styles.Red).Render("✗ Not found")
 				}
 			}
 		}
 
 		if status == "" {
 			if utils.CommandExists(cmd) {
-				status = lipgloss.NewStyle().Foreground(colorGreen).Render("✓ Available")
+				status = lipgloss.NewStyle().Foreground(m.styles.Green).Render("✓ Available")
 			} else {
-				status = lipgloss.NewStyle().Foreground(colorRed).Render("✗ Not found")
+				status = lipgloss.NewStyle().Foreground(m.styles.Red).Render("✗ Not found")
 			}
 		}
 
 		item := fmt.Sprintf("  %s: %s", names[i], status)
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(item))
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(item))
 	}
 
 	content = append(content, "")
 
-	configTitle := lipgloss.NewStyle().Foreground(colorYellow).Bold(true).Render("⚙️ Configuration")
+	configTitle := lipgloss.NewStyle().Foreground(m.styles.Yellow).Bold(true).Render("⚙️ Configuration")
 	content = append(content, configTitle)
 	content = append(content, "")
 
 	configItems := []string{
-		fmt.Sprintf("  Target: %s", lipgloss.NewStyle().Foreground(colorGreen).Render("Hyprland + Wayland")),
-		fmt.Sprintf("  Fallbacks: %s", lipgloss.NewStyle().Foreground(colorBlue).Render("wlr-randr")),
-		fmt.Sprintf("  Font Scaling: %s", lipgloss.NewStyle().Foreground(colorMagenta).Render("GTK, Alacritty, Neovim")),
+		fmt.Sprintf("  Target: %s", lipgloss.NewStyle().Foreground(m.styles.Green).Render("Hyprland + Wayland")),
+		fmt.Sprintf("  Fallbacks: %s", lipgloss.NewStyle().Foreground(m.styles.Blue).Render("wlr-randr")),
+		fmt.Sprintf("  Font Scaling: %s", lipgloss.NewStyle().Foreground(m.styles.Magenta).Render("GTK, Alacritty, Kitty, Foot, Ghostty")),
 	}
 
 	for _, item := range configItems {
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(item))
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(item))
 	}
 
 	content = append(content, "")
 
 	footer := lipgloss.NewStyle().
-		Foreground(colorComment).
+		Foreground(m.styles.Comment).
 		Italic(true).
-		Render("💡 Press Esc to return to the main menu")
+		Render(fmt.Sprintf("💡 Press %s to return to the main menu", keymap.Short(m.keys.Back)))
 	content = append(content, footer)
 
-	return lipgloss.NewStyle().
-		Width(m.width - 8).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorMagenta).
-		Render(strings.Join(content, "\n"))
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Magenta, content, span{})
 }
 
 func (m Model) renderConfirmation(contentHeight int) string {
 	var content []string
+	var focus span
 
 	title := lipgloss.NewStyle().
-		Foreground(colorYellow).
+		Foreground(m.styles.Yellow).
 		Bold(true).
 		Render("⚠️ Confirm Scaling Changes")
 
@@ -1242,7 +2412,7 @@ func (m Model) renderConfirmation(contentHeight int) string {
 	content = append(content, "")
 
 	warningStyle := lipgloss.NewStyle().
-		Foreground(colorRed).
+		Foreground(m.styles.Red).
 		Bold(true)
 
 	warning := warningStyle.Render("⚠️  WARNING: Desktop refresh required!")
@@ -1261,11 +2431,11 @@ func (m Model) renderConfirmation(contentHeight int) string {
 
 	for _, line := range explanationLines {
 		if strings.HasPrefix(line, "🔄") || strings.HasPrefix(line, "❌") || strings.HasPrefix(line, "⚡") {
-			content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render("  "+line))
+			content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render("  "+line))
 		} else if line == "" {
 			content = append(content, line)
 		} else {
-			content = append(content, lipgloss.NewStyle().Foreground(colorComment).Render(line))
+			content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(line))
 		}
 	}
 
@@ -1274,46 +2444,83 @@ func (m Model) renderConfirmation(contentHeight int) string {
 	monitor := m.pendingMonitor
 	option := m.pendingOption
 
-	monitorTitle := lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render("📱 Target Monitor")
-	content = append(content, monitorTitle)
-	content = append(content, "")
+	targets := m.pendingTransaction().Monitors
 
-	monitorInfo := fmt.Sprintf("  %s (%dx%d@%.1fHz)",
-		monitor.Name, monitor.Width, monitor.Height, monitor.RefreshRate)
-	content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(monitorInfo))
-	content = append(content, "")
+	if len(targets) > 1 {
+		monitorTitle := lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render(fmt.Sprintf("📱 Target Monitors (%d, applied together)", len(targets)))
+		content = append(content, monitorTitle)
+		content = append(content, "")
+
+		for _, target := range targets {
+			monitorInfo := fmt.Sprintf("  %s (%dx%d@%.1fHz) → %s",
+				target.Monitor.Name, target.Monitor.Width, target.Monitor.Height, target.Monitor.RefreshRate,
+				lipgloss.NewStyle().Foreground(m.styles.Green).Render(fmt.Sprintf("%.2fx", target.Scale)))
+			content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(monitorInfo))
+		}
+		content = append(content, "")
+	} else {
+		monitorTitle := lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("📱 Target Monitor")
+		content = append(content, monitorTitle)
+		content = append(content, "")
+
+		monitorInfo := fmt.Sprintf("  %s (%dx%d@%.1fHz)",
+			monitor.Name, monitor.Width, monitor.Height, monitor.RefreshRate)
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(monitorInfo))
+		content = append(content, "")
+	}
 
-	settingsTitle := lipgloss.NewStyle().Foreground(colorCyan).Bold(true).Render("🎯 Settings to Apply")
+	settingsTitle := lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true).Render("🎯 Settings to Apply")
 	content = append(content, settingsTitle)
 	content = append(content, "")
 
-	settings := []string{
-		fmt.Sprintf("  Monitor Scale: %s", lipgloss.NewStyle().Foreground(colorGreen).Render(fmt.Sprintf("%.2fx", option.MonitorScale))),
-		fmt.Sprintf("  GTK Scale: %s", lipgloss.NewStyle().Foreground(colorMagenta).Render(fmt.Sprintf("%dx", option.GTKScale))),
-		fmt.Sprintf("  Font DPI: %s", lipgloss.NewStyle().Foreground(colorYellow).Render(fmt.Sprintf("%d", option.FontDPI))),
+	var settings []string
+	if len(targets) <= 1 {
+		settings = append(settings, fmt.Sprintf("  Monitor Scale: %s", lipgloss.NewStyle().Foreground(m.styles.Green).Render(fmt.Sprintf("%.2fx", option.MonitorScale))))
+	}
+	settings = append(settings,
+		fmt.Sprintf("  GTK Scale: %s", lipgloss.NewStyle().Foreground(m.styles.Magenta).Render(fmt.Sprintf("%dx", option.GTKScale))),
+		fmt.Sprintf("  Font DPI: %s", lipgloss.NewStyle().Foreground(m.styles.Yellow).Render(fmt.Sprintf("%d", option.FontDPI))),
+	)
+	if option.CursorSize > 0 {
+		settings = append(settings, fmt.Sprintf("  Cursor Size: %s", lipgloss.NewStyle().Foreground(m.styles.Cyan).Render(fmt.Sprintf("%dpx", option.CursorSize))))
 	}
 
 	for _, setting := range settings {
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(setting))
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(setting))
 	}
 
 	content = append(content, "")
+	summary := m.renderPlanSummary()
+	if m.pendingPlanErr != nil {
+		// Bring a failed plan or apply into view
+		focus = span{len(content), len(content) + len(summary)}
+	}
+	content = append(content, summary...)
 
 	actionName := "Smart Scaling"
-	if m.confirmationAction == ConfirmManualScaling {
+	switch m.confirmationAction {
+	case ConfirmManualScaling:
 		actionName = "Manual Scaling"
+	case ConfirmDrafts:
+		actionName = "All Drafts"
 	}
 
 	actionInfo := fmt.Sprintf("Action: %s - %s",
-		lipgloss.NewStyle().Foreground(colorCyan).Render(actionName),
-		lipgloss.NewStyle().Foreground(colorComment).Render(option.DisplayName))
+		lipgloss.NewStyle().Foreground(m.styles.Cyan).Render(actionName),
+		lipgloss.NewStyle().Foreground(m.styles.Comment).Render(option.DisplayName))
 	content = append(content, actionInfo)
 	content = append(content, "")
 
 	instructionsStyle := lipgloss.NewStyle().
-		Foreground(colorComment).
+		Foreground(m.styles.Comment).
 		Italic(true)
 
+	content = append(content, lipgloss.JoinHorizontal(lipgloss.Top,
+		m.button(zoneConfirm, keymap.Short(m.keys.Select), "Apply", m.styles.Green),
+		"  ",
+		m.button(zoneCancel, keymap.Short(m.keys.Back), "Cancel", m.styles.Red)))
+	content = append(content, "")
+
 	instructions := []string{
 		"💡 Controls:",
 		"  Enter/Space - Apply changes (refresh desktop)",
@@ -1324,120 +2531,478 @@ func (m Model) renderConfirmation(contentHeight int) string {
 		content = append(content, instructionsStyle.Render(instruction))
 	}
 
-	return lipgloss.NewStyle().
-		Width(m.width - 8).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorYellow).
-		Render(strings.Join(content, "\n"))
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Yellow, content, focus)
 }
 
-func (m Model) renderHelp(contentHeight int) string {
+// renderPlanSummary lists the commands and environment variables of the
+// pending plan, and the diff of every file it will touch.
+func (m Model) renderPlanSummary() []string {
+	var lines []string
+
+	lines = append(lines, lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("📋 Plan"))
+	lines = append(lines, "")
+
+	if m.pendingPlanErr != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(m.styles.Red).Render("  ❌ "+m.pendingPlanErr.Error()))
+		lines = append(lines, "")
+		return lines
+	}
+
+	plan := m.pendingPlan
+	subtle := lipgloss.NewStyle().Foreground(m.styles.Subtle)
+
+	for _, note := range plan.Notes {
+		lines = append(lines, lipgloss.NewStyle().Foreground(m.styles.Yellow).Render("  ℹ️  "+note))
+	}
+	for _, cmd := range plan.Commands {
+		lines = append(lines, subtle.Render("  $ "+cmd.String()))
+	}
+	if len(plan.Env) > 0 {
+		env := make([]string, 0, len(plan.Env))
+		for _, change := range plan.Env {
+			env = append(env, change.String())
+		}
+		lines = append(lines, subtle.Render("  env "+strings.Join(env, " ")))
+	}
+	for _, file := range plan.ConfigFiles() {
+		added, removed := diffStat(file.Diff())
+		lines = append(lines, subtle.Render(fmt.Sprintf("  📄 %s ", file.Path))+
+			lipgloss.NewStyle().Foreground(m.styles.Green).Render(fmt.Sprintf("+%d", added))+" "+
+			lipgloss.NewStyle().Foreground(m.styles.Red).Render(fmt.Sprintf("-%d", removed)))
+		lines = append(lines, m.renderDiff(file, "    ")...)
+	}
+	if plan.IsEmpty() {
+		lines = append(lines, subtle.Render("  Nothing to change"))
+	}
+
+	lines = append(lines, "")
+	return lines
+}
+
+// renderDiff colours the hunks of a file change, each line indented by
+// indent. The file name is left to the caller.
+func (m Model) renderDiff(change monitor.FileChange, indent string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(change.Diff(), "\n"), "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		style := lipgloss.NewStyle().Foreground(m.styles.Subtle)
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			style = lipgloss.NewStyle().Foreground(m.styles.Cyan)
+		case strings.HasPrefix(line, "+"):
+			style = lipgloss.NewStyle().Foreground(m.styles.Green)
+		case strings.HasPrefix(line, "-"):
+			style = lipgloss.NewStyle().Foreground(m.styles.Red)
+		}
+		lines = append(lines, style.Render(indent+line))
+	}
+	return lines
+}
+
+// diffStat counts added and removed lines in a unified diff.
+func diffStat(diff string) (int, int) {
+	added, removed := 0, 0
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func (m Model) renderWaybarPreview(contentHeight int) string {
 	var content []string
 
 	title := lipgloss.NewStyle().
-		Foreground(colorYellow).
+		Foreground(m.styles.Cyan).
 		Bold(true).
-		Render("📖 Help & Controls")
+		Render("📝 Review Waybar Changes")
 
 	content = append(content, title)
 	content = append(content, "")
+	content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+		"Waybar's height and font sizes will be scaled to match. A backup is kept and Waybar is reloaded."))
+	content = append(content, "")
 
-	navTitle := lipgloss.NewStyle().Foreground(colorGreen).Bold(true).Render("🎮 Navigation")
-	content = append(content, navTitle)
+	var diffLines []string
+	for _, change := range m.waybarPlan.ConfigFiles() {
+		diffLines = append(diffLines, lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("📄 "+change.Path))
+		diffLines = append(diffLines, m.renderDiff(change, "  ")...)
+		diffLines = append(diffLines, "")
+	}
+
+	content = append(content, diffLines...)
+
+	var focus span
+	if m.waybarErr != nil {
+		// Bring the failed write into view
+		focus = span{len(content), len(content) + 2}
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Red).Render("❌ "+m.waybarErr.Error()))
+		content = append(content, "")
+	}
+
+	if m.isDemoMode {
+		content = append(content, lipgloss.NewStyle().
+			Foreground(m.styles.Yellow).
+			Render("📱 Demo Mode: Changes will be simulated"))
+		content = append(content, "")
+	}
+
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(m.styles.Comment).
+		Italic(true)
+
+	content = append(content, lipgloss.JoinHorizontal(lipgloss.Top,
+		m.button(zoneConfirm, keymap.Short(m.keys.Select), "Write and reload", m.styles.Green),
+		"  ",
+		m.button(zoneCancel, keymap.Short(m.keys.Back), "Skip", m.styles.Red)))
 	content = append(content, "")
 
-	navItems := []string{
-		fmt.Sprintf("  %s %s   Navigate up/down in menus",
-			lipgloss.NewStyle().Foreground(colorGreen).Bold(true).Render("↑↓"),
-			lipgloss.NewStyle().Foreground(colorGreen).Render("k j")),
-		fmt.Sprintf("  %s %s   Navigate left/right (manual scaling)",
-			lipgloss.NewStyle().Foreground(colorCyan).Bold(true).Render("←→"),
-			lipgloss.NewStyle().Foreground(colorCyan).Render("h l")),
-		fmt.Sprintf("  %s       Select option or apply changes",
-			lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render("⏎")),
-		fmt.Sprintf("  %s       Alternative selection",
-			lipgloss.NewStyle().Foreground(colorBlue).Render("Space")),
+	instructions := []string{
+		"💡 Controls:",
+		"  Enter/Space - Write changes and reload Waybar",
+		"  Esc - Skip Waybar changes",
 	}
 
-	for _, item := range navItems {
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(item))
+	for _, instruction := range instructions {
+		content = append(content, instructionsStyle.Render(instruction))
 	}
 
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Cyan, content, focus)
+}
+
+func (m Model) renderDrift(contentHeight int) string {
+	var content []string
+	var focus span
+
+	title := lipgloss.NewStyle().
+		Foreground(m.styles.Yellow).
+		Bold(true).
+		Render("⚠️ The Compositor Adjusted Your Settings")
+
+	content = append(content, title)
+	content = append(content, "")
+	content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+		"After applying, the monitors were detected again and don't match what was requested:"))
 	content = append(content, "")
 
-	cmdTitle := lipgloss.NewStyle().Foreground(colorBlue).Bold(true).Render("⌨️ Global Commands")
-	content = append(content, cmdTitle)
+	for _, d := range m.verification.Drift {
+		line := fmt.Sprintf("  %s %s: requested %s, got %s",
+			lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render(d.Current.Name),
+			d.Property,
+			lipgloss.NewStyle().Foreground(m.styles.Green).Render(d.Requested),
+			lipgloss.NewStyle().Foreground(m.styles.Red).Render(d.Actual))
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(line))
+	}
 	content = append(content, "")
 
-	cmdItems := []string{
-		fmt.Sprintf("  %s %s   Show this help screen",
-			lipgloss.NewStyle().Foreground(colorYellow).Bold(true).Render("h"),
-			lipgloss.NewStyle().Foreground(colorYellow).Render("?")),
-		fmt.Sprintf("  %s       Return to main menu",
-			lipgloss.NewStyle().Foreground(colorMagenta).Bold(true).Render("Esc")),
-		fmt.Sprintf("  %s       Quit application",
-			lipgloss.NewStyle().Foreground(colorRed).Bold(true).Render("q")),
-		fmt.Sprintf("  %s   Force quit",
-			lipgloss.NewStyle().Foreground(colorRed).Bold(true).Render("Ctrl+C")),
+	for i, choice := range m.driftChoices() {
+		if i == 1 {
+			var nearest []string
+			for _, d := range m.verification.ScaleDrift() {
+				nearest = append(nearest, fmt.Sprintf("%.2fx for %s", nearestValidScale(d), d.Current.Name))
+			}
+			choice += " (" + strings.Join(nearest, ", ") + ")"
+		}
+		if i == m.selectedDrift {
+			choice = lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true).Render("▶ " + choice)
+		} else {
+			choice = lipgloss.NewStyle().Foreground(m.styles.Subtle).Render("  " + choice)
+		}
+		if i == m.selectedDrift {
+			focus = span{len(content), len(content) + 1}
+		}
+		content = append(content, m.row(zoneDrift, i, m.width-12, choice))
 	}
 
-	for _, item := range cmdItems {
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(item))
+	if m.driftStatus != "" {
+		content = append(content, "")
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Yellow).Render(m.driftStatus))
 	}
 
 	content = append(content, "")
 
-	modeTitle := lipgloss.NewStyle().Foreground(colorCyan).Bold(true).Render("🎯 Mode-Specific Controls")
-	content = append(content, modeTitle)
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(m.styles.Comment).
+		Italic(true)
+
+	instructions := []string{
+		"💡 Controls:",
+		"  ↑↓ - Choose",
+		"  Enter/Space - Confirm choice",
+		"  Esc - Accept the compositor's values",
+	}
+
+	for _, instruction := range instructions {
+		content = append(content, instructionsStyle.Render(instruction))
+	}
+
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Yellow, content, focus)
+}
+
+func (m Model) renderLogs(contentHeight int) string {
+	var content []string
+
+	title := lipgloss.NewStyle().
+		Foreground(m.styles.Cyan).
+		Bold(true).
+		Render("📜 Logs")
+
+	content = append(content, title)
 	content = append(content, "")
 
-	modeItems := []string{
-		fmt.Sprintf("  %s       Switch to manual scaling (from smart scaling)",
-			lipgloss.NewStyle().Foreground(colorYellow).Bold(true).Render("m")),
-		fmt.Sprintf("  %s       Select control in manual scaling",
-			lipgloss.NewStyle().Foreground(colorMagenta).Bold(true).Render("↑↓")),
-		fmt.Sprintf("  %s       Adjust values in manual scaling",
-			lipgloss.NewStyle().Foreground(colorMagenta).Bold(true).Render("←→")),
+	location := "Entries are kept in memory only"
+	if path := m.logger.Path(); path != "" {
+		location = "Writing to " + path
 	}
+	content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(location))
+	content = append(content, "")
 
-	for _, item := range modeItems {
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(item))
+	lines := m.logger.Recent()
+	if len(lines) == 0 {
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render("Nothing logged yet."))
+	}
+
+	// Show the newest entries that fit, shifted up by the scroll offset
+	maxLines := contentHeight - 16
+	if maxLines < 3 {
+		maxLines = 3
+	}
+	end := len(lines) - m.logOffset
+	if end < 0 {
+		end = 0
+	}
+	start := end - maxLines
+	if start < 0 {
+		start = 0
+	}
+
+	lineWidth := m.width - 16
+	for _, line := range lines[start:end] {
+		if lineWidth > 3 && len(line) > lineWidth {
+			line = line[:lineWidth-3] + "..."
+		}
+		color := m.styles.Subtle
+		switch {
+		case strings.Contains(line, "level=ERROR"):
+			color = m.styles.Red
+		case strings.Contains(line, "level=WARN"):
+			color = m.styles.Yellow
+		case strings.Contains(line, "level=DEBUG"):
+			color = m.styles.Comment
+		}
+		content = append(content, lipgloss.NewStyle().Foreground(color).Render(line))
+	}
+
+	if m.logOffset > 0 {
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+			fmt.Sprintf("  … %d newer lines below", m.logOffset)))
 	}
 
 	content = append(content, "")
 
-	aboutTitle := lipgloss.NewStyle().Foreground(colorMagenta).Bold(true).Render("ℹ️ About")
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(m.styles.Comment).
+		Italic(true)
+
+	instructions := []string{
+		"💡 Controls:",
+		"  ↑↓ - Scroll",
+		"  Esc - Return to main menu",
+	}
+
+	for _, instruction := range instructions {
+		content = append(content, instructionsStyle.Render(instruction))
+	}
+
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Cyan, content, span{})
+}
+
+func (m Model) renderHistory(contentHeight int) string {
+	var content []string
+	var focus span
+
+	title := lipgloss.NewStyle().
+		Foreground(m.styles.Magenta).
+		Bold(true).
+		Render("🕘 Change History")
+
+	content = append(content, title)
+	content = append(content, "")
+
+	if m.historyStatus != "" {
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Yellow).Render(m.historyStatus))
+		content = append(content, "")
+	}
+
+	if len(m.historyEntries) == 0 {
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render("No changes recorded yet."))
+		content = append(content, "")
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+			"A snapshot is taken before every apply, so any change can be rolled back here."))
+	}
+
+	// Keep the selected entry and its details on screen
+	maxEntries := (contentHeight - 14) / 2
+	if maxEntries < 3 {
+		maxEntries = 3
+	}
+	start := 0
+	if m.selectedHistory >= maxEntries {
+		start = m.selectedHistory - maxEntries + 1
+	}
+	end := start + maxEntries
+	if end > len(m.historyEntries) {
+		end = len(m.historyEntries)
+	}
+
+	for i := start; i < end; i++ {
+		entry := m.historyEntries[i]
+		line := fmt.Sprintf("%s  %s", entry.Time.Local().Format("2006-01-02 15:04"), entry.Action)
+		if i == m.selectedHistory {
+			line = lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("▶ " + line)
+		} else {
+			line = lipgloss.NewStyle().Foreground(m.styles.Subtle).Render("  " + line)
+		}
+		if i == m.selectedHistory {
+			focus = span{len(content), len(content) + 1}
+		}
+		content = append(content, m.row(zoneHistory, i, m.width-12, line+"\n"+lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+			fmt.Sprintf("    %s • %s@%s via %s", entry.ID, entry.User, entry.Host, entry.Source))))
+	}
+
+	if m.selectedHistory < len(m.historyEntries) {
+		entry := m.historyEntries[m.selectedHistory]
+		content = append(content, "")
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true).Render("📋 Snapshot"))
+
+		if entry.Reason != "" {
+			content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render("  Why: "+entry.Reason))
+		}
+		for _, state := range entry.Monitors {
+			content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(
+				fmt.Sprintf("  %s: %dx%d @ %.2fx", state.Name, state.Width, state.Height, state.Scale)))
+		}
+		existing := 0
+		for _, file := range entry.Files {
+			if file.Existed {
+				existing++
+			}
+		}
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+			fmt.Sprintf("  %d config files saved", existing)))
+	}
+
+	content = append(content, "")
+
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(m.styles.Comment).
+		Italic(true)
+
+	instructions := []string{
+		"💡 Controls:",
+		"  ↑↓ - Select snapshot",
+		"  Enter/Space - Restore selected snapshot",
+		"  Esc - Return to main menu",
+	}
+
+	for _, instruction := range instructions {
+		content = append(content, instructionsStyle.Render(instruction))
+	}
+
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Magenta, content, focus)
+}
+
+func (m Model) renderHelp(contentHeight int) string {
+	var content []string
+
+	title := lipgloss.NewStyle().
+		Foreground(m.styles.Yellow).
+		Bold(true).
+		Render("📖 Help & Controls")
+
+	content = append(content, title)
+	content = append(content, "")
+
+	// Everything below comes from the active keymap, so it can't disagree
+	// with what the keys do
+	keyColumn := lipgloss.NewStyle().Width(14)
+	row := func(keys string, color lipgloss.Color, description string) string {
+		return "  " + keyColumn.Render(lipgloss.NewStyle().Foreground(color).Bold(true).Render(keys)) +
+			lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(description)
+	}
+	binding := func(b key.Binding, color lipgloss.Color) string {
+		return row(b.Help().Key, color, b.Help().Desc)
+	}
+
+	navTitle := lipgloss.NewStyle().Foreground(m.styles.Green).Bold(true).Render("🎮 Navigation")
+	content = append(content, navTitle)
+	content = append(content, "")
+	content = append(content,
+		row(keymap.Pairs(m.keys.Up, m.keys.Down), m.styles.Green, "Navigate up/down in menus"),
+		row(keymap.Pairs(m.keys.Left, m.keys.Right), m.styles.Cyan, "Adjust values (manual scaling) or pick an option (comparison)"),
+		binding(m.keys.Select, m.styles.Blue),
+		binding(m.keys.Back, m.styles.Magenta),
+		row(keymap.Pairs(m.keys.PageUp, m.keys.PageDown), m.styles.Green, "Scroll a screen that doesn't fit"),
+		row("click", m.styles.Blue, "Select an item; click it again to open it"),
+		row("wheel", m.styles.Green, "Scroll lists, or adjust the manual control under it"),
+	)
+	content = append(content, "")
+
+	cmdTitle := lipgloss.NewStyle().Foreground(m.styles.Blue).Bold(true).Render("⌨️ Global Commands")
+	content = append(content, cmdTitle)
+	content = append(content, "")
+	content = append(content,
+		binding(m.keys.Help, m.styles.Yellow),
+		binding(m.keys.Quit, m.styles.Red),
+		row(keymap.ForceQuit, m.styles.Red, "Force quit"),
+	)
+	content = append(content, "")
+
+	modeTitle := lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true).Render("🎯 Mode-Specific Controls")
+	content = append(content, modeTitle)
+	content = append(content, "")
+	content = append(content,
+		binding(m.keys.Manual, m.styles.Yellow),
+		binding(m.keys.Compare, m.styles.Cyan),
+		binding(m.keys.Stage, m.styles.Cyan),
+		binding(m.keys.Unstage, m.styles.Red),
+		row(keymap.Pairs(m.keys.Undo, m.keys.Redo), m.styles.Magenta, "Undo or redo a manual edit, staging or apply"),
+		row(keymap.Pairs(m.keys.ApplyDrafts, m.keys.DiscardDrafts), m.styles.Magenta, "Apply or discard every monitor's manual draft"),
+		binding(m.keys.Refresh, m.styles.Green),
+		binding(m.keys.Demo, m.styles.Yellow),
+	)
+	content = append(content, "")
+
+	aboutTitle := lipgloss.NewStyle().Foreground(m.styles.Magenta).Bold(true).Render("ℹ️ About")
 	content = append(content, aboutTitle)
 	content = append(content, "")
 
 	aboutItems := []string{
-		fmt.Sprintf("  Version: %s", lipgloss.NewStyle().Foreground(colorGreen).Render("1.0.0")),
-		fmt.Sprintf("  Theme: %s", lipgloss.NewStyle().Foreground(colorMagenta).Render("Tokyo Night")),
-		fmt.Sprintf("  Target: %s", lipgloss.NewStyle().Foreground(colorCyan).Render("Hyprland & Wayland")),
-		fmt.Sprintf("  Built with: %s", lipgloss.NewStyle().Foreground(colorBlue).Render("Go + Bubbletea")),
+		fmt.Sprintf("  Version: %s", lipgloss.NewStyle().Foreground(m.styles.Green).Render("1.0.0")),
+		fmt.Sprintf("  Theme: %s", lipgloss.NewStyle().Foreground(m.styles.Magenta).Render(m.themeInfo())),
+		fmt.Sprintf("  Keymap: %s", lipgloss.NewStyle().Foreground(m.styles.Yellow).Render(m.keys.Preset)),
+		fmt.Sprintf("  Target: %s", lipgloss.NewStyle().Foreground(m.styles.Cyan).Render("Hyprland & Wayland")),
+		fmt.Sprintf("  Built with: %s", lipgloss.NewStyle().Foreground(m.styles.Blue).Render("Go + Bubbletea")),
 	}
 
 	for _, item := range aboutItems {
-		content = append(content, lipgloss.NewStyle().Foreground(colorSubtle).Render(item))
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Subtle).Render(item))
 	}
 
 	content = append(content, "")
 
 	footer := lipgloss.NewStyle().
-		Foreground(colorComment).
+		Foreground(m.styles.Comment).
 		Italic(true).
-		Render("💡 Press Esc to return to the main menu")
+		Render(fmt.Sprintf("💡 Press %s to return to the main menu", keymap.Short(m.keys.Back)))
 	content = append(content, footer)
 
-	return lipgloss.NewStyle().
-		Width(m.width - 8).
-		Height(contentHeight - 2).
-		Padding(2).
-		Background(colorBackground).
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorYellow).
-		Render(strings.Join(content, "\n"))
+	return m.panel(panelMain, m.width-8, contentHeight, m.styles.Yellow, content, span{})
 }
@@ -1,15 +1,36 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/exp/teatest"
 	"github.com/muesli/termenv"
 	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/history"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/keymap"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/logging"
 	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/session"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/theme"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/types"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
 )
 
 func createTestModel() Model {
@@ -20,7 +41,13 @@ func createTestModel() Model {
 		IsTestMode:      true,
 	}
 	services := app.NewServices(config)
-	return NewModelWithServices(services)
+	return detected(NewModelWithServices(services))
+}
+
+// detected runs the model's monitor detection to completion, as the program
+// does after Init.
+func detected(m Model) Model {
+	return m.finishDetection(m.detectMonitors()().(monitorsDetectedMsg))
 }
 
 func TestNewModel(t *testing.T) {
@@ -93,7 +120,7 @@ func TestModelUpdate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			model := NewModel()
+			model := detected(NewModel())
 			updatedModel, cmd := model.Update(tt.msg)
 
 			if model, ok := updatedModel.(Model); ok {
@@ -210,10 +237,28 @@ func TestHandleKeyPress(t *testing.T) {
 			shouldQuit:     false,
 		},
 		{
-			name:           "select settings",
+			name:           "select history",
 			initialMode:    ModeDashboard,
 			initialOption:  4,
 			key:            "enter",
+			expectedMode:   ModeHistory,
+			expectedOption: 0,
+			shouldQuit:     false,
+		},
+		{
+			name:           "select logs",
+			initialMode:    ModeDashboard,
+			initialOption:  5,
+			key:            "enter",
+			expectedMode:   ModeLogs,
+			expectedOption: 0,
+			shouldQuit:     false,
+		},
+		{
+			name:           "select settings",
+			initialMode:    ModeDashboard,
+			initialOption:  6,
+			key:            "enter",
 			expectedMode:   ModeSettings,
 			expectedOption: 0,
 			shouldQuit:     false,
@@ -221,7 +266,7 @@ func TestHandleKeyPress(t *testing.T) {
 		{
 			name:           "select help",
 			initialMode:    ModeDashboard,
-			initialOption:  5,
+			initialOption:  7,
 			key:            "enter",
 			expectedMode:   ModeHelp,
 			expectedOption: 0,
@@ -230,10 +275,10 @@ func TestHandleKeyPress(t *testing.T) {
 		{
 			name:           "select exit",
 			initialMode:    ModeDashboard,
-			initialOption:  6,
+			initialOption:  8,
 			key:            "enter",
 			expectedMode:   ModeDashboard,
-			expectedOption: 6,
+			expectedOption: 8,
 			shouldQuit:     true,
 		},
 		{
@@ -276,7 +321,7 @@ func TestHandleKeyPress(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			model := NewModel()
+			model := detected(NewModel())
 			model.mode = tt.initialMode
 			model.selectedOption = tt.initialOption
 
@@ -415,7 +460,7 @@ func TestManualScalingControls(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			model := NewModel()
+			model := detected(NewModel())
 			model.mode = ModeManualScaling
 			model.selectedManualControl = tt.initialControl
 			model.manualMonitorScale = tt.initialMonitorScale
@@ -468,12 +513,12 @@ func TestModelView(t *testing.T) {
 			expected: "Terminal too small",
 		},
 		{
-			name:     "not ready",
+			name:     "detecting",
 			width:    80,
 			height:   24,
 			ready:    false,
 			mode:     ModeDashboard,
-			expected: "Initializing",
+			expected: "Detecting monitors",
 		},
 		{
 			name:     "dashboard mode",
@@ -501,106 +546,1339 @@ func TestModelView(t *testing.T) {
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			model := NewModel()
-			model.width = tt.width
-			model.height = tt.height
-			model.ready = tt.ready
-			model.mode = tt.mode
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := detected(NewModel())
+			model.width = tt.width
+			model.height = tt.height
+			if !tt.ready {
+				model.load = loadDetecting
+			}
+			model.mode = tt.mode
+
+			view := model.View()
+
+			if !strings.Contains(view, tt.expected) {
+				t.Errorf("View should contain '%s', got: %s", tt.expected, view)
+			}
+		})
+	}
+}
+
+func TestScalingOptions(t *testing.T) {
+	model := detected(NewModel())
+
+	if len(model.monitors) == 0 {
+		t.Skip("No monitors available for testing")
+	}
+
+	scalingManager := monitor.NewScalingManager()
+	options := scalingManager.GetIntelligentScalingOptions(model.monitors[0])
+
+	if len(options) == 0 {
+		t.Error("Should have scaling options")
+	}
+
+	hasRecommended := false
+	for _, option := range options {
+		if option.IsRecommended {
+			hasRecommended = true
+			break
+		}
+	}
+	if !hasRecommended {
+		t.Error("Should have at least one recommended option")
+	}
+}
+
+func TestCompareScales(t *testing.T) {
+	mon := monitor.Monitor{Name: "DP-1", Width: 3840, Height: 2160, Scale: 1.5}
+	options := monitor.NewScalingManager().GetIntelligentScalingOptions(mon)
+
+	comparisons := compareScales(mon, options)
+	if len(comparisons) != len(options)+1 {
+		t.Fatalf("Expected the current scale plus %d options, got %d", len(options), len(comparisons))
+	}
+
+	current := comparisons[0]
+	if current.EffectiveWidth != 2560 || current.EffectiveHeight != 1440 || current.TextSize != 1 {
+		t.Errorf("Unexpected current desktop %+v", current)
+	}
+	if current.Terminals != 9 || current.BrowserShare != 0.5 {
+		t.Errorf("Expected 9 terminals and a half-width browser at 2560x1440, got %+v", current)
+	}
+
+	for _, c := range comparisons[1:] {
+		if c.Scale != 2 {
+			continue
+		}
+		if c.EffectiveWidth != 1920 || c.Terminals != 4 {
+			t.Errorf("Expected 4 terminals at 1920x1080, got %+v", c)
+		}
+		if c.RealEstate != utils.CalculateScreenRealEstate(2) || c.TextSize <= 1.33 || c.TextSize >= 1.34 {
+			t.Errorf("Expected half the native space and a third larger text at 2x, got %+v", c)
+		}
+	}
+}
+
+func TestDesktopMockFitsItsWidth(t *testing.T) {
+	model := createTestModelForVisual(ModeScalingComparison)
+
+	tests := []struct {
+		name      string
+		width     int
+		pxPerCell float64
+		terminals int
+	}{
+		{"too narrow for a terminal", 600, 40, 0},
+		{"rounding widens the row", 1968, 100, 3},
+		{"roomy", 2560, 40, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := model.renderDesktopMock(scaleComparison{EffectiveWidth: tt.width, EffectiveHeight: 1440}, tt.pxPerCell, 10)
+			lines := strings.Split(mock, "\n")
+			if rows := min(int(1440/tt.pxPerCell/2), 10); len(lines) != rows+2 {
+				t.Fatalf("Expected %d rows inside the border, got:\n%s", rows, mock)
+			}
+			for _, line := range lines {
+				if lipgloss.Width(line) != lipgloss.Width(lines[0]) {
+					t.Fatalf("Expected every line as wide as the border, got:\n%s", mock)
+				}
+			}
+			row := lines[1]
+			if got := len(strings.FieldsFunc(row, func(r rune) bool { return r != '▒' })); got != tt.terminals {
+				t.Errorf("Expected %d terminals, got %d in:\n%s", tt.terminals, got, mock)
+			}
+		})
+	}
+}
+
+func TestScalingComparisonNavigation(t *testing.T) {
+	model := createTestModelForVisual(ModeScalingOptions)
+	model.width, model.height = 160, 50
+
+	press := func(key tea.KeyMsg) {
+		t.Helper()
+		updated, _ := model.handleKeyPress(key)
+		model = updated.(Model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if model.mode != ModeScalingComparison {
+		t.Fatalf("Expected c to open the comparison, got mode %v", model.mode)
+	}
+	if view := model.View(); !strings.Contains(view, "Compare Scaling Options") || !strings.Contains(view, "Terminals") {
+		t.Errorf("Expected the comparison to render, got: %s", view)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if model.selectedScalingOpt != 1 {
+		t.Errorf("Expected right to select the next option, got %d", model.selectedScalingOpt)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.mode != ModeScalingOptions || model.selectedScalingOpt != 1 {
+		t.Errorf("Expected esc to return to the options keeping the selection, got mode %v, option %d", model.mode, model.selectedScalingOpt)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.mode != ModeConfirmation || model.pendingOption.DisplayName != model.scalingOptions[1].DisplayName {
+		t.Errorf("Expected enter to confirm the selected option, got mode %v, %q", model.mode, model.pendingOption.DisplayName)
+	}
+}
+
+func TestKeyMap(t *testing.T) {
+	vim, err := keymap.New(keymap.Config{Preset: "vim", Bindings: map[string][]string{"refresh": {"R"}}})
+	if err != nil {
+		t.Fatalf("Failed to build keymap: %v", err)
+	}
+	model := createTestModelForVisual(ModeScalingComparison)
+	model.keys = vim
+	model.width, model.height = 160, 52
+
+	press := func(k string) {
+		t.Helper()
+		updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		model = updated.(Model)
+	}
+
+	// With vim bindings h and l move, and h no longer opens help
+	press("l")
+	press("l")
+	press("h")
+	if model.mode != ModeScalingComparison || model.selectedScalingOpt != 1 {
+		t.Errorf("Expected h/l to move between options, got mode %v, option %d", model.mode, model.selectedScalingOpt)
+	}
+
+	press("?")
+	if model.mode != ModeHelp {
+		t.Fatalf("Expected ? to open help, got mode %v", model.mode)
+	}
+	view := model.View()
+	for _, want := range []string{"k/j", "h/l", "Keymap: vim", "Re-detect monitors"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the help to show %q, got: %s", want, view)
+		}
+	}
+	// The footer shows the keys the active keymap really uses
+	if footer := model.renderFooter(); !strings.Contains(footer, "k/j") || !strings.Contains(footer, "?") {
+		t.Errorf("Expected the footer to follow the keymap, got: %s", footer)
+	}
+
+	// The overridden refresh key works and the preset's doesn't
+	model.mode = ModeDashboard
+	updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if updated.(Model).refreshing {
+		t.Error("Expected r to do nothing once refresh is rebound")
+	}
+	updated, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if !updated.(Model).refreshing || cmd == nil {
+		t.Error("Expected R to refresh")
+	}
+
+	// ctrl+c quits whatever the keymap
+	if _, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Error("Expected ctrl+c to quit")
+	}
+}
+
+func TestThemePalette(t *testing.T) {
+	palette := theme.HighContrastPalette()
+	m := detected(NewModelWithServices(app.NewServices(&app.Config{IsTestMode: true, Theme: &palette})))
+	m.width, m.height = 120, 40
+
+	if m.styles.Blue != palette.Blue || m.styles.Title.GetForeground() != palette.Blue {
+		t.Errorf("Expected the styles to use the configured palette, got %+v", m.styles.Palette)
+	}
+	m.mode = ModeSettings
+	if view := m.View(); !strings.Contains(view, "Theme: high-contrast") {
+		t.Errorf("Expected the settings to name the theme, got: %s", view)
+	}
+
+	noColor := theme.NoColorPalette()
+	m = detected(NewModelWithServices(app.NewServices(&app.Config{IsTestMode: true, Theme: &noColor})))
+	if m.styles.Title.GetForeground() != lipgloss.Color("") {
+		t.Errorf("Expected no colour, got %v", m.styles.Title.GetForeground())
+	}
+}
+
+func TestMonitorSelection(t *testing.T) {
+	model := detected(NewModel())
+
+	if len(model.monitors) == 0 {
+		t.Skip("No monitors available for testing")
+	}
+
+	model.mode = ModeMonitorSelection
+	model.selectedMonitor = 0
+
+	keyMsg := tea.KeyMsg{Type: tea.KeyDown}
+	updatedModel, _ := model.handleKeyPress(keyMsg)
+
+	if model, ok := updatedModel.(Model); ok {
+		if len(model.monitors) > 1 && model.selectedMonitor != 1 {
+			t.Errorf("Expected selectedMonitor 1, got %d", model.selectedMonitor)
+		}
+
+		keyMsg = tea.KeyMsg{Type: tea.KeyUp}
+		updatedModel2, _ := model.handleKeyPress(keyMsg)
+
+		if model2, ok := updatedModel2.(Model); ok {
+			if model2.selectedMonitor != 0 {
+				t.Errorf("Expected selectedMonitor 0, got %d", model2.selectedMonitor)
+			}
+		}
+	}
+}
+
+func TestConfirmationFlow(t *testing.T) {
+	model := detected(NewModel())
+
+	if len(model.monitors) == 0 {
+		t.Skip("No monitors available for testing")
+	}
+
+	model.mode = ModeScalingOptions
+	model.selectedMonitor = 0
+	model.selectedScalingOpt = 0
+
+	keyMsg := tea.KeyMsg{Type: tea.KeyEnter}
+	updatedModel, _ := model.handleKeyPress(keyMsg)
+
+	if model, ok := updatedModel.(Model); ok {
+		if model.mode != ModeConfirmation {
+			t.Errorf("Expected ModeConfirmation, got %v", model.mode)
+		}
+
+		keyMsg = tea.KeyMsg{Type: tea.KeyEscape}
+		updatedModel2, _ := model.handleKeyPress(keyMsg)
+
+		if model2, ok := updatedModel2.(Model); ok {
+			if model2.mode != ModeScalingOptions {
+				t.Errorf("Expected ModeScalingOptions, got %v", model2.mode)
+			}
+		}
+	}
+}
+
+// click renders the model and clicks in the middle of a zone.
+func click(t *testing.T, m Model, kind string, index int) Model {
+	t.Helper()
+	_ = m.View()
+	r, ok := m.zones.Get(zoneID(kind, index))
+	if !ok {
+		t.Fatalf("Expected %s to be on screen in mode %v", zoneID(kind, index), m.mode)
+	}
+	updated, _ := m.Update(tea.MouseMsg{
+		X: r.X + r.Width/2, Y: r.Y + r.Height/2,
+		Action: tea.MouseActionPress, Button: tea.MouseButtonLeft,
+	})
+	return updated.(Model)
+}
+
+// wheel renders the model and scrolls over a zone, or over nothing if kind
+// is empty.
+func wheel(t *testing.T, m Model, kind string, index int, button tea.MouseButton) Model {
+	t.Helper()
+	_ = m.View()
+	x, y := 0, 0
+	if kind != "" {
+		r, ok := m.zones.Get(zoneID(kind, index))
+		if !ok {
+			t.Fatalf("Expected %s to be on screen in mode %v", zoneID(kind, index), m.mode)
+		}
+		x, y = r.X, r.Y
+	}
+	updated, _ := m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: button})
+	return updated.(Model)
+}
+
+func TestMouse(t *testing.T) {
+	model := createTestModelForVisual(ModeDashboard)
+	model.width, model.height = 120, 60
+
+	// Menu entries open on the first click
+	model = click(t, model, zoneMenu, 1)
+	if model.mode != ModeMonitorSelection || model.selectedOption != 1 {
+		t.Fatalf("Expected the click to open monitor selection, got mode %v", model.mode)
+	}
+
+	// List items are selected by one click and opened by a second
+	model = click(t, model, zoneMonitor, 1)
+	if model.mode != ModeMonitorSelection || model.selectedMonitor != 1 {
+		t.Fatalf("Expected the second monitor to be selected, got mode %v, monitor %d", model.mode, model.selectedMonitor)
+	}
+	model = click(t, model, zoneMonitor, 1)
+	if model.mode != ModeDashboard {
+		t.Fatalf("Expected clicking the selected monitor to return to the dashboard, got %v", model.mode)
+	}
+
+	model.mode = ModeScalingOptions
+	model = click(t, model, zoneOption, 1)
+	if model.selectedScalingOpt != 1 || model.mode != ModeScalingOptions {
+		t.Fatalf("Expected the second option to be selected, got %d in mode %v", model.selectedScalingOpt, model.mode)
+	}
+	model = click(t, model, zoneOption, 1)
+	if model.mode != ModeConfirmation || model.pendingOption != model.scalingOptions[1] {
+		t.Fatalf("Expected clicking the selected option to ask for confirmation, got %v", model.mode)
+	}
+
+	model = click(t, model, zoneCancel, 0)
+	if model.mode != ModeScalingOptions {
+		t.Fatalf("Expected Cancel to go back to the options, got %v", model.mode)
+	}
+	model = click(t, model, zoneOption, 1)
+	model = click(t, model, zoneConfirm, 0)
+	if model.mode == ModeConfirmation || model.monitors[1].Scale != model.scalingOptions[1].MonitorScale {
+		t.Errorf("Expected Apply to apply the option, got mode %v, scale %.2f", model.mode, model.monitors[1].Scale)
+	}
+
+	// The wheel moves through lists wherever it is
+	model.mode = ModeDashboard
+	model.selectedOption = 0
+	model = wheel(t, model, "", 0, tea.MouseButtonWheelDown)
+	if model.selectedOption != 1 {
+		t.Errorf("Expected the wheel to move down the menu, got %d", model.selectedOption)
+	}
+}
+
+func TestMouseManualScaling(t *testing.T) {
+	model := createTestModelForVisual(ModeManualScaling)
+	model.width, model.height = 120, 50
+	dpi := model.manualFontDPI
+
+	// The wheel over a control picks it and adjusts its value
+	model = wheel(t, model, zoneControl, 2, tea.MouseButtonWheelUp)
+	if model.selectedManualControl != 2 || model.manualFontDPI != dpi+12 {
+		t.Errorf("Expected the font DPI to go up by 12, got control %d, DPI %d", model.selectedManualControl, model.manualFontDPI)
+	}
+	model = wheel(t, model, zoneControl, 0, tea.MouseButtonWheelUp)
+	if model.selectedManualControl != 0 || model.manualMonitorScale <= 1.0 {
+		t.Errorf("Expected the monitor scale to go up, got control %d, scale %.2f", model.selectedManualControl, model.manualMonitorScale)
+	}
+	model = wheel(t, model, zoneControl, 0, tea.MouseButtonWheelDown)
+	if model.manualMonitorScale != 1.0 {
+		t.Errorf("Expected the monitor scale back at 1.0, got %.2f", model.manualMonitorScale)
+	}
+
+	model = click(t, model, zoneControl, 1)
+	if model.selectedManualControl != 1 {
+		t.Errorf("Expected a click to select the GTK control, got %d", model.selectedManualControl)
+	}
+
+	// Releases and motion do nothing
+	updated, _ := model.Update(tea.MouseMsg{Action: tea.MouseActionRelease, Button: tea.MouseButtonLeft})
+	if updated.(Model).selectedManualControl != 1 {
+		t.Error("Expected a release to be ignored")
+	}
+}
+
+func sixMonitors() []monitor.Monitor {
+	monitors := make([]monitor.Monitor, 6)
+	for i := range monitors {
+		monitors[i] = monitor.Monitor{
+			Name: fmt.Sprintf("DP-%d", i+1), Make: "Dell", Model: "U2720Q",
+			Width: 3840, Height: 2160, RefreshRate: 60, Scale: 1.5, IsActive: true,
+		}
+	}
+	return monitors
+}
+
+func TestViewFitsTerminal(t *testing.T) {
+	modes := []AppMode{
+		ModeDashboard, ModeMonitorSelection, ModeScalingOptions, ModeManualScaling,
+		ModeSettings, ModeHelp, ModeHistory, ModeLogs, ModeScalingComparison,
+	}
+	for _, size := range []struct{ width, height int }{{80, 24}, {100, 30}, {80, 20}} {
+		for _, mode := range modes {
+			model := createTestModelWithMonitors(mode, sixMonitors())
+			model.width, model.height = size.width, size.height
+			if h := lipgloss.Height(model.View()); h != size.height {
+				t.Errorf("Mode %v at %dx%d: expected %d lines, got %d", mode, size.width, size.height, size.height, h)
+			}
+		}
+	}
+}
+
+func TestViewportScrolling(t *testing.T) {
+	model := createTestModelWithMonitors(ModeMonitorSelection, sixMonitors())
+	model.width, model.height = 80, 24
+
+	view0 := model.View()
+	view := view0
+	if strings.Contains(view, "DP-6") || !strings.Contains(view, "▼") {
+		t.Fatalf("Expected the last monitor below the fold with an indicator, got:\n%s", view)
+	}
+
+	// Moving the selection keeps it in view, so every monitor can be reached
+	first := model
+	for i := 0; i < 5; i++ {
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		model = updated.(Model)
+	}
+	view = model.View()
+	if model.selectedMonitor != 5 || !strings.Contains(view, "DP-6") || !strings.Contains(view, "▲") {
+		t.Errorf("Expected the sixth monitor to be scrolled into view, got:\n%s", view)
+	}
+	if first.View() != view0 {
+		t.Error("Drawing a later model must not scroll an earlier one")
+	}
+
+	// The page keys scroll screens without a selection
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	model = updated.(Model)
+	if view := model.View(); !strings.Contains(view, "Help & Controls") {
+		t.Fatalf("Expected help to start at the top, got:\n%s", view)
+	}
+	for i := 0; i < 20; i++ {
+		updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+		model = updated.(Model)
+	}
+	view = model.View()
+	if !strings.Contains(view, "Built with") || strings.Contains(view, "Help & Controls") {
+		t.Errorf("Expected page down to reach the end of the help, got:\n%s", view)
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model = updated.(Model)
+	if !strings.Contains(model.View(), "▼") {
+		t.Error("Expected the up key to scroll the help back up a line")
+	}
+
+	// Leaving and coming back starts at the top again
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(Model)
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	model = updated.(Model)
+	if view := model.View(); !strings.Contains(view, "Help & Controls") {
+		t.Errorf("Expected help to start at the top again, got:\n%s", view)
+	}
+}
+
+func TestDashboardShowsEveryMonitor(t *testing.T) {
+	model := createTestModelWithMonitors(ModeDashboard, sixMonitors())
+	model.width, model.height = 150, 60
+
+	view := model.View()
+	for _, mon := range model.monitors {
+		if !strings.Contains(view, mon.Name) {
+			t.Errorf("Expected %s on the dashboard", mon.Name)
+		}
+	}
+}
+
+type waybarConfigManager struct {
+	MockConfigManager
+	plan     monitor.Plan
+	executed []monitor.Plan
+	// waybarErr fails executing plan
+	waybarErr error
+}
+
+func (w *waybarConfigManager) PlanWaybar(fontScale float64) (monitor.Plan, error) {
+	return w.plan, nil
+}
+
+func (w *waybarConfigManager) Execute(plan monitor.Plan) (monitor.Verification, error) {
+	w.executed = append(w.executed, plan)
+	if plan.Description == w.plan.Description {
+		return monitor.Verification{}, w.waybarErr
+	}
+	return monitor.Verification{}, nil
+}
+
+func TestWaybarPreviewFlow(t *testing.T) {
+	plan := monitor.Plan{Description: "Scale Waybar", Files: []monitor.FileChange{{
+		Path:   "/home/user/.config/waybar/style.css",
+		Before: "* {\n  font-size: 12px;\n}\n",
+		After:  "* {\n  font-size: 18px;\n}\n",
+	}}}
+
+	for _, tt := range []struct {
+		name          string
+		key           tea.KeyMsg
+		expectApplied bool
+	}{
+		{"enter writes changes", tea.KeyMsg{Type: tea.KeyEnter}, true},
+		{"esc skips changes", tea.KeyMsg{Type: tea.KeyEscape}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			configManager := &waybarConfigManager{plan: plan}
+			model := createTestModelForVisual(ModeConfirmation)
+			model.services.ConfigManager = configManager
+			model.isDemoMode = false
+			model.width, model.height = 120, 40
+			model.confirmationAction = ConfirmSmartScaling
+			model.pendingMonitor = model.monitors[0]
+			model.pendingOption = monitor.ScalingOption{MonitorScale: 1.5, FontScale: 1.5}
+
+			updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+			model = updated.(Model)
+			if model.mode != ModeWaybarPreview {
+				t.Fatalf("Expected ModeWaybarPreview, got %v", model.mode)
+			}
+
+			view := model.View()
+			for _, expected := range []string{"Review Waybar Changes", "-  font-size: 12px;", "+  font-size: 18px;"} {
+				if !strings.Contains(view, expected) {
+					t.Errorf("Expected preview to contain %q", expected)
+				}
+			}
+
+			updated, _ = model.handleKeyPress(tt.key)
+			model = updated.(Model)
+			if model.mode != ModeDashboard {
+				t.Errorf("Expected ModeDashboard, got %v", model.mode)
+			}
+			// The scaling plan is always executed; the Waybar plan only on Enter.
+			applied := len(configManager.executed) == 2
+			if applied != tt.expectApplied {
+				t.Errorf("Expected applied=%v, got %d executed plans", tt.expectApplied, len(configManager.executed))
+			}
+		})
+	}
+}
+
+func TestWaybarPreviewFailure(t *testing.T) {
+	plan := monitor.Plan{Description: "Scale Waybar", Files: []monitor.FileChange{{
+		Path:   "/home/user/.config/waybar/style.css",
+		Before: "* {\n  font-size: 12px;\n}\n",
+		After:  "* {\n  font-size: 18px;\n}\n",
+	}}}
+	configManager := &waybarConfigManager{plan: plan, waybarErr: errors.New("waybar is not running")}
+	model := createTestModelForVisual(ModeConfirmation)
+	model.services.ConfigManager = configManager
+	model.services.History = history.NewJournal(t.TempDir(), history.DefaultRetention)
+	model.services.Config.IsTestMode = false
+	model.isDemoMode = false
+	model.width, model.height = 120, 40
+	model.confirmationAction = ConfirmSmartScaling
+	model.pendingMonitor = model.monitors[0]
+	model.pendingOption = monitor.ScalingOption{DisplayName: "1.5x Large", MonitorScale: 1.5, FontScale: 1.5}
+
+	press := func() {
+		t.Helper()
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model = updated.(Model)
+	}
+	press()
+	press()
+	if model.mode != ModeWaybarPreview || model.waybarErr == nil {
+		t.Fatalf("Expected the preview to stay open with the error, got mode %v, %v", model.mode, model.waybarErr)
+	}
+	if view := model.View(); !strings.Contains(view, "waybar is not running") {
+		t.Errorf("Expected the error in the preview, got:\n%s", view)
+	}
+	entries, _ := model.services.History.List()
+	if len(entries) != 2 || entries[0].Action != "Scale Waybar" {
+		t.Errorf("Expected a snapshot before the Waybar write, got %+v", entries)
+	}
+
+	// Trying again doesn't run the failed plan a second time
+	press()
+	if len(configManager.executed) != 2 {
+		t.Errorf("Expected the failed plan to run once, got %d executed plans", len(configManager.executed))
+	}
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	model = updated.(Model)
+	if model.mode != ModeDashboard || model.waybarErr != nil {
+		t.Errorf("Expected esc to skip Waybar, got mode %v, %v", model.mode, model.waybarErr)
+	}
+}
+
+type transactionConfigManager struct {
+	MockConfigManager
+	planned      []monitor.Transaction
+	executed     []monitor.Plan
+	verification monitor.Verification
+	err          error
+	// fontScale and cursorSize are what the settings are at now
+	fontScale  float64
+	cursorSize int
+}
+
+func (c *transactionConfigManager) CurrentFontScale() (float64, error) {
+	if c.fontScale == 0 {
+		return 1, nil
+	}
+	return c.fontScale, nil
+}
+
+func (c *transactionConfigManager) CurrentCursorSize() (int, error) {
+	if c.cursorSize == 0 {
+		return 24, nil
+	}
+	return c.cursorSize, nil
+}
+
+func (c *transactionConfigManager) PlanTransaction(tx monitor.Transaction) (monitor.Plan, error) {
+	c.planned = append(c.planned, tx)
+	return monitor.Plan{Description: tx.Description}, nil
+}
+
+func (c *transactionConfigManager) Execute(plan monitor.Plan) (monitor.Verification, error) {
+	c.executed = append(c.executed, plan)
+	return c.verification, c.err
+}
+
+func TestStagedMultiMonitorApply(t *testing.T) {
+	configManager := &transactionConfigManager{}
+	model := createTestModelForVisual(ModeScalingOptions)
+	model.services.ConfigManager = configManager
+	model.isDemoMode = false
+	model.width, model.height = 120, 40
+
+	model.scalingOptions = []monitor.ScalingOption{{DisplayName: "1.5x", MonitorScale: 1.5, GTKScale: 1, FontDPI: 96}}
+	updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	model = updated.(Model)
+	if model.mode != ModeMonitorSelection || len(model.staged) != 1 {
+		t.Fatalf("Expected monitor selection with 1 staged change, got mode %v, %d staged", model.mode, len(model.staged))
+	}
+	if view := model.View(); !strings.Contains(view, "staged 1.50x") {
+		t.Error("Expected the staged monitor to be marked")
+	}
+
+	updated, _ = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	model = updated.(Model)
+	model.mode = ModeScalingOptions
+	model.scalingOptions = []monitor.ScalingOption{{DisplayName: "2x", MonitorScale: 2.0, GTKScale: 2, FontDPI: 120}}
+	updated, _ = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.mode != ModeConfirmation {
+		t.Fatalf("Expected ModeConfirmation, got %v", model.mode)
+	}
+
+	if len(configManager.planned) != 1 {
+		t.Fatalf("Expected one transaction to be planned, got %d", len(configManager.planned))
+	}
+	tx := configManager.planned[0]
+	if len(tx.Monitors) != 2 || tx.Monitors[0].Monitor.Name != "HDMI-A-1" || tx.Monitors[0].Scale != 1.5 ||
+		tx.Monitors[1].Monitor.Name != "DP-1" || tx.Monitors[1].Scale != 2.0 || tx.GTKScale != 2 {
+		t.Fatalf("Unexpected transaction: %+v", tx)
+	}
+	if view := model.View(); !strings.Contains(view, "Target Monitors (2, applied together)") {
+		t.Error("Expected the confirmation to list both monitors")
+	}
+
+	// A failed transaction keeps the confirmation open with the error.
+	configManager.err = errors.New("monitor DP-1 disappeared while applying; all changes were rolled back")
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.mode != ModeConfirmation || !strings.Contains(model.View(), "rolled back") {
+		t.Fatal("Expected the rollback error to be shown on the confirmation screen")
+	}
+	if model.monitors[0].Scale != 1.0 || len(model.staged) != 1 {
+		t.Error("A failed transaction must leave scales and staged changes alone")
+	}
+
+	configManager.err = nil
+	model.pendingPlanErr = nil
+	updated, _ = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.mode != ModeDashboard {
+		t.Fatalf("Expected ModeDashboard, got %v", model.mode)
+	}
+	if len(configManager.executed) != 2 {
+		t.Errorf("Expected the transaction to be executed, got %d executions", len(configManager.executed))
+	}
+	if model.monitors[0].Scale != 1.5 || model.monitors[1].Scale != 2.0 {
+		t.Errorf("Expected both monitors to be updated, got %.2f and %.2f", model.monitors[0].Scale, model.monitors[1].Scale)
+	}
+	if len(model.staged) != 0 {
+		t.Error("Staged changes should be cleared after applying")
+	}
+}
+
+func TestDriftResolution(t *testing.T) {
+	requested := monitor.MonitorTarget{Monitor: monitor.Monitor{Name: "HDMI-A-1", Width: 1920, Height: 1080, Scale: 1.0}, Scale: 1.3}
+	adjusted := monitor.Monitor{Name: "HDMI-A-1", Width: 1920, Height: 1080, RefreshRate: 60, Scale: 1.2}
+	drifted := monitor.Verification{
+		Monitors: []monitor.Monitor{adjusted},
+		Drift: []monitor.Drift{{
+			Property:  monitor.DriftScale,
+			Requested: "1.3",
+			Actual:    "1.2",
+			Target:    requested,
+			Current:   adjusted,
+		}},
+	}
+
+	confirm := func(t *testing.T, configManager *transactionConfigManager) Model {
+		t.Helper()
+		model := createTestModelForVisual(ModeScalingOptions)
+		model.services.ConfigManager = configManager
+		model.isDemoMode = false
+		model.width, model.height = 120, 40
+		model.scalingOptions = []monitor.ScalingOption{{DisplayName: "1.3x", MonitorScale: 1.3, GTKScale: 1, FontDPI: 96}}
+
+		updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		updated, _ = updated.(Model).handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		model = updated.(Model)
+		if model.mode != ModeDrift {
+			t.Fatalf("Expected ModeDrift, got %v", model.mode)
+		}
+		view := model.View()
+		for _, expected := range []string{"requested", "1.3", "1.2", "Accept the compositor's values", "1.33x for HDMI-A-1"} {
+			if !strings.Contains(view, expected) {
+				t.Errorf("Expected the drift screen to contain %q", expected)
+			}
+		}
+		return model
+	}
+
+	t.Run("accept", func(t *testing.T) {
+		configManager := &transactionConfigManager{verification: drifted}
+		model := confirm(t, configManager)
+
+		updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		model = updated.(Model)
+		if model.mode != ModeDashboard {
+			t.Fatalf("Expected ModeDashboard, got %v", model.mode)
+		}
+		if model.monitors[0].Scale != 1.2 {
+			t.Errorf("Expected the compositor's scale to be kept, got %.2f", model.monitors[0].Scale)
+		}
+		if len(configManager.executed) != 1 {
+			t.Errorf("Accepting must not apply anything else, got %d executions", len(configManager.executed))
+		}
+	})
+
+	t.Run("nearest valid", func(t *testing.T) {
+		configManager := &transactionConfigManager{verification: drifted}
+		model := confirm(t, configManager)
+		configManager.verification = monitor.Verification{}
+		model.services.History = history.NewJournal(t.TempDir(), history.DefaultRetention)
+		model.services.Config.IsTestMode = false
+
+		updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+		updated, _ = updated.(Model).handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+		model = updated.(Model)
+		if model.mode != ModeDashboard {
+			t.Fatalf("Expected ModeDashboard, got %v", model.mode)
+		}
+		if len(configManager.planned) != 2 || configManager.planned[1].Monitors[0].Scale < 1.33 || configManager.planned[1].Monitors[0].Scale > 1.34 {
+			t.Fatalf("Expected the nearest valid scale to be applied, got %+v", configManager.planned)
+		}
+		if model.monitors[0].Scale != configManager.planned[1].Monitors[0].Scale {
+			t.Errorf("Expected the local scale to follow, got %.5f", model.monitors[0].Scale)
+		}
+		if entries, _ := model.services.History.List(); len(entries) != 1 || entries[0].Action != "Use the nearest valid scale" {
+			t.Errorf("Expected the nearest scale to be journaled, got %+v", entries)
+		}
+
+		// Undo goes back to the scale the compositor picked
+		updated, _ = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+		model = updated.(Model)
+		if len(configManager.planned) != 3 || configManager.planned[2].Monitors[0].Scale != 1.2 || model.monitors[0].Scale != 1.2 {
+			t.Errorf("Expected undo to restore 1.2x, got %+v (%q)", configManager.planned, model.history.status)
+		}
+	})
+}
+
+type historyConfigManager struct {
+	MockConfigManager
+	paths    []string
+	applied  []float64
+	executed []monitor.Plan
+}
+
+func (h *historyConfigManager) Execute(plan monitor.Plan) (monitor.Verification, error) {
+	h.executed = append(h.executed, plan)
+	return monitor.Verification{}, nil
+}
+
+func (h *historyConfigManager) ManagedFiles() []string {
+	return h.paths
+}
+
+func (h *historyConfigManager) ApplyMonitorScale(mon monitor.Monitor, scale float64) error {
+	h.applied = append(h.applied, scale)
+	return nil
+}
+
+type failingDetector struct{}
+
+func (failingDetector) DetectMonitors() ([]monitor.Monitor, error) {
+	return nil, monitor.ErrNoBackend
+}
+
+func TestDemoModeResolution(t *testing.T) {
+	tests := []struct {
+		name       string
+		modeDemo   bool
+		detectFail bool
+		forceLive  bool
+		expected   bool
+	}{
+		{"hyprland session", false, false, false, false},
+		{"hyprland session, detection fails", false, true, false, true},
+		{"other session", true, false, false, true},
+		{"other session, detection fails", true, true, false, true},
+		{"forced live, detection fails", false, true, true, false},
+		{"forced live in another session", false, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var detector monitor.DetectorInterface = &MockMonitorDetector{}
+			if tt.detectFail {
+				detector = failingDetector{}
+			}
+			services := &app.Services{
+				Config:          &app.Config{ForceLiveMode: tt.forceLive, IsTestMode: true},
+				MonitorDetector: detector,
+				ScalingManager:  &MockScalingManager{},
+				ConfigManager:   &MockConfigManager{},
+				Mode:            app.Mode{Demo: tt.modeDemo, Probed: true},
+			}
+
+			model := detected(NewModelWithServices(services))
+			if model.isDemoMode != tt.expected {
+				t.Errorf("Expected demo mode %v, got %v", tt.expected, model.isDemoMode)
+			}
+			if tt.forceLive && tt.detectFail && model.load != loadFailed {
+				t.Errorf("Expected the error screen when live mode is forced, got load %v", model.load)
+			}
+			if !tt.detectFail && len(model.monitors) != 1 {
+				t.Errorf("Expected the detected monitor to be shown, got %d", len(model.monitors))
+			}
+		})
+	}
+}
+
+// detectorFunc answers detection with a function.
+type detectorFunc func() ([]monitor.Monitor, error)
+
+func (f detectorFunc) DetectMonitors() ([]monitor.Monitor, error) {
+	return f()
+}
+
+func newDetectionTestModel(t *testing.T, detector monitor.DetectorInterface) *teatest.TestModel {
+	t.Helper()
+	services := &app.Services{
+		Config:          &app.Config{IsTestMode: true},
+		MonitorDetector: detector,
+		ScalingManager:  &MockScalingManager{},
+		ConfigManager:   &MockConfigManager{},
+		Mode:            app.Mode{Probed: true, Reason: "Hyprland session"},
+	}
+	return teatest.NewTestModel(t, NewModelWithServices(services), teatest.WithInitialTermSize(120, 40))
+}
+
+// waitForOutput waits until the program has drawn all of texts. Output read
+// while waiting is consumed, so later waits only see what's drawn after.
+func waitForOutput(t *testing.T, tm *teatest.TestModel, texts ...string) {
+	t.Helper()
+	teatest.WaitFor(t, tm.Output(), func(out []byte) bool {
+		for _, text := range texts {
+			if !strings.Contains(string(out), text) {
+				return false
+			}
+		}
+		return true
+	}, teatest.WithDuration(3*time.Second))
+}
+
+func quit(t *testing.T, tm *teatest.TestModel) Model {
+	t.Helper()
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	return tm.FinalModel(t, teatest.WithFinalTimeout(3*time.Second)).(Model)
+}
+
+func TestDetectionRendersProgressFirst(t *testing.T) {
+	release := make(chan struct{})
+	tm := newDetectionTestModel(t, detectorFunc(func() ([]monitor.Monitor, error) {
+		<-release
+		return (&MockMonitorDetector{}).DetectMonitors()
+	}))
+
+	// The UI is up before the compositor answers
+	waitForOutput(t, tm, "Detecting monitors")
+	close(release)
+	waitForOutput(t, tm, "HDMI-A-1")
+
+	final := quit(t, tm)
+	if final.load != loadReady || len(final.monitors) != 1 || final.isDemoMode {
+		t.Errorf("Expected one live monitor, got load %v, %d monitors, demo %v", final.load, len(final.monitors), final.isDemoMode)
+	}
+}
+
+func TestDetectionErrorRetry(t *testing.T) {
+	var calls atomic.Int32
+	tm := newDetectionTestModel(t, detectorFunc(func() ([]monitor.Monitor, error) {
+		if calls.Add(1) == 1 {
+			return nil, errors.New("hyprctl monitors timed out")
+		}
+		return (&MockMonitorDetector{}).DetectMonitors()
+	}))
 
-			view := model.View()
+	waitForOutput(t, tm, "Monitor detection failed", "hyprctl monitors timed out")
 
-			if !strings.Contains(view, tt.expected) {
-				t.Errorf("View should contain '%s', got: %s", tt.expected, view)
-			}
-		})
+	// Keys other than retry and quit do nothing on the error screen
+	tm.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	waitForOutput(t, tm, "HDMI-A-1")
+
+	if final := quit(t, tm); final.load != loadReady || final.loadErr != nil {
+		t.Errorf("Expected the retry to succeed, got load %v, error %v", final.load, final.loadErr)
 	}
 }
 
-func TestScalingOptions(t *testing.T) {
-	model := NewModel()
+func TestDashboardRefresh(t *testing.T) {
+	var calls atomic.Int32
+	tm := newDetectionTestModel(t, detectorFunc(func() ([]monitor.Monitor, error) {
+		monitors, _ := (&MockMonitorDetector{}).DetectMonitors()
+		switch calls.Add(1) {
+		case 1:
+			return monitors, nil
+		case 2:
+			return append(monitors, monitor.Monitor{Name: "DP-2", Width: 2560, Height: 1440, RefreshRate: 144, Scale: 1, IsActive: true}), nil
+		default:
+			return nil, errors.New("hyprctl: connection refused")
+		}
+	}))
 
-	if len(model.monitors) == 0 {
-		t.Skip("No monitors available for testing")
+	waitForOutput(t, tm, "HDMI-A-1")
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	waitForOutput(t, tm, "Found 2 monitor(s)")
+
+	// A failed refresh keeps the monitors already shown
+	tm.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	waitForOutput(t, tm, "Refresh failed")
+
+	final := quit(t, tm)
+	if final.load != loadReady || len(final.monitors) != 2 {
+		t.Errorf("Expected both monitors after the failed refresh, got load %v, %d monitors", final.load, len(final.monitors))
 	}
+}
 
-	scalingManager := monitor.NewScalingManager()
-	options := scalingManager.GetIntelligentScalingOptions(model.monitors[0])
+func TestDetectionErrorDemoFallback(t *testing.T) {
+	for _, forceLive := range []bool{false, true} {
+		detector := monitor.NewDetectorForBackend(session.BackendHyprctl)
+		detector.SetRunner(runner.Func(func(context.Context, string, ...string) (runner.Result, error) {
+			return runner.Result{ExitCode: -1}, errors.New("hyprctl monitors timed out")
+		}))
+		services := &app.Services{
+			Config:          &app.Config{IsTestMode: true, ForceLiveMode: forceLive},
+			MonitorDetector: detector,
+			ScalingManager:  &MockScalingManager{},
+			ConfigManager:   &MockConfigManager{},
+		}
+		model := detected(NewModelWithServices(services))
+		model.width, model.height = 120, 40
+		if model.load != loadFailed {
+			t.Fatalf("Expected the error screen, got load %v", model.load)
+		}
 
-	if len(options) == 0 {
-		t.Error("Should have scaling options")
+		offered := strings.Contains(model.View(), "demo monitors")
+		if offered == forceLive {
+			t.Errorf("force-live %v: expected demo monitors offered %v", forceLive, !forceLive)
+		}
+
+		updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+		model = updated.(Model)
+		if forceLive && model.load != loadFailed {
+			t.Error("Expected d to do nothing when live mode is forced")
+		}
+		if !forceLive && (model.load != loadReady || !model.isDemoMode || len(model.monitors) == 0) {
+			t.Errorf("Expected demo monitors after d, got load %v, demo %v, %d monitors", model.load, model.isDemoMode, len(model.monitors))
+		}
 	}
+}
 
-	hasRecommended := false
-	for _, option := range options {
-		if option.IsRecommended {
-			hasRecommended = true
-			break
+func TestSimulatedSession(t *testing.T) {
+	services := app.NewSimulatedServices(&app.Config{IsTestMode: true}, simtest.Sandbox(t, "tv"))
+	defer services.Close()
+
+	model := detected(NewModelWithServices(services))
+	if model.isDemoMode {
+		t.Error("A simulation applies to the simulated compositor, not in demo mode")
+	}
+	if len(model.monitors) != 1 || model.monitors[0].Name != "eDP-1" {
+		t.Errorf("Expected the scenario's laptop panel, got %+v", model.monitors)
+	}
+
+	settings := model.renderSettings(40)
+	for _, expected := range []string{"Simulated", "simulated (tv)"} {
+		if !strings.Contains(settings, expected) {
+			t.Errorf("Expected settings to show %q, got:\n%s", expected, settings)
 		}
 	}
-	if !hasRecommended {
-		t.Error("Should have at least one recommended option")
+}
+
+// serveDaemon runs a daemon for a simulated scenario and connects to it.
+func serveDaemon(t *testing.T, scenario string) (*service.Service, *app.Services, *simulate.Sandbox, *client.Client) {
+	t.Helper()
+	daemonServices, sandbox := simtest.Services(t, scenario)
+	path := filepath.Join(simtest.SocketDir(t, "tui"), client.SocketName)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc := service.New(daemonServices)
+	go service.ServeRPC(ctx, listener, svc, svc.Watch(ctx, nil, 0))
+
+	daemon, err := client.Dial(path)
+	if err != nil {
+		t.Fatalf("Failed to connect to the daemon: %v", err)
 	}
+	t.Cleanup(func() { daemon.Close() })
+	return svc, daemonServices, sandbox, daemon
 }
 
-func TestMonitorSelection(t *testing.T) {
-	model := NewModel()
+// daemonModel is a TUI following daemon whose own detector fails, so the
+// monitors can only come from the daemon.
+func daemonModel(daemon *client.Client) Model {
+	services := &app.Services{
+		Config:          &app.Config{IsTestMode: true},
+		MonitorDetector: detectorFunc(func() ([]monitor.Monitor, error) { return nil, errors.New("not a Hyprland session") }),
+		ScalingManager:  &MockScalingManager{},
+		ConfigManager:   &MockConfigManager{},
+	}
+	return detected(NewModelWithServices(services).WithDaemon(daemon))
+}
 
-	if len(model.monitors) == 0 {
-		t.Skip("No monitors available for testing")
+func TestDaemonSharesState(t *testing.T) {
+	svc, _, sandbox, daemon := serveDaemon(t, "6k-laptop")
+	model := daemonModel(daemon)
+	if model.load != loadReady || len(model.monitors) != 2 || model.monitors[1].Name != "DP-1" {
+		t.Fatalf("Expected the daemon's monitors, got load %v, %+v", model.load, model.monitors)
 	}
 
-	model.mode = ModeMonitorSelection
-	model.selectedMonitor = 0
+	// eDP-1 starts at 2x, so pick an option that changes it
+	options, _ := svc.Options("eDP-1")
+	index := 0
+	for i, option := range options {
+		if option.MonitorScale != 2 {
+			index = i + 1
+			break
+		}
+	}
+	if _, err := svc.Apply("test", client.ApplyRequest{Monitor: "eDP-1", Option: index}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	live, _ := sandbox.Compositor.DetectMonitors()
+	msg := model.waitForDaemon()()
+	updated, cmd := model.Update(msg)
+	model = updated.(Model)
+	if model.monitors[0].Scale != live[0].Scale || model.refreshStatus != "Monitors changed" {
+		t.Errorf("Expected eDP-1 at %v after the daemon's change, got %+v (%q)", live[0].Scale, model.monitors[0], model.refreshStatus)
+	}
+	if cmd == nil {
+		t.Fatal("Expected to keep following the daemon")
+	}
 
-	keyMsg := tea.KeyMsg{Type: tea.KeyDown}
-	updatedModel, _ := model.handleKeyPress(keyMsg)
+	daemon.Close()
+	updated, _ = model.Update(cmd())
+	model = updated.(Model)
+	if model.daemon != nil {
+		t.Error("Expected detection to go direct once the daemon is gone")
+	}
+}
 
-	if model, ok := updatedModel.(Model); ok {
-		if len(model.monitors) > 1 && model.selectedMonitor != 1 {
-			t.Errorf("Expected selectedMonitor 1, got %d", model.selectedMonitor)
+func TestDaemonAppliesAndRestores(t *testing.T) {
+	svc, daemonServices, sandbox, daemon := serveDaemon(t, "6k-laptop")
+	model := daemonModel(daemon)
+	// The TUI reads the journal the daemon writes
+	model.services.History = daemonServices.History
+	model.width, model.height = 120, 40
+
+	press := func(key tea.KeyMsg) {
+		t.Helper()
+		updated, _ := model.Update(key)
+		model = updated.(Model)
+	}
+	next := func() tea.Msg {
+		t.Helper()
+		msg := make(chan tea.Msg, 1)
+		go func() { msg <- model.waitForDaemon()() }()
+		select {
+		case m := <-msg:
+			return m
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected a change from the daemon")
+			return nil
 		}
+	}
 
-		keyMsg = tea.KeyMsg{Type: tea.KeyUp}
-		updatedModel2, _ := model.handleKeyPress(keyMsg)
+	model.mode = ModeConfirmation
+	model.confirmationAction = ConfirmManualScaling
+	model.pendingMonitor = model.monitors[0]
+	model.pendingOption = monitor.ScalingOption{DisplayName: "Manual Settings", MonitorScale: 1.5}
+	model.preparePlan()
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.mode != ModeDashboard || model.pendingPlanErr != nil {
+		t.Fatalf("Expected the apply to finish, got mode %v, %v", model.mode, model.pendingPlanErr)
+	}
+	if live, _ := sandbox.Compositor.DetectMonitors(); live[0].Scale != 1.5 {
+		t.Fatalf("Expected the daemon to set eDP-1 to 1.5x, got %v", live[0].Scale)
+	}
+	entries, err := svc.History()
+	if err != nil || len(entries) != 1 || entries[0].Source != "rpc" || !strings.HasPrefix(entries[0].Reason, "Manual scaling") {
+		t.Fatalf("Expected the daemon to record the apply, got %+v, %v", entries, err)
+	}
+	updated, _ := model.Update(next())
+	model = updated.(Model)
 
-		if model2, ok := updatedModel2.(Model); ok {
-			if model2.selectedMonitor != 0 {
-				t.Errorf("Expected selectedMonitor 0, got %d", model2.selectedMonitor)
-			}
+	// A change made elsewhere waits while the user is busy on another screen
+	model.mode = ModeManualScaling
+	options, _ := svc.Options("eDP-1")
+	index := 0
+	for i, option := range options {
+		if option.MonitorScale != 1.5 {
+			index = i + 1
+			break
 		}
 	}
+	if _, err := svc.Apply("test", client.ApplyRequest{Monitor: "eDP-1", Option: index}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	updated, _ = model.Update(next())
+	model = updated.(Model)
+	if model.monitors[0].Scale != 1.5 {
+		t.Errorf("Expected the monitors to stay put on the manual scaling screen, got %+v", model.monitors[0])
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.mode != ModeDashboard || model.monitors[0].Scale != options[index-1].MonitorScale || model.refreshStatus != "Monitors changed" {
+		t.Errorf("Expected the change once back on the dashboard, got mode %v, %+v (%q)", model.mode, model.monitors[0], model.refreshStatus)
+	}
+
+	// Restoring the newest snapshot puts back the 1.5x it was taken at
+	model.selectedOption = 4
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.mode != ModeHistory || len(model.historyEntries) != 2 {
+		t.Fatalf("Expected both applies in history, got mode %v, %d entries", model.mode, len(model.historyEntries))
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.HasPrefix(model.historyStatus, "✅ Restored") {
+		t.Fatalf("Unexpected status: %q", model.historyStatus)
+	}
+	if live, _ := sandbox.Compositor.DetectMonitors(); live[0].Scale != 1.5 {
+		t.Errorf("Expected the daemon to restore eDP-1 to 1.5x, got %v", live[0].Scale)
+	}
+	if len(model.historyEntries) != 3 || model.historyEntries[0].Source != "rpc" {
+		t.Errorf("Expected the daemon to journal the restore, got %+v", model.historyEntries)
+	}
 }
 
-func TestConfirmationFlow(t *testing.T) {
-	model := NewModel()
+func TestDebugLoggingStaysOffStdout(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	services := &app.Services{
+		Config:          &app.Config{NoHyprlandCheck: true, DebugMode: true, IsTestMode: true},
+		MonitorDetector: &MockMonitorDetector{},
+		ScalingManager:  &MockScalingManager{},
+		ConfigManager:   &MockConfigManager{},
+		Logger:          logging.Discard(),
+	}
+	model := detected(NewModelWithServices(services))
 
-	if len(model.monitors) == 0 {
-		t.Skip("No monitors available for testing")
+	writer.Close()
+	os.Stdout = stdout
+	if printed, _ := io.ReadAll(reader); len(printed) != 0 {
+		t.Errorf("Expected nothing on stdout, got %q", printed)
 	}
 
-	model.mode = ModeScalingOptions
-	model.selectedMonitor = 0
-	model.selectedScalingOpt = 0
+	found := false
+	for _, line := range model.logger.Recent() {
+		if strings.Contains(line, "level=INFO") && strings.Contains(line, "loaded monitors") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected detection to be logged, got %v", model.logger.Recent())
+	}
+}
 
-	keyMsg := tea.KeyMsg{Type: tea.KeyEnter}
-	updatedModel, _ := model.handleKeyPress(keyMsg)
+func TestLogViewer(t *testing.T) {
+	model := createTestModelForVisual(ModeDashboard)
+	model.width, model.height = 120, 40
+	for i := 0; i < 40; i++ {
+		model.logger.Info("entry", "n", i)
+	}
 
-	if model, ok := updatedModel.(Model); ok {
-		if model.mode != ModeConfirmation {
-			t.Errorf("Expected ModeConfirmation, got %v", model.mode)
-		}
+	model.selectedOption = 5
+	updated, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.mode != ModeLogs || cmd == nil {
+		t.Fatalf("Expected the log viewer with a refresh tick, got mode %v", model.mode)
+	}
+	view := model.View()
+	if !strings.Contains(view, "n=39") || strings.Contains(view, "n=0 ") {
+		t.Error("Expected the viewer to tail the newest entries")
+	}
 
-		keyMsg = tea.KeyMsg{Type: tea.KeyEscape}
-		updatedModel2, _ := model.handleKeyPress(keyMsg)
+	updated, _ = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyUp})
+	model = updated.(Model)
+	if model.logOffset != 1 || strings.Contains(model.View(), "n=39") {
+		t.Error("Expected scrolling up to hide the newest entry")
+	}
 
-		if model2, ok := updatedModel2.(Model); ok {
-			if model2.mode != ModeScalingOptions {
-				t.Errorf("Expected ModeScalingOptions, got %v", model2.mode)
-			}
+	if _, cmd := model.Update(logTickMsg{}); cmd == nil {
+		t.Error("Expected the viewer to keep refreshing while open")
+	}
+	model.mode = ModeDashboard
+	if _, cmd := model.Update(logTickMsg{}); cmd != nil {
+		t.Error("Expected refreshing to stop once the viewer is closed")
+	}
+}
+
+func TestHistoryRecordAndRestore(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "kitty.conf")
+	if err := os.WriteFile(configPath, []byte("font_size 10\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	configManager := &historyConfigManager{paths: []string{configPath}}
+	model := createTestModelForVisual(ModeConfirmation)
+	model.services.ConfigManager = configManager
+	model.services.History = history.NewJournal(t.TempDir(), history.DefaultRetention)
+	model.isDemoMode = false
+	model.width, model.height = 120, 40
+	model.confirmationAction = ConfirmSmartScaling
+	model.pendingMonitor = model.monitors[0]
+	model.pendingOption = monitor.ScalingOption{DisplayName: "1.5x Large", MonitorScale: 1.5, Reasoning: "Easier to read"}
+
+	updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+
+	// The apply changed the config file behind our back.
+	if err := os.WriteFile(configPath, []byte("font_size 15\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	model.selectedOption = 4
+	updated, _ = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.mode != ModeHistory {
+		t.Fatalf("Expected ModeHistory, got %v", model.mode)
+	}
+	if len(model.historyEntries) != 1 {
+		t.Fatalf("Expected 1 history entry, got %d", len(model.historyEntries))
+	}
+
+	view := model.View()
+	for _, expected := range []string{"Change History", "Apply 1.5x Large to HDMI-A-1", "Why: Easier to read"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected history view to contain %q", expected)
 		}
 	}
+
+	updated, _ = model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != "font_size 10\n" {
+		t.Errorf("Expected config to be restored, got %q", string(data))
+	}
+	if len(configManager.applied) != 1 || configManager.applied[0] != 1.0 {
+		t.Errorf("Expected monitor scale 1.0 to be restored, got %v", configManager.applied)
+	}
+	if !strings.HasPrefix(model.historyStatus, "✅ Restored") {
+		t.Errorf("Unexpected status: %q", model.historyStatus)
+	}
+	if len(model.historyEntries) != 2 {
+		t.Errorf("Expected the restore to be journaled, got %d entries", len(model.historyEntries))
+	}
+}
+
+func TestHistoryFailureStopsApply(t *testing.T) {
+	// A file where the journal's directory should be makes every record fail
+	blocked := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(blocked, nil, 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	configManager := &historyConfigManager{}
+	model := createTestModelForVisual(ModeConfirmation)
+	model.services.ConfigManager = configManager
+	model.services.History = history.NewJournal(blocked, history.DefaultRetention)
+	model.isDemoMode = false
+	model.confirmationAction = ConfirmSmartScaling
+	model.pendingMonitor = model.monitors[0]
+	model.pendingOption = monitor.ScalingOption{DisplayName: "1.5x Large", MonitorScale: 1.5}
+	model.preparePlan()
+
+	updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if len(configManager.executed) != 0 {
+		t.Error("Expected nothing to be applied without a snapshot")
+	}
+	if model.mode != ModeConfirmation || model.pendingPlanErr == nil || !strings.Contains(model.pendingPlanErr.Error(), "failed to record history") {
+		t.Errorf("Expected the journal error on the confirmation screen, got mode %v, %v", model.mode, model.pendingPlanErr)
+	}
 }
 
 func TestEdgeCases(t *testing.T) {
@@ -613,7 +1891,7 @@ func TestEdgeCases(t *testing.T) {
 		{
 			name: "empty monitors list",
 			setupModel: func() Model {
-				model := NewModel()
+				model := detected(NewModel())
 				model.monitors = []monitor.Monitor{}
 				return model
 			},
@@ -623,7 +1901,7 @@ func TestEdgeCases(t *testing.T) {
 		{
 			name: "invalid monitor selection",
 			setupModel: func() Model {
-				model := NewModel()
+				model := detected(NewModel())
 				model.selectedMonitor = 999
 				return model
 			},
@@ -633,7 +1911,7 @@ func TestEdgeCases(t *testing.T) {
 		{
 			name: "invalid scaling option selection",
 			setupModel: func() Model {
-				model := NewModel()
+				model := detected(NewModel())
 				model.mode = ModeScalingOptions
 				model.selectedScalingOpt = 999
 				return model
@@ -644,7 +1922,7 @@ func TestEdgeCases(t *testing.T) {
 		{
 			name: "boundary monitor scale values",
 			setupModel: func() Model {
-				model := NewModel()
+				model := detected(NewModel())
 				model.mode = ModeManualScaling
 				model.selectedManualControl = 0
 				model.manualMonitorScale = 0.1
@@ -656,7 +1934,7 @@ func TestEdgeCases(t *testing.T) {
 		{
 			name: "boundary GTK scale values",
 			setupModel: func() Model {
-				model := NewModel()
+				model := detected(NewModel())
 				model.mode = ModeManualScaling
 				model.selectedManualControl = 1
 				model.manualGTKScale = 1
@@ -668,7 +1946,7 @@ func TestEdgeCases(t *testing.T) {
 		{
 			name: "boundary font DPI values",
 			setupModel: func() Model {
-				model := NewModel()
+				model := detected(NewModel())
 				model.mode = ModeManualScaling
 				model.selectedManualControl = 2
 				model.manualFontDPI = 72
@@ -704,7 +1982,7 @@ func TestEdgeCases(t *testing.T) {
 }
 
 func TestPropertyBasedScaling(t *testing.T) {
-	model := NewModel()
+	model := detected(NewModel())
 	model.mode = ModeManualScaling
 
 	if model.manualMonitorScale <= 0 {
@@ -784,10 +2062,9 @@ func TestTerminalSizeConstraints(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			model := NewModel()
+			model := detected(NewModel())
 			model.width = tt.width
 			model.height = tt.height
-			model.ready = true
 
 			view := model.View()
 
@@ -805,7 +2082,7 @@ func TestTerminalSizeConstraints(t *testing.T) {
 }
 
 func BenchmarkModelUpdate(b *testing.B) {
-	model := NewModel()
+	model := detected(NewModel())
 	msg := tea.WindowSizeMsg{Width: 80, Height: 24}
 
 	b.ResetTimer()
@@ -816,10 +2093,9 @@ func BenchmarkModelUpdate(b *testing.B) {
 }
 
 func BenchmarkModelView(b *testing.B) {
-	model := NewModel()
+	model := detected(NewModel())
 	model.width = 80
 	model.height = 24
-	model.ready = true
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -829,7 +2105,7 @@ func BenchmarkModelView(b *testing.B) {
 }
 
 func BenchmarkKeyPress(b *testing.B) {
-	model := NewModel()
+	model := detected(NewModel())
 	keyMsg := tea.KeyMsg{Type: tea.KeyDown}
 
 	b.ResetTimer()
@@ -841,23 +2117,24 @@ func BenchmarkKeyPress(b *testing.B) {
 
 func TestTerminalColorInheritance(t *testing.T) {
 	t.Run("ANSI_color_definitions", func(t *testing.T) {
+		palette := detected(NewModel()).styles.Palette
 		testCases := []struct {
 			name     string
 			color    lipgloss.Color
 			expected string
 		}{
-			{"colorBackground", colorBackground, ""},
-			{"colorSurface", colorSurface, "0"},
-			{"colorFloat", colorFloat, "8"},
-			{"colorForeground", colorForeground, ""},
-			{"colorComment", colorComment, "8"},
-			{"colorSubtle", colorSubtle, "7"},
-			{"colorBlue", colorBlue, "4"},
-			{"colorCyan", colorCyan, "6"},
-			{"colorGreen", colorGreen, "2"},
-			{"colorYellow", colorYellow, "3"},
-			{"colorRed", colorRed, "1"},
-			{"colorMagenta", colorMagenta, "5"},
+			{"Background", palette.Background, ""},
+			{"Surface", palette.Surface, "0"},
+			{"Float", palette.Float, "8"},
+			{"Foreground", palette.Foreground, ""},
+			{"Comment", palette.Comment, "8"},
+			{"Subtle", palette.Subtle, "7"},
+			{"Blue", palette.Blue, "4"},
+			{"Cyan", palette.Cyan, "6"},
+			{"Green", palette.Green, "2"},
+			{"Yellow", palette.Yellow, "3"},
+			{"Red", palette.Red, "1"},
+			{"Magenta", palette.Magenta, "5"},
 		}
 
 		for _, tc := range testCases {
@@ -907,14 +2184,13 @@ func TestTerminalColorInheritance(t *testing.T) {
 			IsTestMode: true,
 		}
 		services := app.NewServices(config)
-		m := NewModelWithServices(services)
+		m := detected(NewModelWithServices(services))
 		m.width = 100
 		m.height = 30
-		m.ready = true
 
 		_ = m.View()
 
-		headerStyle := m.headerStyle
+		headerStyle := m.styles.Header
 
 		testText := "Test Header"
 		rendered := headerStyle.Render(testText)
@@ -923,14 +2199,14 @@ func TestTerminalColorInheritance(t *testing.T) {
 			t.Error("Header style should render non-empty text")
 		}
 
-		footerStyle := m.footerStyle
+		footerStyle := m.styles.Footer
 		footerRendered := footerStyle.Render("Test Footer")
 
 		if footerRendered == "" {
 			t.Error("Footer style should render non-empty text")
 		}
 
-		titleStyle := m.titleStyle
+		titleStyle := m.styles.Title
 		titleRendered := titleStyle.Render("Test Title")
 
 		if titleRendered == "" {
@@ -975,10 +2251,9 @@ func TestTerminalColorInheritance(t *testing.T) {
 			IsTestMode: true,
 		}
 		services := app.NewServices(config)
-		m := NewModelWithServices(services)
+		m := detected(NewModelWithServices(services))
 		m.width = 100
 		m.height = 30
-		m.ready = true
 
 		renderMethods := []struct {
 			name   string
@@ -1084,10 +2359,9 @@ func TestColorThemeAdaptation(t *testing.T) {
 					IsTestMode: true,
 				}
 				services := app.NewServices(config)
-				m := NewModelWithServices(services)
+				m := detected(NewModelWithServices(services))
 				m.width = 100
 				m.height = 30
-				m.ready = true
 
 				views := []string{
 					m.renderDashboard(20),
@@ -1121,9 +2395,10 @@ func TestColorThemeAdaptation(t *testing.T) {
 		os.Setenv("COLORTERM", "")
 		os.Setenv("TERM", "dumb")
 
+		palette := theme.Terminal()
 		colors := []lipgloss.Color{
-			colorBlue, colorGreen, colorRed, colorYellow,
-			colorCyan, colorMagenta, colorComment, colorSubtle,
+			palette.Blue, palette.Green, palette.Red, palette.Yellow,
+			palette.Cyan, palette.Magenta, palette.Comment, palette.Subtle,
 		}
 
 		for i, color := range colors {
@@ -1139,10 +2414,9 @@ func TestColorThemeAdaptation(t *testing.T) {
 			IsTestMode: true,
 		}
 		services := app.NewServices(config)
-		m := NewModelWithServices(services)
+		m := detected(NewModelWithServices(services))
 		m.width = 100
 		m.height = 30
-		m.ready = true
 
 		dashboard := m.renderDashboard(20)
 		if dashboard == "" {
@@ -1207,7 +2481,7 @@ func TestTerminalThemeInfo(t *testing.T) {
 }
 
 func TestScalingChangesReflectedInDashboard(t *testing.T) {
-	model := NewModel()
+	model := detected(NewModel())
 
 	if len(model.monitors) == 0 {
 		t.Skip("No monitors available for testing")
@@ -1251,7 +2525,7 @@ func TestScalingChangesReflectedInDashboard(t *testing.T) {
 	}
 
 	// Test manual scaling
-	model2 := NewModel()
+	model2 := detected(NewModel())
 	if len(model2.monitors) == 0 {
 		t.Skip("No monitors available for testing")
 	}
@@ -1281,3 +2555,344 @@ func TestScalingChangesReflectedInDashboard(t *testing.T) {
 		}
 	}
 }
+
+func TestUndoRedoManualScaling(t *testing.T) {
+	model := createTestModelForVisual(ModeManualScaling)
+	model.width, model.height = 120, 40
+	start := model.manualSettings()
+
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		updated, _ := model.handleKeyPress(msg)
+		model = updated.(Model)
+	}
+	undo := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}
+	redo := tea.KeyMsg{Type: tea.KeyCtrlR}
+
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	changed := model.manualSettings()
+	if len(model.history.done) != 2 {
+		t.Fatalf("Expected two edits on the stack, got %d", len(model.history.done))
+	}
+	if view := model.View(); !strings.Contains(view, "GTK 1x → 2x") || !strings.Contains(view, "Changes") {
+		t.Errorf("Expected the undo panel beside the controls, got: %s", view)
+	}
+
+	press(undo)
+	press(undo)
+	if model.manualSettings() != start {
+		t.Errorf("Expected undo to restore %+v, got %+v", start, model.manualSettings())
+	}
+	press(undo)
+	if model.history.status != "Nothing to undo" {
+		t.Errorf("Expected an empty stack to say so, got %q", model.history.status)
+	}
+
+	press(redo)
+	press(redo)
+	if model.manualSettings() != changed {
+		t.Errorf("Expected redo to restore %+v, got %+v", changed, model.manualSettings())
+	}
+
+	// A new edit after an undo drops what could be redone
+	press(undo)
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if len(model.history.undone) != 0 {
+		t.Errorf("Expected a new edit to clear redo, got %d", len(model.history.undone))
+	}
+
+	// Edits at a limit change nothing and aren't recorded
+	model.history = undoStack{}
+	model.manualGTKScale = types.MinGTKScale
+	press(tea.KeyMsg{Type: tea.KeyLeft})
+	if len(model.history.done) != 0 {
+		t.Error("Expected an edit that changed nothing to stay off the stack")
+	}
+}
+
+func TestUndoStaging(t *testing.T) {
+	model := createTestModelForVisual(ModeScalingOptions)
+	model.width, model.height = 120, 40
+	model.scalingOptions = []monitor.ScalingOption{{DisplayName: "1.5x", MonitorScale: 1.5}}
+
+	press := func(k string) {
+		t.Helper()
+		updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		model = updated.(Model)
+	}
+
+	press("s")
+	press("x")
+	if len(model.staged) != 0 || len(model.history.done) != 2 {
+		t.Fatalf("Expected stage then unstage on the stack, got %d staged, %d entries", len(model.staged), len(model.history.done))
+	}
+	// Unstaging a monitor that isn't staged isn't a change
+	press("x")
+	if len(model.history.done) != 2 {
+		t.Errorf("Expected a no-op unstage to stay off the stack, got %d entries", len(model.history.done))
+	}
+
+	press("u")
+	if _, ok := model.stagedFor(model.monitors[0].Name); !ok {
+		t.Fatal("Expected undoing the unstage to stage the monitor again")
+	}
+	if view := model.View(); !strings.Contains(view, "Stage 1.50x for "+model.monitors[0].Name) {
+		t.Errorf("Expected the undo panel to list the staging, got: %s", view)
+	}
+	press("u")
+	if len(model.staged) != 0 {
+		t.Error("Expected undoing the stage to leave nothing staged")
+	}
+	updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlR})
+	model = updated.(Model)
+	if len(model.staged) != 1 {
+		t.Errorf("Expected redo to stage the monitor again, got %d staged", len(model.staged))
+	}
+}
+
+func TestUndoApply(t *testing.T) {
+	configManager := &transactionConfigManager{}
+	model := createTestModelForVisual(ModeManualScaling)
+	model.services.ConfigManager = configManager
+	model.isDemoMode = false
+	model.width, model.height = 120, 40
+	original := model.monitors[0].Scale
+
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		updated, _ := model.handleKeyPress(msg)
+		model = updated.(Model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	applied := model.monitors[0].Scale
+	if model.mode != ModeDashboard || applied == original {
+		t.Fatalf("Expected the apply to finish, got mode %v, scale %.2f", model.mode, applied)
+	}
+	if len(model.history.done) != 2 {
+		t.Fatalf("Expected the edit and the apply on the stack, got %d", len(model.history.done))
+	}
+
+	// Undoing the apply applies the old scale through the config manager
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if model.monitors[0].Scale != original {
+		t.Errorf("Expected undo to restore %.2f, got %.2f", original, model.monitors[0].Scale)
+	}
+	inverse := configManager.planned[len(configManager.planned)-1]
+	if len(inverse.Monitors) != 1 || inverse.Monitors[0].Scale != original || inverse.GTKScale != 0 || inverse.FontDPI != 0 {
+		t.Errorf("Unexpected inverse transaction: %+v", inverse)
+	}
+	if len(configManager.executed) != 2 {
+		t.Errorf("Expected the inverse to be executed, got %d executions", len(configManager.executed))
+	}
+
+	// A failed redo leaves everything where it was
+	configManager.err = errors.New("hyprctl failed")
+	press(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if model.monitors[0].Scale != original || len(model.history.undone) != 1 {
+		t.Error("Expected a failed redo to change nothing")
+	}
+	if !strings.Contains(model.history.status, "hyprctl failed") {
+		t.Errorf("Expected the failure to be reported, got %q", model.history.status)
+	}
+
+	configManager.err = nil
+	press(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if model.monitors[0].Scale != applied || model.appliedGTKScale != model.manualGTKScale {
+		t.Errorf("Expected redo to apply %.2f again, got %.2f", applied, model.monitors[0].Scale)
+	}
+
+	// Undo waits while a change is being confirmed
+	model.mode = ModeConfirmation
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if model.monitors[0].Scale != applied {
+		t.Error("Expected undo to do nothing on the confirmation screen")
+	}
+}
+
+func TestUndoApplyRevertsFontAndCursor(t *testing.T) {
+	configManager := &transactionConfigManager{fontScale: 1.25, cursorSize: 32}
+	model := createTestModelForVisual(ModeConfirmation)
+	model.services.ConfigManager = configManager
+	model.services.History = history.NewJournal(t.TempDir(), history.DefaultRetention)
+	model.services.Config.IsTestMode = false
+	model.isDemoMode = false
+	model.confirmationAction = ConfirmManualScaling
+	model.pendingMonitor = model.monitors[0]
+	model.pendingOption = monitor.ScalingOption{DisplayName: "Manual Settings", MonitorScale: 1.5, FontScale: 1.5, CursorSize: 48}
+
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		updated, _ := model.handleKeyPress(msg)
+		model = updated.(Model)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.mode != ModeDashboard {
+		t.Fatalf("Expected the apply to finish, got mode %v", model.mode)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	inverse := configManager.planned[len(configManager.planned)-1]
+	if inverse.FontScale != 1.25 || inverse.CursorSize != 32 {
+		t.Errorf("Expected undo to put back font scale 1.25 and cursor 32, got %+v", inverse)
+	}
+
+	entries, _ := model.services.History.List()
+	if len(entries) != 2 || entries[0].Action != inverse.Description || !strings.HasPrefix(entries[0].Reason, "Undo") {
+		t.Errorf("Expected the undo to be journaled, got %+v", entries)
+	}
+}
+
+func TestDraftsPerMonitor(t *testing.T) {
+	model := createTestModelForVisual(ModeDashboard)
+	model.width, model.height = 120, 60
+
+	send := func(msg tea.Msg) {
+		t.Helper()
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+	}
+	openMenu := func(option int) {
+		t.Helper()
+		model.mode = ModeDashboard
+		model.selectedOption = option
+		send(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	// The controls start at the monitor's detected scale
+	openMenu(3)
+	if model.mode != ModeManualScaling || model.manualMonitorScale != 1.0 {
+		t.Fatalf("Expected manual scaling at 1.0, got mode %v, scale %.2f", model.mode, model.manualMonitorScale)
+	}
+	send(tea.KeyMsg{Type: tea.KeyRight})
+	edited := model.manualMonitorScale
+
+	// Switching to the other monitor shows its own values
+	openMenu(1)
+	send(tea.KeyMsg{Type: tea.KeyDown})
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	openMenu(3)
+	if model.manualMonitorScale != 1.25 {
+		t.Errorf("Expected DP-1's detected 1.25, got %.2f", model.manualMonitorScale)
+	}
+
+	// and switching back keeps the edit
+	openMenu(1)
+	send(tea.KeyMsg{Type: tea.KeyUp})
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := model.View(); !strings.Contains(view, "✎ modified") || !strings.Contains(view, fmt.Sprintf("Draft: %.2fx", edited)) {
+		t.Errorf("Expected the dashboard to mark the drafted monitor, got: %s", view)
+	}
+	openMenu(3)
+	if model.manualMonitorScale != edited {
+		t.Errorf("Expected the draft %.2f to be kept, got %.2f", edited, model.manualMonitorScale)
+	}
+
+	// Editing back to the detected value isn't a modification
+	send(tea.KeyMsg{Type: tea.KeyLeft})
+	if model.modified("HDMI-A-1") {
+		t.Error("Expected a draft back at the detected values to be dropped")
+	}
+}
+
+func TestDraftsSeedFromEnvironment(t *testing.T) {
+	model := createTestModelForVisual(ModeDashboard)
+	model.isDemoMode = false
+	model.getenv = func(name string) string {
+		return map[string]string{"GDK_SCALE": "2", "XFT_DPI": "144"}[name]
+	}
+
+	if got := model.detectedSettings(model.monitors[1]); got != (manualSettings{Scale: 1.25, GTK: 2, DPI: 144}) {
+		t.Errorf("Unexpected detected settings: %+v", got)
+	}
+
+	// What this session applied wins over the environment it started with
+	model.appliedGTKScale, model.appliedFontDPI = 3, 120
+	if got := model.detectedSettings(model.monitors[1]); got.GTK != 3 || got.DPI != 120 {
+		t.Errorf("Expected the applied values, got %+v", got)
+	}
+
+	model.getenv = func(string) string { return "junk" }
+	model.appliedGTKScale, model.appliedFontDPI = 0, 0
+	if got := model.detectedSettings(model.monitors[1]); got.GTK != 1 || got.DPI != types.BaseDPI {
+		t.Errorf("Expected the defaults, got %+v", got)
+	}
+}
+
+func TestApplyAndDiscardDrafts(t *testing.T) {
+	configManager := &transactionConfigManager{}
+	model := createTestModelForVisual(ModeDashboard)
+	model.services.ConfigManager = configManager
+	model.isDemoMode = false
+	model.getenv = func(string) string { return "" }
+	model.width, model.height = 120, 60
+
+	press := func(k string) {
+		t.Helper()
+		updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		model = updated.(Model)
+	}
+
+	model.setDraft("HDMI-A-1", manualSettings{Scale: 1.5, GTK: 1, DPI: 96})
+	model.setDraft("DP-1", manualSettings{Scale: 2.0, GTK: 2, DPI: 120})
+	model.staged = []stagedChange{{Monitor: model.monitors[1], Option: monitor.ScalingOption{MonitorScale: 1.75}}}
+
+	press("D")
+	if len(model.drafts) != 0 {
+		t.Fatal("Expected discard to drop every draft")
+	}
+	press("u")
+	if len(model.drafts) != 2 {
+		t.Fatalf("Expected undo to bring the drafts back, got %d", len(model.drafts))
+	}
+
+	press("A")
+	if model.mode != ModeConfirmation || model.confirmationAction != ConfirmDrafts {
+		t.Fatalf("Expected to confirm the drafts, got mode %v", model.mode)
+	}
+	tx := configManager.planned[len(configManager.planned)-1]
+	scales := make(map[string]float64)
+	for _, target := range tx.Monitors {
+		scales[target.Monitor.Name] = target.Scale
+	}
+	if len(tx.Monitors) != 2 || scales["HDMI-A-1"] != 1.5 || scales["DP-1"] != 2.0 || tx.GTKScale != 1 {
+		t.Fatalf("Expected both drafts with the selected monitor's GTK scale, got %+v", tx)
+	}
+	if view := model.View(); !strings.Contains(view, "All Drafts") {
+		t.Error("Expected the confirmation to say it applies the drafts")
+	}
+
+	updated, _ := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.mode != ModeDashboard || len(model.drafts) != 0 {
+		t.Fatalf("Expected the applied drafts to be cleared, got mode %v, %d drafts", model.mode, len(model.drafts))
+	}
+	if model.monitors[0].Scale != 1.5 || model.monitors[1].Scale != 2.0 {
+		t.Errorf("Expected the drafted scales, got %.2f and %.2f", model.monitors[0].Scale, model.monitors[1].Scale)
+	}
+	if len(model.staged) != 1 {
+		t.Error("Expected applying drafts to leave staged changes alone")
+	}
+}
+
+func TestConfirmationShowsFileDiffs(t *testing.T) {
+	model := createTestModelForVisual(ModeConfirmation)
+	model.width, model.height = 120, 60
+	model.pendingOption = monitor.ScalingOption{DisplayName: "1.5x", MonitorScale: 1.5, GTKScale: 1, FontDPI: 144}
+	model.pendingPlan = monitor.Plan{Files: []monitor.FileChange{{
+		Path:   "/home/user/.config/kitty/kitty.conf",
+		Before: "font_size 10\n",
+		After:  "font_size 15\n",
+	}}}
+
+	view := model.View()
+	for _, expected := range []string{"kitty.conf", "@@ -1 +1 @@", "-font_size 10", "+font_size 15"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the confirmation to show %q, got:\n%s", expected, view)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Zone kinds. Render functions mark clickable elements as "<kind>:<index>"
+// and handleMouse maps a click back to the kind and index.
+const (
+	zoneMenu    = "menu"
+	zoneMonitor = "monitor"
+	zoneOption  = "option"
+	zoneControl = "control"
+	zoneHistory = "history"
+	zoneDrift   = "drift"
+	zoneConfirm = "confirm"
+	zoneCancel  = "cancel"
+)
+
+func zoneID(kind string, index int) string {
+	return fmt.Sprintf("%s:%d", kind, index)
+}
+
+func parseZoneID(id string) (string, int) {
+	kind, n, _ := strings.Cut(id, ":")
+	index, _ := strconv.Atoi(n)
+	return kind, index
+}
+
+// row marks an item as clickable across the full width of its panel, so a
+// click anywhere on the row hits it.
+func (m Model) row(kind string, index, width int, s string) string {
+	return m.zones.Mark(zoneID(kind, index), lipgloss.NewStyle().Width(width).Render(s))
+}
+
+// button draws a clickable button labelled with the key that does the same.
+func (m Model) button(kind, keyName, label string, color lipgloss.Color) string {
+	return m.zones.Mark(zoneID(kind, 0), lipgloss.NewStyle().
+		Background(color).
+		Foreground(m.styles.Background).
+		Bold(true).
+		Padding(0, 2).
+		Render(keyName+" "+label))
+}
+
+// handleMouse hit-tests a mouse event against the zones of the last view.
+// Clicking an item selects it and clicking the selected item again opens it,
+// like the select key; menu entries and buttons act on the first click. The
+// wheel moves through lists, and over a manual scaling control it adjusts
+// that control's value.
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.load != loadReady {
+		return m, nil
+	}
+
+	id, _ := m.zones.At(msg.X, msg.Y)
+	kind, index := parseZoneID(id)
+
+	if tea.MouseEvent(msg).IsWheel() {
+		var up bool
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			up = true
+		case tea.MouseButtonWheelDown:
+		default:
+			return m, nil
+		}
+
+		if m.mode == ModeManualScaling {
+			if kind == zoneControl {
+				m.selectedManualControl = index
+			}
+			if up {
+				return m.perform("right")
+			}
+			return m.perform("left")
+		}
+		if up {
+			return m.perform("up")
+		}
+		return m.perform("down")
+	}
+
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+
+	switch kind {
+	case zoneMenu:
+		m.selectedOption = index
+		return m.perform("select")
+	case zoneMonitor:
+		if index == m.selectedMonitor {
+			return m.perform("select")
+		}
+		m.selectMonitor(index)
+	case zoneOption:
+		if index == m.selectedScalingOpt {
+			return m.perform("select")
+		}
+		m.selectedScalingOpt = index
+	case zoneControl:
+		m.selectedManualControl = index
+	case zoneHistory:
+		if index == m.selectedHistory {
+			return m.perform("select")
+		}
+		m.selectedHistory = index
+	case zoneDrift:
+		if index == m.selectedDrift {
+			return m.perform("select")
+		}
+		m.selectedDrift = index
+	case zoneConfirm:
+		return m.perform("select")
+	case zoneCancel:
+		return m.perform("back")
+	}
+	return m, nil
+}
@@ -0,0 +1,292 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/keymap"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+)
+
+// maxUndo is how many changes the undo stack remembers.
+const maxUndo = 50
+
+// command is a change that can be undone and redone. Both return an error
+// when the change couldn't be made, leaving the stacks where they were.
+type command interface {
+	undo(m *Model) error
+	redo(m *Model) error
+	String() string
+}
+
+// undoStack holds the changes made so far and the ones undone since. A new
+// change clears the redo side.
+type undoStack struct {
+	done   []command
+	undone []command
+	status string
+}
+
+func (s *undoStack) push(c command) {
+	// The full slice expression makes append copy rather than write into an
+	// array another copy of the model still uses
+	s.done = append(s.done[:len(s.done):len(s.done)], c)
+	if len(s.done) > maxUndo {
+		s.done = s.done[len(s.done)-maxUndo:]
+	}
+	s.undone = nil
+	s.status = ""
+}
+
+func (s undoStack) empty() bool {
+	return len(s.done) == 0 && len(s.undone) == 0
+}
+
+// recordManual saves the selected monitor's draft and pushes the change
+// from before, if there was one.
+func (m *Model) recordManual(before manualSettings) {
+	after := m.manualSettings()
+	if after == before || m.selectedMonitor >= len(m.monitors) {
+		return
+	}
+	name := m.monitors[m.selectedMonitor].Name
+	m.setDraft(name, after)
+	m.history.push(manualEdit{monitor: name, before: before, after: after})
+}
+
+// manualEdit is one adjustment of a monitor's manual scaling controls.
+type manualEdit struct {
+	monitor       string
+	before, after manualSettings
+}
+
+func (e manualEdit) undo(m *Model) error {
+	m.setDraft(e.monitor, e.before)
+	return nil
+}
+
+func (e manualEdit) redo(m *Model) error {
+	m.setDraft(e.monitor, e.after)
+	return nil
+}
+
+func (e manualEdit) String() string {
+	switch {
+	case e.before.Scale != e.after.Scale:
+		return fmt.Sprintf("%s scale %.2fx → %.2fx", e.monitor, e.before.Scale, e.after.Scale)
+	case e.before.GTK != e.after.GTK:
+		return fmt.Sprintf("%s GTK %dx → %dx", e.monitor, e.before.GTK, e.after.GTK)
+	default:
+		return fmt.Sprintf("%s DPI %d → %d", e.monitor, e.before.DPI, e.after.DPI)
+	}
+}
+
+// draftsEdit replaces every draft at once, as discarding them does.
+type draftsEdit struct {
+	before, after map[string]manualSettings
+	description   string
+}
+
+func (e draftsEdit) undo(m *Model) error {
+	m.setDrafts(e.before)
+	return nil
+}
+
+func (e draftsEdit) redo(m *Model) error {
+	m.setDrafts(e.after)
+	return nil
+}
+
+func (e draftsEdit) String() string {
+	return e.description
+}
+
+// stageEdit is staging or unstaging a monitor.
+type stageEdit struct {
+	before, after []stagedChange
+	description   string
+}
+
+func (e stageEdit) undo(m *Model) error {
+	m.staged = append([]stagedChange(nil), e.before...)
+	return nil
+}
+
+func (e stageEdit) redo(m *Model) error {
+	m.staged = append([]stagedChange(nil), e.after...)
+	return nil
+}
+
+func (e stageEdit) String() string {
+	return e.description
+}
+
+// applyEdit is an applied transaction. Undoing it applies the inverse
+// transaction, the scales, font scale and cursor size from before, through
+// the config manager. GTK scale and font DPI can only be put back when an
+// earlier apply in this session set them; before that their old values
+// aren't known and they're left alone.
+type applyEdit struct {
+	before, after monitor.Transaction
+}
+
+func (e applyEdit) undo(m *Model) error {
+	return m.runTransaction(e.before, "Undo "+e.after.Description)
+}
+
+func (e applyEdit) redo(m *Model) error {
+	return m.runTransaction(e.after, "Redo")
+}
+
+func (e applyEdit) String() string {
+	return e.after.Description
+}
+
+// inverse is the transaction that puts back what tx changes. A font scale
+// or cursor size that can't be read is left alone.
+func (m Model) inverse(tx monitor.Transaction) monitor.Transaction {
+	inverse := monitor.Transaction{
+		GTKScale: m.appliedGTKScale,
+		FontDPI:  m.appliedFontDPI,
+	}
+	if tx.FontScale > 0 {
+		if scale, err := m.services.ConfigManager.CurrentFontScale(); err == nil {
+			inverse.FontScale = scale
+		}
+	}
+	if tx.CursorSize > 0 {
+		if size, err := m.services.ConfigManager.CurrentCursorSize(); err == nil {
+			inverse.CursorSize = size
+		}
+	}
+	var names []string
+	for _, target := range tx.Monitors {
+		for _, mon := range m.monitors {
+			if mon.Name == target.Monitor.Name {
+				inverse.Monitors = append(inverse.Monitors, monitor.MonitorTarget{Monitor: mon, Scale: mon.Scale})
+				names = append(names, fmt.Sprintf("%.2fx to %s", mon.Scale, mon.Name))
+			}
+		}
+	}
+	inverse.Description = "Restore " + strings.Join(names, ", ")
+	return inverse
+}
+
+// runTransaction applies tx, journaled with reason, then updates the
+// monitors it set.
+func (m *Model) runTransaction(tx monitor.Transaction, reason string) error {
+	if _, err := m.applyTransaction(tx, reason); err != nil {
+		return err
+	}
+	m.applied(tx)
+	return nil
+}
+
+// applied records the result of a successful transaction in the model.
+// The monitors it set have nothing left to draft.
+func (m *Model) applied(tx monitor.Transaction) {
+	drafts := m.draftsCopy()
+	for _, target := range tx.Monitors {
+		for i := range m.monitors {
+			if m.monitors[i].Name == target.Monitor.Name {
+				m.monitors[i].Scale = target.Scale
+			}
+		}
+		delete(drafts, target.Monitor.Name)
+	}
+	if tx.GTKScale > 0 {
+		m.appliedGTKScale = tx.GTKScale
+	}
+	if tx.FontDPI > 0 {
+		m.appliedFontDPI = tx.FontDPI
+	}
+	m.setDrafts(drafts)
+}
+
+// undoable reports whether undo and redo work on the current screen. They
+// wait while a change is being confirmed or its results reviewed, so the
+// stack can't change under a pending plan.
+func (m Model) undoable() bool {
+	switch m.mode {
+	case ModeConfirmation, ModeWaybarPreview, ModeDrift:
+		return false
+	}
+	return true
+}
+
+// undo reverts the most recent change.
+func (m *Model) undo() {
+	if len(m.history.done) == 0 {
+		m.history.status = "Nothing to undo"
+		return
+	}
+	c := m.history.done[len(m.history.done)-1]
+	if err := c.undo(m); err != nil {
+		m.history.status = fmt.Sprintf("❌ Undo failed: %v", err)
+		return
+	}
+	m.history.done = m.history.done[:len(m.history.done)-1]
+	m.history.undone = append(m.history.undone[:len(m.history.undone):len(m.history.undone)], c)
+	m.history.status = "Undid " + c.String()
+}
+
+// redo makes the most recently undone change again.
+func (m *Model) redo() {
+	if len(m.history.undone) == 0 {
+		m.history.status = "Nothing to redo"
+		return
+	}
+	c := m.history.undone[len(m.history.undone)-1]
+	if err := c.redo(m); err != nil {
+		m.history.status = fmt.Sprintf("❌ Redo failed: %v", err)
+		return
+	}
+	m.history.undone = m.history.undone[:len(m.history.undone)-1]
+	m.history.done = append(m.history.done[:len(m.history.done):len(m.history.done)], c)
+	m.history.status = "Redid " + c.String()
+}
+
+// undoPanelWidth is the width of the undo side panel inside its border.
+const undoPanelWidth = 34
+
+// showsUndoPanel reports whether the current screen has the undo panel
+// beside it: screens where changes are made, when there's room and
+// something to show.
+func (m Model) showsUndoPanel() bool {
+	if m.history.empty() || m.width < 100 {
+		return false
+	}
+	return m.mode == ModeManualScaling || m.mode == ModeMonitorSelection
+}
+
+// renderUndoPanel lists the undo stack, newest first, with undone changes
+// that can be redone above it.
+func (m Model) renderUndoPanel(height int) string {
+	var content []string
+
+	content = append(content, lipgloss.NewStyle().Foreground(m.styles.Cyan).Bold(true).Render("↶ Changes"))
+	content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Render(
+		fmt.Sprintf("%s undo  %s redo", keymap.Short(m.keys.Undo), keymap.Short(m.keys.Redo))))
+	content = append(content, "")
+
+	for _, c := range m.history.undone {
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Comment).Italic(true).Render("  "+c.String()))
+	}
+	for i := len(m.history.done) - 1; i >= 0; i-- {
+		line := "  " + m.history.done[i].String()
+		style := lipgloss.NewStyle().Foreground(m.styles.Subtle)
+		if i == len(m.history.done)-1 {
+			line = "▶ " + m.history.done[i].String()
+			style = lipgloss.NewStyle().Foreground(m.styles.Green).Bold(true)
+		}
+		content = append(content, style.Render(line))
+	}
+
+	if m.history.status != "" {
+		content = append(content, "")
+		content = append(content, lipgloss.NewStyle().Foreground(m.styles.Yellow).Render(m.history.status))
+	}
+
+	return m.panel(panelUndo, undoPanelWidth, height, m.styles.Cyan, content, span{})
+}
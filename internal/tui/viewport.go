@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/keymap"
+)
+
+// Panel names. Every screen draws its content in the main panel; the
+// dashboard also has the menu panel beside it, and screens that change
+// settings the undo panel.
+const (
+	panelMain = "main"
+	panelMenu = "menu"
+	panelUndo = "undo"
+)
+
+// span is a range of content entries, [start, end). The zero span is none.
+type span struct {
+	start, end int
+}
+
+// scroller is a panel's viewport and the focus it last scrolled to.
+type scroller struct {
+	viewport.Model
+	focus   span
+	focused bool
+}
+
+// scrollers is the scroll state of every panel.
+type scrollers struct {
+	main, menu, undo scroller
+}
+
+func (s *scrollers) get(name string) *scroller {
+	switch name {
+	case panelMenu:
+		return &s.menu
+	case panelUndo:
+		return &s.undo
+	}
+	return &s.main
+}
+
+// resetScroll puts every panel back at the top, for a newly entered screen.
+func (m *Model) resetScroll() {
+	m.scrollers = scrollers{}
+}
+
+// panelLayout is what a panel is drawn with: its size, its content and the
+// entries to keep in view.
+type panelLayout struct {
+	width, height int
+	content       []string
+	focus         span
+}
+
+// layoutPass collects the panels drawn while layout renders the screen.
+type layoutPass struct {
+	panels map[string]panelLayout
+}
+
+// layout sizes each panel's viewport for the screen as it now stands and
+// scrolls a focus that changed into view. Only the render functions know
+// what a panel holds, so the screen is rendered once to find out. Update
+// calls it after every message, leaving View nothing to change.
+func (m Model) layout() Model {
+	pass := &layoutPass{panels: make(map[string]panelLayout)}
+	probe := m
+	probe.layoutPass = pass
+	probe.render()
+
+	for name, p := range pass.panels {
+		s := m.scrollers.get(name)
+		style, wrapped := m.panelFrame(p.width)
+		fitScroller(s, style, p.height, wrapped(p.content))
+
+		focus := p.focus
+		if focus.end > 0 && (!s.focused || focus != s.focus) {
+			top, bottom := 0, lipgloss.Height(wrapped(p.content[:focus.end]))
+			if focus.start > 0 {
+				top = lipgloss.Height(wrapped(p.content[:focus.start]))
+			}
+			if bottom-s.YOffset > s.Height {
+				s.SetYOffset(bottom - s.Height)
+			}
+			if top < s.YOffset {
+				s.SetYOffset(top)
+			}
+			s.focus, s.focused = focus, true
+		}
+		// The content may have shrunk since the last layout
+		s.SetYOffset(s.YOffset)
+	}
+	return m
+}
+
+// panelFrame is the box style of a panel width columns wide, and a function
+// that wraps content to fit inside it.
+func (m Model) panelFrame(width int) (lipgloss.Style, func([]string) string) {
+	style := lipgloss.NewStyle().
+		Width(width).
+		Padding(1, 2).
+		Background(m.styles.Background).
+		Border(lipgloss.RoundedBorder())
+
+	wrap := lipgloss.NewStyle().Width(width - style.GetHorizontalPadding())
+	return style, func(entries []string) string {
+		return wrap.Render(strings.Join(entries, "\n"))
+	}
+}
+
+// fitScroller sizes s to show body in a box of style height rows tall, and
+// reports whether body is too tall for it and scrolls.
+func fitScroller(s *scroller, style lipgloss.Style, height int, body string) bool {
+	s.Width = style.GetWidth() - style.GetHorizontalPadding()
+	s.Height = height - style.GetVerticalFrameSize()
+	if s.Height < 1 {
+		s.Height = 1
+	}
+	scrollable := lipgloss.Height(body) > s.Height
+	if scrollable && s.Height > 1 {
+		// Room for the indicator
+		s.Height--
+	}
+	s.SetContent(body)
+	return scrollable
+}
+
+// panel draws a bordered box height rows tall, border included, with the
+// content in a viewport. Content taller than the box scrolls and an
+// indicator under it shows there's more. The scroll position is the one
+// layout last worked out, which keeps a non-zero focus in view whenever it
+// changes, so moving the selection keeps it on screen while the page keys
+// can still scroll away from it.
+func (m Model) panel(name string, width, height int, border lipgloss.Color, content []string, focus span) string {
+	if m.layoutPass != nil {
+		m.layoutPass.panels[name] = panelLayout{width: width, height: height, content: content, focus: focus}
+	}
+
+	style, wrapped := m.panelFrame(width)
+	style = style.BorderForeground(border)
+
+	// A copy, so drawing leaves the model alone
+	s := *m.scrollers.get(name)
+	scrollable := fitScroller(&s, style, height, wrapped(content))
+	s.SetYOffset(s.YOffset)
+
+	view := s.View()
+	if scrollable {
+		view += "\n" + m.scrollIndicator(s)
+	}
+	return style.Render(view)
+}
+
+// scrollIndicator says which way there's more content and how to get to it.
+func (m Model) scrollIndicator(s scroller) string {
+	up, down := " ", " "
+	if !s.AtTop() {
+		up = "▲"
+	}
+	if !s.AtBottom() {
+		down = "▼"
+	}
+	text := fmt.Sprintf("%s %3.0f%% %s", up, s.ScrollPercent()*100, down)
+	// Narrow panels get the arrows without the keys
+	if withKeys := text + "  " + keymap.Pair(m.keys.PageUp, m.keys.PageDown) + " scroll"; lipgloss.Width(withKeys) <= s.Width {
+		text = withKeys
+	}
+	return lipgloss.NewStyle().
+		Width(s.Width).
+		Align(lipgloss.Right).
+		Foreground(m.styles.Comment).
+		Render(text)
+}
+
+// scrollsWithArrows reports whether the current screen has nothing to
+// select, so the up and down keys scroll it instead.
+func (m Model) scrollsWithArrows() bool {
+	switch m.mode {
+	case ModeSettings, ModeHelp, ModeConfirmation, ModeWaybarPreview:
+		return true
+	}
+	return false
+}
@@ -35,6 +35,11 @@ func TestVisualRegression(t *testing.T) {
 		vt.MultiSizeTest("scaling_options", model, screenSizes)
 	})
 
+	t.Run("ScalingComparison", func(t *testing.T) {
+		model := createTestModelForVisual(ModeScalingComparison)
+		vt.MultiSizeTest("scaling_comparison", model, screenSizes)
+	})
+
 	t.Run("ManualScaling", func(t *testing.T) {
 		model := createTestModelForVisual(ModeManualScaling)
 		vt.MultiSizeTest("manual_scaling", model, screenSizes)
@@ -125,7 +130,7 @@ func TestVisualRegressionInteractions(t *testing.T) {
 	vt := visualtest.NewVisualTester(t, "testdata/golden")
 
 	t.Run("NavigationStates", func(t *testing.T) {
-		for i := 0; i < 7; i++ {
+		for i := 0; i < len(createTestModelForVisual(ModeDashboard).menuItems); i++ {
 			model := createTestModelForVisual(ModeDashboard)
 			model.selectedOption = i
 
@@ -226,9 +231,8 @@ func createTestModelForVisual(mode AppMode) Model {
 		ConfigManager:   &MockConfigManager{},
 	}
 
-	model := NewModelWithServices(services)
+	model := detected(NewModelWithServices(services))
 	model.mode = mode
-	model.ready = true
 
 	model.monitors = []monitor.Monitor{
 		{
@@ -286,6 +290,15 @@ func (m *MockMonitorDetector) DetectMonitors() ([]monitor.Monitor, error) {
 
 type MockScalingManager struct{}
 
+func (m *MockScalingManager) GetRecommendedScale(mon monitor.Monitor) float64 {
+	for _, option := range m.GetIntelligentScalingOptions(mon) {
+		if option.IsRecommended {
+			return option.MonitorScale
+		}
+	}
+	return 1.0
+}
+
 func (m *MockScalingManager) GetIntelligentScalingOptions(mon monitor.Monitor) []monitor.ScalingOption {
 	return []monitor.ScalingOption{
 		{
@@ -345,6 +358,46 @@ func (m *MockConfigManager) ApplyFontDPI(dpi int) error {
 	return nil
 }
 
+func (m *MockConfigManager) ApplyFontScale(scale float64) error {
+	return nil
+}
+
+func (m *MockConfigManager) ApplyCursorSize(size int) error {
+	return nil
+}
+
+func (m *MockConfigManager) PlanScalingOption(mon monitor.Monitor, option monitor.ScalingOption) (monitor.Plan, error) {
+	return monitor.Plan{}, nil
+}
+
+func (m *MockConfigManager) ApplyTransaction(tx monitor.Transaction) (monitor.Verification, error) {
+	return monitor.Verification{}, nil
+}
+
+func (m *MockConfigManager) PlanTransaction(tx monitor.Transaction) (monitor.Plan, error) {
+	return monitor.Plan{Description: tx.Description}, nil
+}
+
+func (m *MockConfigManager) PlanWaybar(fontScale float64) (monitor.Plan, error) {
+	return monitor.Plan{}, nil
+}
+
+func (m *MockConfigManager) Execute(plan monitor.Plan) (monitor.Verification, error) {
+	return monitor.Verification{}, nil
+}
+
 func (m *MockConfigManager) ApplyCompleteScalingOption(mon monitor.Monitor, option monitor.ScalingOption) error {
 	return nil
 }
+
+func (m *MockConfigManager) ManagedFiles() []string {
+	return nil
+}
+
+func (m *MockConfigManager) CurrentFontScale() (float64, error) {
+	return 1, nil
+}
+
+func (m *MockConfigManager) CurrentCursorSize() (int, error) {
+	return 24, nil
+}
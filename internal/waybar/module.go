@@ -0,0 +1,10 @@
+package waybar
+
+// Module is what a Waybar custom module with "return-type": "json" reads,
+// one object per line.
+type Module struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
@@ -0,0 +1,366 @@
+package waybar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/utils"
+)
+
+var (
+	heightPattern   = regexp.MustCompile(`^(\s*"height"\s*:\s*)([0-9]+)(.*)$`)
+	fontSizePattern = regexp.MustCompile(`(font-size\s*:\s*)([0-9]+(?:\.[0-9]+)?)(px|pt)`)
+)
+
+// Adjustment is a single value that will change in a Waybar file.
+type Adjustment struct {
+	Property string
+	Unit     string
+	From     float64
+	To       float64
+}
+
+// Change is a pending edit to one Waybar file.
+type Change struct {
+	Path        string
+	Before      string
+	After       string
+	Adjustments []Adjustment
+}
+
+// Diff renders the change as a unified diff.
+func (c Change) Diff() string {
+	return utils.UnifiedDiff(filepath.Base(c.Path), c.Before, c.After)
+}
+
+type baseline struct {
+	Base    float64 `json:"base"`
+	Applied float64 `json:"applied"`
+}
+
+type Manager struct {
+	configHome   string
+	stateDir     string
+	baselinePath string
+	reload       func() error
+}
+
+func NewManager(configHome, stateDir string, reload func() error) *Manager {
+	return &Manager{
+		configHome:   configHome,
+		stateDir:     stateDir,
+		baselinePath: filepath.Join(stateDir, "waybar-baselines.json"),
+		reload:       reload,
+	}
+}
+
+func NewDefaultManager() *Manager {
+	return NewManager(utils.ConfigHome(), utils.StateDir(), Reload)
+}
+
+// ConfigPath returns the Waybar config file, preferring config.jsonc like
+// Waybar itself does.
+func (m *Manager) ConfigPath() string {
+	dir := filepath.Join(m.configHome, "waybar")
+	jsonc := filepath.Join(dir, "config.jsonc")
+	if utils.FileExists(jsonc) {
+		return jsonc
+	}
+	return filepath.Join(dir, "config")
+}
+
+func (m *Manager) StylePath() string {
+	return filepath.Join(m.configHome, "waybar", "style.css")
+}
+
+// Paths lists every file Commit may write, including the baseline state
+// file. Backups are not included since they are never overwritten.
+func (m *Manager) Paths() []string {
+	return []string{m.ConfigPath(), m.StylePath(), m.baselinePath}
+}
+
+// Detected reports whether a Waybar config or stylesheet exists.
+func (m *Manager) Detected() bool {
+	return utils.FileExists(m.ConfigPath()) || utils.FileExists(m.StylePath())
+}
+
+// Changes computes the bar height and font size adjustments for scale
+// without writing anything. Values are derived from remembered baselines so
+// that repeated applies don't compound. Only values already present in the
+// files are touched; Waybar sizes everything else automatically.
+func (m *Manager) Changes(scale float64) ([]Change, error) {
+	if scale <= 0 {
+		return nil, fmt.Errorf("invalid font scale: %.2f", scale)
+	}
+
+	baselines, err := m.loadBaselines()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+
+	if path := m.ConfigPath(); utils.FileExists(path) {
+		change, err := m.fileChange(path, func(content string) (string, []Adjustment) {
+			return scaleHeights(content, scale, baselines)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if change.After != change.Before {
+			changes = append(changes, change)
+		}
+	}
+
+	if path := m.StylePath(); utils.FileExists(path) {
+		change, err := m.fileChange(path, func(content string) (string, []Adjustment) {
+			return scaleFontSizes(content, scale, baselines)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if change.After != change.Before {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+func (m *Manager) fileChange(path string, edit func(string) (string, []Adjustment)) (Change, error) {
+	data, err := os.ReadFile(path) // nosec G304
+	if err != nil {
+		return Change{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	after, adjustments := edit(string(data))
+	return Change{Path: path, Before: string(data), After: after, Adjustments: adjustments}, nil
+}
+
+// Commit backs up the original files, writes the changes, records the new
+// baselines and asks Waybar to reload. It returns the backup directory.
+func (m *Manager) Commit(changes []Change) (string, error) {
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	baselineBefore, baselineAfter, err := m.BaselineUpdate(changes)
+	if err != nil {
+		return "", err
+	}
+
+	backupDir := m.BackupDir(time.Now())
+	for _, change := range changes {
+		backupPath := filepath.Join(backupDir, filepath.Base(change.Path))
+		if err := utils.WriteFileAtomic(backupPath, []byte(change.Before), 0600); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", change.Path, err)
+		}
+	}
+
+	for _, change := range changes {
+		if err := utils.WriteFileAtomic(change.Path, []byte(change.After), 0644); err != nil {
+			return backupDir, fmt.Errorf("failed to update %s: %w", change.Path, err)
+		}
+	}
+
+	if baselineAfter != baselineBefore {
+		if err := utils.WriteFileAtomic(m.baselinePath, []byte(baselineAfter), 0600); err != nil {
+			return backupDir, fmt.Errorf("failed to save waybar baselines: %w", err)
+		}
+	}
+
+	if m.reload != nil {
+		if err := m.reload(); err != nil {
+			return backupDir, fmt.Errorf("failed to reload waybar: %w", err)
+		}
+	}
+
+	return backupDir, nil
+}
+
+// BackupDir is where Commit keeps the original files for a commit made at t.
+func (m *Manager) BackupDir(t time.Time) string {
+	return filepath.Join(m.stateDir, "backups", "waybar-"+t.Format("20060102-150405"))
+}
+
+func (m *Manager) BaselinePath() string {
+	return m.baselinePath
+}
+
+// BaselineUpdate returns the current and updated content of the baseline
+// state file once changes have been applied.
+func (m *Manager) BaselineUpdate(changes []Change) (string, string, error) {
+	data, err := os.ReadFile(m.baselinePath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to read waybar baselines: %w", err)
+	}
+	before := string(data)
+
+	baselines, err := m.loadBaselines()
+	if err != nil {
+		return "", "", err
+	}
+	for _, change := range changes {
+		for _, adjustment := range change.Adjustments {
+			key := adjustment.Property
+			b := baselines[key]
+			if b.Base == 0 || !sameSize(b.Applied, adjustment.From) {
+				b.Base = adjustment.From
+			}
+			b.Applied = adjustment.To
+			baselines[key] = b
+		}
+	}
+
+	encoded, err := json.MarshalIndent(baselines, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode waybar baselines: %w", err)
+	}
+	return before, string(encoded) + "\n", nil
+}
+
+// ReloadCommand makes every running Waybar re-read its config and
+// stylesheet. pkill exits 1 when Waybar isn't running, which is fine.
+var ReloadCommand = []string{"pkill", "-USR2", "-x", "waybar"}
+
+// Reload runs ReloadCommand.
+func Reload() error {
+	result, err := runner.NewExec(runner.DefaultTimeout).Run(context.Background(), ReloadCommand[0], ReloadCommand[1:]...)
+	if err != nil && result.ExitCode != 1 {
+		return err
+	}
+	return nil
+}
+
+// scaleHeights rewrites every "height" key in the JSONC config. Each bar is
+// tracked separately so configs with several bars keep their proportions.
+func scaleHeights(content string, scale float64, baselines map[string]baseline) (string, []Adjustment) {
+	var adjustments []Adjustment
+	lines := strings.Split(content, "\n")
+	index := 0
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		match := heightPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		current, _ := strconv.ParseFloat(match[2], 64)
+		key := fmt.Sprintf("height#%d", index)
+		index++
+
+		target := math.Round(baseFor(key, current, baselines) * scale)
+		if target < 1 {
+			target = 1
+		}
+		if sameSize(target, current) {
+			continue
+		}
+
+		lines[i] = match[1] + strconv.Itoa(int(target)) + match[3]
+		adjustments = append(adjustments, Adjustment{Property: key, From: current, To: target})
+	}
+
+	return strings.Join(lines, "\n"), adjustments
+}
+
+// scaleFontSizes rewrites absolute (px/pt) font-size declarations in the
+// stylesheet, leaving relative units and commented-out rules alone.
+func scaleFontSizes(content string, scale float64, baselines map[string]baseline) (string, []Adjustment) {
+	var adjustments []Adjustment
+	lines := strings.Split(content, "\n")
+	index := 0
+	inComment := false
+
+	for i, line := range lines {
+		var out strings.Builder
+		rest := line
+
+		for rest != "" {
+			if inComment {
+				end := strings.Index(rest, "*/")
+				if end < 0 {
+					out.WriteString(rest)
+					rest = ""
+					break
+				}
+				out.WriteString(rest[:end+2])
+				rest = rest[end+2:]
+				inComment = false
+				continue
+			}
+
+			segment := rest
+			if start := strings.Index(rest, "/*"); start >= 0 {
+				segment = rest[:start]
+				rest = rest[start:]
+				inComment = true
+			} else {
+				rest = ""
+			}
+
+			out.WriteString(fontSizePattern.ReplaceAllStringFunc(segment, func(decl string) string {
+				match := fontSizePattern.FindStringSubmatch(decl)
+				current, _ := strconv.ParseFloat(match[2], 64)
+				key := fmt.Sprintf("font-size#%d", index)
+				index++
+
+				target := roundSize(baseFor(key, current, baselines) * scale)
+				if sameSize(target, current) {
+					return decl
+				}
+				adjustments = append(adjustments, Adjustment{Property: key, Unit: match[3], From: current, To: target})
+				return match[1] + strconv.FormatFloat(target, 'f', -1, 64) + match[3]
+			}))
+		}
+
+		lines[i] = out.String()
+	}
+
+	return strings.Join(lines, "\n"), adjustments
+}
+
+// baseFor returns the remembered original value, unless the file was edited
+// by hand since the last apply, in which case the current value wins.
+func baseFor(key string, current float64, baselines map[string]baseline) float64 {
+	if b, ok := baselines[key]; ok && b.Base > 0 && sameSize(b.Applied, current) {
+		return b.Base
+	}
+	return current
+}
+
+func (m *Manager) loadBaselines() (map[string]baseline, error) {
+	baselines := make(map[string]baseline)
+
+	data, err := os.ReadFile(m.baselinePath)
+	if os.IsNotExist(err) {
+		return baselines, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read waybar baselines: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		return nil, fmt.Errorf("failed to parse waybar baselines: %w", err)
+	}
+	return baselines, nil
+}
+
+func roundSize(size float64) float64 {
+	return math.Round(size*10) / 10
+}
+
+func sameSize(a, b float64) bool {
+	return math.Abs(a-b) < 0.05
+}
@@ -0,0 +1,160 @@
+package waybar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfig = `{
+  // Omarchy bar
+  "layer": "top",
+  "height": 26,
+  // "height": 40,
+  "modules-left": ["hyprland/workspaces"]
+}
+`
+
+const testStyle = `* {
+  font-family: JetBrainsMono Nerd Font;
+  font-size: 12px;
+}
+
+/* .old { font-size: 30px; } */
+#clock { font-size: 11pt; margin: 0 1em; }
+#battery { font-size: 1.2em; }
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return string(data)
+}
+
+func newTestManager(t *testing.T) (*Manager, string, *int) {
+	t.Helper()
+	configHome := t.TempDir()
+	reloads := 0
+	manager := NewManager(configHome, t.TempDir(), func() error {
+		reloads++
+		return nil
+	})
+	writeFile(t, filepath.Join(configHome, "waybar", "config.jsonc"), testConfig)
+	writeFile(t, filepath.Join(configHome, "waybar", "style.css"), testStyle)
+	return manager, configHome, &reloads
+}
+
+func TestChangesScalesHeightAndFontSize(t *testing.T) {
+	manager, configHome, _ := newTestManager(t)
+
+	changes, err := manager.Changes(1.5)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+
+	config := changes[0]
+	if config.Path != filepath.Join(configHome, "waybar", "config.jsonc") {
+		t.Errorf("Unexpected config path: %s", config.Path)
+	}
+	if !strings.Contains(config.After, `"height": 39,`) {
+		t.Errorf("Expected height 39, got:\n%s", config.After)
+	}
+	if !strings.Contains(config.After, `// "height": 40,`) {
+		t.Errorf("Commented height should be untouched, got:\n%s", config.After)
+	}
+
+	style := changes[1]
+	for _, expected := range []string{"font-size: 18px;", "font-size: 16.5pt;", "/* .old { font-size: 30px; } */", "font-size: 1.2em;"} {
+		if !strings.Contains(style.After, expected) {
+			t.Errorf("Expected %q in style, got:\n%s", expected, style.After)
+		}
+	}
+	if len(style.Adjustments) != 2 {
+		t.Errorf("Expected 2 font adjustments, got %+v", style.Adjustments)
+	}
+
+	diff := style.Diff()
+	if !strings.Contains(diff, "-  font-size: 12px;") || !strings.Contains(diff, "+  font-size: 18px;") {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+}
+
+func TestCommitBacksUpAndReloads(t *testing.T) {
+	manager, configHome, reloads := newTestManager(t)
+	stylePath := filepath.Join(configHome, "waybar", "style.css")
+
+	changes, err := manager.Changes(2.0)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	backupDir, err := manager.Commit(changes)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	if *reloads != 1 {
+		t.Errorf("Expected one reload, got %d", *reloads)
+	}
+	if got := readFile(t, filepath.Join(backupDir, "style.css")); got != testStyle {
+		t.Errorf("Backup should hold the original style, got:\n%s", got)
+	}
+	if got := readFile(t, stylePath); !strings.Contains(got, "font-size: 24px;") {
+		t.Errorf("Expected scaled style, got:\n%s", got)
+	}
+
+	// Applying again must not compound, and 1.0 restores the originals.
+	changes, err = manager.Changes(2.0)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes when re-applying the same scale, got %d", len(changes))
+	}
+
+	changes, err = manager.Changes(1.0)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if _, err := manager.Commit(changes); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got := readFile(t, stylePath); got != testStyle {
+		t.Errorf("Scale 1.0 should restore the original style, got:\n%s", got)
+	}
+}
+
+func TestChangesWithoutWaybar(t *testing.T) {
+	manager := NewManager(t.TempDir(), t.TempDir(), nil)
+	if manager.Detected() {
+		t.Error("Expected Waybar not to be detected")
+	}
+
+	changes, err := manager.Changes(1.5)
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %d", len(changes))
+	}
+
+	if _, err := manager.Changes(0); err == nil {
+		t.Error("Expected error for zero scale")
+	}
+}
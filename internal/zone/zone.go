@@ -0,0 +1,162 @@
+// Package zone records where clickable elements end up on screen. Render
+// functions wrap an element with Mark, which adds zero-width markers around
+// it; Scan finds the markers in the finished view, notes the rectangle each
+// element occupies and strips them out. Mouse events are then looked up by
+// position with At, so hit-testing follows the layout lipgloss actually
+// produced rather than a second copy of the layout maths.
+package zone
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Markers are CSI sequences with a private final byte: lipgloss and the
+// ansi package treat them as zero width, and Scan removes them before the
+// view reaches the terminal.
+const (
+	startFinal = 'z'
+	endFinal   = 'Z'
+)
+
+// Rect is the screen area of an element. X and Y are the top-left cell.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Contains reports whether the cell at x, y is inside r.
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Manager hands out markers and keeps the zones found by the last Scan. The
+// zero value is not usable; use New.
+type Manager struct {
+	mu    sync.Mutex
+	ids   map[string]int
+	names []string
+	zones map[string]Rect
+}
+
+// New returns an empty Manager.
+func New() *Manager {
+	return &Manager{ids: make(map[string]int), zones: make(map[string]Rect)}
+}
+
+// Mark wraps s so Scan can find it under id. Multi-line elements are padded
+// to a rectangle so the whole block is clickable.
+func (m *Manager) Mark(id, s string) string {
+	m.mu.Lock()
+	n, ok := m.ids[id]
+	if !ok {
+		n = len(m.names)
+		m.ids[id] = n
+		m.names = append(m.names, id)
+	}
+	m.mu.Unlock()
+
+	if strings.Contains(s, "\n") {
+		lines := strings.Split(s, "\n")
+		width := lipgloss.Width(s)
+		for i, line := range lines {
+			if pad := width - lipgloss.Width(line); pad > 0 {
+				lines[i] = line + strings.Repeat(" ", pad)
+			}
+		}
+		s = strings.Join(lines, "\n")
+	}
+
+	marker := "\x1b[" + strconv.Itoa(n)
+	return marker + string(startFinal) + s + marker + string(endFinal)
+}
+
+// Scan records the position of every marked element in view, replacing
+// the zones from the previous scan, and returns view without the markers.
+func (m *Manager) Scan(view string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zones := make(map[string]Rect)
+	type start struct{ x, y int }
+	open := make(map[int]start)
+
+	var out, line strings.Builder
+	out.Grow(len(view))
+	y := 0
+	for i := 0; i < len(view); i++ {
+		c := view[i]
+		if c == '\n' {
+			out.WriteString(line.String())
+			out.WriteByte('\n')
+			line.Reset()
+			y++
+			continue
+		}
+		if n, final, length, ok := parseMarker(view[i:]); ok && n < len(m.names) {
+			x := lipgloss.Width(line.String())
+			if final == startFinal {
+				open[n] = start{x, y}
+			} else if s, ok := open[n]; ok {
+				delete(open, n)
+				zones[m.names[n]] = Rect{X: s.x, Y: s.y, Width: x - s.x, Height: y - s.y + 1}
+			}
+			i += length - 1
+			continue
+		}
+		line.WriteByte(c)
+	}
+	out.WriteString(line.String())
+
+	m.zones = zones
+	return out.String()
+}
+
+// parseMarker reads a marker at the start of s, returning its number, final
+// byte and length.
+func parseMarker(s string) (n int, final byte, length int, ok bool) {
+	if len(s) < 4 || s[0] != '\x1b' || s[1] != '[' {
+		return 0, 0, 0, false
+	}
+	i := 2
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 2 || i >= len(s) || (s[i] != startFinal && s[i] != endFinal) {
+		return 0, 0, 0, false
+	}
+	n, err := strconv.Atoi(s[2:i])
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return n, s[i], i + 1, true
+}
+
+// Get returns the rectangle id occupied in the last scanned view.
+func (m *Manager) Get(id string) (Rect, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.zones[id]
+	return r, ok
+}
+
+// At returns the zone under x, y. When zones are nested the smallest one
+// wins, so an element inside a clickable panel gets the click.
+func (m *Manager) At(x, y int) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found, area := "", -1
+	for id, r := range m.zones {
+		if !r.Contains(x, y) {
+			continue
+		}
+		if a := r.Width * r.Height; area < 0 || a < area || (a == area && id < found) {
+			found, area = id, a
+		}
+	}
+	return found, area >= 0
+}
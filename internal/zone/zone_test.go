@@ -0,0 +1,84 @@
+package zone
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestScanStripsMarkers(t *testing.T) {
+	m := New()
+	view := "a " + m.Mark("first", "one") + "\n" + m.Mark("second", "two")
+
+	if got := m.Scan(view); got != "a one\ntwo" {
+		t.Errorf("Expected the markers to be removed, got %q", got)
+	}
+	if r, ok := m.Get("first"); !ok || r != (Rect{X: 2, Y: 0, Width: 3, Height: 1}) {
+		t.Errorf("Unexpected zone for first: %+v, %v", r, ok)
+	}
+	if r, ok := m.Get("second"); !ok || r != (Rect{X: 0, Y: 1, Width: 3, Height: 1}) {
+		t.Errorf("Unexpected zone for second: %+v, %v", r, ok)
+	}
+}
+
+func TestScanFollowsLayout(t *testing.T) {
+	m := New()
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	left := style.Render("menu")
+	right := style.Render(m.Mark("item", "long\nx"))
+
+	view := m.Scan(lipgloss.JoinVertical(lipgloss.Left, "title", lipgloss.JoinHorizontal(lipgloss.Top, left, right)))
+	if strings.Contains(view, "\x1b[") {
+		t.Errorf("Expected no markers left, got %q", view)
+	}
+
+	// The item sits inside the second box: after the first box (8 cells),
+	// the border and the padding, one line below the title and the border
+	r, ok := m.Get("item")
+	if !ok {
+		t.Fatal("Expected the item to be found")
+	}
+	if r != (Rect{X: 10, Y: 2, Width: 4, Height: 2}) {
+		t.Errorf("Unexpected zone: %+v\n%s", r, view)
+	}
+	if id, ok := m.At(13, 3); !ok || id != "item" {
+		t.Errorf("Expected the padded second line to be part of the item, got %q, %v", id, ok)
+	}
+	if _, ok := m.At(3, 2); ok {
+		t.Error("Expected nothing under the first box")
+	}
+}
+
+func TestAtPrefersInnermost(t *testing.T) {
+	m := New()
+	m.Scan(m.Mark("panel", "ab "+m.Mark("button", "ok")+" cd"))
+
+	if id, _ := m.At(3, 0); id != "button" {
+		t.Errorf("Expected the button inside the panel, got %q", id)
+	}
+	if id, _ := m.At(0, 0); id != "panel" {
+		t.Errorf("Expected the panel, got %q", id)
+	}
+}
+
+func TestScanForgetsOldZones(t *testing.T) {
+	m := New()
+	m.Scan(m.Mark("gone", "x"))
+	m.Scan("plain")
+	if _, ok := m.Get("gone"); ok {
+		t.Error("Expected zones from the previous view to be cleared")
+	}
+}
+
+func TestScanKeepsOtherSequences(t *testing.T) {
+	m := New()
+	colored := "\x1b[31mred\x1b[0m"
+	view := m.Scan(colored + m.Mark("after", "x"))
+	if view != colored+"x" {
+		t.Errorf("Expected colour codes to survive, got %q", view)
+	}
+	if r, _ := m.Get("after"); r.X != 3 {
+		t.Errorf("Expected colour codes to take no width, got x=%d", r.X)
+	}
+}
@@ -4,11 +4,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/ryanyogan/omarchy-monitor-settings/internal/app"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/cli"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/config"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/logging"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/runner"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate"
 	"github.com/ryanyogan/omarchy-monitor-settings/internal/tui"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +24,16 @@ var (
 	noHyprlandCheck bool
 	debugMode       bool
 	forceLiveMode   bool
+	logLevel        string
+	logFormat       string
+	logFile         string
+	simulateFrom    string
+	commandTimeout  time.Duration
+	configFile      string
 	version         = "dev"
+
+	// sandbox is set when --simulate is given, and removed on exit.
+	sandbox *simulate.Sandbox
 )
 
 func main() {
@@ -25,30 +42,111 @@ func main() {
 		Short:   "A stunning TUI for managing monitor resolution and scaling",
 		Long:    "A beautiful terminal interface for detecting and configuring monitor resolution, scaling, and font settings in Hyprland/Wayland environments.",
 		Version: version,
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			if _, err := logging.ParseLevel(logLevel); err != nil {
+				return err
+			}
+			if simulateFrom == "" {
+				return nil
+			}
+			scenario, err := simulate.Load(simulateFrom)
+			if err != nil {
+				return err
+			}
+			sandbox, err = simulate.NewSandbox(scenario)
+			return err
+		},
 		Run: func(_ *cobra.Command, _ []string) {
-			config := &app.Config{
+			settings, err := config.Load(configFile)
+			if err != nil {
+				log.Fatalf("Error loading %s: %v", configFile, err)
+			}
+			keys, err := settings.KeyMap()
+			if err != nil {
+				log.Fatalf("Error loading %s: %v", configFile, err)
+			}
+			palette, err := settings.Palette()
+			if err != nil {
+				log.Fatalf("Error loading %s: %v", configFile, err)
+			}
+
+			appConfig := &app.Config{
 				NoHyprlandCheck: noHyprlandCheck,
 				DebugMode:       debugMode,
 				ForceLiveMode:   forceLiveMode,
 				IsTestMode:      false,
+				LogLevel:        logLevel,
+				LogFormat:       logFormat,
+				LogFile:         logFile,
+				CommandTimeout:  commandTimeout,
+				KeyMap:          keys,
+				Theme:           palette,
 			}
 
-			if err := runTUI(config); err != nil {
+			if err := runTUI(appConfig); err != nil {
 				log.Fatalf("Error running TUI: %v", err)
 			}
 		},
 	}
 
-	rootCmd.Flags().BoolVar(&noHyprlandCheck, "no-hyprland-check", false, "Skip Hyprland environment check (useful for testing)")
-	rootCmd.Flags().BoolVar(&debugMode, "debug", false, "Enable debug mode")
-	rootCmd.Flags().BoolVar(&forceLiveMode, "force-live", false, "Force live mode (bypass all checks for testing)")
+	rootCmd.PersistentFlags().BoolVar(&noHyprlandCheck, "no-hyprland-check", false, "Skip the session check and detect monitors with whichever tool is installed")
+	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Enable debug mode")
+	rootCmd.Flags().BoolVar(&forceLiveMode, "force-live", false, "Never fall back to demo mode, whatever the session or detection result")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Log level: debug, info, warn or error (default info, or debug with --debug)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText, "Log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", logging.DefaultPath(), "Log file, rotated as it grows")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "command-timeout", runner.DefaultTimeout, "Give up on hyprctl and wlr-randr calls that take longer than this")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", config.DefaultPath(), "Settings file (keymap, theme)")
+	rootCmd.PersistentFlags().StringVar(&simulateFrom, "simulate", "", "Run against a simulated compositor from a scenario file or built-in scenario ("+strings.Join(simulate.Builtin(), ", ")+")")
+
+	// Services opened by a command are closed once it finishes, so the log
+	// file is flushed and released
+	var opened []*app.Services
+	newServices := func() *app.Services {
+		services := newAppServices(&app.Config{
+			NoHyprlandCheck: noHyprlandCheck,
+			DebugMode:       debugMode,
+			LogLevel:        logLevel,
+			LogFormat:       logFormat,
+			LogFile:         logFile,
+			CommandTimeout:  commandTimeout,
+		})
+		opened = append(opened, services)
+		return services
+	}
+	closeServices := func() {
+		for _, services := range opened {
+			services.Close()
+		}
+	}
+	rootCmd.AddCommand(cli.NewApplyCommand(newServices))
+	rootCmd.AddCommand(cli.NewHistoryCommand(newServices))
+	rootCmd.AddCommand(cli.NewDoctorCommand(newServices))
+	rootCmd.AddCommand(cli.NewListCommand(newServices))
+	rootCmd.AddCommand(cli.NewRecommendCommand(newServices))
+	rootCmd.AddCommand(cli.NewSchemaCommand())
+	rootCmd.AddCommand(cli.NewWaybarCommand(newServices))
+	rootCmd.AddCommand(cli.NewCycleCommand(newServices))
+	rootCmd.AddCommand(cli.NewScaleCommand(newServices))
+	rootCmd.AddCommand(cli.NewServeCommand(newServices))
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	closeServices()
+	sandbox.Close()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// newAppServices uses the simulated compositor when --simulate is given.
+func newAppServices(config *app.Config) *app.Services {
+	if sandbox != nil {
+		return app.NewSimulatedServices(config, sandbox)
+	}
+	return app.NewServices(config)
+}
+
 func runTUI(config *app.Config) error {
 	if config.IsTestMode {
 		return fmt.Errorf("TUI disabled during tests")
@@ -59,9 +157,17 @@ func runTUI(config *app.Config) error {
 		return fmt.Errorf("TUI disabled during tests")
 	}
 
-	services := app.NewServices(config)
+	services := newAppServices(config)
+	defer services.Close()
 
 	model := tui.NewModelWithServices(services)
+	// Share state with a running serve command, unless simulating
+	if sandbox == nil {
+		if daemon, err := client.Dial(client.DefaultSocketPath()); err == nil {
+			defer daemon.Close()
+			model = model.WithDaemon(daemon)
+		}
+	}
 
 	p := tea.NewProgram(
 		model,
@@ -0,0 +1,250 @@
+// Package client talks to the JSON-RPC socket of a running
+// "omarchy-monitor-settings serve", so other Go programs, and the TUI, work
+// against the same state as the daemon.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// SocketName is the socket's file name in the runtime directory.
+const SocketName = "omarchy-monitor-settings.sock"
+
+// ErrClosed is returned by calls on a closed client, or one whose server
+// went away.
+var ErrClosed = errors.New("connection closed")
+
+// DefaultSocketPath is the socket in $XDG_RUNTIME_DIR, or in a per-user
+// directory under the temp directory without one.
+func DefaultSocketPath() string {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, SocketName)
+	}
+	return filepath.Join(os.TempDir(), "omarchy-monitor-settings-"+strconv.Itoa(os.Getuid()), SocketName)
+}
+
+// Client is a connection to the server. It is safe for concurrent use.
+type Client struct {
+	conn    net.Conn
+	changes chan Document
+
+	writeMu sync.Mutex
+	encoder *json.Encoder
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan RPCResponse
+	err     error
+	done    chan struct{}
+}
+
+// Dial connects to the server listening on path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", path, err)
+	}
+	return New(conn), nil
+}
+
+// New uses an open connection to the server.
+func New(conn net.Conn) *Client {
+	c := &Client{
+		conn:    conn,
+		changes: make(chan Document, 1),
+		encoder: json.NewEncoder(conn),
+		pending: make(map[uint64]chan RPCResponse),
+		done:    make(chan struct{}),
+	}
+	go c.read()
+	return c
+}
+
+// Close hangs up. Calls in flight fail with ErrClosed.
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// Changes delivers the monitors whenever the server sees them change. If
+// the reader falls behind, only the latest document is kept. The channel is
+// closed when the connection is.
+func (c *Client) Changes() <-chan Document {
+	return c.changes
+}
+
+// Call runs method with params, which are encoded as JSON, and decodes the
+// result into result unless it is nil. A failure reported by the server is
+// an *Error.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	var rawParams json.RawMessage
+	if params != nil {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode params: %w", err)
+		}
+		rawParams = encoded
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	reply := make(chan RPCResponse, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	c.writeMu.Lock()
+	err := c.encoder.Encode(RPCRequest{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(strconv.FormatUint(id, 10)),
+		Method:  method,
+		Params:  rawParams,
+	})
+	c.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return ErrClosed
+	case resp := <-reply:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// read dispatches replies to their calls and notifications to Changes until
+// the connection closes.
+func (c *Client) read() {
+	defer close(c.done)
+	defer close(c.changes)
+	defer func() {
+		c.mu.Lock()
+		c.err = ErrClosed
+		c.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var resp RPCResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			continue
+		}
+
+		if resp.Method == ChangedMethod {
+			var doc Document
+			if err := json.Unmarshal(resp.Params, &doc); err == nil {
+				c.publish(doc)
+			}
+			continue
+		}
+
+		id, err := strconv.ParseUint(string(resp.ID), 10, 64)
+		if err != nil {
+			continue
+		}
+		c.mu.Lock()
+		reply, ok := c.pending[id]
+		c.mu.Unlock()
+		if ok {
+			reply <- resp
+		}
+	}
+}
+
+// publish replaces any document the reader hasn't taken yet with doc.
+func (c *Client) publish(doc Document) {
+	for {
+		select {
+		case c.changes <- doc:
+			return
+		default:
+		}
+		select {
+		case <-c.changes:
+		default:
+		}
+	}
+}
+
+// List describes the monitors.
+func (c *Client) List(ctx context.Context) (Document, error) {
+	var doc Document
+	err := c.Call(ctx, "list", nil, &doc)
+	return doc, err
+}
+
+// Recommend describes the named monitor, or every monitor for an empty name,
+// with their scaling options.
+func (c *Client) Recommend(ctx context.Context, name string) (Document, error) {
+	var doc Document
+	err := c.Call(ctx, "recommend", MonitorParams{Monitor: name}, &doc)
+	return doc, err
+}
+
+// Apply applies a smart scaling option, or only plans it for a dry run.
+func (c *Client) Apply(ctx context.Context, req ApplyRequest) (ApplyResult, error) {
+	var result ApplyResult
+	err := c.Call(ctx, "apply", req, &result)
+	return result, err
+}
+
+// Profiles lists the snapshots history can restore, newest first.
+func (c *Client) Profiles(ctx context.Context) ([]Profile, error) {
+	var profiles []Profile
+	err := c.Call(ctx, "profiles", nil, &profiles)
+	return profiles, err
+}
+
+// History lists the snapshots in full, newest first.
+func (c *Client) History(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+	err := c.Call(ctx, "history.list", nil, &entries)
+	return entries, err
+}
+
+// HistoryEntry is the snapshot with the given ID.
+func (c *Client) HistoryEntry(ctx context.Context, id string) (Entry, error) {
+	var entry Entry
+	err := c.Call(ctx, "history.show", IDParams{ID: id}, &entry)
+	return entry, err
+}
+
+// Restore brings back a snapshot.
+func (c *Client) Restore(ctx context.Context, id string) (RestoreResult, error) {
+	var result RestoreResult
+	err := c.Call(ctx, "history.restore", IDParams{ID: id}, &result)
+	return result, err
+}
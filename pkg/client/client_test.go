@@ -0,0 +1,181 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/simulate/simtest"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/client"
+)
+
+// serve runs a server for a simulated scenario on a temporary socket and
+// returns its path.
+func serve(t *testing.T, scenario string) (string, *simulate.Sandbox) {
+	t.Helper()
+	services, sandbox := simtest.Services(t, scenario)
+	path := filepath.Join(simtest.SocketDir(t, "client"), client.SocketName)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := service.New(services)
+	served := make(chan error, 1)
+	go func() {
+		served <- service.ServeRPC(ctx, listener, svc, svc.Watch(ctx, nil, 0))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("ServeRPC failed: %v", err)
+		}
+	})
+	return path, sandbox
+}
+
+func dial(t *testing.T, path string) *client.Client {
+	t.Helper()
+	c, err := client.Dial(path)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient(t *testing.T) {
+	path, sandbox := serve(t, "6k-laptop")
+	c := dial(t, path)
+	other := dial(t, path)
+	ctx := context.Background()
+
+	doc, err := c.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(doc.Monitors) != 2 || doc.Monitors[0].Name != "eDP-1" || doc.Monitors[0].Options != nil {
+		t.Fatalf("Unexpected monitors: %+v", doc)
+	}
+
+	doc, err = c.Recommend(ctx, service.Focused)
+	if err != nil {
+		t.Fatalf("Recommend failed: %v", err)
+	}
+	if len(doc.Monitors) != 1 || doc.Monitors[0].Name != "eDP-1" || len(doc.Monitors[0].Options) < 2 {
+		t.Fatalf("Expected options for the focused monitor, got %+v", doc)
+	}
+	// eDP-1 starts at 2x, so pick an option that changes it
+	index := 0
+	for i, option := range doc.Monitors[0].Options {
+		if option.MonitorScale != 2 {
+			index = i + 1
+			break
+		}
+	}
+	want := doc.Monitors[0].Options[index-1]
+
+	result, err := c.Apply(ctx, client.ApplyRequest{Monitor: "eDP-1", Option: index, DryRun: true})
+	if err != nil || result.Option != want.Name || result.Plan == "" {
+		t.Fatalf("Unexpected dry run: %+v, %v", result, err)
+	}
+	if live, _ := sandbox.Compositor.DetectMonitors(); live[0].Scale != 2 {
+		t.Fatal("A dry run must not change anything")
+	}
+
+	if _, err := c.Apply(ctx, client.ApplyRequest{Monitor: "eDP-1", Option: index, Reason: "From a script"}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	// Every client hears about the change, not just the one that made it
+	for _, each := range []*client.Client{c, other} {
+		select {
+		case changed := <-each.Changes():
+			if changed.Monitors[0].Scale != want.MonitorScale {
+				t.Errorf("Expected eDP-1 at %v, got %+v", want.MonitorScale, changed.Monitors[0])
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected a change notification after applying")
+		}
+	}
+
+	profiles, err := other.Profiles(ctx)
+	if err != nil {
+		t.Fatalf("Profiles failed: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Source != "rpc" || profiles[0].Reason != "From a script" {
+		t.Fatalf("Expected the apply in the profiles, got %+v", profiles)
+	}
+	entries, err := other.History(ctx)
+	if err != nil || len(entries) != 1 || len(entries[0].Monitors) != 2 {
+		t.Fatalf("Expected the full snapshot in history, got %+v, %v", entries, err)
+	}
+	entry, err := other.HistoryEntry(ctx, profiles[0].ID)
+	if err != nil || entry.ID != profiles[0].ID {
+		t.Fatalf("HistoryEntry failed: %+v, %v", entry, err)
+	}
+
+	restored, err := other.Restore(ctx, entry.ID)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored.Restored != entry.ID || restored.Safety == "" {
+		t.Errorf("Unexpected restore: %+v", restored)
+	}
+	if live, _ := sandbox.Compositor.DetectMonitors(); live[0].Scale != 2 {
+		t.Errorf("Expected eDP-1 back at 2x, got %v", live[0].Scale)
+	}
+	select {
+	case changed := <-c.Changes():
+		if changed.Monitors[0].Scale != 2 {
+			t.Errorf("Expected the restored scale, got %+v", changed.Monitors[0])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a change notification after restoring")
+	}
+
+	_, err = c.Apply(ctx, client.ApplyRequest{Monitor: "HDMI-A-1"})
+	var rpcErr *client.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != client.CodeFailed {
+		t.Errorf("Expected a failed error for an unknown monitor, got %v", err)
+	}
+	if err := c.Call(ctx, "resize", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != client.CodeMethodNotFound {
+		t.Errorf("Expected an unknown method to be reported, got %v", err)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	path, _ := serve(t, "6k-laptop")
+	c, err := client.Dial(path)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	c.Close()
+
+	if _, err := c.List(context.Background()); !errors.Is(err, client.ErrClosed) {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+	if _, ok := <-c.Changes(); ok {
+		t.Error("Expected Changes to be closed")
+	}
+	if _, err := client.Dial(filepath.Join(filepath.Dir(path), "missing.sock")); err == nil {
+		t.Error("Expected dialing a missing socket to fail")
+	}
+}
+
+func TestDefaultSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if path := client.DefaultSocketPath(); path != "/run/user/1000/"+client.SocketName {
+		t.Errorf("Expected the socket in the runtime dir, got %s", path)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	if path := client.DefaultSocketPath(); filepath.Base(path) != client.SocketName || filepath.Dir(filepath.Dir(path)) != filepath.Clean(os.TempDir()) {
+		t.Errorf("Expected the socket under the temp dir, got %s", path)
+	}
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+)
+
+// The monitors, as list and recommend describe them.
+type (
+	Document = schema.Document
+	Monitor  = schema.Monitor
+	Option   = schema.Option
+)
+
+// JSON-RPC 2.0 error codes. CodeFailed is for an operation that was
+// understood but failed, such as an unknown monitor.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeFailed         = -32000
+)
+
+// ChangedMethod is the notification the server sends every connection when
+// the monitors change; its params are the monitors as list prints them.
+const ChangedMethod = "monitors.changed"
+
+// RPCRequest is a JSON-RPC 2.0 request, or a notification without an ID.
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// RPCResponse is a JSON-RPC 2.0 response. A server notification has Method
+// and Params instead of an ID.
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Error is the error member of a response, and what Call returns for a
+// failure the server reported.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// MonitorParams names a monitor for recommend: an empty name is every
+// monitor, and "focused" the focused one.
+type MonitorParams struct {
+	Monitor string `json:"monitor,omitempty"`
+}
+
+// IDParams names a snapshot in history.
+type IDParams struct {
+	ID string `json:"id"`
+}
+
+// ApplyRequest picks a monitor, as in MonitorParams, and one of its smart
+// scaling options by 1-based position, or 0 for the recommended one. With
+// Targets it sets those scales instead, along with whichever global settings
+// are non-zero, all at once; Action then describes the change for history.
+type ApplyRequest struct {
+	Monitor string `json:"monitor,omitempty"`
+	Option  int    `json:"option,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+
+	Action     string   `json:"action,omitempty"`
+	Targets    []Target `json:"targets,omitempty"`
+	GTKScale   int      `json:"gtk_scale,omitempty"`
+	FontDPI    int      `json:"font_dpi,omitempty"`
+	FontScale  float64  `json:"font_scale,omitempty"`
+	CursorSize int      `json:"cursor_size,omitempty"`
+}
+
+// Target is the scale to set a monitor to, named as in MonitorParams.
+type Target struct {
+	Monitor string  `json:"monitor"`
+	Scale   float64 `json:"scale"`
+}
+
+// ApplyResult describes what apply did. Plan is the plan as text and Drift
+// lists anything the compositor did differently from what was asked.
+type ApplyResult struct {
+	Monitor string   `json:"monitor"`
+	Option  string   `json:"option"`
+	Plan    string   `json:"plan"`
+	Drift   []string `json:"drift,omitempty"`
+}
+
+// Profile summarizes a snapshot in history.
+type Profile struct {
+	ID     string    `json:"id"`
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Host   string    `json:"host"`
+	Source string    `json:"source"`
+	Action string    `json:"action"`
+	Reason string    `json:"reason"`
+}
+
+// Entry is a snapshot in history in full: who changed what, when and why,
+// and the monitors and files as they were before.
+type Entry struct {
+	ID       string         `json:"id"`
+	Time     time.Time      `json:"time"`
+	User     string         `json:"user"`
+	Host     string         `json:"host"`
+	Source   string         `json:"source"`
+	Action   string         `json:"action"`
+	Reason   string         `json:"reason"`
+	Monitors []MonitorState `json:"monitors"`
+	Files    []FileSnapshot `json:"files"`
+}
+
+// MonitorState is a monitor as a snapshot recorded it.
+type MonitorState struct {
+	Name        string  `json:"name"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	RefreshRate float64 `json:"refresh_rate"`
+	Scale       float64 `json:"scale"`
+	X           int     `json:"x"`
+	Y           int     `json:"y"`
+}
+
+// FileSnapshot is a config file as a snapshot recorded it. Blob names the
+// saved content by its SHA256; a file that didn't exist has neither.
+type FileSnapshot struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+	SHA256  string      `json:"sha256,omitempty"`
+	Blob    string      `json:"blob,omitempty"`
+}
+
+// RestoreResult names the restored snapshot and the one taken of the state
+// it replaced, which restores the change away again.
+type RestoreResult struct {
+	Restored string `json:"restored"`
+	Safety   string `json:"safety"`
+}
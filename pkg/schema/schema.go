@@ -0,0 +1,98 @@
+// Package schema is the machine-readable form of what the detector knows
+// about each monitor, for scripts, Waybar modules and Go programs using
+// pkg/client. The document carries a version, and the JSON Schema that
+// describes it is pinned by golden tests: fields may be added within a
+// version, but renaming, removing or retyping one means a new version.
+package schema
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the schema version written to every document.
+const Version = 1
+
+// JSONSchema is the JSON Schema (draft 2020-12) for Document.
+//
+//go:embed monitors.schema.json
+var JSONSchema []byte
+
+// Output formats for Write.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Document is the top level of list and recommend output.
+type Document struct {
+	SchemaVersion int       `json:"schema_version" yaml:"schema_version"`
+	Monitors      []Monitor `json:"monitors" yaml:"monitors"`
+}
+
+// Monitor is a detected monitor with what the scaling manager computes for
+// it. Options is only filled in by recommend.
+type Monitor struct {
+	Name             string   `json:"name" yaml:"name"`
+	Make             string   `json:"make" yaml:"make"`
+	Model            string   `json:"model" yaml:"model"`
+	Width            int      `json:"width" yaml:"width"`
+	Height           int      `json:"height" yaml:"height"`
+	RefreshRate      float64  `json:"refresh_rate" yaml:"refresh_rate"`
+	Scale            float64  `json:"scale" yaml:"scale"`
+	Position         Position `json:"position" yaml:"position"`
+	Active           bool     `json:"active" yaml:"active"`
+	Primary          bool     `json:"primary" yaml:"primary"`
+	PPI              float64  `json:"ppi" yaml:"ppi"`
+	RecommendedScale float64  `json:"recommended_scale" yaml:"recommended_scale"`
+	Options          []Option `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// Position is a monitor's top-left corner in the layout, in pixels.
+type Position struct {
+	X int `json:"x" yaml:"x"`
+	Y int `json:"y" yaml:"y"`
+}
+
+// Option is a smart scaling option.
+type Option struct {
+	Name            string  `json:"name" yaml:"name"`
+	Description     string  `json:"description" yaml:"description"`
+	Reasoning       string  `json:"reasoning" yaml:"reasoning"`
+	Recommended     bool    `json:"recommended" yaml:"recommended"`
+	MonitorScale    float64 `json:"monitor_scale" yaml:"monitor_scale"`
+	GTKScale        int     `json:"gtk_scale" yaml:"gtk_scale"`
+	FontDPI         int     `json:"font_dpi" yaml:"font_dpi"`
+	FontScale       float64 `json:"font_scale" yaml:"font_scale"`
+	CursorSize      int     `json:"cursor_size" yaml:"cursor_size"`
+	EffectiveWidth  int     `json:"effective_width" yaml:"effective_width"`
+	EffectiveHeight int     `json:"effective_height" yaml:"effective_height"`
+}
+
+// Write encodes the document as indented JSON or as YAML.
+func Write(w io.Writer, doc Document, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown output format %q (available: %s, %s)", format, FormatJSON, FormatYAML)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/monitor"
+	"github.com/ryanyogan/omarchy-monitor-settings/internal/service"
+	"github.com/ryanyogan/omarchy-monitor-settings/pkg/schema"
+)
+
+// checkGolden compares got with testdata/name, rewriting it with
+// UPDATE_GOLDEN=true. A golden change is a change downstream scripts see.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if os.Getenv("UPDATE_GOLDEN") == "true" {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s (run with UPDATE_GOLDEN=true to create it): %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s changed; if this is intended, bump Version when a field was renamed, removed or retyped, "+
+			"then run with UPDATE_GOLDEN=true.\nGot:\n%s", path, got)
+	}
+}
+
+func testMonitors() []monitor.Monitor {
+	return []monitor.Monitor{
+		{Name: "eDP-1", Make: "BOE", Model: "NE135A1M-NY1", Width: 2880, Height: 1920, RefreshRate: 120, Scale: 2, IsActive: true, IsPrimary: true},
+		{Name: "DP-1", Make: "Dell", Model: "U2723QE", Width: 3840, Height: 2160, RefreshRate: 60, Scale: 1.5, Position: monitor.Position{X: 1440}, IsActive: true},
+	}
+}
+
+func TestSchemaGolden(t *testing.T) {
+	checkGolden(t, "monitors.schema.json.golden", schema.JSONSchema)
+}
+
+func TestDocumentGolden(t *testing.T) {
+	scaling := monitor.NewScalingManager()
+
+	for _, tc := range []struct {
+		name        string
+		format      string
+		withOptions bool
+	}{
+		{"list.json.golden", schema.FormatJSON, false},
+		{"list.yaml.golden", schema.FormatYAML, false},
+		{"recommend.json.golden", schema.FormatJSON, true},
+		{"recommend.yaml.golden", schema.FormatYAML, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := schema.Write(&out, service.Describe(testMonitors(), scaling, tc.withOptions), tc.format); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			checkGolden(t, tc.name, out.Bytes())
+		})
+	}
+}
+
+func TestToMonitorRoundTrip(t *testing.T) {
+	scaling := monitor.NewScalingManager()
+	doc := service.Describe(testMonitors(), scaling, false)
+	for i, mon := range doc.Monitors {
+		if got := service.ToMonitor(mon); got != testMonitors()[i] {
+			t.Errorf("Expected %+v back, got %+v", testMonitors()[i], got)
+		}
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := schema.Write(&bytes.Buffer{}, schema.Document{}, "xml"); err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("Expected an unknown format error, got %v", err)
+	}
+}
+
+// TestSchemaMatchesTypes keeps the hand-written schema and the Go types in
+// step: every field is described with the right type, every property is a
+// field, and exactly the fields without omitempty are required.
+func TestSchemaMatchesTypes(t *testing.T) {
+	var root map[string]any
+	if err := json.Unmarshal(schema.JSONSchema, &root); err != nil {
+		t.Fatalf("The schema isn't valid JSON: %v", err)
+	}
+	defs := root["$defs"].(map[string]any)
+
+	var check func(path string, node map[string]any, typ reflect.Type)
+	check = func(path string, node map[string]any, typ reflect.Type) {
+		if ref, ok := node["$ref"].(string); ok {
+			node = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		}
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			properties, _ := node["properties"].(map[string]any)
+			var required, fields []string
+			for i := 0; i < typ.NumField(); i++ {
+				field := typ.Field(i)
+				name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if yamlName, _, _ := strings.Cut(field.Tag.Get("yaml"), ","); yamlName != name {
+					t.Errorf("%s.%s: yaml name %q differs from json name %q", path, field.Name, yamlName, name)
+				}
+				fields = append(fields, name)
+				if opts != "omitempty" {
+					required = append(required, name)
+				}
+				property, ok := properties[name].(map[string]any)
+				if !ok {
+					t.Errorf("%s: %s isn't in the schema", path, name)
+					continue
+				}
+				check(path+"."+name, property, field.Type)
+			}
+			for name := range properties {
+				if !contains(fields, name) {
+					t.Errorf("%s: the schema has %s, which isn't a field", path, name)
+				}
+			}
+			var schemaRequired []string
+			for _, name := range node["required"].([]any) {
+				schemaRequired = append(schemaRequired, name.(string))
+			}
+			sort.Strings(required)
+			sort.Strings(schemaRequired)
+			if !reflect.DeepEqual(required, schemaRequired) {
+				t.Errorf("%s: required is %v, want %v", path, schemaRequired, required)
+			}
+		case reflect.Slice:
+			expectType(t, path, node, "array")
+			check(path+"[]", node["items"].(map[string]any), typ.Elem())
+		case reflect.String:
+			expectType(t, path, node, "string")
+		case reflect.Bool:
+			expectType(t, path, node, "boolean")
+		case reflect.Float64:
+			expectType(t, path, node, "number")
+		case reflect.Int:
+			if _, isConst := node["const"]; !isConst {
+				expectType(t, path, node, "integer")
+			}
+		default:
+			t.Errorf("%s: no schema type for %s", path, typ)
+		}
+	}
+
+	check("document", root, reflect.TypeOf(schema.Document{}))
+
+	if version := root["properties"].(map[string]any)["schema_version"].(map[string]any)["const"]; version != float64(schema.Version) {
+		t.Errorf("The schema pins version %v, but Version is %d", version, schema.Version)
+	}
+}
+
+func expectType(t *testing.T, path string, node map[string]any, want string) {
+	t.Helper()
+	if got := node["type"]; got != want {
+		t.Errorf("%s: schema type is %v, want %s", path, got, want)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
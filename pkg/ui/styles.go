@@ -6,15 +6,18 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	colorComment = lipgloss.Color("8")
-	colorSubtle  = lipgloss.Color("8")
-	colorBlue    = lipgloss.Color("4")
-	colorCyan    = lipgloss.Color("6")
-	colorGreen   = lipgloss.Color("2")
-	colorYellow  = lipgloss.Color("3")
-	colorRed     = lipgloss.Color("1")
-	colorMagenta = lipgloss.Color("5")
+// The terminal's ANSI colours by what they're used for, so text follows
+// whatever colour scheme the terminal has. The TUI's default terminal
+// palette is built from them.
+const (
+	ColorComment = lipgloss.Color("8")
+	ColorSubtle  = lipgloss.Color("7")
+	ColorBlue    = lipgloss.Color("4")
+	ColorCyan    = lipgloss.Color("6")
+	ColorGreen   = lipgloss.Color("2")
+	ColorYellow  = lipgloss.Color("3")
+	ColorRed     = lipgloss.Color("1")
+	ColorMagenta = lipgloss.Color("5")
 )
 
 func Title(text string, color lipgloss.Color) string {
@@ -22,11 +25,11 @@ func Title(text string, color lipgloss.Color) string {
 }
 
 func Subtitle(text string) string {
-	return lipgloss.NewStyle().Foreground(colorSubtle).Render(text)
+	return lipgloss.NewStyle().Foreground(ColorSubtle).Render(text)
 }
 
 func Comment(text string) string {
-	return lipgloss.NewStyle().Foreground(colorComment).Render(text)
+	return lipgloss.NewStyle().Foreground(ColorComment).Render(text)
 }
 
 func Colored(text string, color lipgloss.Color) string {
@@ -58,19 +61,19 @@ func NavKey(key, description string, keyColor lipgloss.Color) string {
 }
 
 func StatusAvailable() string {
-	return Colored("✓ Available", colorGreen)
+	return Colored("✓ Available", ColorGreen)
 }
 
 func StatusNotFound() string {
-	return Colored("✗ Not found", colorRed)
+	return Colored("✗ Not found", ColorRed)
 }
 
 func StatusDemo() string {
-	return Colored("Demo", colorYellow)
+	return Colored("Demo", ColorYellow)
 }
 
 func StatusLive() string {
-	return Colored("Live", colorGreen)
+	return Colored("Live", ColorGreen)
 }
 
 func MonitorDetails(makeVal, modelVal string) string {
@@ -90,29 +93,29 @@ func Selector(color lipgloss.Color) string {
 }
 
 func ScaleValue(scale float64) string {
-	return Colored(fmt.Sprintf("%.2fx", scale), colorGreen)
+	return Colored(fmt.Sprintf("%.2fx", scale), ColorGreen)
 }
 
 func GTKScaleValue(scale int) string {
-	return Colored(fmt.Sprintf("%dx", scale), colorMagenta)
+	return Colored(fmt.Sprintf("%dx", scale), ColorMagenta)
 }
 
 func DPIValue(dpi int) string {
-	return Colored(fmt.Sprintf("%d", dpi), colorYellow)
+	return Colored(fmt.Sprintf("%d", dpi), ColorYellow)
 }
 
 func VersionValue(version string) string {
-	return Colored(version, colorGreen)
+	return Colored(version, ColorGreen)
 }
 
 func ThemeValue(theme string) string {
-	return Colored(theme, colorMagenta)
+	return Colored(theme, ColorMagenta)
 }
 
 func TargetValue(target string) string {
-	return Colored(target, colorCyan)
+	return Colored(target, ColorCyan)
 }
 
 func BuiltWithValue(tech string) string {
-	return Colored(tech, colorBlue)
+	return Colored(tech, ColorBlue)
 }
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	if got := UnifiedDiff("same", "a\nb\n", "a\nb\n"); got != "" {
+		t.Errorf("Expected empty diff for identical content, got:\n%s", got)
+	}
+
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	after := "1\n2 changed\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15 changed\n16\n"
+
+	expected := strings.Join([]string{
+		"--- a/config",
+		"+++ b/config",
+		"@@ -1,5 +1,5 @@",
+		" 1",
+		"-2",
+		"+2 changed",
+		" 3",
+		" 4",
+		" 5",
+		"@@ -12,5 +12,5 @@",
+		" 12",
+		" 13",
+		" 14",
+		"-15",
+		"+15 changed",
+		" 16",
+		"",
+	}, "\n")
+
+	if got := UnifiedDiff("config", before, after); got != expected {
+		t.Errorf("Unexpected diff:\n--- got ---\n%s\n--- expected ---\n%s", got, expected)
+	}
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	expected := "--- a/new\n+++ b/new\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	if got := UnifiedDiff("new", "", "a\nb\n"); got != expected {
+		t.Errorf("Unexpected diff:\n%s", got)
+	}
+}
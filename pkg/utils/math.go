@@ -61,3 +61,28 @@ func FindNextValidScale(current float64, up bool, validScales []float64) float64
 	}
 	return validScales[0]
 }
+
+// NearestValidScale returns the valid scale closest to scale that divides the
+// resolution into whole logical pixels, which is what Hyprland requires. If
+// none of them divide it cleanly, the closest valid scale is returned.
+func NearestValidScale(scale float64, width, height int, validScales []float64) float64 {
+	best, bestFits := 0.0, false
+	for _, candidate := range validScales {
+		fits := dividesEvenly(width, candidate) && dividesEvenly(height, candidate)
+		switch {
+		case best == 0,
+			fits && !bestFits,
+			fits == bestFits && math.Abs(candidate-scale) < math.Abs(best-scale):
+			best, bestFits = candidate, fits
+		}
+	}
+	return best
+}
+
+func dividesEvenly(pixels int, scale float64) bool {
+	if pixels <= 0 {
+		return true
+	}
+	logical := float64(pixels) / scale
+	return math.Abs(logical-math.Round(logical)) < 0.01
+}
@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestNearestValidScale(t *testing.T) {
+	valid := []float64{1.0, 1.25, 1.33333, 1.5, 1.66667, 1.75, 2.0}
+
+	tests := []struct {
+		name          string
+		scale         float64
+		width, height int
+		expected      float64
+	}{
+		{"already valid", 1.5, 2880, 1920, 1.5},
+		{"between two valid scales", 1.3, 2560, 1440, 1.33333},
+		{"closest does not divide the resolution", 1.22, 2256, 1504, 1.33333},
+		{"unknown resolution", 1.7, 0, 0, 1.66667},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NearestValidScale(tt.scale, tt.width, tt.height, valid); got != tt.expected {
+				t.Errorf("NearestValidScale(%.2f, %d, %d) = %.5f, expected %.5f", tt.scale, tt.width, tt.height, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const appDirName = "omarchy-monitor-settings"
+
+func HomeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return os.Getenv("HOME")
+}
+
+func ConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(HomeDir(), ".config")
+}
+
+func StateHome() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(HomeDir(), ".local", "state")
+}
+
+// StateDir is where the application keeps its own bookkeeping
+// (font baselines, change journal, logs).
+func StateDir() string {
+	return filepath.Join(StateHome(), appDirName)
+}
+
+// ConfigDir holds the application's own settings file.
+func ConfigDir() string {
+	return filepath.Join(ConfigHome(), appDirName)
+}